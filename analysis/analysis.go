@@ -2,8 +2,11 @@
 package analysis
 
 import (
+	"context"
+	"github.com/mongoeye/mongoeye/mongo/driver"
 	"github.com/mongoeye/mongoeye/mongo/expr"
-	"gopkg.in/mgo.v2"
+	"strconv"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,34 +16,60 @@ const NameSeparator = "."
 // ArrayItemMark represents array item in full field name
 const ArrayItemMark = "[]"
 
+// MapKeyMark represents any key of object with dynamic keys (map) in full field name
+const MapKeyMark = "{key}"
+
+// ArrayPositionMark represents array item at the position in full field name, eg. "[0]"
+func ArrayPositionMark(position uint) string {
+	return "[" + strconv.FormatUint(uint64(position), 10) + "]"
+}
+
+// IsArrayPositionMark returns true if the segment of full field name is ArrayPositionMark.
+func IsArrayPositionMark(segment string) bool {
+	if len(segment) < 3 || segment[0] != '[' || segment[len(segment)-1] != ']' {
+		return false
+	}
+	_, err := strconv.ParseUint(segment[1:len(segment)-1], 10, 64)
+	return err == nil
+}
+
 // AggregationMinVersion is minimal MongoDB version that allows analysis using aggregation framework
 var AggregationMinVersion = []int{3, 5, 10}
 
 // AggregationMinVersionStr (string) is minimal MongoDB version that allows analysis using aggregation framework
 var AggregationMinVersionStr = "3.5.10"
 
+// PercentileMinVersion is minimal MongoDB version that allows computing quantiles using $percentile accumulator
+var PercentileMinVersion = []int{7, 0, 0}
+
+// RegexMatchMinVersion is minimal MongoDB version that allows counting string formats using $regexMatch
+var RegexMatchMinVersion = []int{4, 2, 0}
+
 // RandomSampleMinVersion is minimal MongoDB version that allows analysis using random samples
 var RandomSampleMinVersion = []int{3, 2, 0}
 
 // RandomSampleMinVersionStr (string) is minimal MongoDB version that allows analysis using random samples
 var RandomSampleMinVersionStr = "3.2.0"
 
-// Analysis consists of the options, target collection and the four contiguous stages.
+// Analysis consists of the options, source of documents and the four contiguous stages.
 type Analysis struct {
-	options     *Options        // common options
-	collection  *mgo.Collection // target collection
-	sampleStage *Stage          // data sampling
-	expandStage *Stage          // extract values from document fields
-	groupStage  *Stage          // grouping values with the same field name and type, aggregation calculation
-	mergeStage  *Stage          // merge different types of the same field
+	options     *Options // common options
+	source      Source   // source of documents, eg. collection
+	sampleStage *Stage   // data sampling
+	expandStage *Stage   // extract values from document fields
+	groupStage  *Stage   // grouping values with the same field name and type, aggregation calculation
+	mergeStage  *Stage   // merge different types of the same field
+	ctx         context.Context
+	errors      *runErrors
 }
 
 // Options for all stages of analysis.
 type Options struct {
-	Location    *time.Location // time location for calculations with dates
-	Concurrency int            // number of parallel processes for local calculations
-	BufferSize  int            // buffer size between phases
-	BatchSize   int            // number of documents in one batch from the database
+	Location      *time.Location // time location for calculations with dates
+	Concurrency   int            // number of parallel processes for local calculations
+	BufferSize    int            // buffer size between phases
+	BatchSize     int            // number of documents in one batch from the database
+	SkipCorrupted bool           // skip and count corrupted documents instead of stopping analysis
 }
 
 // Stage can be represented by a pipeline that runs in the database
@@ -53,13 +82,17 @@ type Stage struct {
 }
 
 // PipelineFactory generate pipeline according analysis options.
-type PipelineFactory func(analysisOptions *Options) *expr.Pipeline
+// Error is returned if options are invalid.
+type PipelineFactory func(analysisOptions *Options) (*expr.Pipeline, error)
 
 // Processor function has a channel from the previous stage at its input.
 // Return value is output channel that fed to the next stage.
 // The input of the first stage is raw (binary) data from the database.
 // The output of the last stage are the final results.
-type Processor func(inputCh interface{}, options *Options) interface{}
+// When the context is done, processor must drain the input channel without processing and close the output channel.
+// Error is returned if the processor cannot be started, eg. options are invalid.
+// Errors that occur later during processing are reported by Fail or Corrupted function.
+type Processor func(ctx context.Context, inputCh interface{}, options *Options) (interface{}, error)
 
 // NewAnalysis - analysis factory.
 func NewAnalysis(options *Options) Analysis {
@@ -96,12 +129,21 @@ func (a *Analysis) SetMergeStage(stage *Stage) {
 }
 
 // SetCollection set target collection.
-func (a *Analysis) SetCollection(c *mgo.Collection) {
-	a.collection = c
+func (a *Analysis) SetCollection(c driver.Collection) {
+	a.source = NewCollectionSource(c)
+}
+
+// SetSource set source of documents.
+// Source may be a collection or some offline data, eg. BSON files.
+func (a *Analysis) SetSource(s Source) {
+	a.source = s
 }
 
-// Run the analysis on the selected collection.
-func (a *Analysis) Run() interface{} {
+// Run the analysis on the selected source.
+// If the context is done, then the source stops reading and stages return partial results.
+// Error is returned if stages cannot be linked together.
+// Errors that occur during processing stop the analysis, they are available by Err method.
+func (a *Analysis) Run(ctx context.Context) (interface{}, error) {
 	stages := []*Stage{
 		a.sampleStage,
 		a.expandStage,
@@ -109,15 +151,40 @@ func (a *Analysis) Run() interface{} {
 		a.mergeStage,
 	}
 
-	dbPipeline, in, out := LinkStages(stages, a.options)
+	ctx, a.errors = newRunContext(ctx, a.options)
+	a.ctx = ctx
 
-	dbPipeline.ToRawChannel(
-		a.collection,
-		in,
-		a.options.Concurrency,
-		a.options.BufferSize,
-		a.options.BatchSize,
-	)
+	dbPipeline, in, out, err := LinkStages(ctx, stages, a.options)
+	if err != nil {
+		a.errors.cancel()
+		return nil, err
+	}
+
+	a.source.ToRawChannel(ctx, dbPipeline, in, a.options)
+
+	return out, nil
+}
+
+// Context returns the context of the last run, it is used to process the output channel.
+// Errors reported by Fail to this context are returned by Err method.
+func (a *Analysis) Context() context.Context {
+	return a.ctx
+}
 
-	return out
+// Err returns the first error that stopped the analysis.
+// It must be called after the output channel has been read.
+func (a *Analysis) Err() error {
+	if a.errors == nil {
+		return nil
+	}
+	return a.errors.getErr()
+}
+
+// CorruptedCount returns number of skipped corrupted documents.
+// It must be called after the output channel has been read.
+func (a *Analysis) CorruptedCount() uint64 {
+	if a.errors == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&a.errors.corruptedCount)
 }
@@ -1,6 +1,7 @@
 package analysis
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/helpers"
 	"github.com/mongoeye/mongoeye/tests"
 	"github.com/stretchr/testify/assert"
@@ -53,7 +54,7 @@ func TestAnalysis_SetMergeStage(t *testing.T) {
 }
 
 func TestAnalysis_Run(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	c.Insert(
@@ -62,7 +63,7 @@ func TestAnalysis_Run(t *testing.T) {
 	)
 
 	sampleStage := &Stage{
-		Processor: func(inputCh interface{}, options *Options) interface{} {
+		Processor: func(ctx context.Context, inputCh interface{}, options *Options) (interface{}, error) {
 			outCh := make(chan bson.M)
 
 			if ch, ok := inputCh.(chan []byte); ok {
@@ -78,12 +79,12 @@ func TestAnalysis_Run(t *testing.T) {
 				panic("Unexpected type.")
 			}
 
-			return outCh
+			return outCh, nil
 		},
 	}
 
 	expandStage := &Stage{
-		Processor: func(inputCh interface{}, options *Options) interface{} {
+		Processor: func(ctx context.Context, inputCh interface{}, options *Options) (interface{}, error) {
 			outCh := make(chan bson.M)
 
 			if ch, ok := inputCh.(chan bson.M); ok {
@@ -99,12 +100,12 @@ func TestAnalysis_Run(t *testing.T) {
 				panic("Unexpected type.")
 			}
 
-			return outCh
+			return outCh, nil
 		},
 	}
 
 	groupStage := &Stage{
-		Processor: func(inputCh interface{}, options *Options) interface{} {
+		Processor: func(ctx context.Context, inputCh interface{}, options *Options) (interface{}, error) {
 			outCh := make(chan bson.M)
 
 			if ch, ok := inputCh.(chan bson.M); ok {
@@ -120,12 +121,12 @@ func TestAnalysis_Run(t *testing.T) {
 				panic("Unexpected type.")
 			}
 
-			return outCh
+			return outCh, nil
 		},
 	}
 
 	mergeStage := &Stage{
-		Processor: func(inputCh interface{}, options *Options) interface{} {
+		Processor: func(ctx context.Context, inputCh interface{}, options *Options) (interface{}, error) {
 			outCh := make(chan bson.M)
 
 			if ch, ok := inputCh.(chan bson.M); ok {
@@ -141,7 +142,7 @@ func TestAnalysis_Run(t *testing.T) {
 				panic("Unexpected type.")
 			}
 
-			return outCh
+			return outCh, nil
 		},
 	}
 
@@ -151,13 +152,14 @@ func TestAnalysis_Run(t *testing.T) {
 		BufferSize:  100,
 		BatchSize:   50,
 	})
-	analysis.SetCollection(c)
+	analysis.SetCollection(tests.Collection(c))
 	analysis.SetSampleStage(sampleStage)
 	analysis.SetExpandStage(expandStage)
 	analysis.SetGroupStage(groupStage)
 	analysis.SetMergeStage(mergeStage)
 
-	outCh := analysis.Run()
+	outCh, err := analysis.Run(context.Background())
+	assert.Nil(t, err)
 
 	if ch, ok := outCh.(chan bson.M); ok {
 		assert.Equal(t, bson.M{"test": "abc_e_g_m"}, <-ch)
@@ -12,10 +12,16 @@ var (
 	BsonMinValue            string
 	BsonMaxValue            string
 	BsonAvgValue            string
+	BsonSumValue            string
+	BsonVarianceValue       string
+	BsonStdDevValue         string
+	BsonValueQuantiles      string
 	BsonLengthStats         string
 	BsonMinLength           string
 	BsonMaxLength           string
 	BsonAvgLength           string
+	BsonLengthQuantiles     string
+	BsonQuantiles           []string
 	BsonMostFrequent        string
 	BsonLeastFrequent       string
 	BsonValueFreqValue      string
@@ -24,6 +30,10 @@ var (
 	BsonLengthHistogram     string
 	BsonWeekdayHistogram    string
 	BsonHourHistogram       string
+	BsonStringFormats       string
+	BsonStringFormatKeys    []string
+	BsonTruncation          string
+	BsonTruncationKeys      []string
 	BsonHistogramStart      string
 	BsonHistogramEnd        string
 	BsonHistogramRange      string
@@ -52,9 +62,12 @@ func init() {
 	t := Type{}
 	v := ValueStats{}
 	l := LengthStats{}
+	q := Quantiles{}
 	f := ValueFreq{}
 	h := Histogram{}
 	i := Interval{}
+	sf := StringFormats{}
+	tr := Truncation{}
 
 	BsonId = "_id"
 	BsonFieldType = helpers.GetBSONFieldName(t, "Name")
@@ -64,10 +77,24 @@ func init() {
 	BsonMinValue = helpers.GetBSONFieldName(v, "Min")
 	BsonMaxValue = helpers.GetBSONFieldName(v, "Max")
 	BsonAvgValue = helpers.GetBSONFieldName(v, "Avg")
+	BsonSumValue = helpers.GetBSONFieldName(v, "Sum")
+	BsonVarianceValue = helpers.GetBSONFieldName(v, "Variance")
+	BsonStdDevValue = helpers.GetBSONFieldName(v, "StdDev")
+	BsonValueQuantiles = helpers.GetBSONFieldName(v, "Quantiles")
 	BsonLengthStats = helpers.GetBSONFieldName(t, "LengthStats")
 	BsonMinLength = helpers.GetBSONFieldName(l, "Min")
 	BsonMaxLength = helpers.GetBSONFieldName(l, "Max")
 	BsonAvgLength = helpers.GetBSONFieldName(l, "Avg")
+	BsonLengthQuantiles = helpers.GetBSONFieldName(l, "Quantiles")
+	BsonQuantiles = []string{
+		helpers.GetBSONFieldName(q, "P5"),
+		helpers.GetBSONFieldName(q, "P25"),
+		helpers.GetBSONFieldName(q, "Median"),
+		helpers.GetBSONFieldName(q, "P75"),
+		helpers.GetBSONFieldName(q, "P90"),
+		helpers.GetBSONFieldName(q, "P95"),
+		helpers.GetBSONFieldName(q, "P99"),
+	}
 	BsonMostFrequent = helpers.GetBSONFieldName(t, "MostFrequent")
 	BsonLeastFrequent = helpers.GetBSONFieldName(t, "LeastFrequent")
 	BsonValueFreqValue = helpers.GetBSONFieldName(f, "Value")
@@ -84,6 +111,25 @@ func init() {
 	BsonIntervalCount = helpers.GetBSONFieldName(i, "Count")
 	BsonWeekdayHistogram = helpers.GetBSONFieldName(t, "WeekdayHistogram")
 	BsonHourHistogram = helpers.GetBSONFieldName(t, "HourHistogram")
+	BsonStringFormats = helpers.GetBSONFieldName(t, "StringFormats")
+	BsonStringFormatKeys = []string{
+		helpers.GetBSONFieldName(sf, "Empty"),
+		helpers.GetBSONFieldName(sf, "Integer"),
+		helpers.GetBSONFieldName(sf, "Decimal"),
+		helpers.GetBSONFieldName(sf, "Date"),
+		helpers.GetBSONFieldName(sf, "UUID"),
+		helpers.GetBSONFieldName(sf, "ObjectId"),
+		helpers.GetBSONFieldName(sf, "Email"),
+		helpers.GetBSONFieldName(sf, "URL"),
+		helpers.GetBSONFieldName(sf, "IP"),
+	}
+	BsonTruncation = helpers.GetBSONFieldName(t, "Truncation")
+	BsonTruncationKeys = []string{
+		helpers.GetBSONFieldName(tr, "Depth"),
+		helpers.GetBSONFieldName(tr, "Arrays"),
+		helpers.GetBSONFieldName(tr, "Items"),
+		helpers.GetBSONFieldName(tr, "MaxItems"),
+	}
 
 	JsonHistogramStart = helpers.GetJSONFieldName(h, "Start")
 	JsonHistogramEnd = helpers.GetJSONFieldName(h, "End")
@@ -0,0 +1,69 @@
+package analysis
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// Errors of one analysis run.
+// Stages and sources run in goroutines, so they cannot return errors directly.
+// The first reported error cancels the context of the run and all stages stop.
+type runErrors struct {
+	cancel         context.CancelFunc
+	skipCorrupted  bool
+	mutex          sync.Mutex
+	err            error
+	corruptedCount uint64
+}
+
+type runErrorsKey struct{}
+
+// Create context of analysis run.
+func newRunContext(ctx context.Context, options *Options) (context.Context, *runErrors) {
+	ctx, cancel := context.WithCancel(ctx)
+	e := &runErrors{cancel: cancel, skipCorrupted: options.SkipCorrupted}
+	return context.WithValue(ctx, runErrorsKey{}, e), e
+}
+
+func (e *runErrors) fail(err error) {
+	e.mutex.Lock()
+	if e.err == nil {
+		e.err = err
+	}
+	e.mutex.Unlock()
+
+	e.cancel()
+}
+
+func (e *runErrors) getErr() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.err
+}
+
+// Fail stops the analysis with error, only the first error is kept.
+// It is used in goroutines of stages and sources.
+// If the context does not belong to the analysis run, then the error is only logged,
+// there is no run to cancel and the caller skips the failed item.
+func Fail(ctx context.Context, err error) {
+	if e, ok := ctx.Value(runErrorsKey{}).(*runErrors); ok {
+		e.fail(err)
+		return
+	}
+
+	log.Printf("Analysis error: %s", err)
+}
+
+// Corrupted reports corrupted document.
+// If the SkipCorrupted option is enabled, then the document is only counted.
+// Otherwise the analysis fails with the error.
+func Corrupted(ctx context.Context, err error) {
+	if e, ok := ctx.Value(runErrorsKey{}).(*runErrors); ok && e.skipCorrupted {
+		atomic.AddUint64(&e.corruptedCount, 1)
+		return
+	}
+
+	Fail(ctx, err)
+}
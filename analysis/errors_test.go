@@ -0,0 +1,53 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestFail(t *testing.T) {
+	ctx, e := newRunContext(context.Background(), &Options{})
+
+	Fail(ctx, errors.New("first"))
+	Fail(ctx, errors.New("second"))
+
+	assert.Equal(t, context.Canceled, ctx.Err())
+	assert.Equal(t, "first", e.getErr().Error())
+}
+
+func TestFail_WithoutRun(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	log.SetOutput(out)
+	defer log.SetOutput(os.Stderr)
+
+	assert.NotPanics(t, func() {
+		Fail(context.Background(), errors.New("error"))
+	})
+	assert.Contains(t, out.String(), "Analysis error: error")
+}
+
+func TestCorrupted(t *testing.T) {
+	ctx, e := newRunContext(context.Background(), &Options{})
+
+	Corrupted(ctx, errors.New("corrupted"))
+
+	assert.Equal(t, context.Canceled, ctx.Err())
+	assert.Equal(t, "corrupted", e.getErr().Error())
+	assert.Equal(t, uint64(0), e.corruptedCount)
+}
+
+func TestCorrupted_Skip(t *testing.T) {
+	ctx, e := newRunContext(context.Background(), &Options{SkipCorrupted: true})
+
+	Corrupted(ctx, errors.New("corrupted"))
+	Corrupted(ctx, errors.New("corrupted"))
+
+	assert.Nil(t, ctx.Err())
+	assert.Nil(t, e.getErr())
+	assert.Equal(t, uint64(2), e.corruptedCount)
+}
@@ -0,0 +1,99 @@
+package analysis
+
+import "strings"
+
+// Keys of objects are escaped in full field names, so the names are unambiguous.
+// Empty keys, keys containing NameSeparator and keys starting with KeyQuote, "[" or "{" (that could be confused with marks)
+// are enclosed in KeyQuote, eg. key "a.b" is `"a.b"` and nested key "c" is `"a.b".c`.
+// Inside quotes, KeyQuote and KeyEscape are escaped by KeyEscape.
+const (
+	KeyQuote  = `"`
+	KeyEscape = `\`
+)
+
+// KeyQuotedPrefixes - first characters of keys that are quoted.
+var KeyQuotedPrefixes = []string{KeyQuote, "[", "{"}
+
+// EscapeKey returns key of object as segment of full field name.
+func EscapeKey(key string) string {
+	if !KeyNeedsQuote(key) {
+		return key
+	}
+
+	key = strings.Replace(key, KeyEscape, KeyEscape+KeyEscape, -1)
+	key = strings.Replace(key, KeyQuote, KeyEscape+KeyQuote, -1)
+	return KeyQuote + key + KeyQuote
+}
+
+// KeyNeedsQuote returns true if the key is quoted in full field name, see EscapeKey.
+func KeyNeedsQuote(key string) bool {
+	if key == "" || strings.Contains(key, NameSeparator) {
+		return true
+	}
+	for _, prefix := range KeyQuotedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnescapeKey returns key of object from segment of full field name, marks are returned unchanged.
+func UnescapeKey(segment string) string {
+	if len(segment) < 2 || !strings.HasPrefix(segment, KeyQuote) || !strings.HasSuffix(segment, KeyQuote) {
+		return segment
+	}
+
+	key := make([]byte, 0, len(segment)-2)
+	for i := 1; i < len(segment)-1; i++ {
+		if segment[i] == KeyEscape[0] && i+1 < len(segment)-1 {
+			i++
+		}
+		key = append(key, segment[i])
+	}
+	return string(key)
+}
+
+// IsMark returns true if the segment of full field name is ArrayItemMark, ArrayPositionMark or MapKeyMark, not a key.
+func IsMark(segment string) bool {
+	return segment == ArrayItemMark || segment == MapKeyMark || IsArrayPositionMark(segment)
+}
+
+// SplitName splits full field name to segments, quoted keys are not split, see EscapeKey.
+func SplitName(name string) []string {
+	segments := make([]string, 0, strings.Count(name, NameSeparator)+1)
+
+	start := 0
+	quoted := false
+	for i := 0; i < len(name); i++ {
+		switch {
+		case quoted && name[i] == KeyEscape[0]:
+			i++
+		case name[i] == KeyQuote[0] && (quoted || i == start):
+			quoted = !quoted
+		case !quoted && name[i] == NameSeparator[0]:
+			segments = append(segments, name[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(segments, name[start:])
+}
+
+// JoinName joins segments to full field name, keys must be already escaped.
+func JoinName(segments ...string) string {
+	return strings.Join(segments, NameSeparator)
+}
+
+// NameLevel returns level of the field, root level is zero.
+func NameLevel(name string) uint {
+	return uint(len(SplitName(name)) - 1)
+}
+
+// SplitParentName splits full field name to the name of the parent and the last segment.
+// Name of the parent of root field is empty.
+func SplitParentName(name string) (parent string, segment string) {
+	segments := SplitName(name)
+	last := len(segments) - 1
+	return JoinName(segments[:last]...), segments[last]
+}
@@ -0,0 +1,74 @@
+package analysis
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEscapeKey(t *testing.T) {
+	assert.Equal(t, "abc", EscapeKey("abc"))
+	assert.Equal(t, "$x", EscapeKey("$x"))
+	assert.Equal(t, `a\b`, EscapeKey(`a\b`))
+	assert.Equal(t, `a"b`, EscapeKey(`a"b`))
+	assert.Equal(t, `"a.b"`, EscapeKey("a.b"))
+	assert.Equal(t, `""`, EscapeKey(""))
+	assert.Equal(t, `"[]"`, EscapeKey("[]"))
+	assert.Equal(t, `"[0]"`, EscapeKey("[0]"))
+	assert.Equal(t, `"{key}"`, EscapeKey("{key}"))
+	assert.Equal(t, `"\"q"`, EscapeKey(`"q`))
+	assert.Equal(t, `"a\\.b"`, EscapeKey(`a\.b`))
+}
+
+func TestUnescapeKey(t *testing.T) {
+	keys := []string{"abc", "$x", `a\b`, `a"b`, "a.b", "", "[]", "[0]", "{key}", `"q`, `a\.b`, `"`, `\`}
+	for _, key := range keys {
+		assert.Equal(t, key, UnescapeKey(EscapeKey(key)), key)
+	}
+
+	assert.Equal(t, ArrayItemMark, UnescapeKey(ArrayItemMark))
+	assert.Equal(t, MapKeyMark, UnescapeKey(MapKeyMark))
+}
+
+func TestIsMark(t *testing.T) {
+	assert.True(t, IsMark(ArrayItemMark))
+	assert.True(t, IsMark(MapKeyMark))
+	assert.True(t, IsMark("[3]"))
+	assert.False(t, IsMark(EscapeKey("[]")))
+	assert.False(t, IsMark("abc"))
+}
+
+func TestSplitName(t *testing.T) {
+	assert.Equal(t, []string{"abc"}, SplitName("abc"))
+	assert.Equal(t, []string{"a", "b", "[]", "c"}, SplitName("a.b.[].c"))
+	assert.Equal(t, []string{"x", `"a.b"`, "c"}, SplitName(`x."a.b".c`))
+	assert.Equal(t, []string{`""`, `""`}, SplitName(`"".""`))
+	assert.Equal(t, []string{`"a\".b"`, "c"}, SplitName(`"a\".b".c`))
+	assert.Equal(t, []string{`"a\\"`, "b"}, SplitName(`"a\\".b`))
+	assert.Equal(t, []string{`a"b`, "c"}, SplitName(`a"b.c`))
+}
+
+func TestJoinName(t *testing.T) {
+	assert.Equal(t, "abc", JoinName("abc"))
+	assert.Equal(t, `x."a.b".c`, JoinName("x", EscapeKey("a.b"), "c"))
+}
+
+func TestNameLevel(t *testing.T) {
+	assert.Equal(t, uint(0), NameLevel("abc"))
+	assert.Equal(t, uint(0), NameLevel(`"a.b"`))
+	assert.Equal(t, uint(2), NameLevel(`x."a.b".c`))
+	assert.Equal(t, uint(3), NameLevel("a.[].{key}.b"))
+}
+
+func TestSplitParentName(t *testing.T) {
+	parent, segment := SplitParentName("abc")
+	assert.Equal(t, "", parent)
+	assert.Equal(t, "abc", segment)
+
+	parent, segment = SplitParentName(`x."a.b"`)
+	assert.Equal(t, "x", parent)
+	assert.Equal(t, `"a.b"`, segment)
+
+	parent, segment = SplitParentName(`"a.b".c`)
+	assert.Equal(t, `"a.b"`, parent)
+	assert.Equal(t, "c", segment)
+}
@@ -1,6 +1,7 @@
 package analysis
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/mongoeye/mongoeye/helpers"
 	"gopkg.in/mgo.v2/bson"
@@ -12,15 +13,70 @@ import (
 type Fields []*Field
 
 func (r Fields) Len() int           { return len(r) }
-func (r Fields) Less(i, j int) bool { return strings.ToLower(r[i].Name) < strings.ToLower(r[j].Name) }
+func (r Fields) Less(i, j int) bool { return lessName(r[i].Name, r[j].Name) }
 func (r Fields) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
 
+// Names are compared by segments, so nested fields always follow their parent.
+func lessName(a string, b string) bool {
+	sa, sb := SplitName(a), SplitName(b)
+	for k := 0; k < len(sa) && k < len(sb); k++ {
+		if x, y := strings.ToLower(sa[k]), strings.ToLower(sb[k]); x != y {
+			return x < y
+		}
+	}
+	return len(sa) < len(sb)
+}
+
+// ComputePresence computes Missing and PresentInParent of all fields.
+// Parent of root field is the document, parent of array item is the array, otherwise parent is the object.
+func (r Fields) ComputePresence(docsCount uint64) {
+	byName := make(map[string]*Field, len(r))
+	for _, f := range r {
+		byName[f.Name] = f
+	}
+
+	for _, f := range r {
+		if f.DocsCount < docsCount {
+			f.Missing = docsCount - f.DocsCount
+		} else {
+			f.Missing = 0
+		}
+
+		parentsCount := docsCount
+		if parentName, segment := SplitParentName(f.Name); parentName != "" {
+			parentType := "object"
+			if segment == ArrayItemMark || IsArrayPositionMark(segment) {
+				parentType = "array"
+			}
+
+			parentsCount = 0
+			if parent := byName[parentName]; parent != nil {
+				for _, t := range parent.Types {
+					if t.Name == parentType {
+						parentsCount = t.Count
+					}
+				}
+			}
+		}
+
+		if parentsCount > 0 {
+			f.PresentInParent = float64(f.ParentsCount) / float64(parentsCount) * 100
+		} else {
+			f.PresentInParent = 0
+		}
+	}
+}
+
 // Field - analysis results for one document field.
 type Field struct {
-	Name  string `json:"name"   yaml:"name"    bson:"n"`
-	Level uint   `json:"level"  yaml:"level"`
-	Count uint64 `json:"count"  yaml:"count"   bson:"c"`
-	Types Types  `json:"types"  yaml:"types"   bson:"T"`
+	Name            string  `json:"name"             yaml:"name"             bson:"n"`
+	Level           uint    `json:"level"            yaml:"level"`
+	Count           uint64  `json:"count"            yaml:"count"            bson:"c"`
+	DocsCount       uint64  `json:"docs"             yaml:"docs"             bson:"d"` // number of documents that contain the field
+	ParentsCount    uint64  `json:"parents"          yaml:"parents"          bson:"p"` // number of parent objects or arrays that contain the field
+	Missing         uint64  `json:"missing"          yaml:"missing"`                   // number of documents without the field, see Fields.ComputePresence
+	PresentInParent float64 `json:"presentInParent"  yaml:"presentInParent"`           // percentage of parent objects or arrays that contain the field
+	Types           Types   `json:"types"            yaml:"types"            bson:"T"`
 }
 
 // Types of the one document field.
@@ -60,6 +116,7 @@ type Type struct {
 	Name             string            `json:"type"                          yaml:"type"                          bson:"t"`
 	Count            uint64            `json:"count"                         yaml:"count"                         bson:"c"`
 	CountUnique      uint64            `json:"unique,omitempty"              yaml:"unique,omitempty"              bson:"cu,omitempty"`
+	CountUniqueError float64           `json:"uniqueError,omitempty"         yaml:"uniqueError,omitempty"         bson:"cuE,omitempty"` // relative standard error of estimated CountUnique in percent, zero = exact count
 	ValueStats       *ValueStats       `json:"value,omitempty"               yaml:"value,omitempty"               bson:"ve,omitempty"`
 	LengthStats      *LengthStats      `json:"length,omitempty"              yaml:"length,omitempty"              bson:"le,omitempty"`
 	MostFrequent     ValueFreqSlice    `json:"mostFrequent,omitempty"        yaml:"mostFrequent,omitempty"        bson:"mF,omitempty"`
@@ -68,20 +125,93 @@ type Type struct {
 	LengthHistogram  *Histogram        `json:"lengthHistogram,omitempty"     yaml:"lengthHistogram,omitempty"     bson:"lH,omitempty"`
 	WeekdayHistogram *WeekdayHistogram `json:"weekdayHistogram,omitempty"    yaml:"weekdayHistogram,omitempty"    bson:"wH,omitempty"`
 	HourHistogram    *HourHistogram    `json:"hourHistogram,omitempty"       yaml:"hourHistogram,omitempty"       bson:"hH,omitempty"`
+	StringFormats    *StringFormats    `json:"stringFormats,omitempty"       yaml:"stringFormats,omitempty"       bson:"sF,omitempty"`
+	KeyShapes        *KeyShapes        `json:"keyShapes,omitempty"           yaml:"keyShapes,omitempty"           bson:"kS,omitempty"`
+	Truncation       *Truncation       `json:"truncated,omitempty"           yaml:"truncated,omitempty"           bson:"tr,omitempty"`
+	ArrayStats       *ArrayStats       `json:"arrayStats,omitempty"          yaml:"arrayStats,omitempty"          bson:"aS,omitempty"`
 }
 
 // ValueStats - Min, Max, Avg value.
 type ValueStats struct {
-	Min interface{} `json:"min"           yaml:"min"             bson:"i"`
-	Max interface{} `json:"max"           yaml:"max"             bson:"a"`
-	Avg interface{} `json:"avg,omitempty" yaml:"avg,omitempty"   bson:"g"`
+	Min       interface{} `json:"min"                 yaml:"min"                 bson:"i"`
+	Max       interface{} `json:"max"                 yaml:"max"                 bson:"a"`
+	Avg       interface{} `json:"avg,omitempty"       yaml:"avg,omitempty"       bson:"g"`
+	Sum       interface{} `json:"sum,omitempty"       yaml:"sum,omitempty"       bson:"s,omitempty"`
+	Variance  interface{} `json:"variance,omitempty"  yaml:"variance,omitempty"  bson:"vr,omitempty"` // population variance, in seconds^2 for dates
+	StdDev    interface{} `json:"stddev,omitempty"    yaml:"stddev,omitempty"    bson:"sd,omitempty"` // population standard deviation, in seconds for dates
+	Quantiles *Quantiles  `json:"quantiles,omitempty" yaml:"quantiles,omitempty" bson:"q,omitempty"`
 }
 
 // LengthStats - Min, Max, Avg length.
 type LengthStats struct {
-	Min uint    `json:"min"           yaml:"min"             bson:"il"`
-	Max uint    `json:"max"           yaml:"max"             bson:"al"`
-	Avg float64 `json:"avg,omitempty" yaml:"avg,omitempty"   bson:"gl"`
+	Min       uint       `json:"min"                 yaml:"min"                 bson:"il"`
+	Max       uint       `json:"max"                 yaml:"max"                 bson:"al"`
+	Avg       float64    `json:"avg,omitempty"       yaml:"avg,omitempty"       bson:"gl"`
+	Quantiles *Quantiles `json:"quantiles,omitempty" yaml:"quantiles,omitempty" bson:"ql,omitempty"`
+}
+
+// QuantileProbabilities - probabilities of quantiles in the order of Quantiles.Values.
+var QuantileProbabilities = []float64{0.05, 0.25, 0.5, 0.75, 0.9, 0.95, 0.99}
+
+// Quantiles of values or lengths. Values between samples are linearly interpolated.
+type Quantiles struct {
+	P5     interface{} `json:"p5"     yaml:"p5"     bson:"p5"`
+	P25    interface{} `json:"p25"    yaml:"p25"    bson:"p25"`
+	Median interface{} `json:"median" yaml:"median" bson:"p50"`
+	P75    interface{} `json:"p75"    yaml:"p75"    bson:"p75"`
+	P90    interface{} `json:"p90"    yaml:"p90"    bson:"p90"`
+	P95    interface{} `json:"p95"    yaml:"p95"    bson:"p95"`
+	P99    interface{} `json:"p99"    yaml:"p99"    bson:"p99"`
+}
+
+// Values returns pointers to quantiles in the order of QuantileProbabilities.
+func (q *Quantiles) Values() []*interface{} {
+	return []*interface{}{&q.P5, &q.P25, &q.Median, &q.P75, &q.P90, &q.P95, &q.P99}
+}
+
+// StringFormats - number of string values that look like a common format.
+// One value can match several formats, eg. "123" is also a decimal.
+type StringFormats struct {
+	Empty    uint64 `json:"empty,omitempty"    yaml:"empty,omitempty"    bson:"e,omitempty"` // empty or whitespace only
+	Integer  uint64 `json:"integer,omitempty"  yaml:"integer,omitempty"  bson:"i,omitempty"`
+	Decimal  uint64 `json:"decimal,omitempty"  yaml:"decimal,omitempty"  bson:"d,omitempty"`  // integer, decimal or scientific notation
+	Date     uint64 `json:"date,omitempty"     yaml:"date,omitempty"     bson:"dt,omitempty"` // ISO 8601 date or date and time
+	UUID     uint64 `json:"uuid,omitempty"     yaml:"uuid,omitempty"     bson:"u,omitempty"`
+	ObjectId uint64 `json:"objectId,omitempty" yaml:"objectId,omitempty" bson:"o,omitempty"` // 24 hexadecimal digits
+	Email    uint64 `json:"email,omitempty"    yaml:"email,omitempty"    bson:"em,omitempty"`
+	URL      uint64 `json:"url,omitempty"      yaml:"url,omitempty"      bson:"ur,omitempty"`
+	IP       uint64 `json:"ip,omitempty"       yaml:"ip,omitempty"       bson:"ip,omitempty"` // IPv4 or IPv6 address
+}
+
+// Values returns pointers to counts in the order of fields.
+func (f *StringFormats) Values() []*uint64 {
+	return []*uint64{&f.Empty, &f.Integer, &f.Decimal, &f.Date, &f.UUID, &f.ObjectId, &f.Email, &f.URL, &f.IP}
+}
+
+// KeyShapes - number of keys by shape, stored for values of objects collapsed to MapKeyMark.
+type KeyShapes struct {
+	Date     uint64 `json:"date,omitempty"     yaml:"date,omitempty"     bson:"dt,omitempty"` // starts with ISO 8601 date, eg. 2017-04-01
+	Number   uint64 `json:"number,omitempty"   yaml:"number,omitempty"   bson:"n,omitempty"`
+	ObjectId uint64 `json:"objectId,omitempty" yaml:"objectId,omitempty" bson:"o,omitempty"` // 24 hexadecimal digits
+	UUID     uint64 `json:"uuid,omitempty"     yaml:"uuid,omitempty"     bson:"u,omitempty"`
+	Other    uint64 `json:"other,omitempty"    yaml:"other,omitempty"    bson:"ot,omitempty"`
+}
+
+// Truncation - objects and arrays whose content was not fully analyzed because of the depth or array length limits.
+// It is stored only if some content was skipped.
+type Truncation struct {
+	Depth    uint64 `json:"depth,omitempty"    yaml:"depth,omitempty"    bson:"d,omitempty"`  // objects or arrays with nested fields beyond the max depth
+	Arrays   uint64 `json:"arrays,omitempty"   yaml:"arrays,omitempty"   bson:"a,omitempty"`  // arrays longer than the max length
+	Items    uint64 `json:"items,omitempty"    yaml:"items,omitempty"    bson:"i,omitempty"`  // total number of skipped array items
+	MaxItems uint64 `json:"maxItems,omitempty" yaml:"maxItems,omitempty" bson:"mi,omitempty"` // max number of skipped items of one array
+}
+
+// ArrayStats - number of arrays by their items.
+type ArrayStats struct {
+	Empty       uint64 `json:"empty"       yaml:"empty"       bson:"e"`
+	Homogeneous uint64 `json:"homogeneous" yaml:"homogeneous" bson:"h"` // non-empty arrays with items of one type
+	Duplicates  uint64 `json:"duplicates"  yaml:"duplicates"  bson:"d"` // arrays with at least two items of the same type and value
+	Sorted      uint64 `json:"sorted"      yaml:"sorted"      bson:"s"` // arrays with at least two numbers, strings, dates or objectIds in ascending order
 }
 
 // ValueFreqSlice - frequency of values occurrence.
@@ -163,6 +293,38 @@ func (h Histogram) MarshalJSON() ([]byte, error) {
 	)), nil
 }
 
+// UnmarshalJSON - convert []Count to intervals.
+// Empty intervals are skipped.
+func (h *Histogram) UnmarshalJSON(data []byte) error {
+	decoded := new(struct {
+		Start         interface{} `json:"start"`
+		End           interface{} `json:"end"`
+		Range         float64     `json:"range"`
+		Step          float64     `json:"step"`
+		NumberOfSteps uint        `json:"numOfSteps"`
+		Intervals     []Count     `json:"intervals"`
+	})
+
+	err := json.Unmarshal(data, decoded)
+	if err != nil {
+		return err
+	}
+
+	h.Start = decoded.Start
+	h.End = decoded.End
+	h.Range = decoded.Range
+	h.Step = decoded.Step
+	h.NumberOfSteps = decoded.NumberOfSteps
+	h.Intervals = Intervals{}
+	for i, count := range decoded.Intervals {
+		if count > 0 {
+			h.Intervals = append(h.Intervals, &Interval{Interval: uint(i), Count: count})
+		}
+	}
+
+	return nil
+}
+
 // MarshalYAML - convert intervals to []Count.
 // Key is a interval and empty intervals are added.
 func (h Histogram) MarshalYAML() (interface{}, error) {
@@ -301,3 +463,14 @@ func (hh *HourHistogram) SetBSON(raw bson.Raw) error {
 func (hh HourHistogram) MarshalYAML() (interface{}, error) {
 	return hh[:], nil
 }
+
+// Shapes - the most frequent shapes of documents, the first one is the dominant shape.
+type Shapes []*Shape
+
+// Shape of document, the set of field names and types.
+type Shape struct {
+	Count    uint64        `json:"count"             yaml:"count"`
+	Examples []interface{} `json:"examples"          yaml:"examples"`          // _id of the first documents with the shape
+	Added    []string      `json:"added,omitempty"   yaml:"added,omitempty"`   // fields missing in the dominant shape, eg. "address.city (string)"
+	Removed  []string      `json:"removed,omitempty" yaml:"removed,omitempty"` // fields of the dominant shape missing in the shape
+}
@@ -28,6 +28,66 @@ func TestResults_Sort(t *testing.T) {
 	assert.Equal(t, Field{Name: "xyz"}, *results[4])
 }
 
+func TestResults_SortNested(t *testing.T) {
+	results := Fields{
+		{Name: "a-b"},
+		{Name: "a.c"},
+		{Name: `"a.b"`},
+		{Name: "a"},
+		{Name: `"a.b".c`},
+		{Name: "A.b"},
+	}
+
+	sort.Sort(results)
+
+	names := []string{}
+	for _, f := range results {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{`"a.b"`, `"a.b".c`, "a", "A.b", "a.c", "a-b"}, names)
+}
+
+func TestFields_ComputePresence(t *testing.T) {
+	fields := Fields{
+		{Name: "a", Count: 10, DocsCount: 8, ParentsCount: 8, Types: Types{{Name: "null", Count: 2}, {Name: "object", Count: 6}, {Name: "array", Count: 2}}},
+		{Name: "a.b", Count: 3, DocsCount: 3, ParentsCount: 3},
+		{Name: "a.[]", Count: 7, DocsCount: 2, ParentsCount: 1},
+		{Name: "x.y", Count: 1, DocsCount: 1, ParentsCount: 1},
+		{Name: "a.[0]", Count: 1, DocsCount: 1, ParentsCount: 1},
+		{Name: `"a.b"`, Count: 4, DocsCount: 4, ParentsCount: 4, Types: Types{{Name: "object", Count: 4}}},
+		{Name: `"a.b".c`, Count: 2, DocsCount: 2, ParentsCount: 2},
+	}
+
+	fields.ComputePresence(10)
+
+	assert.Equal(t, uint64(2), fields[0].Missing)
+	assert.Equal(t, float64(80), fields[0].PresentInParent)
+	assert.Equal(t, uint64(7), fields[1].Missing)
+	assert.Equal(t, float64(50), fields[1].PresentInParent)
+	assert.Equal(t, uint64(8), fields[2].Missing)
+	assert.Equal(t, float64(50), fields[2].PresentInParent)
+	assert.Equal(t, uint64(9), fields[3].Missing)
+	assert.Equal(t, float64(0), fields[3].PresentInParent)
+	assert.Equal(t, uint64(9), fields[4].Missing)
+	assert.Equal(t, float64(50), fields[4].PresentInParent)
+	assert.Equal(t, uint64(6), fields[5].Missing)
+	assert.Equal(t, float64(40), fields[5].PresentInParent)
+	assert.Equal(t, uint64(8), fields[6].Missing)
+	assert.Equal(t, float64(50), fields[6].PresentInParent)
+}
+
+func TestArrayPositionMark(t *testing.T) {
+	assert.Equal(t, "[0]", ArrayPositionMark(0))
+	assert.Equal(t, "[12]", ArrayPositionMark(12))
+
+	assert.True(t, IsArrayPositionMark("[0]"))
+	assert.True(t, IsArrayPositionMark("[12]"))
+	assert.False(t, IsArrayPositionMark(ArrayItemMark))
+	assert.False(t, IsArrayPositionMark("[a]"))
+	assert.False(t, IsArrayPositionMark("[-1]"))
+	assert.False(t, IsArrayPositionMark("0"))
+}
+
 func TestTypes_Sort(t *testing.T) {
 	types := Types{
 		{Name: "object"},
@@ -138,6 +198,36 @@ func TestHistogram_MarshalJSON(t *testing.T) {
 	assert.Equal(t, "{\"start\":100,\"end\":105,\"range\":0,\"step\":1,\"numOfSteps\":6,\"intervals\":[0,25,0,0,10,0]}", string(j))
 }
 
+func TestHistogram_UnmarshalJSON(t *testing.T) {
+	h := Histogram{}
+	err := json.Unmarshal([]byte("{\"start\":100,\"end\":105,\"range\":5,\"step\":1,\"numOfSteps\":6,\"intervals\":[0,25,0,0,10,0]}"), &h)
+	assert.Nil(t, err)
+
+	assert.Equal(t, Histogram{
+		Start:         float64(100),
+		End:           float64(105),
+		Range:         5,
+		Step:          1,
+		NumberOfSteps: 6,
+		Intervals: Intervals{
+			{
+				Interval: 1,
+				Count:    25,
+			},
+			{
+				Interval: 4,
+				Count:    10,
+			},
+		},
+	}, h)
+}
+
+func TestHistogram_UnmarshalJSON_Invalid(t *testing.T) {
+	h := Histogram{}
+	err := json.Unmarshal([]byte("{\"intervals\":\"abc\"}"), &h)
+	assert.NotEqual(t, nil, err)
+}
+
 func TestHistogram_MarshalJSON_InvalidIntervalSort(t *testing.T) {
 	h := Histogram{
 		Start:         100,
@@ -0,0 +1,58 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"time"
+)
+
+// Source feeds raw (binary) documents to the first stage of analysis.
+// The pipeline contains all stages that run in the database.
+// Source must close the output channel when all documents have been sent or when the context is done.
+// Errors are reported by Fail function, corrupted documents by Corrupted function.
+type Source interface {
+	ToRawChannel(ctx context.Context, pipeline *expr.Pipeline, outCh chan<- []byte, options *Options)
+}
+
+// CollectionSource reads documents from MongoDB collection using aggregation pipeline.
+type CollectionSource struct {
+	Collection driver.Collection
+}
+
+// NewCollectionSource - CollectionSource factory.
+func NewCollectionSource(c driver.Collection) *CollectionSource {
+	return &CollectionSource{Collection: c}
+}
+
+// Count returns number of documents in the collection.
+func (s *CollectionSource) Count() (int, error) {
+	return s.Collection.Count()
+}
+
+// ToRawChannel runs pipeline in the database and sends results to the output channel.
+// Deadline of the context is also used as the server-side time limit (maxTimeMS).
+// Cursor errors stop the analysis.
+func (s *CollectionSource) ToRawChannel(ctx context.Context, pipeline *expr.Pipeline, outCh chan<- []byte, options *Options) {
+	pipeline.SetErrorHandler(func(err error) {
+		Fail(ctx, fmt.Errorf("Cannot read documents from the database: %s.", err))
+	})
+
+	if deadline, ok := ctx.Deadline(); ok {
+		maxTime := time.Until(deadline)
+		if maxTime < time.Millisecond {
+			maxTime = time.Millisecond
+		}
+		pipeline.SetMaxTime(maxTime)
+	}
+
+	pipeline.ToRawChannel(
+		ctx,
+		s.Collection,
+		outCh,
+		options.Concurrency,
+		options.BufferSize,
+		options.BatchSize,
+	)
+}
@@ -1,6 +1,7 @@
 package analysis
 
 import (
+	"context"
 	"fmt"
 	"github.com/mongoeye/mongoeye/mongo/expr"
 )
@@ -13,9 +14,14 @@ var stageNames = [...]string{
 }
 
 // LinkStages links individual stages together.
-func LinkStages(stages []*Stage, options *Options) (*expr.Pipeline, chan<- []byte, interface{}) {
+// Error is returned if stages are incorrect or some stage cannot be started.
+// Cursor errors of the returned pipeline stop the analysis, see Fail.
+func LinkStages(ctx context.Context, stages []*Stage, options *Options) (*expr.Pipeline, chan<- []byte, interface{}, error) {
 	// Create pipeline
 	p := expr.NewPipeline()
+	p.SetErrorHandler(func(err error) {
+		Fail(ctx, err)
+	})
 
 	// Channels
 	var in = make(chan []byte, options.BufferSize)
@@ -27,7 +33,9 @@ func LinkStages(stages []*Stage, options *Options) (*expr.Pipeline, chan<- []byt
 	// Validate and link stages together
 	for i, s := range stages {
 		name := stageNames[i]
-		assertStageNotEmpty(name, s)
+		if err := checkStageNotEmpty(name, s); err != nil {
+			return nil, nil, nil, err
+		}
 
 		stage := *s
 
@@ -35,10 +43,14 @@ func LinkStages(stages []*Stage, options *Options) (*expr.Pipeline, chan<- []byt
 		if stage.PipelineFactory != nil {
 			if inDatabase {
 				// Append pipeline to global pipeline
-				p.AddStages(stage.PipelineFactory(options).GetStages()...)
+				stagePipeline, err := stage.PipelineFactory(options)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				p.AddStages(stagePipeline.GetStages()...)
 			} else {
 				// If is stage defined with Pipeline then all previous stages must be too.
-				panic(fmt.Sprintf("Stage %s is defined by PipelineFactory, but the processing of data in the database has been completed in some previous stage.", name))
+				return nil, nil, nil, fmt.Errorf("Stage %s is defined by PipelineFactory, but the processing of data in the database has been completed in some previous stage.", name)
 			}
 		}
 
@@ -47,15 +59,22 @@ func LinkStages(stages []*Stage, options *Options) (*expr.Pipeline, chan<- []byt
 			inDatabase = false
 
 			// Include processor to pipeline
-			channel = stage.Processor(channel, options)
+			var err error
+			channel, err = stage.Processor(ctx, channel, options)
+			if err != nil {
+				// Close input, so already started processors finish
+				close(in)
+				return nil, nil, nil, err
+			}
 		}
 	}
 
-	return p, in, channel
+	return p, in, channel, nil
 }
 
-func assertStageNotEmpty(name string, stage *Stage) {
+func checkStageNotEmpty(name string, stage *Stage) error {
 	if (*stage).PipelineFactory == nil && (*stage).Processor == nil {
-		panic(fmt.Sprintf("Incorrect stage %s. analysis stage cannot has empty both PipelineFactory and Processor.", name))
+		return fmt.Errorf("Incorrect stage %s. analysis stage cannot has empty both PipelineFactory and Processor.", name)
 	}
+	return nil
 }
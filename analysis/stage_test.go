@@ -1,6 +1,7 @@
 package analysis
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/mongo/expr"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -10,65 +11,63 @@ import (
 func TestLinkStages_PipelineStageAfterProcessorStage(t *testing.T) {
 	stages := []*Stage{
 		{
-			PipelineFactory: func(analysisOptions *Options) *expr.Pipeline {
-				return expr.NewPipeline()
+			PipelineFactory: func(analysisOptions *Options) (*expr.Pipeline, error) {
+				return expr.NewPipeline(), nil
 			},
 		},
 		{
-			Processor: func(inputCh interface{}, options *Options) interface{} {
-				return make(chan []byte)
+			Processor: func(ctx context.Context, inputCh interface{}, options *Options) (interface{}, error) {
+				return make(chan []byte), nil
 			},
 		},
 		{
-			PipelineFactory: func(analysisOptions *Options) *expr.Pipeline {
-				return expr.NewPipeline()
+			PipelineFactory: func(analysisOptions *Options) (*expr.Pipeline, error) {
+				return expr.NewPipeline(), nil
 			},
 		},
 		{
-			Processor: func(inputCh interface{}, options *Options) interface{} {
-				return make(chan []byte)
+			Processor: func(ctx context.Context, inputCh interface{}, options *Options) (interface{}, error) {
+				return make(chan []byte), nil
 			},
 		},
 	}
 
-	assert.Panics(t, func() {
-		LinkStages(stages, &Options{
-			Location:    time.UTC,
-			Concurrency: 4,
-			BufferSize:  50,
-			BatchSize:   100,
-		})
+	_, _, _, err := LinkStages(context.Background(), stages, &Options{
+		Location:    time.UTC,
+		Concurrency: 4,
+		BufferSize:  50,
+		BatchSize:   100,
 	})
+	assert.Error(t, err)
 }
 
 func TestLinkStages_EmptyStage(t *testing.T) {
 	stages := []*Stage{
 		{
-			PipelineFactory: func(analysisOptions *Options) *expr.Pipeline {
-				return expr.NewPipeline()
+			PipelineFactory: func(analysisOptions *Options) (*expr.Pipeline, error) {
+				return expr.NewPipeline(), nil
 			},
 		},
 		{
 			// empty
 		},
 		{
-			PipelineFactory: func(analysisOptions *Options) *expr.Pipeline {
-				return expr.NewPipeline()
+			PipelineFactory: func(analysisOptions *Options) (*expr.Pipeline, error) {
+				return expr.NewPipeline(), nil
 			},
 		},
 		{
-			Processor: func(inputCh interface{}, options *Options) interface{} {
-				return make(chan []byte)
+			Processor: func(ctx context.Context, inputCh interface{}, options *Options) (interface{}, error) {
+				return make(chan []byte), nil
 			},
 		},
 	}
 
-	assert.Panics(t, func() {
-		LinkStages(stages, &Options{
-			Location:    time.UTC,
-			Concurrency: 4,
-			BufferSize:  50,
-			BatchSize:   100,
-		})
+	_, _, _, err := LinkStages(context.Background(), stages, &Options{
+		Location:    time.UTC,
+		Concurrency: 4,
+		BufferSize:  50,
+		BatchSize:   100,
 	})
+	assert.Error(t, err)
 }
@@ -4,6 +4,7 @@
 package sample
 
 import (
+	"errors"
 	"github.com/mongoeye/mongoeye/analysis"
 	"gopkg.in/mgo.v2/bson"
 )
@@ -33,5 +34,11 @@ const (
 	RandomNDocuments
 )
 
+// ErrInvalidSample - unknown sample method.
+var ErrInvalidSample = errors.New("Invalid sample. Use one of the following: first, last, random, all.")
+
+// ErrLimitWithAllSample - limit cannot be used with all documents.
+var ErrLimitWithAllSample = errors.New("Limit option can not be used together with sample = all. Set limit to 0 or use one of the following samples: first, last, random.")
+
 // StageFactory prototype.
 type StageFactory func(sampleOptions *Options) *analysis.Stage
@@ -11,7 +11,7 @@ import (
 // NewStage - SampleInDB stage factory.
 func NewStage(sampleOptions *sample.Options) *analysis.Stage {
 	return &analysis.Stage{
-		PipelineFactory: func(analysisOptions *analysis.Options) *expr.Pipeline {
+		PipelineFactory: func(analysisOptions *analysis.Options) (*expr.Pipeline, error) {
 			// Create pipeline
 			p := expr.NewPipeline()
 
@@ -32,10 +32,10 @@ func NewStage(sampleOptions *sample.Options) *analysis.Stage {
 				p.AddStage("sample", bson.M{"size": sampleOptions.Limit})
 			case sample.AllDocuments:
 				if sampleOptions.Limit != 0 {
-					panic("Limit option can not be used together with sample = all. Set limit to 0 or use one of the following samples: first, last, random.")
+					return nil, sample.ErrLimitWithAllSample
 				}
 			default:
-				panic("Invalid sample. Use one of the following: first, last, random, all.")
+				return nil, sample.ErrInvalidSample
 			}
 
 			// Project
@@ -43,7 +43,7 @@ func NewStage(sampleOptions *sample.Options) *analysis.Stage {
 				p.AddStage("project", sampleOptions.Project)
 			}
 
-			return p
+			return p, nil
 		},
 	}
 }
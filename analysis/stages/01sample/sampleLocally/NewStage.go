@@ -0,0 +1,179 @@
+// Package sampleLocally is the implementation of the sampling stage that runs locally.
+// It is used for sources that cannot run aggregation pipeline, eg. BSON files.
+package sampleLocally
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/01sample"
+	"github.com/mongoeye/mongoeye/decoder"
+	"github.com/mongoeye/mongoeye/mongo/query"
+	"gopkg.in/mgo.v2/bson"
+	"reflect"
+	"sync"
+)
+
+// Document with lazy decoded content, order of fields is preserved for projection.
+type document struct {
+	raw []byte
+	doc bson.D
+}
+
+// Decode raw document if it is not decoded yet.
+func (d *document) decode() (bson.D, error) {
+	if d.doc == nil {
+		doc := bson.D{}
+		if err := bson.Unmarshal(d.raw, &doc); err != nil {
+			return nil, &decoder.CorruptedError{Reason: err.Error()}
+		}
+		d.doc = doc
+	}
+	return d.doc, nil
+}
+
+// NewStage - SampleLocally stage factory.
+//
+// Match, sample and project are applied in the same order as in the database.
+// First and last N documents are selected by sorting according to _id field.
+func NewStage(sampleOptions *sample.Options) *analysis.Stage {
+	return &analysis.Stage{
+		Processor: func(ctx context.Context, _input interface{}, analysisOptions *analysis.Options) (interface{}, error) {
+			// Assert type of input channel
+			var input chan []byte
+			if _input, ok := _input.(chan []byte); ok {
+				input = _input
+			} else {
+				return nil, errors.New("Unexpected input. Expected 'chan []byte'. Given: " + reflect.TypeOf(_input).String())
+			}
+
+			// Validate sample
+			switch sampleOptions.Method {
+			case sample.FirstNDocuments, sample.LastNDocuments, sample.RandomNDocuments:
+			case sample.AllDocuments:
+				if sampleOptions.Limit != 0 {
+					return nil, sample.ErrLimitWithAllSample
+				}
+			default:
+				return nil, sample.ErrInvalidSample
+			}
+
+			// Compile match
+			var match query.Filter
+			if len(sampleOptions.Match) != 0 {
+				var err error
+				match, err = query.CompileFilter(sampleOptions.Match)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid match: %s.", err)
+				}
+			}
+
+			// Compile project
+			var project query.Projection
+			if len(sampleOptions.Project) != 0 {
+				var err error
+				project, err = query.CompileProjection(sampleOptions.Project)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid project: %s.", err)
+				}
+			}
+
+			// Nothing to do
+			if match == nil && project == nil && sampleOptions.Method == sample.AllDocuments {
+				return input, nil
+			}
+
+			matched := runMatchWorkers(ctx, input, match, analysisOptions)
+			sampled := runSampler(ctx, matched, sampleOptions, analysisOptions)
+			return runProjectWorkers(ctx, sampled, project, analysisOptions), nil
+		},
+	}
+}
+
+// Match workers filter documents in parallel.
+// When the context is done, the workers stop, senders to the input also stop by the context.
+func runMatchWorkers(ctx context.Context, input <-chan []byte, match query.Filter, analysisOptions *analysis.Options) chan *document {
+	output := make(chan *document, analysisOptions.BufferSize)
+	wg := &sync.WaitGroup{}
+	wg.Add(analysisOptions.Concurrency)
+
+	for i := 0; i < analysisOptions.Concurrency; i++ {
+		go func() {
+			defer wg.Done()
+
+			for raw := range input {
+				if ctx.Err() != nil {
+					return
+				}
+
+				d := &document{raw: raw}
+				if match != nil {
+					doc, err := d.decode()
+					if err != nil {
+						analysis.Corrupted(ctx, err)
+						continue
+					} else if !match(doc.Map()) {
+						continue
+					}
+				}
+
+				select {
+				case output <- d:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(output)
+	}()
+
+	return output
+}
+
+// Project workers modify documents in parallel and encode them back to raw data.
+func runProjectWorkers(ctx context.Context, input <-chan *document, project query.Projection, analysisOptions *analysis.Options) chan []byte {
+	output := make(chan []byte, analysisOptions.BufferSize)
+	wg := &sync.WaitGroup{}
+	wg.Add(analysisOptions.Concurrency)
+
+	for i := 0; i < analysisOptions.Concurrency; i++ {
+		go func() {
+			defer wg.Done()
+
+			for d := range input {
+				raw := d.raw
+				if project != nil {
+					doc, err := d.decode()
+					if err != nil {
+						analysis.Corrupted(ctx, err)
+						continue
+					}
+
+					raw, err = bson.Marshal(project(doc))
+					if err != nil {
+						analysis.Fail(ctx, err)
+						continue
+					}
+				}
+
+				select {
+				case output <- raw:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(output)
+	}()
+
+	return output
+}
@@ -0,0 +1,95 @@
+package sampleLocally
+
+import (
+	"context"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/01sample"
+	"github.com/mongoeye/mongoeye/analysis/stages/01sample/tests"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+func TestSampleLocally_Match(t *testing.T) {
+	sampleTests.RunTestMatch(t, NewStage)
+}
+
+func TestSampleLocally_Project(t *testing.T) {
+	sampleTests.RunTestProject(t, NewStage)
+}
+
+func TestSampleLocally_First(t *testing.T) {
+	sampleTests.RunTestFirst(t, NewStage)
+}
+
+func TestSampleLocally_Last(t *testing.T) {
+	sampleTests.RunTestLast(t, NewStage)
+}
+
+func TestSampleLocally_Random(t *testing.T) {
+	sampleTests.RunTestRandom(t, NewStage)
+}
+
+func TestSampleLocally_All(t *testing.T) {
+	sampleTests.RunTestAll(t, NewStage)
+}
+
+func TestSampleLocally_InvalidSample(t *testing.T) {
+	sampleTests.RunTestInvalidSample(t, NewStage)
+}
+
+func TestSampleLocally_InvalidLimitWithAllSample(t *testing.T) {
+	sampleTests.RunTestInvalidLimitWithAllSample(t, NewStage)
+}
+
+func Benchmark_SampleFirst1000(b *testing.B) {
+	sampleTests.RunBenchmarkSampleFirst1000(b, NewStage)
+}
+
+func Benchmark_SampleLast1000(b *testing.B) {
+	sampleTests.RunBenchmarkSampleLast1000(b, NewStage)
+}
+
+func Benchmark_SampleRandom1000(b *testing.B) {
+	sampleTests.RunBenchmarkSampleRandom1000(b, NewStage)
+}
+
+func Benchmark_SampleAll(b *testing.B) {
+	sampleTests.RunBenchmarkSampleAll(b, NewStage)
+}
+
+func TestSampleLocally_Canceled(t *testing.T) {
+	input := make(chan []byte, 10)
+	for i := 0; i < 10; i++ {
+		raw, _ := bson.Marshal(bson.M{"_id": i, "a": i})
+		input <- raw
+	}
+	close(input)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stage := NewStage(&sample.Options{Method: sample.FirstNDocuments, Limit: 10, Project: bson.M{"a": 1}})
+	out, err := stage.Processor(ctx, input, &analysis.Options{Concurrency: 1})
+	assert.Nil(t, err)
+
+	// Nobody reads the output, workers must stop by the context
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	count := 0
+	done := make(chan struct{})
+	go func() {
+		for range out.(chan []byte) {
+			count++
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		assert.True(t, count < 10)
+	case <-time.After(time.Second):
+		assert.Fail(t, "Output channel was not closed.")
+	}
+}
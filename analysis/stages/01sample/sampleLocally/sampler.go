@@ -0,0 +1,137 @@
+package sampleLocally
+
+import (
+	"container/heap"
+	"context"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/01sample"
+	"github.com/mongoeye/mongoeye/mongo/query"
+	"gopkg.in/mgo.v2/bson"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Sampler selects documents according to sample method.
+// Only documents that pass the match are counted.
+func runSampler(ctx context.Context, input chan *document, sampleOptions *sample.Options, analysisOptions *analysis.Options) chan *document {
+	if sampleOptions.Method == sample.AllDocuments {
+		return input
+	}
+
+	output := make(chan *document, analysisOptions.BufferSize)
+
+	go func() {
+		defer close(output)
+
+		var selected []*document
+
+		switch sampleOptions.Method {
+		case sample.FirstNDocuments:
+			selected = selectByIdOrder(ctx, input, sampleOptions.Limit, 1)
+		case sample.LastNDocuments:
+			selected = selectByIdOrder(ctx, input, sampleOptions.Limit, -1)
+		case sample.RandomNDocuments:
+			selected = selectRandom(input, sampleOptions.Limit)
+		}
+
+		for _, d := range selected {
+			select {
+			case output <- d:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return output
+}
+
+// Value of _id field, nil if document has no _id.
+func documentId(doc bson.D) interface{} {
+	for _, e := range doc {
+		if e.Name == "_id" {
+			return e.Value
+		}
+	}
+	return nil
+}
+
+// Select N documents with the lowest (direction = 1) or highest (direction = -1) _id.
+func selectByIdOrder(ctx context.Context, input <-chan *document, limit uint64, direction int) []*document {
+	h := &idHeap{direction: direction}
+
+	for d := range input {
+		doc, err := d.decode()
+		if err != nil {
+			analysis.Corrupted(ctx, err)
+			continue
+		}
+
+		item := idHeapItem{id: documentId(doc), doc: d}
+
+		if uint64(h.Len()) < limit {
+			heap.Push(h, item)
+		} else if h.Len() > 0 && h.before(item, h.items[0]) {
+			// New document precedes the worst selected document
+			h.items[0] = item
+			heap.Fix(h, 0)
+		}
+	}
+
+	// Sort selected documents
+	sort.Slice(h.items, func(i, j int) bool {
+		return h.before(h.items[i], h.items[j])
+	})
+
+	out := make([]*document, len(h.items))
+	for i, item := range h.items {
+		out[i] = item.doc
+	}
+
+	return out
+}
+
+// Select N random documents (reservoir sampling).
+func selectRandom(input <-chan *document, limit uint64) []*document {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	reservoir := make([]*document, 0)
+
+	var seen uint64
+	for d := range input {
+		seen++
+		if uint64(len(reservoir)) < limit {
+			reservoir = append(reservoir, d)
+		} else if j := uint64(r.Int63n(int64(seen))); j < limit {
+			reservoir[j] = d
+		}
+	}
+
+	return reservoir
+}
+
+type idHeapItem struct {
+	id  interface{}
+	doc *document
+}
+
+// Heap has on the top the document that is the last in the required order.
+type idHeap struct {
+	items     []idHeapItem
+	direction int
+}
+
+func (h *idHeap) before(a idHeapItem, b idHeapItem) bool {
+	return query.Compare(a.id, b.id)*h.direction < 0
+}
+
+func (h *idHeap) Len() int           { return len(h.items) }
+func (h *idHeap) Less(i, j int) bool { return h.before(h.items[j], h.items[i]) }
+func (h *idHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *idHeap) Push(x interface{}) { h.items = append(h.items, x.(idHeapItem)) }
+func (h *idHeap) Pop() (x interface{}) {
+	n := len(h.items)
+	x = h.items[n-1]
+	h.items = h.items[:n-1]
+	return
+}
@@ -6,15 +6,14 @@ import (
 	"github.com/mongoeye/mongoeye/analysis/stages/01sample"
 	"github.com/mongoeye/mongoeye/tests"
 	"github.com/mongoeye/mongoeye/tests/analysis"
-	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"runtime"
 	"testing"
 	"time"
 )
 
-func setup() *mgo.Collection {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+func setup() *tests.TestCollection {
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 
 	c.Insert(bson.M{
 		"_id":  bson.ObjectIdHex("58ed0817344c64f7fca5847a"),
@@ -35,17 +34,20 @@ func setup() *mgo.Collection {
 	return c
 }
 
-func tearDown(c *mgo.Collection) {
+func tearDown(c *tests.TestCollection) {
 	tests.DropTestCollection(c)
 }
 
-func testStage(t *testing.T, c *mgo.Collection, sampleStage *analysis.Stage, expected []interface{}) []interface{} {
+func testStage(t *testing.T, c *tests.TestCollection, sampleStage *analysis.Stage, expected []interface{}) []interface{} {
 	numCpu := runtime.NumCPU()
 	runtime.GOMAXPROCS(numCpu)
 
-	out := analysisTests.RunStages(c, time.UTC, []*analysis.Stage{
+	out, err := analysisTests.RunStages(c, time.UTC, []*analysis.Stage{
 		sampleStage,
 	})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	results := sample.BsonChannelToSlice(sample.RawToBsonChannel(out))
 
@@ -54,15 +56,18 @@ func testStage(t *testing.T, c *mgo.Collection, sampleStage *analysis.Stage, exp
 	return results
 }
 
-func benchmarkStage(b *testing.B, c *mgo.Collection, sampleStage *analysis.Stage, loadResults bool) {
+func benchmarkStage(b *testing.B, c *tests.TestCollection, sampleStage *analysis.Stage, loadResults bool) {
 	numCpu := runtime.NumCPU()
 	runtime.GOMAXPROCS(numCpu)
 
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		out := analysisTests.RunStages(c, time.UTC, []*analysis.Stage{
+		out, err := analysisTests.RunStages(c, time.UTC, []*analysis.Stage{
 			sampleStage,
 		})
+		if err != nil {
+			b.Fatal(err)
+		}
 
 		if loadResults {
 			sample.BsonChannelToSlice(sample.RawToBsonChannel(out))
@@ -72,9 +72,12 @@ func RunTestRandom(t *testing.T, sampleFactory sample.StageFactory) {
 		Limit:  2,
 	}
 
-	out := analysisTests.RunStages(c, time.UTC, []*analysis.Stage{
+	out, err := analysisTests.RunStages(c, time.UTC, []*analysis.Stage{
 		sampleFactory(&options),
 	})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	results := sample.BsonChannelToSlice(sample.RawToBsonChannel(out))
 
@@ -120,9 +123,10 @@ func RunTestInvalidSample(t *testing.T, sampleFactory sample.StageFactory) {
 		Method: 123,
 	}
 
-	assert.Panics(t, func() {
-		testStage(t, c, sampleFactory(&options), nil)
+	_, err := analysisTests.RunStages(c, time.UTC, []*analysis.Stage{
+		sampleFactory(&options),
 	})
+	assert.Error(t, err)
 }
 
 // RunTestInvalidLimitWithAllSample tests limit together with all sample.
@@ -135,7 +139,8 @@ func RunTestInvalidLimitWithAllSample(t *testing.T, sampleFactory sample.StageFa
 		Limit:  1,
 	}
 
-	assert.Panics(t, func() {
-		testStage(t, c, sampleFactory(&options), nil)
+	_, err := analysisTests.RunStages(c, time.UTC, []*analysis.Stage{
+		sampleFactory(&options),
 	})
+	assert.Error(t, err)
 }
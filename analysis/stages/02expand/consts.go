@@ -10,6 +10,10 @@ var (
 	BsonLevel     string
 	BsonLength    string
 	BsonValue     string
+	BsonDoc       string
+	BsonParent    string
+	BsonTruncated string
+	BsonSkipped   string
 )
 
 func init() {
@@ -20,4 +24,8 @@ func init() {
 	BsonLevel = helpers.GetBSONFieldName(t, "Level")
 	BsonLength = helpers.GetBSONFieldName(t, "Length")
 	BsonValue = helpers.GetBSONFieldName(t, "Value")
+	BsonDoc = helpers.GetBSONFieldName(t, "Doc")
+	BsonParent = helpers.GetBSONFieldName(t, "Parent")
+	BsonTruncated = helpers.GetBSONFieldName(t, "Truncated")
+	BsonSkipped = helpers.GetBSONFieldName(t, "SkippedItems")
 }
@@ -1,6 +1,9 @@
 package expand
 
 import (
+	"context"
+	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
 	"gopkg.in/mgo.v2/bson"
 	"reflect"
 	"sync"
@@ -8,7 +11,8 @@ import (
 
 // ToValueChannel converts input channel to Value channel.
 // The input may by raw []byte channel or Value channel (no conversion).
-func ToValueChannel(input interface{}, concurrency int, bufferSize int) chan Value {
+// Invalid values from the database stop the analysis, see analysis.Fail.
+func ToValueChannel(ctx context.Context, input interface{}, concurrency int, bufferSize int) chan Value {
 	if ch, ok := input.(chan Value); ok {
 		return ch
 	} else if ch, ok := input.(chan []byte); ok {
@@ -24,7 +28,10 @@ func ToValueChannel(input interface{}, concurrency int, bufferSize int) chan Val
 
 				for bin := range ch {
 					v := Value{}
-					bson.Unmarshal(bin, &v)
+					if err := bson.Unmarshal(bin, &v); err != nil {
+						analysis.Fail(ctx, fmt.Errorf("Invalid value of expand stage: %s.", err))
+						continue
+					}
 					outCh <- v
 				}
 			}()
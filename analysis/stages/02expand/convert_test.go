@@ -1,6 +1,7 @@
 package expand
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/mgo.v2/bson"
 	"testing"
@@ -8,7 +9,7 @@ import (
 
 func TestToValueChannel_ValueChannel(t *testing.T) {
 	ch := make(chan Value, 10)
-	valueCh := ToValueChannel(ch, 1, 1)
+	valueCh := ToValueChannel(context.Background(), ch, 1, 1)
 
 	ch <- Value{Name: "f1", Type: "string", Level: 0, Value: "abc", Length: 3}
 	ch <- Value{Name: "f2", Type: "int", Level: 1, Value: 100}
@@ -31,7 +32,7 @@ func TestToValueChannel_ValueChannel(t *testing.T) {
 
 func TestToValueChannel_ByteChannel(t *testing.T) {
 	ch := make(chan []byte, 10)
-	valueCh := ToValueChannel(ch, 1, 1)
+	valueCh := ToValueChannel(context.Background(), ch, 1, 1)
 
 	var raw []byte
 	raw, _ = bson.Marshal(bson.M{BsonFieldName: "f1", BsonFieldType: "string", BsonLevel: 0, BsonValue: "abc", BsonLength: 3})
@@ -61,7 +62,7 @@ func TestToValueChannel_InvalidType(t *testing.T) {
 	ch := make(chan []int, 10)
 
 	assert.Panics(t, func() {
-		ToValueChannel(ch, 1, 1)
+		ToValueChannel(context.Background(), ch, 1, 1)
 	})
 }
 
@@ -15,8 +15,35 @@ type Options struct {
 	StoreStringLength bool // store string length (before truncation)
 	StoreArrayLength  bool // store array length (before shortening)
 	StoreObjectLength bool // store number of object fields
+
+	// Objects with dynamic keys (maps) are collapsed, so values of all keys have the same name with analysis.MapKeyMark.
+	// Only expandLocally supports collapsing.
+	MapMinKeys uint     // collapse objects with at least N keys of the same shape (date, number, objectId, uuid) and values of the same type, zero = disabled
+	MapMaxKeys uint     // collapse objects with at least N keys and values of the same type regardless of key shape, zero = disabled
+	MapPaths   []string // always collapse objects with these names
+	NoMapPaths []string // never collapse objects with these names
+
+	Shapes *Shapes // collect shapes of documents, only expandLocally supports it, nil = disabled
+
+	Fields *FieldFilter // analyze only selected fields, filter is applied to fields up to MaxLevel, nil = all fields
+
+	DepthFor          PathLimits // overrides MaxDepth for nested fields of the paths, eg. {"order.items": 6}
+	ArrayMaxLengthFor PathLimits // overrides ArrayMaxLength for arrays at the paths and in their nested fields
+
+	// Only expandLocally supports array statistics and positions.
+	StoreArrayStats bool // store Value.ArrayFlags of arrays
+	ArrayPositions  uint // items at the first N positions are also sent as positional fields, eg. tags.[0], zero = disabled
 }
 
+// Flags of array stored in Value.ArrayFlags, see Options.StoreArrayStats.
+// All items are checked, including the items beyond the max length.
+const (
+	ArrayEmpty       = 1 << iota
+	ArrayHomogeneous // all items have the same type
+	ArrayDuplicates  // at least two items have the same type and value
+	ArraySorted      // at least two items, all numbers, strings, dates or objectIds in ascending order
+)
+
 // Value of field with given name and type
 type Value struct {
 	Name   string      `bson:"n"` // name of field
@@ -24,6 +51,16 @@ type Value struct {
 	Level  uint        `bson:"e"` // level of nested field, root level is zero
 	Length uint        `bson:"l"` // length of value, it is available for some types (if enabled in options)
 	Value  interface{} `bson:"v"` // value of field (if enabled in options)
+	Doc    uint        `bson:"d"` // 1 if it is the first value of field in the document, otherwise 0
+	Parent uint        `bson:"p"` // 1 if it is the first value of field in the parent object or array, otherwise 0
+
+	// Content of objects and arrays skipped because of limits, see Options.MaxDepth and Options.ArrayMaxLength.
+	Truncated    uint `bson:"x,omitempty"` // 1 if the object or array is not empty, but its nested fields are beyond the max depth
+	SkippedItems uint `bson:"s,omitempty"` // number of array items beyond the max length
+
+	KeyShape string `bson:"k,omitempty"` // shape of the key if the parent object was collapsed, see Options.MapMinKeys
+
+	ArrayFlags uint `bson:"f,omitempty"` // flags of array, see Options.StoreArrayStats
 }
 
 // StageFactory prototype.
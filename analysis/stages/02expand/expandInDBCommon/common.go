@@ -2,16 +2,23 @@
 package expandInDBCommon
 
 import (
+	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/mongoeye/mongoeye/mongo/expr"
 	"gopkg.in/mgo.v2/bson"
+	"strconv"
 )
 
+// DocId - id of the analyzed document, it is stored in each value until MarkFirstInDocument.
+var DocId = expr.Var("ROOT", analysis.BsonId)
+
 // FieldVars is an auxiliary structure that serves to build aggregation in the database.
 type FieldVars struct {
-	Name  interface{}
-	Type  interface{}
-	Value interface{}
-	Level uint
+	Name   interface{}
+	Type   interface{}
+	Value  interface{}
+	Level  uint
+	Parent interface{}
 }
 
 // ProcessStringField generates operations that process string field in aggregation pipeline.
@@ -20,7 +27,9 @@ func ProcessStringField(fullName interface{}, field FieldVars, options *expand.O
 		expand.BsonFieldName: fullName,
 		expand.BsonFieldType: field.Type,
 		expand.BsonLevel:     field.Level,
+		expand.BsonParent:    field.Parent,
 		expand.BsonNested:    nil,
+		analysis.BsonId:      DocId,
 	}
 
 	if options.StoreStringLength {
@@ -46,7 +55,9 @@ func ProcessScalarField(fullName interface{}, field FieldVars, options *expand.O
 		expand.BsonFieldName: fullName,
 		expand.BsonFieldType: field.Type,
 		expand.BsonLevel:     field.Level,
+		expand.BsonParent:    field.Parent,
 		expand.BsonNested:    nil,
+		analysis.BsonId:      DocId,
 	}
 
 	if options.StoreValue {
@@ -55,3 +66,229 @@ func ProcessScalarField(fullName interface{}, field FieldVars, options *expand.O
 
 	return m
 }
+
+// EscapeKey generates operation that escapes key of object in full field name, same as analysis.EscapeKey.
+func EscapeKey(key interface{}) bson.M {
+	needsQuote := expr.Or(
+		expr.Eq(key, ""),
+		expr.Gte(expr.IndexOfBytes(key, analysis.NameSeparator), 0),
+		expr.In(expr.SubstrCP(key, 0, 1), analysis.KeyQuotedPrefixes),
+	)
+
+	escaped := expr.Replace(key, analysis.KeyEscape, analysis.KeyEscape+analysis.KeyEscape)
+	escaped = expr.Replace(escaped, analysis.KeyQuote, analysis.KeyEscape+analysis.KeyQuote)
+
+	return expr.Cond(needsQuote, expr.Concat(analysis.KeyQuote, escaped, analysis.KeyQuote), key)
+}
+
+// ItemParent generates operation that sets flag Parent of array item, only the first item is the first value in the array.
+func ItemParent(index interface{}) bson.M {
+	return expr.Cond(expr.Eq(index, 0), 1, 0)
+}
+
+// NestedIf generates operation that expands nested fields only if the condition is met, see expand.Options.ExpandNestedExpr.
+func NestedIf(cond interface{}, nested interface{}) interface{} {
+	if cond == true {
+		return nested
+	}
+	return expr.Cond(cond, nested, nil)
+}
+
+// Truncated generates operation that returns 1 if nested fields are not expanded and the object or array is not empty.
+func Truncated(cond interface{}, length interface{}) interface{} {
+	if cond == true {
+		return 0
+	}
+
+	truncated := expr.Cond(expr.Gt(length, 0), 1, 0)
+	if cond == false {
+		return truncated
+	}
+	return expr.Cond(cond, 0, truncated)
+}
+
+// SkippedItems generates operation that returns number of array items beyond the max length, if the items are expanded.
+func SkippedItems(cond interface{}, length interface{}, maxLength interface{}) interface{} {
+	if cond == false {
+		return 0
+	}
+
+	skipped := expr.Cond(expr.Gt(length, maxLength), expr.Subtract(length, maxLength), 0)
+	if cond == true {
+		return skipped
+	}
+	return expr.Cond(cond, skipped, 0)
+}
+
+// MarkFirstInDocument adds stages that set flag Doc of the first value of each field in the document.
+// Documents are identified by _id, values are grouped by document and field name.
+func MarkFirstInDocument(p *expr.Pipeline) {
+	p.AddStage("group", bson.M{
+		analysis.BsonId: bson.M{
+			"d": expr.Field(analysis.BsonId),
+			"n": expr.Field(expand.BsonFieldName),
+		},
+		"v": bson.M{"$push": expr.Var("ROOT")},
+	})
+	p.AddStage("unwind", bson.M{
+		"path":              expr.Field("v"),
+		"includeArrayIndex": "i",
+	})
+	p.AddStage("replaceRoot", bson.M{
+		"newRoot": expr.MergeObjects([]interface{}{
+			expr.Field("v"),
+			bson.M{expand.BsonDoc: expr.Cond(expr.Eq(expr.Field("i"), 0), 1, 0)},
+		}),
+	})
+	p.AddStage("project", bson.M{analysis.BsonId: 0})
+}
+
+// RemovedValue replaces values removed by the field filter, so lengths of objects and arrays are preserved.
+var RemovedValue = expr.Literal(bson.M{"_mongoeyeRemoved": true})
+
+// FilterFields adds stage that removes fields not selected by the field filter, see expand.Options.Fields.
+// Removed values are replaced by RemovedValue, they are counted to the lengths, but they are not reported, same as in expandLocally.
+// The filtered document is stored next to _id, so documents are still identified by DocId.
+func FilterFields(p *expr.Pipeline, options *expand.Options) {
+	if options.Fields == nil {
+		return
+	}
+
+	p.AddStage("project", bson.M{
+		analysis.BsonId:   1,
+		expand.BsonNested: filterObject(expr.Var("ROOT"), []interface{}{}, 0, options.Fields.Root() == expand.FieldIncluded, options),
+	})
+}
+
+// Root returns the document to expand, see FilterFields.
+func Root(options *expand.Options) string {
+	if options.Fields == nil {
+		return expr.Var("ROOT")
+	}
+
+	return expr.Var("ROOT", expand.BsonNested)
+}
+
+// Filter fields of object, fields are converted to key-value pairs and back.
+func filterObject(object interface{}, superiors []interface{}, level uint, parentIncluded interface{}, options *expand.Options) interface{} {
+	itemVar := "fo" + strconv.Itoa(int(level))
+	key := expr.Var(itemVar + ".k")
+	segments := append(superiors[:len(superiors):len(superiors)], expand.DynamicSegment{Expr: EscapeKey(key)})
+	items, included, keep := filterItems(expr.ObjectToArray(object), itemVar, bson.M{"k": key, "v": expr.Var(itemVar + ".v")}, segments, parentIncluded, options)
+
+	return expr.ArrayToObject(expr.Map(
+		items,
+		itemVar,
+		bson.M{
+			"k": key,
+			"v": keptOrRemoved(keep, filterValue(expr.Var(itemVar+".v"), segments, level, included, options)),
+		},
+	))
+}
+
+// Filter items of array, items are named by analysis.ArrayItemMark.
+func filterArray(array interface{}, superiors []interface{}, level uint, parentIncluded interface{}, options *expand.Options) interface{} {
+	itemVar := "fa" + strconv.Itoa(int(level))
+	segments := append(superiors[:len(superiors):len(superiors)], analysis.ArrayItemMark)
+	items, included, keep := filterItems(array, itemVar, bson.M{"v": expr.Var(itemVar)}, segments, parentIncluded, options)
+
+	return expr.Map(
+		items,
+		itemVar,
+		keptOrRemoved(keep, filterValue(expr.Var(itemVar+".v"), segments, level, included, options)),
+	)
+}
+
+// Evaluate conditions of items, result items contain value (v), key of object field (k), condition of inclusion (i) and of keeping (p).
+// Segments are names of the superiors and the item. Conditions of inclusion and keeping are returned as variables or constants.
+func filterItems(input interface{}, itemVar string, item bson.M, segments []interface{}, parentIncluded interface{}, options *expand.Options) (interface{}, interface{}, interface{}) {
+	included := options.Fields.IncludedExpr(segments, parentIncluded)
+	keep := options.Fields.KeepExpr(segments, included, expr.Type(item["v"]))
+
+	item["i"] = included
+	item["p"] = keep
+
+	items := expr.Map(input, itemVar, item)
+
+	if _, ok := included.(bool); !ok {
+		included = expr.Var(itemVar + ".i")
+	}
+	if _, ok := keep.(bool); !ok {
+		keep = expr.Var(itemVar + ".p")
+	}
+	return items, included, keep
+}
+
+// Value if it is kept, otherwise RemovedValue.
+func keptOrRemoved(keep interface{}, value interface{}) interface{} {
+	switch keep {
+	case true:
+		return value
+	case false:
+		return RemovedValue
+	}
+	return expr.Cond(keep, value, RemovedValue)
+}
+
+// KeptFields returns key-value pairs of object without fields removed by the field filter.
+func KeptFields(object interface{}, options *expand.Options) interface{} {
+	return KeptItems(expr.ObjectToArray(object), "v", options)
+}
+
+// KeptItems returns items of array that are not removed by the field filter, property is the value of key-value pair or empty.
+func KeptItems(array interface{}, property string, options *expand.Options) interface{} {
+	if options.Fields == nil {
+		return array
+	}
+
+	item := "kept"
+	if property != "" {
+		item += "." + property
+	}
+	return expr.Filter(array, "kept", expr.Ne(expr.Var(item), RemovedValue))
+}
+
+// KeptValue returns value of field without removed nested fields, level is the level of the field.
+func KeptValue(value interface{}, level uint, options *expand.Options) interface{} {
+	// Removed values are only to the max depth, see filterValue
+	if options.Fields == nil || level >= options.MaxLevel() {
+		return value
+	}
+
+	itemVar := "kv" + strconv.Itoa(int(level))
+	fields := KeptFields(value, options)
+	items := KeptItems(value, "", options)
+
+	// Nested values are mapped only if they can contain removed fields
+	if level+1 < options.MaxLevel() {
+		fields = expr.Map(fields, itemVar, bson.M{
+			"k": expr.Var(itemVar + ".k"),
+			"v": KeptValue(expr.Var(itemVar+".v"), level+1, options),
+		})
+		items = expr.Map(items, itemVar, KeptValue(expr.Var(itemVar), level+1, options))
+	}
+
+	return expr.Cond(
+		expr.Eq(expr.Type(value), "object"),
+		expr.ArrayToObject(fields),
+		expr.Cond(expr.Eq(expr.Type(value), "array"), items, value),
+	)
+}
+
+// Filter nested fields of the value, if they are not deeper than the max depth.
+func filterValue(value interface{}, segments []interface{}, level uint, included interface{}, options *expand.Options) interface{} {
+	// Nested fields of included field are removed only by exclude patterns
+	if level >= options.MaxLevel() || included == true && !options.Fields.HasExcludes() {
+		return value
+	}
+
+	return expr.Cond(
+		expr.Eq(expr.Type(value), "object"),
+		filterObject(value, segments, level+1, included, options),
+		expr.Cond(
+			expr.Eq(expr.Type(value), "array"),
+			filterArray(value, segments, level+1, included, options),
+			value,
+		),
+	)
+}
@@ -13,18 +13,21 @@ import (
 // NewStage - ExpandInDBDepth stage factory.
 func NewStage(expandOptions *expand.Options) *analysis.Stage {
 	return &analysis.Stage{
-		PipelineFactory: func(analysisOptions *analysis.Options) *expr.Pipeline {
+		PipelineFactory: func(analysisOptions *analysis.Options) (*expr.Pipeline, error) {
 			// Create pipeline
 			p := expr.NewPipeline()
 
+			// Remove fields not selected by the field filter
+			expandInDBCommon.FilterFields(p, expandOptions)
+
 			// Extract fields from nested documents
 			p.AddStage("project", bson.M{
 				analysis.BsonId:   0,
-				expand.BsonNested: processObject([]interface{}{}, expr.Var("ROOT"), 0, expandOptions),
+				expand.BsonNested: processObject([]interface{}{}, expandInDBCommon.Root(expandOptions), 0, expandOptions),
 			})
 
 			// Expand nested fields to specified depth
-			for level := uint(0); level <= expandOptions.MaxDepth; level++ {
+			for level := uint(0); level <= expandOptions.MaxLevel(); level++ {
 				p.AddStage("unwind", bson.M{
 					"path":                       expr.Field(expand.BsonNested),
 					"preserveNullAndEmptyArrays": true,
@@ -41,7 +44,10 @@ func NewStage(expandOptions *expand.Options) *analysis.Stage {
 			// Remove empty property NESTED
 			p.AddStage("project", bson.M{expand.BsonNested: 0})
 
-			return p
+			// Mark the first value of each field in the document
+			expandInDBCommon.MarkFirstInDocument(p)
+
+			return p, nil
 		},
 	}
 }
@@ -51,14 +57,15 @@ func processObject(superiors []interface{}, object interface{}, level uint, expa
 	// Each level has its own different variable to avoid collisions
 	itemVar := "xl" + strconv.Itoa(int(level))
 	field := expandInDBCommon.FieldVars{
-		Name:  expr.Var(itemVar + ".k"),
-		Type:  expr.Var(expand.BsonFieldType),
-		Value: expr.Var(itemVar + ".v"),
-		Level: level,
+		Name:   expandInDBCommon.EscapeKey(expr.Var(itemVar + ".k")),
+		Type:   expr.Var(expand.BsonFieldType),
+		Value:  expr.Var(itemVar + ".v"),
+		Level:  level,
+		Parent: 1,
 	}
 
 	return expr.Map(
-		expr.ObjectToArray(object),
+		expandInDBCommon.KeptFields(object, expandOptions),
 		itemVar,
 		expr.Let(
 			bson.M{expand.BsonFieldType: expr.Type(field.Value)},
@@ -69,24 +76,34 @@ func processObject(superiors []interface{}, object interface{}, level uint, expa
 
 // Process elements from array.
 func processArray(superiors []interface{}, field expandInDBCommon.FieldVars, expandOptions *expand.Options) bson.M {
+	itemsVar := "xs" + strconv.Itoa(int(field.Level))
+	indexVar := "xi" + strconv.Itoa(int(field.Level))
 	itemVar := "xa" + strconv.Itoa(int(field.Level))
 
 	item := expandInDBCommon.FieldVars{
-		Name:  analysis.ArrayItemMark,
-		Type:  expr.Var(expand.BsonFieldType),
-		Value: expr.Var(itemVar),
-		Level: field.Level + 1,
+		Name:   analysis.ArrayItemMark,
+		Type:   expr.Var(expand.BsonFieldType),
+		Value:  expr.Var(itemVar),
+		Level:  field.Level + 1,
+		Parent: expandInDBCommon.ItemParent(expr.Var(indexVar)),
 	}
 
-	return expr.Map(
-		field.Value,
-		itemVar,
-		expr.Let(
-			bson.M{expand.BsonFieldType: expr.Type(item.Value)},
-			processField(
-				append(superiors, field.Name),
-				item,
-				expandOptions,
+	// Items are iterated by index, so the first item can be recognized
+	return expr.Let(
+		bson.M{itemsVar: expandInDBCommon.KeptItems(field.Value, "", expandOptions)},
+		expr.Map(
+			expr.Range(0, expr.Size(expr.Var(itemsVar))),
+			indexVar,
+			expr.Let(
+				bson.M{itemVar: expr.ArrayElemAt(expr.Var(itemsVar), expr.Var(indexVar))},
+				expr.Let(
+					bson.M{expand.BsonFieldType: expr.Type(item.Value)},
+					processField(
+						append(superiors, field.Name),
+						item,
+						expandOptions,
+					),
+				),
 			),
 		),
 	)
@@ -143,6 +160,8 @@ func processArrayField(superiors []interface{}, fullName interface{}, field expa
 		expand.BsonFieldName: fullName,
 		expand.BsonFieldType: field.Type,
 		expand.BsonLevel:     field.Level,
+		expand.BsonParent:    field.Parent,
+		analysis.BsonId:      expandInDBCommon.DocId,
 	}
 
 	// Array length
@@ -150,21 +169,28 @@ func processArrayField(superiors []interface{}, fullName interface{}, field expa
 		m[expand.BsonLength] = expr.Size(field.Value)
 	}
 
+	expandNested := expandOptions.ExpandNestedExpr(fullName, field.Level)
+	maxLength := expandOptions.ArrayMaxLengthExpr(fullName, field.Level)
+
+	// Truncated content
+	m[expand.BsonTruncated] = expandInDBCommon.Truncated(expandNested, expr.Size(field.Value))
+	m[expand.BsonSkipped] = expandInDBCommon.SkippedItems(expandNested, expr.Size(field.Value), maxLength)
+
 	// Limit array size
-	field.Value = expr.Slice(field.Value, expandOptions.ArrayMaxLength)
+	field.Value = expr.Slice(field.Value, maxLength)
 
 	// Array items
-	if field.Level >= expandOptions.MaxDepth {
+	if expandNested == false {
 		m[expand.BsonNested] = nil
 	} else {
-		m[expand.BsonNested] = expr.ConcatArrays(
+		m[expand.BsonNested] = expandInDBCommon.NestedIf(expandNested, expr.ConcatArrays(
 			[]interface{}{nil}, // null represent parent field
 			processArray(superiors, field, expandOptions),
-		)
+		))
 	}
 
 	if expandOptions.StoreValue {
-		m[expand.BsonValue] = field.Value
+		m[expand.BsonValue] = expandInDBCommon.KeptValue(field.Value, field.Level, expandOptions)
 	}
 
 	return m
@@ -176,14 +202,18 @@ func processObjectField(superiors []interface{}, fullName interface{}, field exp
 		expand.BsonFieldName: fullName,
 		expand.BsonFieldType: field.Type,
 		expand.BsonLevel:     field.Level,
+		expand.BsonParent:    field.Parent,
+		analysis.BsonId:      expandInDBCommon.DocId,
 	}
 
 	if expandOptions.StoreValue {
-		m[expand.BsonValue] = field.Value
+		m[expand.BsonValue] = expandInDBCommon.KeptValue(field.Value, field.Level, expandOptions)
 	}
 
 	// Nested fields
-	if field.Level >= expandOptions.MaxDepth {
+	expandNested := expandOptions.ExpandNestedExpr(fullName, field.Level)
+	m[expand.BsonTruncated] = expandInDBCommon.Truncated(expandNested, expr.Size(expr.ObjectToArray(field.Value)))
+	if expandNested == false {
 		m[expand.BsonNested] = nil
 		if expandOptions.StoreObjectLength {
 			m[expand.BsonLength] = expr.Size(expr.ObjectToArray(field.Value))
@@ -196,13 +226,14 @@ func processObjectField(superiors []interface{}, fullName interface{}, field exp
 			expandOptions,
 		)
 
-		if expandOptions.StoreObjectLength {
+		// Fields removed by the field filter are not in nested fields, but they are counted to the length
+		if expandOptions.StoreObjectLength && expandOptions.Fields == nil {
 			fieldsVar := "xf"
 
-			m[expand.BsonNested] = expr.ConcatArrays(
+			m[expand.BsonNested] = expandInDBCommon.NestedIf(expandNested, expr.ConcatArrays(
 				[]interface{}{nil}, // null represent parent field
 				expr.Var(fieldsVar),
-			)
+			))
 
 			m[expand.BsonLength] = expr.Size(expr.Var(fieldsVar))
 
@@ -211,10 +242,14 @@ func processObjectField(superiors []interface{}, fullName interface{}, field exp
 				m,
 			)
 		} else {
-			m[expand.BsonNested] = expr.ConcatArrays(
+			m[expand.BsonNested] = expandInDBCommon.NestedIf(expandNested, expr.ConcatArrays(
 				[]interface{}{nil}, // null represent parent field
 				fields,
-			)
+			))
+
+			if expandOptions.StoreObjectLength {
+				m[expand.BsonLength] = expr.Size(expr.ObjectToArray(field.Value))
+			}
 		}
 	}
 
@@ -86,6 +86,36 @@ func TestExpandInDBDepthStringFieldAll(t *testing.T) {
 	expandTests.RunTestStringFieldAll(t, NewStage)
 }
 
+func TestExpandInDBDepthFieldsExclude(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestFieldsExclude(t, NewStage)
+}
+
+func TestExpandInDBDepthFieldsInclude(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestFieldsInclude(t, NewStage)
+}
+
+func TestExpandInDBDepthFieldsValue(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestFieldsValue(t, NewStage)
+}
+
+func TestExpandInDBDepthDepthFor(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestDepthFor(t, NewStage)
+}
+
+func TestExpandInDBDepthArrayMaxLengthFor(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestArrayMaxLengthFor(t, NewStage)
+}
+
+func TestExpandInDBDepthEscapedKeys(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestEscapedKeys(t, NewStage)
+}
+
 func BenchmarkExpandInDBDepthDepth0MinFull(b *testing.B) {
 	tests.SkipBIfNotSupportAggregationAlgorithm(b)
 	expandTests.RunBenchmarkDepth0Min(b, NewStage)
@@ -13,27 +13,29 @@ import (
 // NewStage - ExpandInDBSeq stage factory.
 func NewStage(expandOptions *expand.Options) *analysis.Stage {
 	return &analysis.Stage{
-		PipelineFactory: func(analysisOptions *analysis.Options) *expr.Pipeline {
+		PipelineFactory: func(analysisOptions *analysis.Options) (*expr.Pipeline, error) {
 			// Create pipeline
 			p := expr.NewPipeline()
 
+			// Remove fields not selected by the field filter
+			expandInDBCommon.FilterFields(p, expandOptions)
+
 			// Root level
 			p.AddStage("project", bson.M{
 				analysis.BsonId:   0,
-				expand.BsonNested: processObject(expr.Var("ROOT"), 0, expandOptions),
+				expand.BsonNested: processObject(expandInDBCommon.Root(expandOptions), nil, 0, expandOptions),
 			})
 			p.AddStage("unwind", expr.Field(expand.BsonNested))
 
 			// Deeper levels
 			var level uint
-			for ; level <= expandOptions.MaxDepth; level++ {
-				isLast := level == expandOptions.MaxDepth
+			for ; level <= expandOptions.MaxLevel(); level++ {
 				p.AddStage("replaceRoot", bson.M{
 					// Skip fields without nested fields
 					"newRoot": expr.Cond(
 						expr.Eq(expr.Field(expand.BsonNested), nil),
 						expr.Var("ROOT"),
-						processNestedFields(isLast, level, expandOptions),
+						processNestedFields(level, expandOptions),
 					),
 				})
 
@@ -46,34 +48,44 @@ func NewStage(expandOptions *expand.Options) *analysis.Stage {
 			// Remove empty property NESTED
 			p.AddStage("project", bson.M{expand.BsonNested: 0})
 
-			return p
+			// Mark the first value of each field in the document
+			expandInDBCommon.MarkFirstInDocument(p)
+
+			return p, nil
 		},
 	}
 }
 
-// Extract fields from object.
-func processObject(object interface{}, level uint, expandOptions *expand.Options) interface{} {
+// Extract fields from object, parentName is expression of the full name of the object (nil for root).
+func processObject(object interface{}, parentName interface{}, level uint, expandOptions *expand.Options) interface{} {
 	// Each level has its own different variable to avoid collisions
 	itemVar := "xl" + strconv.Itoa(int(level))
 	field := expandInDBCommon.FieldVars{
-		Name:  expr.Var(itemVar + ".k"),
-		Type:  expr.Var(expand.BsonFieldType),
-		Value: expr.Var(itemVar + ".v"),
-		Level: level,
+		Name:   expandInDBCommon.EscapeKey(expr.Var(itemVar + ".k")),
+		Type:   expr.Var(expand.BsonFieldType),
+		Value:  expr.Var(itemVar + ".v"),
+		Level:  level,
+		Parent: 1,
 	}
 
 	return expr.Map(
-		expr.ObjectToArray(object),
+		expandInDBCommon.KeptFields(object, expandOptions),
 		itemVar,
 		expr.Let(
 			bson.M{expand.BsonFieldType: expr.Type(field.Value)},
-			processField(field, expandOptions),
+			processField(field, parentName, expandOptions),
 		),
 	)
 }
 
 // Process object or array field.
-func processField(field expandInDBCommon.FieldVars, expandOptions *expand.Options) interface{} {
+// Values contain only name relative to the parent, full name is used for limits of the field.
+func processField(field expandInDBCommon.FieldVars, parentName interface{}, expandOptions *expand.Options) interface{} {
+	fullName := field.Name
+	if parentName != nil {
+		fullName = expr.Concat(parentName, analysis.NameSeparator, field.Name)
+	}
+
 	// Type switch
 	sw := expr.Switch()
 
@@ -86,7 +98,7 @@ func processField(field expandInDBCommon.FieldVars, expandOptions *expand.Option
 	// Array
 	sw.AddBranch(
 		expr.Eq(field.Type, "array"),
-		processArrayField(field.Name, field, expandOptions),
+		processArrayField(field.Name, fullName, field, expandOptions),
 	)
 
 	// Object
@@ -107,11 +119,13 @@ func processObjectField(fullName interface{}, field expandInDBCommon.FieldVars,
 		expand.BsonFieldName: fullName,
 		expand.BsonFieldType: field.Type,
 		expand.BsonLevel:     field.Level,
+		expand.BsonParent:    field.Parent,
 		expand.BsonNested:    field.Value,
+		analysis.BsonId:      expandInDBCommon.DocId,
 	}
 
 	if expandOptions.StoreValue {
-		m[expand.BsonValue] = field.Value
+		m[expand.BsonValue] = expandInDBCommon.KeptValue(field.Value, field.Level, expandOptions)
 	}
 
 	if expandOptions.StoreObjectLength {
@@ -122,12 +136,14 @@ func processObjectField(fullName interface{}, field expandInDBCommon.FieldVars,
 }
 
 // Process array field.
-func processArrayField(fullName interface{}, field expandInDBCommon.FieldVars, expandOptions *expand.Options) bson.M {
+func processArrayField(name interface{}, fullName interface{}, field expandInDBCommon.FieldVars, expandOptions *expand.Options) bson.M {
 	m := bson.M{
-		expand.BsonFieldName: fullName,
+		expand.BsonFieldName: name,
 		expand.BsonFieldType: field.Type,
 		expand.BsonLevel:     field.Level,
+		expand.BsonParent:    field.Parent,
 		expand.BsonNested:    expr.Var("xslice"),
+		analysis.BsonId:      expandInDBCommon.DocId,
 	}
 
 	// Array length
@@ -136,18 +152,21 @@ func processArrayField(fullName interface{}, field expandInDBCommon.FieldVars, e
 	}
 
 	if expandOptions.StoreValue {
-		m[expand.BsonValue] = expr.Var("xslice")
+		m[expand.BsonValue] = expandInDBCommon.KeptValue(expr.Var("xslice"), field.Level, expandOptions)
 	}
 
+	// Items beyond the max length, used if the items are expanded
+	maxLength := expandOptions.ArrayMaxLengthExpr(fullName, field.Level)
+	m[expand.BsonSkipped] = expandInDBCommon.SkippedItems(true, expr.Size(field.Value), maxLength)
+
 	return expr.Let(
-		bson.M{"xslice": expr.Slice(field.Value, expandOptions.ArrayMaxLength)},
+		bson.M{"xslice": expr.Slice(field.Value, maxLength)},
 		m,
 	)
 }
 
 // Process nested fields one level below.
-func processNestedFields(isLast bool, level uint, expandOptions *expand.Options) bson.M {
-	analysisNested := !isLast // analysis nested documents to a specified depth
+func processNestedFields(level uint, expandOptions *expand.Options) bson.M {
 	prefix := expr.Field(expand.BsonNested) + analysis.NameSeparator
 
 	// Field name
@@ -165,6 +184,9 @@ func processNestedFields(isLast bool, level uint, expandOptions *expand.Options)
 		)
 	}
 
+	// Analysis nested documents to a specified depth
+	analysisNested := expandOptions.ExpandNestedExpr(fullName, level)
+
 	sw := expr.Switch()
 
 	// String
@@ -196,7 +218,9 @@ func processNestedStringField(fullName interface{}, prefix string, level uint, e
 		expand.BsonFieldName: fullName,
 		expand.BsonFieldType: prefix + expand.BsonFieldType,
 		expand.BsonLevel:     level,
+		expand.BsonParent:    prefix + expand.BsonParent,
 		expand.BsonNested:    nil,
+		analysis.BsonId:      expandInDBCommon.DocId,
 	}
 
 	if expandOptions.StoreStringLength {
@@ -210,11 +234,13 @@ func processNestedStringField(fullName interface{}, prefix string, level uint, e
 	return m
 }
 
-func processNestedObjectField(fullName interface{}, prefix string, analysisNested bool, level uint, expandOptions *expand.Options) bson.M {
+func processNestedObjectField(fullName interface{}, prefix string, analysisNested interface{}, level uint, expandOptions *expand.Options) bson.M {
 	m := bson.M{
 		expand.BsonFieldName: fullName,
 		expand.BsonFieldType: prefix + expand.BsonFieldType,
 		expand.BsonLevel:     level,
+		expand.BsonParent:    prefix + expand.BsonParent,
+		analysis.BsonId:      expandInDBCommon.DocId,
 	}
 
 	if expandOptions.StoreValue {
@@ -225,11 +251,13 @@ func processNestedObjectField(fullName interface{}, prefix string, analysisNeste
 		m[expand.BsonLength] = prefix + expand.BsonLength
 	}
 
-	if analysisNested {
-		m[expand.BsonNested] = expr.ConcatArrays(
+	m[expand.BsonTruncated] = expandInDBCommon.Truncated(analysisNested, expr.Size(expr.ObjectToArray(prefix+expand.BsonNested)))
+
+	if analysisNested != false {
+		m[expand.BsonNested] = expandInDBCommon.NestedIf(analysisNested, expr.ConcatArrays(
 			[]interface{}{nil}, // null represent parent field
-			processObject(prefix+expand.BsonNested, level+1, expandOptions),
-		)
+			processObject(prefix+expand.BsonNested, fullName, level+1, expandOptions),
+		))
 	} else {
 		m[expand.BsonNested] = nil
 	}
@@ -237,11 +265,13 @@ func processNestedObjectField(fullName interface{}, prefix string, analysisNeste
 	return m
 }
 
-func processNestedArrayField(fullName interface{}, prefix string, analysisNested bool, level uint, expandOptions *expand.Options) bson.M {
+func processNestedArrayField(fullName interface{}, prefix string, analysisNested interface{}, level uint, expandOptions *expand.Options) bson.M {
 	m := bson.M{
 		expand.BsonFieldName: fullName,
 		expand.BsonFieldType: prefix + expand.BsonFieldType,
 		expand.BsonLevel:     level,
+		expand.BsonParent:    prefix + expand.BsonParent,
+		analysis.BsonId:      expandInDBCommon.DocId,
 	}
 
 	// Array length
@@ -253,25 +283,44 @@ func processNestedArrayField(fullName interface{}, prefix string, analysisNested
 		m[expand.BsonValue] = prefix + expand.BsonValue
 	}
 
-	if analysisNested {
+	// Nested contains only the first items, skipped items are counted by processArrayField
+	m[expand.BsonTruncated] = expandInDBCommon.Truncated(analysisNested, expr.Add(expr.Size(prefix+expand.BsonNested), prefix+expand.BsonSkipped))
+	switch analysisNested {
+	case true:
+		m[expand.BsonSkipped] = prefix + expand.BsonSkipped
+	case false:
+		m[expand.BsonSkipped] = 0
+	default:
+		m[expand.BsonSkipped] = expr.Cond(analysisNested, prefix+expand.BsonSkipped, 0)
+	}
+
+	if analysisNested != false {
 		field := expandInDBCommon.FieldVars{
-			Name:  analysis.ArrayItemMark,
-			Type:  expr.Var(expand.BsonFieldType),
-			Value: expr.Var("i"),
-			Level: level + 1,
+			Name:   analysis.ArrayItemMark,
+			Type:   expr.Var(expand.BsonFieldType),
+			Value:  expr.Var("i"),
+			Level:  level + 1,
+			Parent: expandInDBCommon.ItemParent(expr.Var("ix")),
 		}
 
-		m[expand.BsonNested] = expr.ConcatArrays(
+		// Items are iterated by index, so the first item can be recognized, removed items are skipped
+		m[expand.BsonNested] = expandInDBCommon.NestedIf(analysisNested, expr.ConcatArrays(
 			[]interface{}{nil}, // null represent parent field
-			expr.Map(
-				prefix+expand.BsonNested,
-				"i",
-				expr.Let(
-					bson.M{expand.BsonFieldType: bson.M{"$type": field.Value}},
-					processField(field, expandOptions),
+			expr.Let(
+				bson.M{"xs": expandInDBCommon.KeptItems(prefix+expand.BsonNested, "", expandOptions)},
+				expr.Map(
+					expr.Range(0, expr.Size(expr.Var("xs"))),
+					"ix",
+					expr.Let(
+						bson.M{"i": expr.ArrayElemAt(expr.Var("xs"), expr.Var("ix"))},
+						expr.Let(
+							bson.M{expand.BsonFieldType: bson.M{"$type": field.Value}},
+							processField(field, fullName, expandOptions),
+						),
+					),
 				),
 			),
-		)
+		))
 	} else {
 		m[expand.BsonNested] = nil
 	}
@@ -284,7 +333,9 @@ func processNestedScalarField(fullName interface{}, prefix string, level uint, e
 		expand.BsonFieldName: fullName,
 		expand.BsonFieldType: prefix + expand.BsonFieldType,
 		expand.BsonLevel:     level,
+		expand.BsonParent:    prefix + expand.BsonParent,
 		expand.BsonNested:    nil,
+		analysis.BsonId:      expandInDBCommon.DocId,
 	}
 
 	if expandOptions.StoreValue {
@@ -86,6 +86,36 @@ func TestExpandInDBSeqStringFieldAll(t *testing.T) {
 	expandTests.RunTestStringFieldAll(t, NewStage)
 }
 
+func TestExpandInDBSeqFieldsExclude(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestFieldsExclude(t, NewStage)
+}
+
+func TestExpandInDBSeqFieldsInclude(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestFieldsInclude(t, NewStage)
+}
+
+func TestExpandInDBSeqFieldsValue(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestFieldsValue(t, NewStage)
+}
+
+func TestExpandInDBSeqDepthFor(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestDepthFor(t, NewStage)
+}
+
+func TestExpandInDBSeqArrayMaxLengthFor(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestArrayMaxLengthFor(t, NewStage)
+}
+
+func TestExpandInDBSeqEscapedKeys(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	expandTests.RunTestEscapedKeys(t, NewStage)
+}
+
 func BenchmarkExpandInDBSeqDepth0MinFull(b *testing.B) {
 	tests.SkipBIfNotSupportAggregationAlgorithm(b)
 	expandTests.RunBenchmarkDepth0Min(b, NewStage)
@@ -2,6 +2,8 @@
 package expandLocally
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
@@ -20,13 +22,13 @@ import (
 // processing binary data from the database to achieve maximum speed.
 func NewStage(expandOptions *expand.Options) *analysis.Stage {
 	return &analysis.Stage{
-		Processor: func(_input interface{}, analysisOptions *analysis.Options) interface{} {
+		Processor: func(ctx context.Context, _input interface{}, analysisOptions *analysis.Options) (interface{}, error) {
 			// Assert type of input channel
 			var input <-chan []byte
 			if _input, ok := _input.(chan []byte); ok {
 				input = _input
 			} else {
-				panic("Unexpected input. Expected 'chan []byte'. Given: " + reflect.TypeOf(_input).String())
+				return nil, errors.New("Unexpected input. Expected 'chan []byte'. Given: " + reflect.TypeOf(_input).String())
 			}
 
 			// Create output channel and wait group
@@ -36,7 +38,7 @@ func NewStage(expandOptions *expand.Options) *analysis.Stage {
 
 			// Run workers
 			for i := 0; i < analysisOptions.Concurrency; i++ {
-				go expandWorker(input, output, expandOptions, wg)
+				go expandWorker(ctx, input, output, expandOptions, wg)
 			}
 
 			// Close the channel after work
@@ -45,57 +47,158 @@ func NewStage(expandOptions *expand.Options) *analysis.Stage {
 				close(output)
 			}()
 
-			return output
+			return output, nil
 		},
 	}
 }
 
 // Expand worker is gradually processing binary documents from input channel.
-func expandWorker(input <-chan []byte, output chan<- expand.Value, options *expand.Options, wg *sync.WaitGroup) {
+// Values of document are sent only if whole document is decoded, so corrupted document can be skipped.
+// When the context is done, the input is drained without processing.
+func expandWorker(ctx context.Context, input <-chan []byte, output chan<- expand.Value, options *expand.Options, wg *sync.WaitGroup) {
 	defer wg.Done()
 
+	values := make([]expand.Value, 0, 64)
+
 	for bin := range input {
-		d := decoder.NewDecoder(bin)
-		processDocument(d, "", 0, options, output, true)
+		if ctx.Err() != nil {
+			continue
+		}
+
+		values = values[:0]
+		if err := expandDocument(bin, options, &values); err != nil {
+			analysis.Corrupted(ctx, err)
+			continue
+		}
+
+		for _, v := range values {
+			output <- v
+		}
+	}
+}
+
+// Expand binary document to values, corrupted document is returned as error.
+func expandDocument(bin []byte, options *expand.Options, values *[]expand.Value) (err error) {
+	defer decoder.Recover(&err)
+
+	root := expand.FieldIncluded
+	if options.Fields != nil {
+		root = options.Fields.Root()
 	}
+
+	processDocument(decoder.NewDecoder(bin), "", 0, options, values, true, root)
+	markFirstInDocument(*values)
+
+	if options.Shapes != nil {
+		options.Shapes.Add(*values, func() interface{} { return documentId(bin) })
+	}
+
+	return nil
+}
+
+// Get _id of binary document.
+func documentId(bin []byte) interface{} {
+	doc := struct {
+		Id interface{} `bson:"_id"`
+	}{}
+	bson.Unmarshal(bin, &doc)
+
+	return doc.Id
+}
+
+// Mark the first value of each field in the document.
+// Fields in arrays can have more values in one document.
+func markFirstInDocument(values []expand.Value) {
+	seen := make(map[string]bool, len(values))
+	for i := range values {
+		if !seen[values[i].Name] {
+			seen[values[i].Name] = true
+			values[i].Doc = 1
+		}
+	}
+}
+
+// Match the field by the filter, the field is skipped if it is excluded or if it is a scalar value of a parent.
+func matchField(name string, kind byte, level uint, parent expand.FieldMatch, options *expand.Options) (expand.FieldMatch, bool) {
+	if options.Fields == nil || level > options.MaxLevel() {
+		return parent, true
+	}
+
+	match := options.Fields.Match(name, parent)
+	switch match {
+	case expand.FieldIncluded:
+		return match, true
+	case expand.FieldParent:
+		return match, kind == 0x03 || kind == 0x04
+	}
+
+	return match, false
 }
 
-// Process binary document.
-func processDocument(d *decoder.Decoder, prefix string, level uint, options *expand.Options, output chan<- expand.Value, send bool) bson.M {
+// Process binary document, match is the result of the field filter for the document.
+// Returned object contains only kept fields, length is the number of all fields.
+func processDocument(d *decoder.Decoder, prefix string, level uint, options *expand.Options, output *[]expand.Value, send bool, match expand.FieldMatch) (bson.M, uint) {
 	_, end := d.ReadLength()
 
 	m := bson.M{}
+	length := uint(0)
+
+	// Keys are collected only for nested objects, whose values are sent
+	var keys []objectKey
+	detectMap := send && prefix != "" && isMapDetectionEnabled(options)
 
 	for d.CurrentByte() != '\x00' {
 		d.AssertBefore(end)
 
 		kind := d.ReadByte()
 		name := d.ReadCStr()
+		length++
 
 		var subName string
 		if prefix != "" {
-			subName = prefix + analysis.NameSeparator + name
+			subName = prefix + analysis.NameSeparator + analysis.EscapeKey(name)
 		} else {
-			subName = name
+			subName = analysis.EscapeKey(name)
+		}
+
+		fieldMatch, keep := matchField(subName, kind, level, match, options)
+		if !keep {
+			d.SkipValue(kind)
+			d.AssertBefore(end)
+			continue
 		}
 
-		v := processField(subName, kind, d, level, options, output, send)
+		if detectMap {
+			keys = append(keys, objectKey{name: name, kind: kind, start: len(*output)})
+		}
+
+		v := processField(subName, kind, d, level, 1, options, output, send, fieldMatch)
 
 		m[name] = v
 
+		if detectMap {
+			keys[len(keys)-1].end = len(*output)
+		}
+
 		d.AssertBefore(end)
 	}
 
 	d.AssertEnd(end)
 
-	return m
+	if detectMap && isMap(prefix, keys, options) {
+		collapseMap(prefix, keys, *output)
+	}
+
+	return m, length
 }
 
 // Process one field of binary document.
-func processField(name string, kind byte, d *decoder.Decoder, level uint, options *expand.Options, output chan<- expand.Value, send bool) interface{} {
+// Parent is 1 if it is the first value of the field in the parent object or array.
+func processField(name string, kind byte, d *decoder.Decoder, level uint, parent uint, options *expand.Options, output *[]expand.Value, send bool, match expand.FieldMatch) interface{} {
 	value := expand.Value{
-		Name:  name,
-		Level: level,
+		Name:   name,
+		Level:  level,
+		Parent: parent,
 	}
 
 	switch kind {
@@ -126,19 +229,21 @@ func processField(name string, kind byte, d *decoder.Decoder, level uint, option
 	case 0x03: // Document
 		value.Type = "object"
 
-		subSend := send
-		if level >= options.MaxDepth {
-			subSend = false
-		}
+		expandFields := options.ExpandNested(name, level)
+		subSend := send && expandFields
 
-		m := processDocument(d, name, level+1, options, output, subSend)
+		m, length := processDocument(d, name, level+1, options, output, subSend, match)
+
+		if !expandFields && length > 0 {
+			value.Truncated = 1
+		}
 
 		if options.StoreValue {
 			value.Value = m
 		}
 
 		if options.StoreObjectLength {
-			value.Length = uint(len(m))
+			value.Length = length
 		}
 	case 0x04: // Array
 		value.Type = "array"
@@ -147,22 +252,60 @@ func processField(name string, kind byte, d *decoder.Decoder, level uint, option
 		_, arrayEnd := d.ReadLength()
 
 		length := uint(0)
+		kept := uint(0)
+		maxLength := options.ArrayMaxLengthOf(name)
+		expandItems := options.ExpandNested(name, level)
 		values := []interface{}{}
+
+		var stats *arrayStats
+		if options.StoreArrayStats {
+			stats = newArrayStats()
+		}
+
 		for d.CurrentByte() != '\x00' {
 			d.AssertBefore(arrayEnd)
 
 			subKind := d.ReadByte()
 			d.ReadCStr() // read array key
+			start := d.Position()
+
+			// Removed items are counted to the length, but they are not reported
+			itemMatch, keep := matchField(subName, subKind, level+1, match, options)
+			if !keep {
+				d.SkipValue(subKind)
+				if stats != nil {
+					stats.add(subKind, d.In[start:d.Position()])
+				}
+				d.AssertBefore(arrayEnd)
+				length++
+				continue
+			}
 
 			// The value of item must be always read, but not always continue to next stages
-			subSend := send
-			if length >= options.ArrayMaxLength || level >= options.MaxDepth {
-				subSend = false
+			subSend := send && expandItems && length < maxLength
+
+			// Only the first reported item is the first value in the array
+			itemParent := uint(0)
+			if kept == 0 {
+				itemParent = 1
 			}
+			kept++
+
+			v := processField(subName, subKind, d, level+1, itemParent, options, output, subSend, itemMatch)
 
-			v := processField(subName, subKind, d, level+1, options, output, subSend)
+			if stats != nil {
+				stats.add(subKind, d.In[start:d.Position()])
+			}
 
-			if options.StoreValue && length < options.ArrayMaxLength {
+			// Item is also sent as positional field, eg. tags.[0]
+			if subSend && length < options.ArrayPositions {
+				item := (*output)[len(*output)-1]
+				item.Name = name + analysis.NameSeparator + analysis.ArrayPositionMark(length)
+				item.Parent = 1
+				*output = append(*output, item)
+			}
+
+			if options.StoreValue && length < maxLength {
 				values = append(values, v)
 			}
 
@@ -173,6 +316,16 @@ func processField(name string, kind byte, d *decoder.Decoder, level uint, option
 
 		d.AssertEnd(arrayEnd)
 
+		if !expandItems && length > 0 {
+			value.Truncated = 1
+		} else if expandItems && length > maxLength {
+			value.SkippedItems = length - maxLength
+		}
+
+		if stats != nil {
+			value.ArrayFlags = stats.flags()
+		}
+
 		if options.StoreValue {
 			value.Value = values
 		}
@@ -319,11 +472,11 @@ func processField(name string, kind byte, d *decoder.Decoder, level uint, option
 			value.Value = bson.MinKey
 		}
 	default:
-		panic(fmt.Sprintf("Unknown element kind (0x%02X)", kind))
+		panic(&decoder.CorruptedError{Reason: fmt.Sprintf("unknown element kind (0x%02X)", kind)})
 	}
 
 	if send {
-		output <- value
+		*output = append(*output, value)
 	}
 
 	return value.Value
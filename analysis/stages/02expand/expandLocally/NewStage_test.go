@@ -1,12 +1,14 @@
 package expandLocally
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
 	"github.com/mongoeye/mongoeye/analysis/stages/02expand/tests"
 	"github.com/mongoeye/mongoeye/decoder"
 	"github.com/mongoeye/mongoeye/tests"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
 	"testing"
 	"time"
 )
@@ -75,22 +77,54 @@ func TestExpandLocallyStringFieldAll(t *testing.T) {
 	expandTests.RunTestStringFieldAll(t, NewStage)
 }
 
+func TestExpandLocallyFieldsExclude(t *testing.T) {
+	expandTests.RunTestFieldsExclude(t, NewStage)
+}
+
+func TestExpandLocallyFieldsInclude(t *testing.T) {
+	expandTests.RunTestFieldsInclude(t, NewStage)
+}
+
+func TestExpandLocallyFieldsValue(t *testing.T) {
+	expandTests.RunTestFieldsValue(t, NewStage)
+}
+
+func TestExpandLocallyDepthFor(t *testing.T) {
+	expandTests.RunTestDepthFor(t, NewStage)
+}
+
+func TestExpandLocallyArrayMaxLengthFor(t *testing.T) {
+	expandTests.RunTestArrayMaxLengthFor(t, NewStage)
+}
+
+func TestExpandLocallyEscapedKeys(t *testing.T) {
+	expandTests.RunTestEscapedKeys(t, NewStage)
+}
+
 func TestExpandLocallyInvalidFieldKind(t *testing.T) {
 	d := decoder.NewDecoder([]byte("abcdefgh"))
 
 	assert.Panics(t, func() {
-		processField("name", 0xEE, d, 0, &expand.Options{}, nil, false)
+		processField("name", 0xEE, d, 0, 1, &expand.Options{}, nil, false, expand.FieldIncluded)
 	})
 }
 
+func TestExpandLocallyCorruptedDocument(t *testing.T) {
+	bin, _ := bson.Marshal(bson.D{{Name: "a", Value: 1}, {Name: "b", Value: "abc"}})
+	bin[len(bin)-2] = 0x01 // damage the end of string
+
+	values := []expand.Value{}
+	err := expandDocument(bin, &expand.Options{}, &values)
+	assert.IsType(t, &decoder.CorruptedError{}, err)
+}
+
 func TestExpandLocallyInvalidChannelType(t *testing.T) {
 	stage := NewStage(&expand.Options{})
 
 	ch := make(chan int)
 
-	assert.Panics(t, func() {
-		stage.Processor(ch, &analysis.Options{Location: time.UTC, Concurrency: 1, BufferSize: 1, BatchSize: 1})
-	})
+	_, err := stage.Processor(context.Background(), ch, &analysis.Options{Location: time.UTC, Concurrency: 1, BufferSize: 1, BatchSize: 1})
+	assert.Error(t, err)
 }
 
 func BenchmarkExpandLocallyDepth0MinFull(b *testing.B) {
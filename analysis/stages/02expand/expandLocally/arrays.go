@@ -0,0 +1,110 @@
+package expandLocally
+
+import (
+	"bytes"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/mongoeye/mongoeye/decoder"
+)
+
+// Items of one array collected for expand.Value.ArrayFlags.
+type arrayStats struct {
+	count       uint
+	kind        byte // kind of the first item
+	homogeneous bool
+	sorted      bool
+	duplicates  bool
+	previous    orderKey
+	seen        map[string]struct{} // kind and binary value of items
+}
+
+func newArrayStats() *arrayStats {
+	return &arrayStats{homogeneous: true, sorted: true}
+}
+
+// Add the item, raw is its binary value.
+func (s *arrayStats) add(kind byte, raw []byte) {
+	if s.count == 0 {
+		s.kind = kind
+	} else if kind != s.kind {
+		s.homogeneous = false
+	}
+
+	if s.sorted {
+		key, ok := newOrderKey(kind, raw)
+		if !ok || (s.count > 0 && key.less(s.previous)) {
+			s.sorted = false
+		}
+		s.previous = key
+	}
+
+	if !s.duplicates {
+		if s.seen == nil {
+			s.seen = make(map[string]struct{})
+		}
+		id := string(kind) + string(raw)
+		if _, found := s.seen[id]; found {
+			s.duplicates = true
+			s.seen = nil
+		} else {
+			s.seen[id] = struct{}{}
+		}
+	}
+
+	s.count++
+}
+
+func (s *arrayStats) flags() uint {
+	if s.count == 0 {
+		return expand.ArrayEmpty
+	}
+
+	flags := uint(0)
+	if s.homogeneous {
+		flags |= expand.ArrayHomogeneous
+	}
+	if s.duplicates {
+		flags |= expand.ArrayDuplicates
+	}
+	if s.sorted && s.count > 1 {
+		flags |= expand.ArraySorted
+	}
+	return flags
+}
+
+// Key of the item for the check of order, numbers of different types are compared together.
+type orderKey struct {
+	class  byte
+	number float64
+	bytes  []byte
+}
+
+// Items that are not numbers, strings, dates or objectIds are not ordered.
+func newOrderKey(kind byte, raw []byte) (orderKey, bool) {
+	d := decoder.NewDecoder(raw)
+	switch kind {
+	case 0x01: // Float64
+		return orderKey{class: 'n', number: d.ReadFloat64()}, true
+	case 0x10: // Int32
+		return orderKey{class: 'n', number: float64(d.ReadInt32())}, true
+	case 0x12: // Int64
+		return orderKey{class: 'n', number: float64(d.ReadInt64())}, true
+	case 0x02: // UTF-8 string
+		return orderKey{class: 's', bytes: []byte(d.ReadStr())}, true
+	case 0x09: // Date
+		return orderKey{class: 'd', number: float64(d.ReadInt64())}, true
+	case 0x07: // ObjectId
+		return orderKey{class: 'o', bytes: raw}, true
+	}
+	return orderKey{}, false
+}
+
+// Keys of different classes are never in order.
+func (k orderKey) less(other orderKey) bool {
+	if k.class != other.class {
+		return true
+	}
+	if k.class == 's' || k.class == 'o' {
+		return bytes.Compare(k.bytes, other.bytes) < 0
+	}
+	return k.number < other.number
+}
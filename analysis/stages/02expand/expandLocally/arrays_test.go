@@ -0,0 +1,88 @@
+package expandLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+func TestExpandLocallyArrays_Flags(t *testing.T) {
+	oid := bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2")
+	date := time.Date(2017, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		items    []interface{}
+		expected uint
+	}{
+		{[]interface{}{}, expand.ArrayEmpty},
+		{[]interface{}{1}, expand.ArrayHomogeneous},
+		{[]interface{}{1, 2, 2, 3}, expand.ArrayHomogeneous | expand.ArrayDuplicates | expand.ArraySorted},
+		{[]interface{}{3, 1, 2}, expand.ArrayHomogeneous},
+		{[]interface{}{1, 2.5, int64(3)}, expand.ArraySorted},
+		{[]interface{}{1, int64(1)}, expand.ArraySorted},
+		{[]interface{}{"a", "b", "b"}, expand.ArrayHomogeneous | expand.ArrayDuplicates | expand.ArraySorted},
+		{[]interface{}{"b", "a"}, expand.ArrayHomogeneous},
+		{[]interface{}{1, "a"}, 0},
+		{[]interface{}{date, date.Add(time.Hour)}, expand.ArrayHomogeneous | expand.ArraySorted},
+		{[]interface{}{oid, oid}, expand.ArrayHomogeneous | expand.ArrayDuplicates | expand.ArraySorted},
+		{[]interface{}{true, false}, expand.ArrayHomogeneous},
+		{[]interface{}{bson.M{"a": 1}, bson.M{"a": 1}}, expand.ArrayHomogeneous | expand.ArrayDuplicates},
+		{[]interface{}{bson.D{{Name: "a", Value: 1}, {Name: "b", Value: 1}}, bson.D{{Name: "b", Value: 1}, {Name: "a", Value: 1}}}, expand.ArrayHomogeneous},
+		{[]interface{}{nil, nil}, expand.ArrayHomogeneous | expand.ArrayDuplicates},
+	}
+
+	for _, c := range cases {
+		values := expandTestDocument(t, bson.M{"a": c.items}, &expand.Options{
+			MaxDepth:        0,
+			ArrayMaxLength:  1,
+			StoreArrayStats: true,
+		})
+
+		assert.Equal(t, c.expected, values[len(values)-1].ArrayFlags, "items %v", c.items)
+	}
+}
+
+func TestExpandLocallyArrays_FlagsDisabled(t *testing.T) {
+	values := expandTestDocument(t, bson.M{"a": []interface{}{}}, &expand.Options{})
+
+	assert.Equal(t, uint(0), values[0].ArrayFlags)
+}
+
+func TestExpandLocallyArrays_Positions(t *testing.T) {
+	doc := bson.D{
+		{Name: "point", Value: []interface{}{14.4, 50.1, bson.M{"alt": 200}}},
+	}
+
+	values := expandTestDocument(t, doc, &expand.Options{
+		MaxDepth:       2,
+		ArrayMaxLength: 10,
+		ArrayPositions: 2,
+		StoreValue:     true,
+	})
+
+	assert.Equal(t, []expand.Value{
+		{Name: "point.[]", Type: "double", Level: 1, Value: 14.4, Doc: 1, Parent: 1},
+		{Name: "point.[0]", Type: "double", Level: 1, Value: 14.4, Doc: 1, Parent: 1},
+		{Name: "point.[]", Type: "double", Level: 1, Value: 50.1, Doc: 0, Parent: 0},
+		{Name: "point.[1]", Type: "double", Level: 1, Value: 50.1, Doc: 1, Parent: 1},
+		{Name: "point.[].alt", Type: "int", Level: 2, Value: 200, Doc: 1, Parent: 1},
+		{Name: "point.[]", Type: "object", Level: 1, Value: bson.M{"alt": 200}, Doc: 0, Parent: 0},
+		{Name: "point", Type: "array", Level: 0, Value: []interface{}{14.4, 50.1, bson.M{"alt": 200}}, Doc: 1, Parent: 1},
+	}, values)
+}
+
+func TestExpandLocallyArrays_PositionsBeyondMaxLength(t *testing.T) {
+	values := expandTestDocument(t, bson.M{"a": []interface{}{1, 2, 3}}, &expand.Options{
+		MaxDepth:       1,
+		ArrayMaxLength: 1,
+		ArrayPositions: 3,
+	})
+
+	names := []string{}
+	for _, v := range values {
+		names = append(names, v.Name)
+	}
+	assert.Equal(t, []string{"a.[]", "a.[0]", "a"}, names)
+}
@@ -0,0 +1,87 @@
+package expandLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+var fieldsTestDoc = bson.D{
+	{Name: "name", Value: "Alice"},
+	{Name: "audit", Value: bson.D{
+		{Name: "by", Value: "admin"},
+		{Name: "raw", Value: []byte{1, 2, 3}},
+	}},
+	{Name: "items", Value: []interface{}{
+		bson.D{{Name: "price", Value: 10}, {Name: "raw", Value: "x"}},
+		5,
+		bson.D{{Name: "price", Value: 20}},
+	}},
+	{Name: "data", Value: bson.D{
+		{Name: "raw", Value: "abc"},
+		{Name: "size", Value: 3},
+	}},
+}
+
+func TestExpandLocallyFields_Exclude(t *testing.T) {
+	fields, err := expand.NewFieldFilter(nil, []string{"audit", "**.raw"})
+	assert.Nil(t, err)
+
+	values := expandTestDocument(t, fieldsTestDoc, &expand.Options{
+		MaxDepth:          5,
+		StringMaxLength:   100,
+		ArrayMaxLength:    10,
+		StoreValue:        true,
+		StoreArrayLength:  true,
+		StoreObjectLength: true,
+		Fields:            fields,
+	})
+
+	assert.Equal(t, []expand.Value{
+		{Name: "name", Type: "string", Level: 0, Value: "Alice", Doc: 1, Parent: 1},
+		{Name: "items.[].price", Type: "int", Level: 2, Value: 10, Doc: 1, Parent: 1},
+		{Name: "items.[]", Type: "object", Level: 1, Length: 2, Value: bson.M{"price": 10}, Doc: 1, Parent: 1},
+		{Name: "items.[]", Type: "int", Level: 1, Value: 5, Doc: 0, Parent: 0},
+		{Name: "items.[].price", Type: "int", Level: 2, Value: 20, Doc: 0, Parent: 1},
+		{Name: "items.[]", Type: "object", Level: 1, Length: 1, Value: bson.M{"price": 20}, Doc: 0, Parent: 0},
+		{Name: "items", Type: "array", Level: 0, Length: 3, Value: []interface{}{bson.M{"price": 10}, 5, bson.M{"price": 20}}, Doc: 1, Parent: 1},
+		{Name: "data.size", Type: "int", Level: 1, Value: 3, Doc: 1, Parent: 1},
+		{Name: "data", Type: "object", Level: 0, Length: 2, Value: bson.M{"size": 3}, Doc: 1, Parent: 1},
+	}, values)
+}
+
+func TestExpandLocallyFields_Include(t *testing.T) {
+	fields, err := expand.NewFieldFilter([]string{"items.[].price"}, nil)
+	assert.Nil(t, err)
+
+	values := expandTestDocument(t, fieldsTestDoc, &expand.Options{
+		MaxDepth:         5,
+		ArrayMaxLength:   10,
+		StoreArrayLength: true,
+		Fields:           fields,
+	})
+
+	// Scalar items are not reported, but they are counted to the length of the parent array
+	assert.Equal(t, []expand.Value{
+		{Name: "items.[].price", Type: "int", Level: 2, Doc: 1, Parent: 1},
+		{Name: "items.[]", Type: "object", Level: 1, Doc: 1, Parent: 1},
+		{Name: "items.[].price", Type: "int", Level: 2, Doc: 0, Parent: 1},
+		{Name: "items.[]", Type: "object", Level: 1, Doc: 0, Parent: 0},
+		{Name: "items", Type: "array", Level: 0, Length: 3, Doc: 1, Parent: 1},
+	}, values)
+}
+
+func TestExpandLocallyFields_MaxDepth(t *testing.T) {
+	fields, err := expand.NewFieldFilter(nil, []string{"**.raw"})
+	assert.Nil(t, err)
+
+	// Nested fields deeper than max depth are not filtered
+	values := expandTestDocument(t, fieldsTestDoc, &expand.Options{
+		MaxDepth:          0,
+		StoreObjectLength: true,
+		Fields:            fields,
+	})
+
+	assert.Equal(t, expand.Value{Name: "data", Type: "object", Level: 0, Length: 2, Doc: 1, Parent: 1, Truncated: 1}, values[3])
+}
@@ -0,0 +1,74 @@
+package expandLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+func TestExpandLocallyKeys_Escaped(t *testing.T) {
+	doc := bson.D{
+		{Name: "a.b", Value: bson.M{"c": 1}},
+		{Name: "", Value: "empty"},
+		{Name: "$x", Value: true},
+		{Name: "[]", Value: nil},
+		{Name: `q"`, Value: 1},
+	}
+
+	values := expandTestDocument(t, doc, &expand.Options{MaxDepth: 5})
+	assert.Equal(t, []expand.Value{
+		{Name: `"a.b".c`, Type: "int", Level: 1, Doc: 1, Parent: 1},
+		{Name: `"a.b"`, Type: "object", Level: 0, Doc: 1, Parent: 1},
+		{Name: `""`, Type: "string", Level: 0, Doc: 1, Parent: 1},
+		{Name: "$x", Type: "bool", Level: 0, Doc: 1, Parent: 1},
+		{Name: `"[]"`, Type: "null", Level: 0, Doc: 1, Parent: 1},
+		{Name: `q"`, Type: "int", Level: 0, Doc: 1, Parent: 1},
+	}, values)
+}
+
+func TestExpandLocallyKeys_Filters(t *testing.T) {
+	doc := bson.D{
+		{Name: "a.b", Value: bson.D{
+			{Name: "c", Value: 1},
+			{Name: "d", Value: bson.M{"e": 1}},
+		}},
+		{Name: "a", Value: bson.M{"b": 1}},
+	}
+
+	fields, err := expand.NewFieldFilter([]string{`"a.b".*`}, nil)
+	assert.Nil(t, err)
+
+	values := expandTestDocument(t, doc, &expand.Options{MaxDepth: 5, Fields: fields})
+	names := []string{}
+	for _, v := range values {
+		names = append(names, v.Name)
+	}
+	assert.Equal(t, []string{`"a.b".c`, `"a.b".d.e`, `"a.b".d`, `"a.b"`}, names)
+
+	values = expandTestDocument(t, doc, &expand.Options{MaxDepth: 0, DepthFor: expand.PathLimits{`"a.b"`: 1}})
+	names = []string{}
+	for _, v := range values {
+		names = append(names, v.Name)
+	}
+	assert.Equal(t, []string{`"a.b".c`, `"a.b".d`, `"a.b"`, "a"}, names)
+}
+
+func TestExpandLocallyKeys_Map(t *testing.T) {
+	doc := bson.D{
+		{Name: "hosts", Value: bson.D{
+			{Name: "10.0.0.1", Value: bson.M{"up": true}},
+			{Name: "10.0.0.2", Value: bson.M{"up": true}},
+			{Name: "10.0.0.3", Value: bson.M{"up": false}},
+		}},
+	}
+
+	values := expandTestDocument(t, doc, &expand.Options{MaxDepth: 5, MapMaxKeys: 3})
+	assert.Equal(t, "hosts.{key}.up", values[0].Name)
+	assert.Equal(t, "hosts.{key}", values[1].Name)
+
+	// Disabled path
+	values = expandTestDocument(t, doc, &expand.Options{MaxDepth: 5, MapMaxKeys: 3, NoMapPaths: []string{"hosts"}})
+	assert.Equal(t, `hosts."10.0.0.1".up`, values[0].Name)
+	assert.Equal(t, `hosts."10.0.0.1"`, values[1].Name)
+}
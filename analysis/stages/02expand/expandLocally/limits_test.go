@@ -0,0 +1,58 @@
+package expandLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+var limitsTestDoc = bson.D{
+	{Name: "order", Value: bson.D{
+		{Name: "items", Value: []interface{}{
+			bson.D{{Name: "price", Value: 10}},
+		}},
+		{Name: "note", Value: bson.D{{Name: "text", Value: "x"}}},
+	}},
+	{Name: "tags", Value: []interface{}{1, 2, 3}},
+	{Name: "codes", Value: []interface{}{1, 2, 3}},
+}
+
+func TestExpandLocallyLimits_DepthFor(t *testing.T) {
+	values := expandTestDocument(t, limitsTestDoc, &expand.Options{
+		MaxDepth:       0,
+		ArrayMaxLength: 10,
+		DepthFor:       expand.PathLimits{"order.items": 5},
+	})
+
+	// Parent of the path is expanded, siblings are not
+	assert.Equal(t, []expand.Value{
+		{Name: "order.items.[].price", Type: "int", Level: 3, Doc: 1, Parent: 1},
+		{Name: "order.items.[]", Type: "object", Level: 2, Doc: 1, Parent: 1},
+		{Name: "order.items", Type: "array", Level: 1, Doc: 1, Parent: 1},
+		{Name: "order.note", Type: "object", Level: 1, Doc: 1, Parent: 1, Truncated: 1},
+		{Name: "order", Type: "object", Level: 0, Doc: 1, Parent: 1},
+		{Name: "tags", Type: "array", Level: 0, Doc: 1, Parent: 1, Truncated: 1},
+		{Name: "codes", Type: "array", Level: 0, Doc: 1, Parent: 1, Truncated: 1},
+	}, values)
+}
+
+func TestExpandLocallyLimits_ArrayMaxLengthFor(t *testing.T) {
+	values := expandTestDocument(t, limitsTestDoc, &expand.Options{
+		MaxDepth:          1,
+		ArrayMaxLength:    1,
+		ArrayMaxLengthFor: expand.PathLimits{"tags": 2},
+		StoreArrayLength:  true,
+	})
+
+	assert.Equal(t, []expand.Value{
+		{Name: "order.items", Type: "array", Level: 1, Length: 1, Doc: 1, Parent: 1, Truncated: 1},
+		{Name: "order.note", Type: "object", Level: 1, Doc: 1, Parent: 1, Truncated: 1},
+		{Name: "order", Type: "object", Level: 0, Doc: 1, Parent: 1},
+		{Name: "tags.[]", Type: "int", Level: 1, Doc: 1, Parent: 1},
+		{Name: "tags.[]", Type: "int", Level: 1, Doc: 0, Parent: 0},
+		{Name: "tags", Type: "array", Level: 0, Length: 3, Doc: 1, Parent: 1, SkippedItems: 1},
+		{Name: "codes.[]", Type: "int", Level: 1, Doc: 1, Parent: 1},
+		{Name: "codes", Type: "array", Level: 0, Length: 3, Doc: 1, Parent: 1, SkippedItems: 2},
+	}, values)
+}
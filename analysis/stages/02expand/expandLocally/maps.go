@@ -0,0 +1,79 @@
+package expandLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/mongoeye/mongoeye/helpers"
+)
+
+// Key of the processed object and range of its values in the output.
+type objectKey struct {
+	name  string
+	kind  byte
+	start int
+	end   int
+}
+
+// Is detection of objects with dynamic keys enabled?
+func isMapDetectionEnabled(options *expand.Options) bool {
+	return options.MapMinKeys > 0 || options.MapMaxKeys > 0 || len(options.MapPaths) > 0
+}
+
+// Object is a map if all values have the same type and there are enough keys of the same shape (or enough keys at all).
+func isMap(name string, keys []objectKey, options *expand.Options) bool {
+	if helpers.InStringSlice(name, options.NoMapPaths) {
+		return false
+	}
+
+	if helpers.InStringSlice(name, options.MapPaths) {
+		return true
+	}
+
+	count := uint(len(keys))
+	if count == 0 || (options.MapMinKeys == 0 || count < options.MapMinKeys) && (options.MapMaxKeys == 0 || count < options.MapMaxKeys) {
+		return false
+	}
+
+	for _, key := range keys[1:] {
+		if key.kind != keys[0].kind {
+			return false
+		}
+	}
+
+	if options.MapMaxKeys > 0 && count >= options.MapMaxKeys {
+		return true
+	}
+
+	shape := expand.GetKeyShape(keys[0].name)
+	if shape == expand.KeyShapeOther {
+		return false
+	}
+
+	for _, key := range keys[1:] {
+		if expand.GetKeyShape(key.name) != shape {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Rename values of all keys to MapKeyMark, nested fields of the values are renamed too.
+// Only the first value is the first in the parent object.
+func collapseMap(name string, keys []objectKey, output []expand.Value) {
+	mapName := name + analysis.NameSeparator + analysis.MapKeyMark
+
+	for i, key := range keys {
+		keyName := name + analysis.NameSeparator + analysis.EscapeKey(key.name)
+		for j := key.start; j < key.end; j++ {
+			v := &output[j]
+			if v.Name == keyName {
+				v.KeyShape = expand.GetKeyShape(key.name)
+				if i > 0 {
+					v.Parent = 0
+				}
+			}
+			v.Name = mapName + v.Name[len(keyName):]
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package expandLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+func expandTestDocument(t *testing.T, doc interface{}, options *expand.Options) []expand.Value {
+	bin, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := []expand.Value{}
+	assert.Nil(t, expandDocument(bin, options, &values))
+	return values
+}
+
+func TestExpandLocallyMap(t *testing.T) {
+	doc := bson.D{
+		{Name: "stats", Value: bson.D{
+			{Name: "2017-04-01", Value: bson.M{"views": 1}},
+			{Name: "2017-04-02", Value: bson.M{"views": 2}},
+			{Name: "2017-04-03", Value: bson.M{"views": 3}},
+		}},
+	}
+
+	values := expandTestDocument(t, doc, &expand.Options{MaxDepth: 5, MapMinKeys: 3})
+	assert.Equal(t, []expand.Value{
+		{Name: "stats.{key}.views", Type: "int", Level: 2, Doc: 1, Parent: 1},
+		{Name: "stats.{key}", Type: "object", Level: 1, Doc: 1, Parent: 1, KeyShape: expand.KeyShapeDate},
+		{Name: "stats.{key}.views", Type: "int", Level: 2, Doc: 0, Parent: 1},
+		{Name: "stats.{key}", Type: "object", Level: 1, Doc: 0, Parent: 0, KeyShape: expand.KeyShapeDate},
+		{Name: "stats.{key}.views", Type: "int", Level: 2, Doc: 0, Parent: 1},
+		{Name: "stats.{key}", Type: "object", Level: 1, Doc: 0, Parent: 0, KeyShape: expand.KeyShapeDate},
+		{Name: "stats", Type: "object", Level: 0, Doc: 1, Parent: 1},
+	}, values)
+
+	// Not enough keys
+	values = expandTestDocument(t, doc, &expand.Options{MaxDepth: 5, MapMinKeys: 4})
+	assert.Equal(t, "stats.2017-04-01", values[1].Name)
+
+	// Disabled path
+	values = expandTestDocument(t, doc, &expand.Options{MaxDepth: 5, MapMinKeys: 3, NoMapPaths: []string{"stats"}})
+	assert.Equal(t, "stats.2017-04-01", values[1].Name)
+}
+
+func TestExpandLocallyMap_Heuristics(t *testing.T) {
+	options := &expand.Options{MaxDepth: 5, MapMinKeys: 2, MapMaxKeys: 4}
+	name := func(doc bson.D) string {
+		values := expandTestDocument(t, bson.D{{Name: "m", Value: doc}}, options)
+		return values[0].Name
+	}
+
+	// Keys with the same shape
+	assert.Equal(t, "m.{key}", name(bson.D{{Name: "1", Value: 1}, {Name: "2", Value: 2}}))
+
+	// Different shapes of keys
+	assert.Equal(t, "m.1", name(bson.D{{Name: "1", Value: 1}, {Name: "2017-04-01", Value: 2}}))
+
+	// Different types of values
+	assert.Equal(t, "m.1", name(bson.D{{Name: "1", Value: 1}, {Name: "2", Value: "a"}}))
+
+	// Names are not dynamic keys
+	assert.Equal(t, "m.a", name(bson.D{{Name: "a", Value: 1}, {Name: "b", Value: 2}}))
+
+	// Many keys of any shape
+	assert.Equal(t, "m.{key}", name(bson.D{{Name: "a", Value: 1}, {Name: "b", Value: 2}, {Name: "c", Value: 3}, {Name: "d", Value: 4}}))
+
+	// Forced path
+	options.MapPaths = []string{"m"}
+	assert.Equal(t, "m.{key}", name(bson.D{{Name: "a", Value: 1}, {Name: "b", Value: "x"}}))
+}
+
+func TestExpandLocallyMap_Nested(t *testing.T) {
+	doc := bson.D{
+		{Name: "users", Value: bson.D{
+			{Name: "58e20d849d3ae7e1f8eac9c0", Value: bson.D{{Name: "1", Value: true}, {Name: "2", Value: false}}},
+			{Name: "58e20d849d3ae7e1f8eac9c1", Value: bson.D{{Name: "3", Value: true}, {Name: "4", Value: true}}},
+		}},
+	}
+
+	values := expandTestDocument(t, doc, &expand.Options{MaxDepth: 5, MapMinKeys: 2})
+	names := []string{}
+	for _, v := range values {
+		names = append(names, v.Name)
+	}
+
+	assert.Equal(t, []string{
+		"users.{key}.{key}",
+		"users.{key}.{key}",
+		"users.{key}",
+		"users.{key}.{key}",
+		"users.{key}.{key}",
+		"users.{key}",
+		"users",
+	}, names)
+	assert.Equal(t, expand.KeyShapeNumber, values[0].KeyShape)
+	assert.Equal(t, expand.KeyShapeObjectId, values[2].KeyShape)
+}
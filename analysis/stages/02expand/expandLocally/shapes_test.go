@@ -0,0 +1,24 @@
+package expandLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+func TestExpandLocallyShapes(t *testing.T) {
+	options := &expand.Options{MaxDepth: 5, MapMinKeys: 3, Shapes: expand.NewShapes()}
+
+	expandTestDocument(t, bson.M{"_id": 1, "name": "a"}, options)
+	expandTestDocument(t, bson.M{"_id": 2, "name": "b", "stats": bson.M{"1": 1, "2": 2, "3": 3}}, options)
+	expandTestDocument(t, bson.M{"_id": 3, "name": "c", "stats": bson.M{"4": 4, "5": 5, "6": 6, "7": 7}}, options)
+
+	shapes, count := options.Shapes.Top(10)
+	assert.Equal(t, uint64(2), count)
+	assert.Equal(t, analysis.Shapes{
+		{Count: 2, Examples: []interface{}{2, 3}},
+		{Count: 1, Examples: []interface{}{1}, Removed: []string{"stats (object)", "stats.{key} (int)"}},
+	}, shapes)
+}
@@ -0,0 +1,327 @@
+package expand
+
+import (
+	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// FieldMatch is the result of matching a field by FieldFilter.
+type FieldMatch int
+
+// Results of matching.
+const (
+	FieldExcluded FieldMatch = iota // field is removed from the document together with its nested fields
+	FieldIncluded                   // field is analyzed, its nested fields are only checked for exclusion
+	FieldParent                     // field is analyzed only as a parent of included fields, scalar values are removed
+)
+
+// Max number of cached results, names of objects with dynamic keys are unlimited.
+const maxCachedFields = 100000
+
+// FieldFilter selects fields by glob patterns of their names, eg. "audit", "**.raw", "items.[].price".
+// Segments of the name are separated by analysis.NameSeparator, array items are matched by analysis.ArrayItemMark.
+// Keys are matched in the escaped form, eg. `"a.b"` matches key "a.b", see analysis.EscapeKey.
+// In one segment, "*" matches any characters and "?" one character, "**" matches any number of segments.
+//
+// If there are include patterns, then only matching fields and their parents are analyzed.
+// Fields matching exclude patterns are always removed.
+type FieldFilter struct {
+	include [][]string
+	exclude [][]string
+
+	cache     sync.Map // fieldKey -> FieldMatch
+	cacheSize int64
+}
+
+type fieldKey struct {
+	name   string
+	parent FieldMatch
+}
+
+// NewFieldFilter compiles patterns of included and excluded fields.
+func NewFieldFilter(include []string, exclude []string) (*FieldFilter, error) {
+	f := &FieldFilter{}
+
+	var err error
+	if f.include, err = splitPatterns(include); err != nil {
+		return nil, err
+	}
+	if f.exclude, err = splitPatterns(exclude); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func splitPatterns(patterns []string) ([][]string, error) {
+	out := make([][]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		segments := analysis.SplitName(pattern)
+		for _, s := range segments {
+			if s == "" || strings.Contains(s, "**") && s != "**" {
+				return nil, fmt.Errorf("Invalid field pattern '%s'.", pattern)
+			}
+		}
+		out = append(out, segments)
+	}
+	return out, nil
+}
+
+// Root returns match of the document, it is the parent of root fields.
+func (f *FieldFilter) Root() FieldMatch {
+	if len(f.include) > 0 {
+		return FieldParent
+	}
+	return FieldIncluded
+}
+
+// NeedsRegexMatch returns true if some pattern cannot be evaluated in database without $regexMatch (MongoDB 4.2+).
+func (f *FieldFilter) NeedsRegexMatch() bool {
+	for _, patterns := range [][][]string{f.include, f.exclude} {
+		for _, segments := range patterns {
+			for _, s := range segments {
+				if s != "*" && s != "**" && hasWildcard(s) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Match the field with full name, parent is the match of the parent object or array.
+func (f *FieldFilter) Match(name string, parent FieldMatch) FieldMatch {
+	key := fieldKey{name, parent}
+	if m, ok := f.cache.Load(key); ok {
+		return m.(FieldMatch)
+	}
+
+	segments := make([]interface{}, 0, 4)
+	for _, s := range analysis.SplitName(name) {
+		segments = append(segments, s)
+	}
+
+	m := FieldExcluded
+	if f.excluded(segments) == true {
+		// excluded with all nested fields
+	} else if parent == FieldIncluded || f.included(segments) == true {
+		m = FieldIncluded
+	} else if f.parentOfIncluded(segments) == true {
+		m = FieldParent
+	}
+
+	if atomic.AddInt64(&f.cacheSize, 1) <= maxCachedFields {
+		f.cache.Store(key, m)
+	}
+
+	return m
+}
+
+// Conditions of matching, used to build the filter in database.
+// Segments are strings or DynamicSegment, each condition is bool if the result is known, otherwise it is an aggregation expression.
+
+// DynamicSegment is segment of the name unknown before evaluation in database, eg. expression of escaped object key.
+type DynamicSegment struct {
+	Expr interface{}
+}
+
+// HasExcludes returns true if there are exclude patterns, otherwise fields of included objects are not filtered.
+func (f *FieldFilter) HasExcludes() bool {
+	return len(f.exclude) > 0
+}
+
+// IncludedExpr returns condition of inclusion of the field, parentIncluded is condition of inclusion of the parent.
+func (f *FieldFilter) IncludedExpr(segments []interface{}, parentIncluded interface{}) interface{} {
+	return and(not(f.excluded(segments)), or(parentIncluded, f.included(segments)))
+}
+
+// KeepExpr returns condition that the value of the field is kept, included is condition from IncludedExpr.
+// Objects and arrays are also kept as parents of included fields.
+func (f *FieldFilter) KeepExpr(segments []interface{}, included interface{}, valueType interface{}) interface{} {
+	parent := and(not(f.excluded(segments)), f.parentOfIncluded(segments))
+	return or(included, and(parent, expr.In(valueType, []interface{}{"object", "array"})))
+}
+
+func (f *FieldFilter) excluded(segments []interface{}) interface{} {
+	var conds []interface{}
+	for _, pattern := range f.exclude {
+		conds = append(conds, matchSegments(pattern, segments))
+	}
+	return or(conds...)
+}
+
+func (f *FieldFilter) included(segments []interface{}) interface{} {
+	var conds []interface{}
+	for _, pattern := range f.include {
+		conds = append(conds, matchSegments(pattern, segments))
+	}
+	return or(conds...)
+}
+
+func (f *FieldFilter) parentOfIncluded(segments []interface{}) interface{} {
+	var conds []interface{}
+	for _, pattern := range f.include {
+		conds = append(conds, matchPrefix(pattern, segments))
+	}
+	return or(conds...)
+}
+
+// Pattern matches all segments of the name.
+func matchSegments(pattern []string, segments []interface{}) interface{} {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		cond := matchSegments(pattern[1:], segments)
+		if len(segments) > 0 {
+			cond = or(cond, matchSegments(pattern, segments[1:]))
+		}
+		return cond
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	return and(matchSegment(pattern[0], segments[0]), matchSegments(pattern[1:], segments[1:]))
+}
+
+// Pattern can match some nested field of the name.
+func matchPrefix(pattern []string, segments []interface{}) interface{} {
+	if len(segments) == 0 {
+		return len(pattern) > 0
+	}
+
+	if len(pattern) == 0 {
+		return false
+	}
+
+	if pattern[0] == "**" {
+		return true
+	}
+
+	return and(matchSegment(pattern[0], segments[0]), matchPrefix(pattern[1:], segments[1:]))
+}
+
+func matchSegment(pattern string, segment interface{}) interface{} {
+	dynamic, ok := segment.(DynamicSegment)
+	if !ok {
+		return matchWildcard(pattern, segment.(string))
+	}
+
+	if pattern == "*" {
+		return true
+	}
+
+	if !hasWildcard(pattern) {
+		return expr.Eq(dynamic.Expr, literal(pattern))
+	}
+
+	return expr.RegexMatch(dynamic.Expr, wildcardToRegex(pattern))
+}
+
+func hasWildcard(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?")
+}
+
+// Match string by pattern with "*" and "?" wildcards.
+func matchWildcard(pattern string, s string) bool {
+	p := []rune(pattern)
+	r := []rune(s)
+
+	// Position of the last star and of the string when it was reached
+	star, mark := -1, 0
+	i, j := 0, 0
+	for j < len(r) {
+		if i < len(p) && (p[i] == '?' || p[i] == r[j]) {
+			i++
+			j++
+		} else if i < len(p) && p[i] == '*' {
+			star, mark = i, j
+			i++
+		} else if star >= 0 {
+			mark++
+			i, j = star+1, mark
+		} else {
+			return false
+		}
+	}
+
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+
+	return i == len(p)
+}
+
+func wildcardToRegex(pattern string) string {
+	out := "^"
+	for _, part := range strings.SplitAfter(pattern, "") {
+		switch part {
+		case "*":
+			out += ".*"
+		case "?":
+			out += "."
+		default:
+			out += regexp.QuoteMeta(part)
+		}
+	}
+	return out + `\z`
+}
+
+// Boolean operations on conditions, bool constants are simplified.
+
+func and(conds ...interface{}) interface{} {
+	items := make([]interface{}, 0, len(conds))
+	for _, c := range conds {
+		if b, ok := c.(bool); ok {
+			if !b {
+				return false
+			}
+			continue
+		}
+		items = append(items, c)
+	}
+
+	switch len(items) {
+	case 0:
+		return true
+	case 1:
+		return items[0]
+	}
+	return expr.And(items...)
+}
+
+func or(conds ...interface{}) interface{} {
+	items := make([]interface{}, 0, len(conds))
+	for _, c := range conds {
+		if b, ok := c.(bool); ok {
+			if b {
+				return true
+			}
+			continue
+		}
+		items = append(items, c)
+	}
+
+	switch len(items) {
+	case 0:
+		return false
+	case 1:
+		return items[0]
+	}
+	return expr.Or(items...)
+}
+
+func not(cond interface{}) interface{} {
+	if b, ok := cond.(bool); ok {
+		return !b
+	}
+	return expr.Not(cond)
+}
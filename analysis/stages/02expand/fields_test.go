@@ -0,0 +1,159 @@
+package expand
+
+import (
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewFieldFilter_Invalid(t *testing.T) {
+	for _, pattern := range []string{"", "a..b", "a.", "a**", "a.**b"} {
+		_, err := NewFieldFilter([]string{pattern}, nil)
+		assert.Error(t, err, "pattern %q", pattern)
+
+		_, err = NewFieldFilter(nil, []string{pattern})
+		assert.Error(t, err, "pattern %q", pattern)
+	}
+}
+
+func TestFieldFilter_Exclude(t *testing.T) {
+	f, err := NewFieldFilter(nil, []string{"audit", "**.raw", "items.[].tmp*"})
+	assert.Nil(t, err)
+	assert.Equal(t, FieldIncluded, f.Root())
+
+	cases := map[string]FieldMatch{
+		"name":            FieldIncluded,
+		"audit":           FieldExcluded,
+		"auditLog":        FieldIncluded,
+		"raw":             FieldExcluded,
+		"data.raw":        FieldExcluded,
+		"data.[].raw":     FieldExcluded,
+		"data.rawData":    FieldIncluded,
+		"items.[]":        FieldIncluded,
+		"items.[].tmp":    FieldExcluded,
+		"items.[].tmpX":   FieldExcluded,
+		"items.[].price":  FieldIncluded,
+		"items.tmp":       FieldIncluded,
+		"items.[].[].tmp": FieldIncluded,
+	}
+
+	for name, match := range cases {
+		assert.Equal(t, match, f.Match(name, FieldIncluded), "name %q", name)
+	}
+}
+
+func TestFieldFilter_Include(t *testing.T) {
+	f, err := NewFieldFilter([]string{"name", "items.[].price", "**.id"}, []string{"secret.id"})
+	assert.Nil(t, err)
+	assert.Equal(t, FieldParent, f.Root())
+
+	cases := map[string]FieldMatch{
+		"name":       FieldIncluded,
+		"other":      FieldParent, // can contain "id" field
+		"items":      FieldParent,
+		"items.[]":   FieldParent,
+		"items.name": FieldParent,
+		"id":         FieldIncluded,
+		"a.b.id":     FieldIncluded,
+		"secret.id":  FieldExcluded,
+	}
+
+	for name, match := range cases {
+		assert.Equal(t, match, f.Match(name, FieldParent), "name %q", name)
+	}
+
+	// Nested fields of included field
+	assert.Equal(t, FieldIncluded, f.Match("name.first", FieldIncluded))
+	assert.Equal(t, FieldExcluded, f.Match("secret.id", FieldIncluded))
+
+	// Without "**" pattern
+	f, err = NewFieldFilter([]string{"items.[].price"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, FieldParent, f.Match("items", FieldParent))
+	assert.Equal(t, FieldParent, f.Match("items.[]", FieldParent))
+	assert.Equal(t, FieldIncluded, f.Match("items.[].price", FieldParent))
+	assert.Equal(t, FieldExcluded, f.Match("items.[].name", FieldParent))
+	assert.Equal(t, FieldExcluded, f.Match("name", FieldParent))
+}
+
+func TestFieldFilter_EscapedKeys(t *testing.T) {
+	f, err := NewFieldFilter([]string{`"a.b".c`, `""`}, []string{`**."[]"`})
+	assert.Nil(t, err)
+
+	cases := map[string]FieldMatch{
+		`"a.b"`:        FieldParent,
+		`"a.b".c`:      FieldIncluded,
+		`"a.b".c."[]"`: FieldExcluded,
+		"a":            FieldExcluded,
+		`""`:           FieldIncluded,
+	}
+
+	for name, match := range cases {
+		assert.Equal(t, match, f.Match(name, FieldParent), "name %q", name)
+	}
+
+	// Nested fields of included field
+	assert.Equal(t, FieldIncluded, f.Match(`"".[]`, FieldIncluded))
+	assert.Equal(t, FieldExcluded, f.Match(`"".[]."[]"`, FieldIncluded))
+}
+
+func TestFieldFilter_NeedsRegexMatch(t *testing.T) {
+	f, _ := NewFieldFilter([]string{"a.*.b", "**.c"}, []string{"d.[]"})
+	assert.False(t, f.NeedsRegexMatch())
+
+	f, _ = NewFieldFilter(nil, []string{"d.tmp*"})
+	assert.True(t, f.NeedsRegexMatch())
+
+	f, _ = NewFieldFilter([]string{"a?"}, nil)
+	assert.True(t, f.NeedsRegexMatch())
+}
+
+func TestFieldFilter_Expr(t *testing.T) {
+	f, _ := NewFieldFilter([]string{"a.*", "b.c"}, []string{"a.x*"})
+	key := expr.Var("k")
+	segment := DynamicSegment{Expr: key}
+
+	// Static segments are evaluated immediately
+	assert.Equal(t, true, f.IncludedExpr([]interface{}{"b", "c"}, false))
+	assert.Equal(t, false, f.IncludedExpr([]interface{}{"d"}, false))
+	assert.Equal(t, false, f.KeepExpr([]interface{}{"d"}, false, expr.Type(key)))
+
+	// Dynamic segments
+	assert.Equal(t,
+		expr.Not(expr.RegexMatch(key, `^x.*\z`)),
+		f.IncludedExpr([]interface{}{"a", segment}, false),
+	)
+	assert.Equal(t,
+		expr.Or(expr.Var("i"), expr.And(
+			expr.Or(expr.Eq(key, "a"), expr.Eq(key, "b")),
+			expr.In(expr.Type(key), []interface{}{"object", "array"}),
+		)),
+		f.KeepExpr([]interface{}{segment}, expr.Var("i"), expr.Type(key)),
+	)
+}
+
+func TestMatchWildcard(t *testing.T) {
+	cases := []struct {
+		pattern string
+		s       string
+		match   bool
+	}{
+		{"abc", "abc", true},
+		{"abc", "abd", false},
+		{"a*", "a", true},
+		{"a*", "abc", true},
+		{"*c", "abc", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "abcd", false},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"*b*b*", "abxbx", true},
+		{"[]", "[]", true},
+		{"[]", "a", false},
+		{"č*", "čaj", true},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.match, matchWildcard(c.pattern, c.s), "%q ~ %q", c.pattern, c.s)
+	}
+}
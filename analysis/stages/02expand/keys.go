@@ -0,0 +1,84 @@
+package expand
+
+// Shapes of object keys, see Options.MapMinKeys.
+const (
+	KeyShapeDate     = "date"
+	KeyShapeNumber   = "number"
+	KeyShapeObjectId = "objectId"
+	KeyShapeUUID     = "uuid"
+	KeyShapeOther    = "other"
+)
+
+// GetKeyShape returns shape of the object key.
+// Keys are checked without regular expressions, because shapes are detected for all objects.
+func GetKeyShape(key string) string {
+	switch {
+	case isNumber(key):
+		return KeyShapeNumber
+	case isDate(key):
+		return KeyShapeDate
+	case len(key) == 24 && isHex(key):
+		return KeyShapeObjectId
+	case isUUID(key):
+		return KeyShapeUUID
+	}
+
+	return KeyShapeOther
+}
+
+// Integer or decimal number, eg. -12.5
+func isNumber(key string) bool {
+	if len(key) > 0 && key[0] == '-' {
+		key = key[1:]
+	}
+
+	digits := 0
+	dot := false
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] >= '0' && key[i] <= '9':
+			digits++
+		case key[i] == '.' && !dot && digits > 0 && i < len(key)-1:
+			dot = true
+		default:
+			return false
+		}
+	}
+
+	return digits > 0
+}
+
+// Starts with ISO 8601 date, eg. 2017-04-01 or 2017-04-01T12:30:00Z
+func isDate(key string) bool {
+	if len(key) < 10 || key[4] != '-' || key[7] != '-' {
+		return false
+	}
+
+	for _, i := range []int{0, 1, 2, 3, 5, 6, 8, 9} {
+		if key[i] < '0' || key[i] > '9' {
+			return false
+		}
+	}
+
+	return len(key) == 10 || key[10] == 'T' || key[10] == ' '
+}
+
+// UUID, eg. 123e4567-e89b-12d3-a456-426655440000
+func isUUID(key string) bool {
+	if len(key) != 36 || key[8] != '-' || key[13] != '-' || key[18] != '-' || key[23] != '-' {
+		return false
+	}
+
+	return isHex(key[0:8]) && isHex(key[9:13]) && isHex(key[14:18]) && isHex(key[19:23]) && isHex(key[24:])
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+
+	return len(s) > 0
+}
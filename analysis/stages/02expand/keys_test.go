@@ -0,0 +1,28 @@
+package expand
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetKeyShape(t *testing.T) {
+	cases := map[string]string{
+		"123":                                  KeyShapeNumber,
+		"-1.5":                                 KeyShapeNumber,
+		"1.":                                   KeyShapeOther,
+		"-":                                    KeyShapeOther,
+		"2017-04-01":                           KeyShapeDate,
+		"2017-04-01T12:30:00Z":                 KeyShapeDate,
+		"2017-04-01x":                          KeyShapeOther,
+		"2017-4-1":                             KeyShapeOther,
+		"58e20d849d3ae7e1f8eac9c0":             KeyShapeObjectId,
+		"123e4567-e89b-12d3-a456-426655440000": KeyShapeUUID,
+		"123e4567-e89b-12d3-a456-42665544000x": KeyShapeOther,
+		"name":                                 KeyShapeOther,
+		"":                                     KeyShapeOther,
+	}
+
+	for key, shape := range cases {
+		assert.Equal(t, shape, GetKeyShape(key), "key %q", key)
+	}
+}
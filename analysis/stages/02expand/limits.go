@@ -0,0 +1,178 @@
+package expand
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// PathLimits overrides a limit for objects and arrays at the paths, eg. {"order.items": 6}.
+// Keys in the paths are escaped same as in full field names, see analysis.EscapeKey.
+// The limit of the longest matching path applies to the path and its nested fields.
+type PathLimits map[string]uint
+
+// Lookup returns limit of the field and true, if the field or some of its parents has an override.
+func (l PathLimits) Lookup(name string) (uint, bool) {
+	for {
+		if v, ok := l[name]; ok {
+			return v, true
+		}
+
+		if name == "" {
+			return 0, false
+		}
+		name, _ = analysis.SplitParentName(name)
+	}
+}
+
+// Returns true if the field is parent of some path.
+func (l PathLimits) leadsTo(name string) bool {
+	for path := range l {
+		if strings.HasPrefix(path, name+analysis.NameSeparator) {
+			return true
+		}
+	}
+	return false
+}
+
+// Paths sorted from the shortest, so the longest path is checked first in the built conditions.
+func (l PathLimits) sortedPaths() []string {
+	paths := make([]string, 0, len(l))
+	for path := range l {
+		paths = append(paths, path)
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		if len(paths[i]) != len(paths[j]) {
+			return len(paths[i]) < len(paths[j])
+		}
+		return paths[i] < paths[j]
+	})
+
+	return paths
+}
+
+// ExpandNested returns true if nested fields of the object or array are expanded, see MaxDepth and DepthFor.
+// Parents of the paths in DepthFor are always expanded, so the paths are reached.
+func (o *Options) ExpandNested(name string, level uint) bool {
+	depth := o.MaxDepth
+	if v, ok := o.DepthFor.Lookup(name); ok {
+		depth = v
+	}
+
+	return level < depth || o.DepthFor.leadsTo(name)
+}
+
+// ArrayMaxLengthOf returns max number of analyzed items of the array, see ArrayMaxLength and ArrayMaxLengthFor.
+func (o *Options) ArrayMaxLengthOf(name string) uint {
+	if v, ok := o.ArrayMaxLengthFor.Lookup(name); ok {
+		return v
+	}
+	return o.ArrayMaxLength
+}
+
+// MaxLevel returns max level of expanded fields.
+func (o *Options) MaxLevel() uint {
+	level := o.MaxDepth
+	for path, depth := range o.DepthFor {
+		if depth > level {
+			level = depth
+		}
+		if l := pathLevel(path); l > level {
+			level = l
+		}
+	}
+	return level
+}
+
+// ExpandNestedExpr returns ExpandNested as condition evaluated in database, fullName is expression of the field name.
+// The result is bool if it is known without evaluation.
+func (o *Options) ExpandNestedExpr(fullName interface{}, level uint) interface{} {
+	var result interface{} = level < o.MaxDepth
+	for _, path := range o.DepthFor.sortedPaths() {
+		result = cond(pathMatchExpr(path, fullName, level), level < o.DepthFor[path], result)
+	}
+
+	leadsTo := []interface{}{result}
+	for _, path := range o.DepthFor.sortedPaths() {
+		leadsTo = append(leadsTo, pathParentExpr(path, fullName, level))
+	}
+
+	return or(leadsTo...)
+}
+
+// ArrayMaxLengthExpr returns ArrayMaxLengthOf as expression evaluated in database, fullName is expression of the array name.
+// The result is uint if it is known without evaluation.
+func (o *Options) ArrayMaxLengthExpr(fullName interface{}, level uint) interface{} {
+	var result interface{} = o.ArrayMaxLength
+	for _, path := range o.ArrayMaxLengthFor.sortedPaths() {
+		result = cond(pathMatchExpr(path, fullName, level), o.ArrayMaxLengthFor[path], result)
+	}
+	return result
+}
+
+// Level of the field with given path.
+func pathLevel(path string) uint {
+	return analysis.NameLevel(path)
+}
+
+// Condition that the field at the level is the path or its nested field.
+func pathMatchExpr(path string, fullName interface{}, level uint) interface{} {
+	switch l := pathLevel(path); {
+	case level < l:
+		return false
+	case level == l:
+		return expr.Eq(fullName, literal(path))
+	}
+
+	prefix := path + analysis.NameSeparator
+	return expr.Eq(expr.SubstrCP(fullName, 0, utf8.RuneCountInString(prefix)), literal(prefix))
+}
+
+// Condition that the field at the level is parent of the path.
+func pathParentExpr(path string, fullName interface{}, level uint) interface{} {
+	if level >= pathLevel(path) {
+		return false
+	}
+
+	segments := analysis.SplitName(path)
+	return expr.Eq(fullName, literal(analysis.JoinName(segments[:level+1]...)))
+}
+
+// Strings starting with "$" are field paths in aggregation expressions, so they must be literal.
+func literal(s string) interface{} {
+	if strings.HasPrefix(s, "$") {
+		return expr.Literal(s)
+	}
+	return s
+}
+
+// Conditional value, constant condition and equal constant branches are simplified.
+func cond(c interface{}, t interface{}, f interface{}) interface{} {
+	if b, ok := c.(bool); ok {
+		if b {
+			return t
+		}
+		return f
+	}
+
+	if isConst(t) && isConst(f) && t == f {
+		return t
+	}
+
+	if t == true && f == false {
+		return c
+	}
+
+	return expr.Cond(c, t, f)
+}
+
+func isConst(v interface{}) bool {
+	switch v.(type) {
+	case bool, uint:
+		return true
+	}
+	return false
+}
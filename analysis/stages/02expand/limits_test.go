@@ -0,0 +1,117 @@
+package expand
+
+import (
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPathLimits_Lookup(t *testing.T) {
+	l := PathLimits{"order": 3, "order.items": 6}
+
+	cases := map[string]interface{}{
+		"order":                  uint(3),
+		"order.id":               uint(3),
+		"order.items":            uint(6),
+		"order.items.[].price":   uint(6),
+		"orders":                 nil,
+		"user.order":             nil,
+		"order.itemsCount":       uint(3),
+		"order.items.[].[].name": uint(6),
+	}
+
+	for name, expected := range cases {
+		v, ok := l.Lookup(name)
+		if expected == nil {
+			assert.False(t, ok, name)
+		} else {
+			assert.True(t, ok, name)
+			assert.Equal(t, expected, v, name)
+		}
+	}
+}
+
+func TestPathLimits_LookupEscaped(t *testing.T) {
+	l := PathLimits{`"a.b"`: 3, `x."".y`: 6}
+
+	v, ok := l.Lookup(`"a.b".c`)
+	assert.True(t, ok)
+	assert.Equal(t, uint(3), v)
+
+	v, ok = l.Lookup(`x."".y.[]`)
+	assert.True(t, ok)
+	assert.Equal(t, uint(6), v)
+
+	_, ok = l.Lookup("a.b.c")
+	assert.False(t, ok)
+	_, ok = l.Lookup(`x.""`)
+	assert.False(t, ok)
+}
+
+func TestOptions_ExpandNested(t *testing.T) {
+	o := &Options{MaxDepth: 1, DepthFor: PathLimits{"a.b.c": 5, "x": 0}}
+
+	// Global depth
+	assert.True(t, o.ExpandNested("y", 0))
+	assert.False(t, o.ExpandNested("y.z", 1))
+
+	// Parents of the path
+	assert.True(t, o.ExpandNested("a", 0))
+	assert.True(t, o.ExpandNested("a.b", 1))
+	assert.False(t, o.ExpandNested("a.d", 1))
+
+	// Path and its nested fields
+	assert.True(t, o.ExpandNested("a.b.c", 2))
+	assert.True(t, o.ExpandNested("a.b.c.[].d", 4))
+	assert.False(t, o.ExpandNested("a.b.c.[].d.e", 5))
+	assert.False(t, o.ExpandNested("x", 0))
+
+	assert.Equal(t, uint(5), o.MaxLevel())
+	assert.Equal(t, uint(3), (&Options{MaxDepth: 1, DepthFor: PathLimits{"a.b.c.d": 0}}).MaxLevel())
+	assert.Equal(t, uint(2), (&Options{MaxDepth: 2}).MaxLevel())
+}
+
+func TestOptions_ArrayMaxLengthOf(t *testing.T) {
+	o := &Options{ArrayMaxLength: 20, ArrayMaxLengthFor: PathLimits{"tags": 1000}}
+
+	assert.Equal(t, uint(1000), o.ArrayMaxLengthOf("tags"))
+	assert.Equal(t, uint(1000), o.ArrayMaxLengthOf("tags.[]"))
+	assert.Equal(t, uint(20), o.ArrayMaxLengthOf("tagsList"))
+}
+
+func TestOptions_ExpandNestedExpr(t *testing.T) {
+	name := expr.Var("n")
+	o := &Options{MaxDepth: 1, DepthFor: PathLimits{"a.b": 3}}
+
+	// Known without evaluation
+	assert.Equal(t, true, (&Options{MaxDepth: 1}).ExpandNestedExpr(name, 0))
+	assert.Equal(t, false, (&Options{MaxDepth: 1}).ExpandNestedExpr(name, 1))
+	assert.Equal(t, true, o.ExpandNestedExpr(name, 0))
+	assert.Equal(t, false, o.ExpandNestedExpr(name, 3))
+
+	// Path itself and nested fields
+	assert.Equal(t, expr.Eq(name, "a.b"), o.ExpandNestedExpr(name, 1))
+	assert.Equal(t, expr.Eq(expr.SubstrCP(name, 0, 4), "a.b."), o.ExpandNestedExpr(name, 2))
+
+	// Parent of the path
+	o = &Options{MaxDepth: 0, DepthFor: PathLimits{"a.b": 3}}
+	assert.Equal(t, expr.Eq(name, "a"), o.ExpandNestedExpr(name, 0))
+
+	// Escaped keys and keys starting with "$"
+	o = &Options{MaxDepth: 0, DepthFor: PathLimits{`"a.b"`: 3, "$x.y": 3}}
+	assert.Equal(t,
+		expr.Or(expr.Eq(name, `"a.b"`), expr.Eq(name, expr.Literal("$x"))),
+		o.ExpandNestedExpr(name, 0),
+	)
+}
+
+func TestOptions_ArrayMaxLengthExpr(t *testing.T) {
+	name := expr.Var("n")
+	o := &Options{ArrayMaxLength: 20, ArrayMaxLengthFor: PathLimits{"tags": 1000, "a.b": 5}}
+
+	assert.Equal(t, expr.Cond(expr.Eq(name, "tags"), uint(1000), uint(20)), o.ArrayMaxLengthExpr(name, 0))
+	assert.Equal(t,
+		expr.Cond(expr.Eq(expr.SubstrCP(name, 0, 5), "tags."), uint(1000), expr.Cond(expr.Eq(name, "a.b"), uint(5), uint(20))),
+		o.ArrayMaxLengthExpr(name, 1),
+	)
+}
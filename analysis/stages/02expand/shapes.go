@@ -0,0 +1,130 @@
+package expand
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ShapeExamples - number of stored _id examples for each shape.
+const ShapeExamples = 3
+
+// ShapesMaxCount - maximal number of tracked shapes, documents with other shapes are not counted.
+const ShapesMaxCount = 10000
+
+// Shapes collects shapes of documents (the set of field names and types).
+// Values sent by expand stage lose link to the document, so shapes are collected directly in the stage.
+// Collector is safe for concurrent use.
+type Shapes struct {
+	mutex  sync.Mutex
+	shapes map[string]*shape
+}
+
+type shape struct {
+	key      string
+	fields   []string
+	count    uint64
+	examples []interface{}
+}
+
+// NewShapes creates empty collector of shapes.
+func NewShapes() *Shapes {
+	return &Shapes{shapes: make(map[string]*shape)}
+}
+
+// Add shape of one document given by its values, id is called to get _id of the document if an example is needed.
+func (s *Shapes) Add(values []Value, id func() interface{}) {
+	fields := make([]string, 0, len(values))
+	for _, v := range values {
+		fields = append(fields, v.Name+" ("+v.Type+")")
+	}
+	sort.Strings(fields)
+	fields = uniqueStrings(fields)
+	key := strings.Join(fields, "\x00")
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sh, found := s.shapes[key]
+	if !found {
+		if len(s.shapes) >= ShapesMaxCount {
+			return
+		}
+		sh = &shape{key: key, fields: fields}
+		s.shapes[key] = sh
+	}
+
+	sh.count++
+	if len(sh.examples) < ShapeExamples {
+		sh.examples = append(sh.examples, id())
+	}
+}
+
+// Top returns n most frequent shapes and number of all found shapes.
+// Each shape contains differences from the dominant (the most frequent) shape.
+func (s *Shapes) Top(n uint) (analysis.Shapes, uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	all := make([]*shape, 0, len(s.shapes))
+	for _, sh := range s.shapes {
+		all = append(all, sh)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].count != all[j].count {
+			return all[i].count > all[j].count
+		}
+		return all[i].key < all[j].key
+	})
+
+	if uint(len(all)) > n {
+		all = all[:n]
+	}
+
+	out := make(analysis.Shapes, 0, len(all))
+	for _, sh := range all {
+		added, removed := diffFields(all[0].fields, sh.fields)
+		out = append(out, &analysis.Shape{
+			Count:    sh.count,
+			Examples: sh.examples,
+			Added:    added,
+			Removed:  removed,
+		})
+	}
+
+	return out, uint64(len(s.shapes))
+}
+
+// Differences between two sorted sets of fields.
+func diffFields(base []string, fields []string) (added []string, removed []string) {
+	i, j := 0, 0
+	for i < len(base) || j < len(fields) {
+		switch {
+		case j == len(fields) || (i < len(base) && base[i] < fields[j]):
+			removed = append(removed, base[i])
+			i++
+		case i == len(base) || fields[j] < base[i]:
+			added = append(added, fields[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+
+	return
+}
+
+// Remove duplicates from the sorted slice.
+func uniqueStrings(s []string) []string {
+	out := s[:0]
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,34 @@
+package expand
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestShapes(t *testing.T) {
+	v1 := []Value{{Name: "_id", Type: "int"}, {Name: "name", Type: "string"}}
+	v2 := []Value{{Name: "_id", Type: "int"}, {Name: "name", Type: "string"}, {Name: "age", Type: "int"}}
+	v3 := []Value{{Name: "_id", Type: "int"}, {Name: "tags", Type: "array"}, {Name: "tags", Type: "string"}, {Name: "tags", Type: "string"}}
+
+	s := NewShapes()
+	for i := 1; i <= 5; i++ {
+		s.Add(v1, func() interface{} { return i })
+	}
+	s.Add(v2, func() interface{} { return 6 })
+	s.Add(v3, func() interface{} { return 7 })
+	s.Add(v3, func() interface{} { return 8 })
+
+	shapes, count := s.Top(2)
+	assert.Equal(t, uint64(3), count)
+	assert.Equal(t, analysis.Shapes{
+		{Count: 5, Examples: []interface{}{1, 2, 3}},
+		{Count: 2, Examples: []interface{}{7, 8}, Added: []string{"tags (array)", "tags (string)"}, Removed: []string{"name (string)"}},
+	}, shapes)
+}
+
+func TestShapes_Empty(t *testing.T) {
+	shapes, count := NewShapes().Top(10)
+	assert.Equal(t, uint64(0), count)
+	assert.Equal(t, analysis.Shapes{}, shapes)
+}
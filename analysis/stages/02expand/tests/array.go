@@ -32,19 +32,25 @@ func RunTestArrayFieldMinimal(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_array",
-			Type:  "array",
+			Level:  0,
+			Name:   "_array",
+			Type:   "array",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_array.[]",
-			Type:  "string",
+			Level:  1,
+			Name:   "_array.[]",
+			Type:   "string",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level: 1,
@@ -62,14 +68,18 @@ func RunTestArrayFieldMinimal(t *testing.T, stageFactory expand.StageFactory) {
 			Type:  "objectId",
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_array",
-			Type:  "array",
+			Level:  0,
+			Name:   "_array",
+			Type:   "array",
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
@@ -101,20 +111,26 @@ func RunTestArrayFieldLength(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
 			Name:   "_array",
 			Type:   "array",
 			Length: 4,
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_array.[]",
-			Type:  "string",
+			Level:  1,
+			Name:   "_array.[]",
+			Type:   "string",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level: 1,
@@ -132,15 +148,19 @@ func RunTestArrayFieldLength(t *testing.T, stageFactory expand.StageFactory) {
 			Type:  "objectId",
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
 			Name:   "_array",
 			Type:   "array",
 			Length: 0,
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
@@ -172,10 +192,12 @@ func RunTestArrayFieldValue(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level: 0,
@@ -186,13 +208,17 @@ func RunTestArrayFieldValue(t *testing.T, stageFactory expand.StageFactory) {
 				123.456,
 				bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c3"),
 			},
-			Type: "array",
+			Type:   "array",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_array.[]",
-			Value: "string",
-			Type:  "string",
+			Level:  1,
+			Name:   "_array.[]",
+			Value:  "string",
+			Type:   "string",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level: 1,
@@ -213,16 +239,20 @@ func RunTestArrayFieldValue(t *testing.T, stageFactory expand.StageFactory) {
 			Type:  "objectId",
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1"),
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1"),
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_array",
-			Value: []interface{}{},
-			Type:  "array",
+			Level:  0,
+			Name:   "_array",
+			Value:  []interface{}{},
+			Type:   "array",
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
@@ -247,10 +277,12 @@ func RunTestArrayFieldArrayMaxLength(t *testing.T, stageFactory expand.StageFact
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level: 0,
@@ -258,14 +290,19 @@ func RunTestArrayFieldArrayMaxLength(t *testing.T, stageFactory expand.StageFact
 			Value: []interface{}{
 				1, 2, 3,
 			},
-			Type:   "array",
-			Length: 10,
+			Type:         "array",
+			Length:       10,
+			Doc:          1,
+			Parent:       1,
+			SkippedItems: 7,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_array.[]",
-			Value: 1,
-			Type:  "int",
+			Level:  1,
+			Name:   "_array.[]",
+			Value:  1,
+			Type:   "int",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level: 1,
@@ -316,10 +353,12 @@ func RunTestArrayFieldMaxDepth(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level: 0,
@@ -341,12 +380,16 @@ func RunTestArrayFieldMaxDepth(t *testing.T, stageFactory expand.StageFactory) {
 			},
 			Type:   "array",
 			Length: 5,
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_array.[]",
-			Value: 1,
-			Type:  "int",
+			Level:  1,
+			Name:   "_array.[]",
+			Value:  1,
+			Type:   "int",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level: 1,
@@ -385,10 +428,12 @@ func RunTestArrayFieldMaxDepth(t *testing.T, stageFactory expand.StageFactory) {
 			Length: 4,
 		},
 		expand.Value{
-			Level: 2,
-			Name:  "_array.[].[]",
-			Value: 5,
-			Type:  "int",
+			Level:  2,
+			Name:   "_array.[].[]",
+			Value:  5,
+			Type:   "int",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level: 2,
@@ -414,8 +459,9 @@ func RunTestArrayFieldMaxDepth(t *testing.T, stageFactory expand.StageFactory) {
 					},
 				},
 			},
-			Type:   "array",
-			Length: 3,
+			Type:      "array",
+			Length:    3,
+			Truncated: 1,
 		},
 	}
 
@@ -14,7 +14,7 @@ import (
 func RunBenchmarkDepth0Min(b *testing.B, stageFactory expand.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := expand.Options{}
 	copier.Copy(&options, &testOptions)
@@ -31,7 +31,7 @@ func RunBenchmarkDepth0Min(b *testing.B, stageFactory expand.StageFactory) {
 func RunBenchmarkDepth0Full(b *testing.B, stageFactory expand.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := expand.Options{}
 	copier.Copy(&options, &testOptions)
@@ -48,7 +48,7 @@ func RunBenchmarkDepth0Full(b *testing.B, stageFactory expand.StageFactory) {
 func RunBenchmarkDepth5Full(b *testing.B, stageFactory expand.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := expand.Options{}
 	copier.Copy(&options, &testOptions)
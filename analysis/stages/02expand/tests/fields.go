@@ -0,0 +1,111 @@
+package expandTests
+
+import (
+	"github.com/jinzhu/copier"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/mongoeye/mongoeye/tests"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+func insertFieldsTestDoc(c *tests.TestCollection) {
+	c.Insert(bson.M{
+		"_id":  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+		"name": "Alice",
+		"audit": bson.M{
+			"by":  "admin",
+			"raw": "x",
+		},
+		"items": []interface{}{
+			bson.M{"price": 10, "raw": "x"},
+			5,
+			bson.M{"price": 20},
+		},
+		"data": bson.M{
+			"raw":  "abc",
+			"size": 3,
+		},
+	})
+}
+
+// RunTestFieldsExclude tests expand stage with exclude patterns of the field filter.
+func RunTestFieldsExclude(t *testing.T, stageFactory expand.StageFactory) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	insertFieldsTestDoc(c)
+
+	options := expand.Options{}
+	copier.Copy(&options, &testOptions)
+	options.StoreArrayLength = true
+	options.StoreObjectLength = true
+	options.Fields, _ = expand.NewFieldFilter(nil, []string{"audit", "**.raw"})
+
+	expected := []interface{}{
+		expand.Value{Level: 0, Name: "_id", Type: "objectId", Doc: 1, Parent: 1},
+		expand.Value{Level: 0, Name: "name", Type: "string", Doc: 1, Parent: 1},
+		expand.Value{Level: 0, Name: "items", Type: "array", Length: 3, Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "items.[]", Type: "object", Length: 2, Doc: 1, Parent: 1},
+		expand.Value{Level: 2, Name: "items.[].price", Type: "int", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "items.[]", Type: "int"},
+		expand.Value{Level: 1, Name: "items.[]", Type: "object", Length: 1},
+		expand.Value{Level: 2, Name: "items.[].price", Type: "int", Parent: 1},
+		expand.Value{Level: 0, Name: "data", Type: "object", Length: 2, Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "data.size", Type: "int", Doc: 1, Parent: 1},
+	}
+
+	testStage(t, c, stageFactory(&options), expected)
+}
+
+// RunTestFieldsInclude tests expand stage with include patterns of the field filter.
+func RunTestFieldsInclude(t *testing.T, stageFactory expand.StageFactory) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	insertFieldsTestDoc(c)
+
+	options := expand.Options{}
+	copier.Copy(&options, &testOptions)
+	options.StoreArrayLength = true
+	options.Fields, _ = expand.NewFieldFilter([]string{"items.[].price"}, nil)
+
+	// Scalar items are not reported, but they are counted to the length of the parent array
+	expected := []interface{}{
+		expand.Value{Level: 0, Name: "items", Type: "array", Length: 3, Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "items.[]", Type: "object", Doc: 1, Parent: 1},
+		expand.Value{Level: 2, Name: "items.[].price", Type: "int", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "items.[]", Type: "object"},
+		expand.Value{Level: 2, Name: "items.[].price", Type: "int", Parent: 1},
+	}
+
+	testStage(t, c, stageFactory(&options), expected)
+}
+
+// RunTestFieldsValue tests expand stage with exclude patterns and StoreValue option, values do not contain removed fields.
+func RunTestFieldsValue(t *testing.T, stageFactory expand.StageFactory) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	insertFieldsTestDoc(c)
+
+	options := expand.Options{}
+	copier.Copy(&options, &testOptions)
+	options.StoreValue = true
+	options.StoreArrayLength = true
+	options.Fields, _ = expand.NewFieldFilter(nil, []string{"audit", "**.raw"})
+
+	expected := []interface{}{
+		expand.Value{Level: 0, Name: "_id", Type: "objectId", Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"), Doc: 1, Parent: 1},
+		expand.Value{Level: 0, Name: "name", Type: "string", Value: "Alice", Doc: 1, Parent: 1},
+		expand.Value{Level: 0, Name: "items", Type: "array", Length: 3, Value: []interface{}{bson.M{"price": 10}, 5, bson.M{"price": 20}}, Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "items.[]", Type: "object", Value: bson.M{"price": 10}, Doc: 1, Parent: 1},
+		expand.Value{Level: 2, Name: "items.[].price", Type: "int", Value: 10, Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "items.[]", Type: "int", Value: 5},
+		expand.Value{Level: 1, Name: "items.[]", Type: "object", Value: bson.M{"price": 20}},
+		expand.Value{Level: 2, Name: "items.[].price", Type: "int", Value: 20, Parent: 1},
+		expand.Value{Level: 0, Name: "data", Type: "object", Value: bson.M{"size": 3}, Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "data.size", Type: "int", Value: 3, Doc: 1, Parent: 1},
+	}
+
+	testStage(t, c, stageFactory(&options), expected)
+}
@@ -0,0 +1,56 @@
+package expandTests
+
+import (
+	"github.com/jinzhu/copier"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/mongoeye/mongoeye/tests"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+// RunTestEscapedKeys tests expand stage with keys containing dots, quotes, dollars or empty keys.
+func RunTestEscapedKeys(t *testing.T, stageFactory expand.StageFactory) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	err := c.Insert(bson.M{
+		"_id": bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+		"a.b": bson.M{"c": 1},
+		"a":   bson.M{"b": "x"},
+		"":    nil,
+		"[]":  true,
+		"obj": bson.M{
+			"$x":   1,
+			`"q"`:  "y",
+			"1.5":  2.5,
+			"deep": bson.M{"": bson.M{"z": 1}},
+		},
+	})
+	if err != nil {
+		t.Skipf("MongoDB does not support the keys: %s", err)
+	}
+
+	options := expand.Options{}
+	copier.Copy(&options, &testOptions)
+	options.MaxDepth = 1
+	options.DepthFor = expand.PathLimits{`obj.deep.""`: 5}
+
+	expected := []interface{}{
+		expand.Value{Level: 0, Name: "_id", Type: "objectId", Doc: 1, Parent: 1},
+		expand.Value{Level: 0, Name: `"a.b"`, Type: "object", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: `"a.b".c`, Type: "int", Doc: 1, Parent: 1},
+		expand.Value{Level: 0, Name: "a", Type: "object", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "a.b", Type: "string", Doc: 1, Parent: 1},
+		expand.Value{Level: 0, Name: `""`, Type: "null", Doc: 1, Parent: 1},
+		expand.Value{Level: 0, Name: `"[]"`, Type: "bool", Doc: 1, Parent: 1},
+		expand.Value{Level: 0, Name: "obj", Type: "object", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "obj.$x", Type: "int", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: `obj."\"q\""`, Type: "string", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: `obj."1.5"`, Type: "double", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "obj.deep", Type: "object", Doc: 1, Parent: 1},
+		expand.Value{Level: 2, Name: `obj.deep.""`, Type: "object", Doc: 1, Parent: 1},
+		expand.Value{Level: 3, Name: `obj.deep."".z`, Type: "int", Doc: 1, Parent: 1},
+	}
+
+	testStage(t, c, stageFactory(&options), expected)
+}
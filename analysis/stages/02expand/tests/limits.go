@@ -0,0 +1,79 @@
+package expandTests
+
+import (
+	"github.com/jinzhu/copier"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/mongoeye/mongoeye/tests"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+func insertLimitsTestDoc(c *tests.TestCollection) {
+	c.Insert(bson.M{
+		"_id": bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+		"order": bson.M{
+			"items": []interface{}{
+				bson.M{"price": 10},
+			},
+			"note": bson.M{"text": "x"},
+		},
+		"tags":  []interface{}{1, 2, 3},
+		"codes": []interface{}{1, 2, 3},
+	})
+}
+
+// RunTestDepthFor tests expand stage with max depth overridden for the path.
+func RunTestDepthFor(t *testing.T, stageFactory expand.StageFactory) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	insertLimitsTestDoc(c)
+
+	options := expand.Options{}
+	copier.Copy(&options, &testOptions)
+	options.MaxDepth = 0
+	options.DepthFor = expand.PathLimits{"order.items": 5}
+
+	// Parent of the path is expanded, siblings are not
+	expected := []interface{}{
+		expand.Value{Level: 0, Name: "_id", Type: "objectId", Doc: 1, Parent: 1},
+		expand.Value{Level: 0, Name: "order", Type: "object", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "order.items", Type: "array", Doc: 1, Parent: 1},
+		expand.Value{Level: 2, Name: "order.items.[]", Type: "object", Doc: 1, Parent: 1},
+		expand.Value{Level: 3, Name: "order.items.[].price", Type: "int", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "order.note", Type: "object", Doc: 1, Parent: 1, Truncated: 1},
+		expand.Value{Level: 0, Name: "tags", Type: "array", Doc: 1, Parent: 1, Truncated: 1},
+		expand.Value{Level: 0, Name: "codes", Type: "array", Doc: 1, Parent: 1, Truncated: 1},
+	}
+
+	testStage(t, c, stageFactory(&options), expected)
+}
+
+// RunTestArrayMaxLengthFor tests expand stage with max array length overridden for the path.
+func RunTestArrayMaxLengthFor(t *testing.T, stageFactory expand.StageFactory) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	insertLimitsTestDoc(c)
+
+	options := expand.Options{}
+	copier.Copy(&options, &testOptions)
+	options.MaxDepth = 1
+	options.ArrayMaxLength = 1
+	options.ArrayMaxLengthFor = expand.PathLimits{"tags": 2}
+	options.StoreArrayLength = true
+
+	expected := []interface{}{
+		expand.Value{Level: 0, Name: "_id", Type: "objectId", Doc: 1, Parent: 1},
+		expand.Value{Level: 0, Name: "order", Type: "object", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "order.items", Type: "array", Length: 1, Doc: 1, Parent: 1, Truncated: 1},
+		expand.Value{Level: 1, Name: "order.note", Type: "object", Doc: 1, Parent: 1, Truncated: 1},
+		expand.Value{Level: 0, Name: "tags", Type: "array", Length: 3, Doc: 1, Parent: 1, SkippedItems: 1},
+		expand.Value{Level: 1, Name: "tags.[]", Type: "int", Doc: 1, Parent: 1},
+		expand.Value{Level: 1, Name: "tags.[]", Type: "int"},
+		expand.Value{Level: 0, Name: "codes", Type: "array", Length: 3, Doc: 1, Parent: 1, SkippedItems: 2},
+		expand.Value{Level: 1, Name: "codes.[]", Type: "int", Doc: 1, Parent: 1},
+	}
+
+	testStage(t, c, stageFactory(&options), expected)
+}
@@ -33,49 +33,67 @@ func RunTestObjectFieldMinimal(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_subDocument",
-			Type:  "object",
+			Level:  0,
+			Name:   "_subDocument",
+			Type:   "object",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_subDocument.Null",
-			Type:  "null",
+			Level:  1,
+			Name:   "_subDocument.Null",
+			Type:   "null",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_subDocument.String",
-			Type:  "string",
+			Level:  1,
+			Name:   "_subDocument.String",
+			Type:   "string",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_subDocument.Int",
-			Type:  "int",
+			Level:  1,
+			Name:   "_subDocument.Int",
+			Type:   "int",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_subDocument.Double",
-			Type:  "double",
+			Level:  1,
+			Name:   "_subDocument.Double",
+			Type:   "double",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_subDocument.Id",
-			Type:  "objectId",
+			Level:  1,
+			Name:   "_subDocument.Id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_subDocument",
-			Type:  "object",
+			Level:  0,
+			Name:   "_subDocument",
+			Type:   "object",
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
@@ -108,51 +126,69 @@ func RunTestObjectFieldLength(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
 			Name:   "_subDocument",
 			Type:   "object",
 			Length: 5,
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_subDocument.Null",
-			Type:  "null",
+			Level:  1,
+			Name:   "_subDocument.Null",
+			Type:   "null",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_subDocument.String",
-			Type:  "string",
+			Level:  1,
+			Name:   "_subDocument.String",
+			Type:   "string",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_subDocument.Int",
-			Type:  "int",
+			Level:  1,
+			Name:   "_subDocument.Int",
+			Type:   "int",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_subDocument.Double",
-			Type:  "double",
+			Level:  1,
+			Name:   "_subDocument.Double",
+			Type:   "double",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 1,
-			Name:  "_subDocument.Id",
-			Type:  "objectId",
+			Level:  1,
+			Name:   "_subDocument.Id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
 			Name:   "_subDocument",
 			Type:   "object",
 			Length: 0,
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
@@ -192,38 +228,51 @@ func RunTestObjectFieldMaxDepth(t *testing.T, stageFactory expand.StageFactory)
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
 			Name:   "_subDocument",
 			Type:   "object",
 			Length: 1,
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  1,
 			Name:   "_subDocument.Level1",
 			Type:   "object",
 			Length: 1,
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level:  2,
-			Name:   "_subDocument.Level1.Level2",
-			Type:   "object",
-			Length: 1,
+			Level:     2,
+			Name:      "_subDocument.Level1.Level2",
+			Type:      "object",
+			Length:    1,
+			Doc:       1,
+			Parent:    1,
+			Truncated: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
 			Name:   "_subDocument",
 			Type:   "object",
 			Length: 0,
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
@@ -256,10 +305,12 @@ func RunTestObjectFieldValue(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level: 0,
@@ -269,12 +320,17 @@ func RunTestObjectFieldValue(t *testing.T, stageFactory expand.StageFactory) {
 				"key1": "value1",
 				"key2": "value2",
 			},
+			Doc:       1,
+			Parent:    1,
+			Truncated: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1"),
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1"),
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level: 0,
@@ -283,6 +339,9 @@ func RunTestObjectFieldValue(t *testing.T, stageFactory expand.StageFactory) {
 			Value: bson.M{
 				"key3": "value3",
 			},
+			Doc:       1,
+			Parent:    1,
+			Truncated: 1,
 		},
 	}
 
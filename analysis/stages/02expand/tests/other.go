@@ -53,104 +53,144 @@ func RunTestScalarTypesMinimal(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_double",
-			Type:  "double",
+			Level:  0,
+			Name:   "_double",
+			Type:   "double",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_string",
-			Type:  "string",
+			Level:  0,
+			Name:   "_string",
+			Type:   "string",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_binData",
-			Type:  "binData",
+			Level:  0,
+			Name:   "_binData",
+			Type:   "binData",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_undefined",
-			Type:  "undefined",
+			Level:  0,
+			Name:   "_undefined",
+			Type:   "undefined",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_bool",
-			Type:  "bool",
+			Level:  0,
+			Name:   "_bool",
+			Type:   "bool",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_date",
-			Type:  "date",
+			Level:  0,
+			Name:   "_date",
+			Type:   "date",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_null",
-			Type:  "null",
+			Level:  0,
+			Name:   "_null",
+			Type:   "null",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_regex",
-			Type:  "regex",
+			Level:  0,
+			Name:   "_regex",
+			Type:   "regex",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_dbPointer",
-			Type:  "dbPointer",
+			Level:  0,
+			Name:   "_dbPointer",
+			Type:   "dbPointer",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_javascript",
-			Type:  "javascript",
+			Level:  0,
+			Name:   "_javascript",
+			Type:   "javascript",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_symbol",
-			Type:  "symbol",
+			Level:  0,
+			Name:   "_symbol",
+			Type:   "symbol",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_javascriptWithScope",
-			Type:  "javascriptWithScope",
+			Level:  0,
+			Name:   "_javascriptWithScope",
+			Type:   "javascriptWithScope",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_int",
-			Type:  "int",
+			Level:  0,
+			Name:   "_int",
+			Type:   "int",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_timestamp",
-			Type:  "timestamp",
+			Level:  0,
+			Name:   "_timestamp",
+			Type:   "timestamp",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_long",
-			Type:  "long",
+			Level:  0,
+			Name:   "_long",
+			Type:   "long",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_decimal",
-			Type:  decimalType,
+			Level:  0,
+			Name:   "_decimal",
+			Type:   decimalType,
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_maxKey",
-			Type:  "maxKey",
+			Level:  0,
+			Name:   "_maxKey",
+			Type:   "maxKey",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_minKey",
-			Type:  "minKey",
+			Level:  0,
+			Name:   "_minKey",
+			Type:   "minKey",
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
@@ -203,136 +243,180 @@ func RunTestScalarTypesValue(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Value: bson.ObjectIdHex("58de3f123d9654ba801bb32d"),
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Value:  bson.ObjectIdHex("58de3f123d9654ba801bb32d"),
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_double",
-			Value: 123.456,
-			Type:  "double",
+			Level:  0,
+			Name:   "_double",
+			Value:  123.456,
+			Type:   "double",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_string",
-			Value: "Šašo",
-			Type:  "string",
+			Level:  0,
+			Name:   "_string",
+			Value:  "Šašo",
+			Type:   "string",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_binData",
-			Value: []byte("abc"),
-			Type:  "binData",
+			Level:  0,
+			Name:   "_binData",
+			Value:  []byte("abc"),
+			Type:   "binData",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_binData_kind",
-			Value: bson.Binary{Kind: 0x05, Data: []byte("abc")},
-			Type:  "binData",
+			Level:  0,
+			Name:   "_binData_kind",
+			Value:  bson.Binary{Kind: 0x05, Data: []byte("abc")},
+			Type:   "binData",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_undefined",
-			Value: bson.Undefined,
-			Type:  "undefined",
+			Level:  0,
+			Name:   "_undefined",
+			Value:  bson.Undefined,
+			Type:   "undefined",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_bool",
-			Value: true,
-			Type:  "bool",
+			Level:  0,
+			Name:   "_bool",
+			Value:  true,
+			Type:   "bool",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_date",
-			Value: time.Unix(1490959237, 0),
-			Type:  "date",
+			Level:  0,
+			Name:   "_date",
+			Value:  time.Unix(1490959237, 0),
+			Type:   "date",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_date_min",
-			Value: time.Time{},
-			Type:  "date",
+			Level:  0,
+			Name:   "_date_min",
+			Value:  time.Time{},
+			Type:   "date",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_null",
-			Value: nil,
-			Type:  "null",
+			Level:  0,
+			Name:   "_null",
+			Value:  nil,
+			Type:   "null",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Value: bson.ObjectIdHex("58de3f123d9654ba801bb30e"),
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Value:  bson.ObjectIdHex("58de3f123d9654ba801bb30e"),
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_regex",
-			Value: bson.RegEx{Pattern: ".*", Options: "i"},
-			Type:  "regex",
+			Level:  0,
+			Name:   "_regex",
+			Value:  bson.RegEx{Pattern: ".*", Options: "i"},
+			Type:   "regex",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_dbPointer",
-			Value: bson.DBPointer{Namespace: "ns", Id: bson.ObjectIdHex("58de3f123d9654ba801bb20f")},
-			Type:  "dbPointer",
-		},
+			Level:  0,
+			Name:   "_dbPointer",
+			Value:  bson.DBPointer{Namespace: "ns", Id: bson.ObjectIdHex("58de3f123d9654ba801bb20f")},
+			Type:   "dbPointer",
+			Doc:    1,
+			Parent: 1,
+		},
 		expand.Value{
-			Level: 0,
-			Name:  "_javascript",
-			Value: bson.JavaScript{Code: "var x = 1+1;", Scope: nil},
-			Type:  "javascript",
-		},
+			Level:  0,
+			Name:   "_javascript",
+			Value:  bson.JavaScript{Code: "var x = 1+1;", Scope: nil},
+			Type:   "javascript",
+			Doc:    1,
+			Parent: 1,
+		},
 		expand.Value{
-			Level: 0,
-			Name:  "_symbol",
-			Value: "x",
-			Type:  "symbol",
-		},
+			Level:  0,
+			Name:   "_symbol",
+			Value:  "x",
+			Type:   "symbol",
+			Doc:    1,
+			Parent: 1,
+		},
 		expand.Value{
-			Level: 0,
-			Name:  "_javascriptWithScope",
-			Value: bson.JavaScript{Code: "var x = 1+y;", Scope: bson.M{"y": 5}},
-			Type:  "javascriptWithScope",
-		},
+			Level:  0,
+			Name:   "_javascriptWithScope",
+			Value:  bson.JavaScript{Code: "var x = 1+y;", Scope: bson.M{"y": 5}},
+			Type:   "javascriptWithScope",
+			Doc:    1,
+			Parent: 1,
+		},
 		expand.Value{
-			Level: 0,
-			Name:  "_int",
-			Value: 123,
-			Type:  "int",
-		},
+			Level:  0,
+			Name:   "_int",
+			Value:  123,
+			Type:   "int",
+			Doc:    1,
+			Parent: 1,
+		},
 		expand.Value{
-			Level: 0,
-			Name:  "_timestamp",
-			Value: bson.MongoTimestamp(1490959237),
-			Type:  "timestamp",
-		},
+			Level:  0,
+			Name:   "_timestamp",
+			Value:  bson.MongoTimestamp(1490959237),
+			Type:   "timestamp",
+			Doc:    1,
+			Parent: 1,
+		},
 		expand.Value{
-			Level: 0,
-			Name:  "_long",
-			Value: int64(456),
-			Type:  "long",
-		},
+			Level:  0,
+			Name:   "_long",
+			Value:  int64(456),
+			Type:   "long",
+			Doc:    1,
+			Parent: 1,
+		},
 		expand.Value{
-			Level: 0,
-			Name:  "_decimal",
-			Value: decimal,
-			Type:  decimalType,
-		},
+			Level:  0,
+			Name:   "_decimal",
+			Value:  decimal,
+			Type:   decimalType,
+			Doc:    1,
+			Parent: 1,
+		},
 		expand.Value{
-			Level: 0,
-			Name:  "_maxKey",
-			Value: bson.MaxKey,
-			Type:  "maxKey",
-		},
+			Level:  0,
+			Name:   "_maxKey",
+			Value:  bson.MaxKey,
+			Type:   "maxKey",
+			Doc:    1,
+			Parent: 1,
+		},
 		expand.Value{
-			Level: 0,
-			Name:  "_minKey",
-			Value: bson.MinKey,
-			Type:  "minKey",
+			Level:  0,
+			Name:   "_minKey",
+			Value:  bson.MinKey,
+			Type:   "minKey",
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
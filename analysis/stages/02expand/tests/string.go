@@ -27,24 +27,32 @@ func RunTestStringFieldMinimal(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "String",
-			Type:  "string",
+			Level:  0,
+			Name:   "String",
+			Type:   "string",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "String",
-			Type:  "string",
+			Level:  0,
+			Name:   "String",
+			Type:   "string",
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
@@ -71,28 +79,36 @@ func RunTestStringFieldValue(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "String",
-			Type:  "string",
-			Value: "abc",
+			Level:  0,
+			Name:   "String",
+			Type:   "string",
+			Value:  "abc",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1"),
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1"),
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "String",
-			Type:  "string",
-			Value: "",
+			Level:  0,
+			Name:   "String",
+			Type:   "string",
+			Value:  "",
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
@@ -119,26 +135,34 @@ func RunTestStringFieldLength(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
 			Name:   "String",
 			Type:   "string",
 			Length: 4,
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
 			Name:   "String",
 			Type:   "string",
 			Length: 0,
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
@@ -167,10 +191,12 @@ func RunTestStringFieldMaxLength(t *testing.T, stageFactory expand.StageFactory)
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c0"),
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c0"),
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
@@ -178,12 +204,16 @@ func RunTestStringFieldMaxLength(t *testing.T, stageFactory expand.StageFactory)
 			Type:   "string",
 			Length: 4,
 			Value:  "Šašo",
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1"),
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1"),
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
@@ -191,6 +221,8 @@ func RunTestStringFieldMaxLength(t *testing.T, stageFactory expand.StageFactory)
 			Type:   "string",
 			Length: 19,
 			Value:  "abcdef",
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
@@ -218,10 +250,12 @@ func RunTestStringFieldAll(t *testing.T, stageFactory expand.StageFactory) {
 
 	expected := []interface{}{
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
@@ -229,12 +263,16 @@ func RunTestStringFieldAll(t *testing.T, stageFactory expand.StageFactory) {
 			Type:   "string",
 			Value:  "abc",
 			Length: 3,
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
-			Level: 0,
-			Name:  "_id",
-			Type:  "objectId",
-			Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1"),
+			Level:  0,
+			Name:   "_id",
+			Type:   "objectId",
+			Value:  bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1"),
+			Doc:    1,
+			Parent: 1,
 		},
 		expand.Value{
 			Level:  0,
@@ -242,6 +280,8 @@ func RunTestStringFieldAll(t *testing.T, stageFactory expand.StageFactory) {
 			Type:   "string",
 			Value:  "",
 			Length: 0,
+			Doc:    1,
+			Parent: 1,
 		},
 	}
 
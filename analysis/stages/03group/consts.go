@@ -2,10 +2,16 @@ package group
 
 import "github.com/mongoeye/mongoeye/helpers"
 
-// BsonFieldName represents field name
-var BsonFieldName string
+// Abbreviations for aggregation pipeline.
+var (
+	BsonFieldName    string
+	BsonDocsCount    string
+	BsonParentsCount string
+)
 
 func init() {
 	r := Result{}
 	BsonFieldName = helpers.GetBSONFieldName(r, "Name")
+	BsonDocsCount = helpers.GetBSONFieldName(r, "DocsCount")
+	BsonParentsCount = helpers.GetBSONFieldName(r, "ParentsCount")
 }
@@ -1,6 +1,8 @@
 package group
 
 import (
+	"context"
+	"fmt"
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/helpers"
 	"gopkg.in/mgo.v2/bson"
@@ -11,7 +13,8 @@ import (
 
 // ToResultChannel converts input channel to Result channel.
 // The input may by raw []byte channel or Result channel (no conversion).
-func ToResultChannel(inCh interface{}, location *time.Location, concurrency int, bufferSize int) <-chan Result {
+// Invalid results from the database stop the analysis, see analysis.Fail.
+func ToResultChannel(ctx context.Context, inCh interface{}, location *time.Location, concurrency int, bufferSize int) <-chan Result {
 	if ch, ok := inCh.(chan Result); ok {
 		// local results
 		return ch
@@ -24,7 +27,7 @@ func ToResultChannel(inCh interface{}, location *time.Location, concurrency int,
 
 		// Run workers
 		for i := 0; i < concurrency; i++ {
-			go convertWorker(inCh, outCh, location, wg)
+			go convertWorker(ctx, inCh, outCh, location, wg)
 		}
 
 		// Wait for workers
@@ -39,14 +42,14 @@ func ToResultChannel(inCh interface{}, location *time.Location, concurrency int,
 	panic("Invalid input. Expected 'chan expand.Value' or 'chan []byte'. Given: " + reflect.TypeOf(inCh).String())
 }
 
-func convertWorker(inCh <-chan []byte, outCh chan<- Result, location *time.Location, wg *sync.WaitGroup) {
+func convertWorker(ctx context.Context, inCh <-chan []byte, outCh chan<- Result, location *time.Location, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for bin := range inCh {
 		r := Result{}
-		err := bson.Unmarshal(bin, &r)
-		if err != nil {
-			panic(err)
+		if err := bson.Unmarshal(bin, &r); err != nil {
+			analysis.Fail(ctx, fmt.Errorf("Invalid result of group stage: %s.", err))
+			continue
 		}
 
 		NormalizeType(&r.Type, location)
@@ -83,6 +86,19 @@ func NormalizeType(t *analysis.Type, location *time.Location) {
 		}
 	}
 
+	// Sum of integers is float64 as in groupLocally
+	if t.ValueStats != nil && t.ValueStats.Sum != nil && t.Name != "decimal" {
+		t.ValueStats.Sum = helpers.ToDouble(t.ValueStats.Sum)
+	}
+
+	if t.ValueStats != nil && t.ValueStats.Quantiles != nil {
+		normalizeQuantiles(t.ValueStats.Quantiles, t.Name == "decimal", location)
+	}
+
+	if t.LengthStats != nil && t.LengthStats.Quantiles != nil {
+		normalizeQuantiles(t.LengthStats.Quantiles, false, location)
+	}
+
 	if t.LengthHistogram != nil {
 		t.LengthHistogram.Start = int(helpers.ToDouble(t.LengthHistogram.Start))
 		t.LengthHistogram.End = int(helpers.ToDouble(t.LengthHistogram.End))
@@ -108,6 +124,23 @@ func NormalizeType(t *analysis.Type, location *time.Location) {
 	}
 }
 
+// Quantiles of dates and decimals have the same type as values, other quantiles are float64.
+func normalizeQuantiles(q *analysis.Quantiles, decimal bool, location *time.Location) {
+	for _, v := range q.Values() {
+		switch value := (*v).(type) {
+		case nil:
+		case time.Time:
+			*v = value.In(location)
+		default:
+			if decimal {
+				*v = helpers.DoubleToDecimal(helpers.ToDouble(value))
+			} else {
+				*v = helpers.ToDouble(value)
+			}
+		}
+	}
+}
+
 // ResultChannelToSlice reads Result channel into slice.
 func ResultChannelToSlice(ch <-chan Result) []interface{} {
 	out := []interface{}{}
@@ -1,6 +1,7 @@
 package group
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/helpers"
 	"github.com/stretchr/testify/assert"
@@ -11,7 +12,7 @@ import (
 
 func TestToGroupResultChannel_Minimal(t *testing.T) {
 	rawCh := make(chan []byte, 50)
-	grCh := ToResultChannel(rawCh, time.UTC, 1, 50)
+	grCh := ToResultChannel(context.Background(), rawCh, time.UTC, 1, 50)
 
 	bsonData := bson.M{
 		BsonFieldName:          "name",
@@ -42,7 +43,7 @@ func TestToGroupResultChannel_Minimal(t *testing.T) {
 
 func TestToGroupResultChannel_Full(t *testing.T) {
 	rawCh := make(chan []byte, 50)
-	grCh := ToResultChannel(rawCh, time.UTC, 1, 50)
+	grCh := ToResultChannel(context.Background(), rawCh, time.UTC, 1, 50)
 
 	bsonData := bson.M{
 		BsonFieldName:            "name",
@@ -235,14 +236,14 @@ func TestToGroupResultChannel_Full(t *testing.T) {
 
 func TestToGroupResultChannel_GroupResult(t *testing.T) {
 	inCh := make(chan Result, 50)
-	outCh := ToResultChannel(inCh, time.UTC, 1, 50)
+	outCh := ToResultChannel(context.Background(), inCh, time.UTC, 1, 50)
 	assert.Equal(t, (<-chan Result)(inCh), outCh)
 }
 
 func TestToGroupResultChannel_InvalidType(t *testing.T) {
 	ch := make(chan int, 50)
 	assert.Panics(t, func() {
-		ToResultChannel(ch, time.UTC, 1, 50)
+		ToResultChannel(context.Background(), ch, time.UTC, 1, 50)
 	})
 }
 
@@ -342,3 +343,54 @@ func TestNormalizeType_Decimal(t *testing.T) {
 	assert.Equal(t, helpers.ParseDecimal("12"), data.ValueHistogram.Start)
 	assert.Equal(t, helpers.ParseDecimal("21"), data.ValueHistogram.End)
 }
+
+func TestNormalizeType_Quantiles(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	date := helpers.ParseDate("2017-04-17T23:59:59+00:00")
+
+	data := &analysis.Type{
+		Name: "decimal",
+		ValueStats: &analysis.ValueStats{
+			Quantiles: &analysis.Quantiles{P5: 1.5, Median: helpers.ParseDecimal("2"), P99: nil},
+		},
+		LengthStats: &analysis.LengthStats{
+			Quantiles: &analysis.Quantiles{P5: 2, Median: int64(3), P99: 4.5},
+		},
+	}
+
+	NormalizeType(data, loc)
+	assert.Equal(t, helpers.ParseDecimal("1.5"), data.ValueStats.Quantiles.P5)
+	assert.Equal(t, helpers.ParseDecimal("2"), data.ValueStats.Quantiles.Median)
+	assert.Nil(t, data.ValueStats.Quantiles.P99)
+	assert.Equal(t, &analysis.Quantiles{P5: 2.0, Median: 3.0, P99: 4.5}, data.LengthStats.Quantiles)
+
+	data = &analysis.Type{
+		Name: "date",
+		ValueStats: &analysis.ValueStats{
+			Min:       date,
+			Max:       date,
+			Quantiles: &analysis.Quantiles{Median: date},
+		},
+	}
+
+	NormalizeType(data, loc)
+	assert.Equal(t, date.In(loc), data.ValueStats.Quantiles.Median)
+}
+
+func TestNormalizeType_Sum(t *testing.T) {
+	data := &analysis.Type{
+		Name:       "int",
+		ValueStats: &analysis.ValueStats{Min: 1, Max: 2, Sum: 3},
+	}
+
+	NormalizeType(data, time.UTC)
+	assert.Equal(t, float64(3), data.ValueStats.Sum)
+
+	data = &analysis.Type{
+		Name:       "decimal",
+		ValueStats: &analysis.ValueStats{Sum: helpers.ParseDecimal("3.5")},
+	}
+
+	NormalizeType(data, time.UTC)
+	assert.Equal(t, helpers.ParseDecimal("3.5"), data.ValueStats.Sum)
+}
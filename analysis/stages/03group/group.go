@@ -10,21 +10,57 @@ import "github.com/mongoeye/mongoeye/analysis"
 type Options struct {
 	ProcessObjectIdAsDate bool // objectId will be converted to date and analysis
 	//MaxItemsForFreqAnalysis   uint // for the frequency analysis (unique, top, bottom), the first N samples will be used, zero = all items
-	StoreMinMaxAvgValue   bool // store minimum, maximum and average value if possible
-	StoreMinMaxAvgLength  bool // store minimum, maximum and average length
-	StoreCountOfUnique    bool // store number of unique values
-	StoreMostFrequent     uint // saves the N values that most occur, zero = disabled
-	StoreLeastFrequent    uint // saves the N values that least occur, zero = disabled
+	StoreMinMaxAvgValue   bool         // store minimum, maximum and average value if possible
+	StoreSumAndDeviation  bool         // store sum, variance and standard deviation together with value stats
+	StoreMinMaxAvgLength  bool         // store minimum, maximum and average length
+	StoreQuantiles        bool         // store quantiles together with value and length stats
+	UsePercentileOperator bool         // groupInDB computes quantiles with $percentile (MongoDB 7.0+), otherwise from sorted values
+	StoreCountOfUnique    bool         // store number of unique values
+	UniqueMethod          UniqueMethod // method of counting unique values, only groupLocally can estimate
+	UniqueMemoryLimit     uint64       // estimated memory in bytes for exact count, AutoUnique switches to estimate if exceeded
+	StoreMostFrequent     uint         // saves the N values that most occur, zero = disabled
+	StoreLeastFrequent    uint         // saves the N values that least occur, zero = disabled
 	StoreWeekdayHistogram bool
 	StoreHourHistogram    bool
+	StoreStringFormats    bool // count string values matching StringFormatPatterns, groupInDB requires MongoDB 4.2+
+	StoreArrayStats       bool // count arrays by expand.Value.ArrayFlags, only groupLocally supports it
 	ValueHistogramMaxRes  uint // create histogram from values, zero = disabled
 	LengthHistogramMaxRes uint // create histogram from length of values, zero = disabled
 }
 
+// UniqueMethod defines the method of counting unique values.
+type UniqueMethod uint8
+
+const (
+	// ExactUnique - count unique values from frequency table of values
+	ExactUnique UniqueMethod = iota
+
+	// ApproxUnique - estimate number of unique values with HyperLogLog sketch
+	ApproxUnique
+
+	// AutoUnique - count exactly until UniqueMemoryLimit is exceeded, then estimate
+	AutoUnique
+)
+
 // IsNecessaryToCalcValueFreq - will be value frequency distribution needed for further calculations?
 func (options *Options) IsNecessaryToCalcValueFreq() bool {
-	return options.StoreCountOfUnique ||
-		options.StoreMostFrequent > 0 ||
+	return options.IsNecessaryToCalcExactUnique() ||
+		options.IsValueFreqNecessaryForOtherStats()
+}
+
+// IsNecessaryToCalcExactUnique - will be unique values counted from frequency distribution?
+func (options *Options) IsNecessaryToCalcExactUnique() bool {
+	return options.StoreCountOfUnique && options.UniqueMethod != ApproxUnique
+}
+
+// IsNecessaryToEstimateUnique - will be unique values estimated with HyperLogLog sketch?
+func (options *Options) IsNecessaryToEstimateUnique() bool {
+	return options.StoreCountOfUnique && options.UniqueMethod != ExactUnique
+}
+
+// IsValueFreqNecessaryForOtherStats - will be value frequency distribution needed for other stats than count of unique values?
+func (options *Options) IsValueFreqNecessaryForOtherStats() bool {
+	return options.StoreMostFrequent > 0 ||
 		options.StoreLeastFrequent > 0 ||
 		options.ValueHistogramMaxRes > 0
 }
@@ -36,8 +72,10 @@ func (options *Options) IsNecessaryToCalcLengthFreq() bool {
 
 // Result - values from expand stage are grouped by name and type.
 type Result struct {
-	Name string        `bson:"n"`
-	Type analysis.Type `bson:",inline"`
+	Name         string        `bson:"n"`
+	DocsCount    uint64        `bson:"d"` // number of documents that contain the field with this type as the first value
+	ParentsCount uint64        `bson:"p"` // number of parent objects or arrays that contain the field with this type as the first value
+	Type         analysis.Type `bson:",inline"`
 }
 
 // StoreMinMaxValueTypes - Types for which the minimum and maximum values are calculated and stored,
@@ -64,6 +102,43 @@ var StoreAvgValueTypes = []string{
 	"decimal",
 }
 
+// StoreDeviationValueTypes - types for which variance and standard deviation are calculated and stored,
+// if options.StoreMinMaxAvgValue == true && options.StoreSumAndDeviation == true.
+// Sum is stored for StoreAvgValueTypes.
+var StoreDeviationValueTypes = []string{
+	"double",
+	"bool",
+	"date",
+	"int",
+	"long",
+	"decimal",
+}
+
+// StoreQuantileValueTypes - types for which quantiles of values are calculated and stored,
+// if options.StoreMinMaxAvgValue == true && options.StoreQuantiles == true
+var StoreQuantileValueTypes = []string{
+	"double",
+	"date",
+	"int",
+	"long",
+	"decimal",
+}
+
+// StringFormatPatterns - regular expressions of string formats in the order of analysis.StringFormats.Values,
+// if options.StoreStringFormats == true.
+// Patterns are the same for Go and MongoDB ($regexMatch), so \z is used instead of $ that matches before trailing newline in PCRE.
+var StringFormatPatterns = []string{
+	`^[ \t\n\x0B\f\r]*\z`,
+	`^[+-]?[0-9]+\z`,
+	`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?\z`,
+	`^[0-9]{4}-[0-9]{2}-[0-9]{2}([T ][0-9]{2}:[0-9]{2}(:[0-9]{2}(\.[0-9]+)?)?(Z|[+-][0-9]{2}:?[0-9]{2})?)?\z`,
+	`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\z`,
+	`^[0-9a-fA-F]{24}\z`,
+	`^[^ \t\n\x0B\f\r@]+@[^ \t\n\x0B\f\r@]+\.[^ \t\n\x0B\f\r@]+\z`,
+	`^[a-zA-Z][a-zA-Z0-9+.-]*://[^ \t\n\x0B\f\r]+\z`,
+	`^((25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\.){3}(25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\z|^([0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}\z|^([0-9a-fA-F]{1,4}(:[0-9a-fA-F]{1,4})*)?::([0-9a-fA-F]{1,4}(:[0-9a-fA-F]{1,4})*)?\z`,
+}
+
 // StoreLengthTypes - types for which the minimum, maximum, and average values are stored,
 // if options.StoreMinMaxAvgLength == true
 var StoreLengthTypes = []string{
@@ -10,12 +10,12 @@ import (
 // NewStage - GroupInDB stage factory
 func NewStage(groupOptions *group.Options) *analysis.Stage {
 	return &analysis.Stage{
-		PipelineFactory: func(analysisOptions *analysis.Options) *expr.Pipeline {
+		PipelineFactory: func(analysisOptions *analysis.Options) (*expr.Pipeline, error) {
 			p := expr.NewPipeline()
 			GroupValues(p, groupOptions)
 			ComputeStats(p, groupOptions, analysisOptions)
 			GroupStats(p, groupOptions)
-			return p
+			return p, nil
 		},
 	}
 }
@@ -21,6 +21,34 @@ func TestGroupInDBValueMinMaxAvg(t *testing.T) {
 	groupTests.RunTestValueMinMaxAvg(t, NewStage)
 }
 
+func TestGroupInDBQuantiles(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	groupTests.RunTestQuantiles(t, NewStage)
+}
+
+func TestGroupInDBValueSumAndDeviation(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	groupTests.RunTestValueSumAndDeviation(t, NewStage)
+}
+
+func TestGroupInDBStringFormats(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	if !tests.HasMongoDBRegexMatchSupport() {
+		t.Skip("A newer version of the database is needed.")
+	}
+	groupTests.RunTestStringFormats(t, NewStage)
+}
+
+func TestGroupInDBTruncation(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	groupTests.RunTestTruncation(t, NewStage)
+}
+
+func TestGroupInDBEscapedKeys(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+	groupTests.RunTestEscapedKeys(t, NewStage)
+}
+
 func TestGroupInDBValueTopValues(t *testing.T) {
 	tests.SkipTIfNotSupportAggregationAlgorithm(t)
 	groupTests.RunTestValueTopValues(t, NewStage)
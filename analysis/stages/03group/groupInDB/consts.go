@@ -2,6 +2,10 @@ package groupInDB
 
 const bsonAllValues = "AL"
 const bsonValueFreq = "vF"
+const bsonValueQuantiles = "QV"
+const bsonLengthQuantiles = "QL"
+const bsonStringFormat = "SF"
+const bsonTruncation = "TR"
 const stats = "S"
 const statType = "sT"
 const baseStats = "bS"
@@ -31,6 +31,10 @@ func GroupStats(p *expr.Pipeline, groupOptions *group.Options) {
 			group.BsonFieldName:    expr.Field(analysis.BsonId, group.BsonFieldName),
 			analysis.BsonFieldType: expr.Field(analysis.BsonId, analysis.BsonFieldType),
 			analysis.BsonCount:     expr.Field(analysis.BsonCount),
+			group.BsonDocsCount:    expr.Field(group.BsonDocsCount),
+			group.BsonParentsCount: expr.Field(group.BsonParentsCount),
+			analysis.BsonStringFormats: expr.Field(analysis.BsonStringFormats),
+			analysis.BsonTruncation:    expr.Field(analysis.BsonTruncation),
 		},
 	)
 
@@ -49,7 +53,11 @@ func GroupStats(p *expr.Pipeline, groupOptions *group.Options) {
 					expr.Eq(expr.Field(analysis.BsonMaxValue), 1),
 					expr.Field(analysis.BsonMaxValue),
 				),
-				analysis.BsonAvgValue: expr.Field(analysis.BsonAvgValue),
+				analysis.BsonAvgValue:       expr.Field(analysis.BsonAvgValue),
+				analysis.BsonSumValue:       expr.Field(analysis.BsonSumValue),
+				analysis.BsonVarianceValue:  expr.Field(analysis.BsonVarianceValue),
+				analysis.BsonStdDevValue:    expr.Field(analysis.BsonStdDevValue),
+				analysis.BsonValueQuantiles: expr.Field(analysis.BsonValueQuantiles),
 			},
 		},
 	)
@@ -61,7 +69,8 @@ func GroupStats(p *expr.Pipeline, groupOptions *group.Options) {
 			analysis.BsonLengthStats: bson.M{
 				analysis.BsonMinLength: expr.Field(analysis.BsonMinLength),
 				analysis.BsonMaxLength: expr.Field(analysis.BsonMaxLength),
-				analysis.BsonAvgLength: expr.Field(analysis.BsonAvgLength),
+				analysis.BsonAvgLength:       expr.Field(analysis.BsonAvgLength),
+				analysis.BsonLengthQuantiles: expr.Field(analysis.BsonLengthQuantiles),
 			},
 		},
 	)
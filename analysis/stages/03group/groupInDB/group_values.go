@@ -6,6 +6,7 @@ import (
 	"github.com/mongoeye/mongoeye/analysis/stages/03group"
 	"github.com/mongoeye/mongoeye/mongo/expr"
 	"gopkg.in/mgo.v2/bson"
+	"time"
 )
 
 // GroupValues from expand stage by name and type.
@@ -36,9 +37,18 @@ func GroupValues(p *expr.Pipeline, options *group.Options) {
 			group.BsonFieldName:    expr.Field(expand.BsonFieldName),
 			analysis.BsonFieldType: expr.Field(expand.BsonFieldType),
 		},
-		analysis.BsonCount: bson.M{"$sum": 1},
+		analysis.BsonCount:     bson.M{"$sum": 1},
+		group.BsonDocsCount:    bson.M{"$sum": expr.Field(expand.BsonDoc)},
+		group.BsonParentsCount: bson.M{"$sum": expr.Field(expand.BsonParent)},
 	}
 
+	// Count content of objects and arrays skipped because of limits, in the order of analysis.BsonTruncationKeys
+	skipped := expr.Field(expand.BsonSkipped)
+	fields[bsonTruncation+analysis.BsonTruncationKeys[0]] = bson.M{"$sum": expr.Field(expand.BsonTruncated)}
+	fields[bsonTruncation+analysis.BsonTruncationKeys[1]] = bson.M{"$sum": expr.Cond(expr.Gt(skipped, 0), 1, 0)}
+	fields[bsonTruncation+analysis.BsonTruncationKeys[2]] = bson.M{"$sum": skipped}
+	fields[bsonTruncation+analysis.BsonTruncationKeys[3]] = bson.M{"$max": skipped}
+
 	push := bson.M{}
 
 	// Store value
@@ -47,7 +57,8 @@ func GroupValues(p *expr.Pipeline, options *group.Options) {
 		options.StoreCountOfUnique ||
 		options.StoreMostFrequent > 0 ||
 		options.StoreLeastFrequent > 0 ||
-		options.ValueHistogramMaxRes > 0 {
+		options.ValueHistogramMaxRes > 0 ||
+		(options.StoreMinMaxAvgValue && options.StoreQuantiles && !options.UsePercentileOperator) {
 
 		push[expand.BsonValue] = expr.Field(expand.BsonValue)
 	}
@@ -61,8 +72,37 @@ func GroupValues(p *expr.Pipeline, options *group.Options) {
 		fields[analysis.BsonAvgValue] = bson.M{"$avg": expr.Field(expand.BsonValue)}
 	}
 
+	// Calculate sum and standard deviation, dates in milliseconds
+	if options.StoreMinMaxAvgValue && options.StoreSumAndDeviation {
+		value := expr.Field(expand.BsonValue)
+		fields[analysis.BsonSumValue] = bson.M{"$sum": value}
+		fields[analysis.BsonStdDevValue] = bson.M{"$stdDevPop": expr.Cond(
+			expr.Eq(expr.Type(value), "date"),
+			expr.Subtract(value, time.Unix(0, 0)),
+			value,
+		)}
+	}
+
+	// Calculate value quantiles
+	if options.StoreMinMaxAvgValue && options.StoreQuantiles && options.UsePercentileOperator {
+		fields[bsonValueQuantiles] = PercentileAccumulator(expr.Field(expand.BsonValue))
+	}
+
+	// Count string formats
+	if options.StoreStringFormats {
+		isString := expr.Eq(expr.Field(expand.BsonFieldType), "string")
+		for i, pattern := range group.StringFormatPatterns {
+			fields[bsonStringFormat+analysis.BsonStringFormatKeys[i]] = bson.M{"$sum": expr.Cond(
+				isString,
+				expr.Cond(expr.RegexMatch(expr.Field(expand.BsonValue), pattern), 1, 0),
+				0,
+			)}
+		}
+	}
+
 	// Store length
-	if options.LengthHistogramMaxRes > 0 {
+	if options.LengthHistogramMaxRes > 0 ||
+		(options.StoreMinMaxAvgLength && options.StoreQuantiles && !options.UsePercentileOperator) {
 		push[expand.BsonLength] = expr.Field(expand.BsonLength)
 	}
 
@@ -75,6 +115,11 @@ func GroupValues(p *expr.Pipeline, options *group.Options) {
 		fields[analysis.BsonAvgLength] = bson.M{"$avg": expr.Field(expand.BsonLength)}
 	}
 
+	// Calculate length quantiles
+	if options.StoreMinMaxAvgLength && options.StoreQuantiles && options.UsePercentileOperator {
+		fields[bsonLengthQuantiles] = PercentileAccumulator(expr.Field(expand.BsonLength))
+	}
+
 	// Store value or length?
 	if len(push) > 0 {
 		fields[bsonAllValues] = bson.M{"$push": push}
@@ -105,6 +150,7 @@ func prepareFields(p *expr.Pipeline, options *group.Options) {
 		options.StoreCountOfUnique ||
 		options.StoreMostFrequent > 0 ||
 		options.StoreLeastFrequent > 0 ||
+		options.StoreStringFormats ||
 		options.ValueHistogramMaxRes > 0 {
 
 		typeSw := expr.Switch()
@@ -153,6 +199,10 @@ func prepareFields(p *expr.Pipeline, options *group.Options) {
 	p.AddStage("project", bson.M{
 		expand.BsonFieldName: 1,
 		expand.BsonFieldType: 1,
+		expand.BsonDoc:       1,
+		expand.BsonParent:    1,
+		expand.BsonTruncated: 1,
+		expand.BsonSkipped:   1,
 		expand.BsonValue:     valueProject,
 		expand.BsonLength:    lengthProject,
 	})
@@ -30,9 +30,38 @@ func BaseComputation(options *group.Options) *expr.Pipeline {
 			analysis.BsonFieldType: expr.Field(analysis.BsonId, analysis.BsonFieldType),
 			statType:               baseStats,
 		},
-		analysis.BsonCount: 1,
+		analysis.BsonCount:     1,
+		group.BsonDocsCount:    1,
+		group.BsonParentsCount: 1,
 	}
 
+	// String formats, only for strings
+	if options.StoreStringFormats {
+		formats := bson.M{}
+		for _, key := range analysis.BsonStringFormatKeys {
+			formats[key] = expr.Field(bsonStringFormat + key)
+		}
+		project[analysis.BsonStringFormats] = expr.Cond(
+			expr.Eq(expr.Field(analysis.BsonId, analysis.BsonFieldType), "string"),
+			formats,
+			expr.Var("REMOVE"),
+		)
+	}
+
+	// Truncation, only if some content was skipped
+	truncation := bson.M{}
+	for _, key := range analysis.BsonTruncationKeys {
+		truncation[key] = expr.Field(bsonTruncation + key)
+	}
+	project[analysis.BsonTruncation] = expr.Cond(
+		expr.Gt(expr.Add(
+			expr.Field(bsonTruncation+analysis.BsonTruncationKeys[0]),
+			expr.Field(bsonTruncation+analysis.BsonTruncationKeys[1]),
+		), 0),
+		truncation,
+		expr.Var("REMOVE"),
+	)
+
 	p.AddStage("project", project)
 
 	return p
@@ -2,6 +2,7 @@ package groupInDB
 
 import (
 	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
 	"github.com/mongoeye/mongoeye/analysis/stages/03group"
 	"github.com/mongoeye/mongoeye/mongo/expr"
 	"gopkg.in/mgo.v2/bson"
@@ -19,6 +20,7 @@ import (
 //			MIN_LENGTH: 2,
 //			MAX_LENGTH: 5,
 //			AVG_LENGTH: 2.45,
+//			LENGTH_QUANTILES: {...},
 //			...
 //		},
 //		...
@@ -30,6 +32,14 @@ func LengthStatsComputation(options *group.Options) *expr.Pipeline {
 		(analysis.BsonId + "." + analysis.BsonFieldType): bson.M{"$in": group.StoreLengthTypes},
 	})
 
+	if options.StoreQuantiles && !options.UsePercentileOperator {
+		SortAllValues(p, expand.BsonLength, bsonLengthQuantiles, []string{
+			analysis.BsonMinLength,
+			analysis.BsonMaxLength,
+			analysis.BsonAvgLength,
+		})
+	}
+
 	project := bson.M{
 		analysis.BsonId: bson.M{
 			group.BsonFieldName:    expr.Field(analysis.BsonId, group.BsonFieldName),
@@ -41,6 +51,14 @@ func LengthStatsComputation(options *group.Options) *expr.Pipeline {
 		analysis.BsonAvgLength: 1,
 	}
 
+	if options.StoreQuantiles {
+		if options.UsePercentileOperator {
+			project[analysis.BsonLengthQuantiles] = QuantilesFromPercentile(bsonLengthQuantiles)
+		} else {
+			project[analysis.BsonLengthQuantiles] = QuantilesFromSorted(bsonLengthQuantiles)
+		}
+	}
+
 	p.AddStage("project", project)
 
 	return p
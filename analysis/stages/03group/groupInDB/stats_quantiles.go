@@ -0,0 +1,111 @@
+package groupInDB
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// PercentileAccumulator returns $percentile accumulator (MongoDB 7.0+) for quantiles of input.
+// Result is an array of doubles in the order of analysis.QuantileProbabilities, dates are converted to milliseconds.
+func PercentileAccumulator(input interface{}) bson.M {
+	return bson.M{
+		"$percentile": bson.M{
+			"input": expr.Cond(
+				expr.Eq(expr.Type(input), "date"),
+				bson.M{"$toLong": input},
+				input,
+			),
+			"p":      analysis.QuantileProbabilities,
+			"method": "approximate",
+		},
+	}
+}
+
+// SortAllValues pushes sorted values or lengths from ALL_VALUES to the output field.
+// Other fields of the group are preserved.
+// Example results:
+//
+//	[
+//		{
+//			ID: {
+//				FIELD_NAME: "name"
+//				FIELD_TYPE: "string",
+//			}
+//			OUTPUT: [1, 5, 7, 12, ...]
+//			...
+//		},
+//		...
+//	]
+func SortAllValues(p *expr.Pipeline, key string, output string, preserve []string) {
+	p.AddStage("unwind", bson.M{
+		"path":                       expr.Field(bsonAllValues),
+		"preserveNullAndEmptyArrays": true,
+	})
+
+	p.AddStage("sort", bson.M{bsonAllValues + "." + key: 1})
+
+	group := bson.M{
+		analysis.BsonId: expr.Field(analysis.BsonId),
+		output:          bson.M{"$push": expr.Field(bsonAllValues, key)},
+	}
+	for _, field := range preserve {
+		group[field] = bson.M{"$first": expr.Field(field)}
+	}
+
+	p.AddStage("group", group)
+}
+
+// QuantilesFromPercentile converts result of PercentileAccumulator to analysis.Quantiles, dates and decimals are restored.
+func QuantilesFromPercentile(percentile string) interface{} {
+	typeField := expr.Field(analysis.BsonId, analysis.BsonFieldType)
+	q := bson.M{}
+	for i, key := range analysis.BsonQuantiles {
+		value := expr.ArrayElemAt(expr.Field(percentile), i)
+		sw := expr.Switch()
+		sw.AddBranch(expr.In(typeField, []interface{}{"date", "objectId"}), bson.M{"$toDate": value})
+		sw.AddBranch(expr.Eq(typeField, "decimal"), bson.M{"$toDecimal": value})
+		sw.SetDefault(value)
+		q[key] = sw.Bson()
+	}
+
+	return expr.Cond(
+		expr.Eq(expr.Type(expr.Field(percentile)), "array"),
+		q,
+		expr.Var("REMOVE"),
+	)
+}
+
+// QuantilesFromSorted interpolates quantiles from sorted values, see SortAllValues.
+func QuantilesFromSorted(sorted string) interface{} {
+	q := bson.M{}
+	for i, key := range analysis.BsonQuantiles {
+		q[key] = expr.Let(
+			bson.M{
+				"pos": expr.Multiply(
+					analysis.QuantileProbabilities[i],
+					expr.Subtract(expr.Size(expr.Field(sorted)), 1),
+				),
+			},
+			expr.Let(
+				bson.M{
+					"lo": expr.ArrayElemAt(expr.Field(sorted), expr.Floor(expr.Var("pos"))),
+					"hi": expr.ArrayElemAt(expr.Field(sorted), expr.Ceil(expr.Var("pos"))),
+				},
+				expr.Add(
+					expr.Var("lo"),
+					expr.Multiply(
+						expr.Subtract(expr.Var("hi"), expr.Var("lo")),
+						expr.Subtract(expr.Var("pos"), expr.Floor(expr.Var("pos"))),
+					),
+				),
+			),
+		)
+	}
+
+	return expr.Cond(
+		expr.Gt(expr.Size(expr.Field(sorted)), 0),
+		q,
+		expr.Var("REMOVE"),
+	)
+}
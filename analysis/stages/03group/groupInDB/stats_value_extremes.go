@@ -2,6 +2,7 @@ package groupInDB
 
 import (
 	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
 	"github.com/mongoeye/mongoeye/analysis/stages/03group"
 	"github.com/mongoeye/mongoeye/mongo/expr"
 	"gopkg.in/mgo.v2/bson"
@@ -19,6 +20,10 @@ import (
 //			MIN_VALUE: "abc",
 //			MAX_VALUE: "NKLXYZ",
 //			AVG_VALUE: nil,
+//			SUM_VALUE: nil,
+//			STDDEV_VALUE: nil,
+//			VARIANCE_VALUE: nil,
+//			VALUE_QUANTILES: {...},
 //			...
 //		},
 //		...
@@ -37,6 +42,16 @@ func ValueStatsComputation(options *group.Options) *expr.Pipeline {
 		(analysis.BsonId + "." + analysis.BsonFieldType): bson.M{"$in": allowedTypes},
 	})
 
+	if options.StoreQuantiles && !options.UsePercentileOperator {
+		SortAllValues(p, expand.BsonValue, bsonValueQuantiles, []string{
+			analysis.BsonMinValue,
+			analysis.BsonMaxValue,
+			analysis.BsonAvgValue,
+			analysis.BsonSumValue,
+			analysis.BsonStdDevValue,
+		})
+	}
+
 	project := bson.M{
 		analysis.BsonId: bson.M{
 			statType:               valueStats,
@@ -48,6 +63,62 @@ func ValueStatsComputation(options *group.Options) *expr.Pipeline {
 		analysis.BsonAvgValue: 1,
 	}
 
+	if options.StoreSumAndDeviation {
+		typeField := expr.Field(analysis.BsonId, analysis.BsonFieldType)
+
+		dateTypes := []string{"date"}
+		deviationTypes := append([]string{}, group.StoreDeviationValueTypes...)
+		if options.ProcessObjectIdAsDate {
+			dateTypes = append(dateTypes, "objectId")
+			deviationTypes = append(deviationTypes, "objectId")
+		}
+
+		project[analysis.BsonSumValue] = expr.Cond(
+			expr.In(typeField, group.StoreAvgValueTypes),
+			expr.Field(analysis.BsonSumValue),
+			expr.Var("REMOVE"),
+		)
+
+		// Standard deviation of dates in seconds
+		stdDev := expr.Cond(
+			expr.In(typeField, dateTypes),
+			expr.Divide(expr.Field(analysis.BsonStdDevValue), 1000),
+			expr.Field(analysis.BsonStdDevValue),
+		)
+
+		project[analysis.BsonStdDevValue] = expr.Cond(
+			expr.In(typeField, deviationTypes),
+			stdDev,
+			expr.Var("REMOVE"),
+		)
+
+		project[analysis.BsonVarianceValue] = expr.Cond(
+			expr.In(typeField, deviationTypes),
+			expr.Let(bson.M{"sd": stdDev}, expr.Multiply(expr.Var("sd"), expr.Var("sd"))),
+			expr.Var("REMOVE"),
+		)
+	}
+
+	if options.StoreQuantiles {
+		quantileTypes := append([]string{}, group.StoreQuantileValueTypes...)
+		if options.ProcessObjectIdAsDate {
+			quantileTypes = append(quantileTypes, "objectId")
+		}
+
+		var quantiles interface{}
+		if options.UsePercentileOperator {
+			quantiles = QuantilesFromPercentile(bsonValueQuantiles)
+		} else {
+			quantiles = QuantilesFromSorted(bsonValueQuantiles)
+		}
+
+		project[analysis.BsonValueQuantiles] = expr.Cond(
+			expr.In(expr.Field(analysis.BsonId, analysis.BsonFieldType), quantileTypes),
+			quantiles,
+			expr.Var("REMOVE"),
+		)
+	}
+
 	p.AddStage("project", project)
 
 	return p
@@ -2,6 +2,7 @@
 package groupLocally
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
 	"github.com/mongoeye/mongoeye/analysis/stages/03group"
@@ -10,9 +11,9 @@ import (
 // NewStage - GroupLocally stage factory.
 func NewStage(groupOptions *group.Options) *analysis.Stage {
 	return &analysis.Stage{
-		Processor: func(_input interface{}, analysisOptions *analysis.Options) interface{} {
+		Processor: func(ctx context.Context, _input interface{}, analysisOptions *analysis.Options) (interface{}, error) {
 			// Input channel
-			input := expand.ToValueChannel(_input, analysisOptions.Concurrency, analysisOptions.BufferSize)
+			input := expand.ToValueChannel(ctx, _input, analysisOptions.Concurrency, analysisOptions.BufferSize)
 
 			// Create output channel and wait group
 			output := make(chan group.Result, analysisOptions.BufferSize)
@@ -24,7 +25,7 @@ func NewStage(groupOptions *group.Options) *analysis.Stage {
 				dateHourFreq:    runDateHourFreqWorkers(groupOptions, analysisOptions),
 			}
 
-			groupProcess := runGroupWorkers(input, dataProcesses, groupOptions, analysisOptions)
+			groupProcess := runGroupWorkers(ctx, input, dataProcesses, groupOptions, analysisOptions)
 			mergeProcess := runMergeWorker(groupProcess, groupOptions, analysisOptions)
 			statsProcess := runStatsWorkers(dataProcesses, mergeProcess, output, groupOptions, analysisOptions)
 
@@ -34,7 +35,7 @@ func NewStage(groupOptions *group.Options) *analysis.Stage {
 				close(output)
 			}()
 
-			return output
+			return output, nil
 		},
 	}
 }
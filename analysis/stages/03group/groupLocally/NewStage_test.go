@@ -17,6 +17,26 @@ func TestGroupLocallyValueMinMaxAvg(t *testing.T) {
 	groupTests.RunTestValueMinMaxAvg(t, NewStage)
 }
 
+func TestGroupLocallyQuantiles(t *testing.T) {
+	groupTests.RunTestQuantiles(t, NewStage)
+}
+
+func TestGroupLocallyValueSumAndDeviation(t *testing.T) {
+	groupTests.RunTestValueSumAndDeviation(t, NewStage)
+}
+
+func TestGroupLocallyStringFormats(t *testing.T) {
+	groupTests.RunTestStringFormats(t, NewStage)
+}
+
+func TestGroupLocallyTruncation(t *testing.T) {
+	groupTests.RunTestTruncation(t, NewStage)
+}
+
+func TestGroupLocallyEscapedKeys(t *testing.T) {
+	groupTests.RunTestEscapedKeys(t, NewStage)
+}
+
 func TestGroupLocallyValueTopValues(t *testing.T) {
 	groupTests.RunTestValueTopValues(t, NewStage)
 }
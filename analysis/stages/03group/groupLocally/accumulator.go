@@ -1,6 +1,7 @@
 package groupLocally
 
 import (
+	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/analysis/stages/03group"
 	"github.com/mongoeye/mongoeye/helpers"
 	"math"
@@ -14,7 +15,9 @@ type GroupId struct {
 
 // Accumulator represents aggregation in one group worker.
 type Accumulator struct {
-	Count uint64
+	Count        uint64
+	DocsCount    uint64
+	ParentsCount uint64
 
 	ConvertObjectIdToDate bool
 
@@ -25,11 +28,27 @@ type Accumulator struct {
 	StoreAvgValue bool
 	ValuesSum     float64
 
+	StoreSum  bool
+	Deviation *helpers.RunningStats
+
 	StoreMinMaxAvgLength bool
 	MinLength            uint
 	MaxLength            uint
 	LengthSum            uint64
 
+	ValueQuantiles  *helpers.TDigest
+	LengthQuantiles *helpers.TDigest
+
+	UniqueSketch *helpers.HyperLogLog
+
+	StringFormats *analysis.StringFormats
+
+	ArrayStats *analysis.ArrayStats
+
+	KeyShapes *analysis.KeyShapes // created with the first value of collapsed object
+
+	Truncation *analysis.Truncation // created with the first truncated object or array
+
 	StoreValueDistribution       bool
 	StoreLengthDistribution      bool
 	StoreDateWeekdayDistribution bool
@@ -51,13 +70,17 @@ func createAccumulator(id GroupId, options *group.Options) *Accumulator {
 		t = "date"
 	}
 
-	if (options.StoreCountOfUnique && helpers.InStringSlice(t, group.StoreCountOfUniqueTypes)) ||
+	if (options.IsNecessaryToCalcExactUnique() && helpers.InStringSlice(t, group.StoreCountOfUniqueTypes)) ||
 		(options.StoreMostFrequent > 0 && helpers.InStringSlice(t, group.StoreTopValuesTypes)) ||
 		(options.StoreLeastFrequent > 0 && helpers.InStringSlice(t, group.StoreBottomValuesTypes)) ||
 		(options.ValueHistogramMaxRes > 0 && helpers.InStringSlice(t, group.ValueHistogramTypes)) {
 		acc.StoreValueDistribution = true
 	}
 
+	if options.IsNecessaryToEstimateUnique() && helpers.InStringSlice(t, group.StoreCountOfUniqueTypes) {
+		acc.UniqueSketch = helpers.NewHyperLogLog(helpers.HyperLogLogPrecision)
+	}
+
 	if options.LengthHistogramMaxRes > 0 && helpers.InStringSlice(t, group.LengthHistogramTypes) {
 		acc.StoreLengthDistribution = true
 	}
@@ -67,6 +90,13 @@ func createAccumulator(id GroupId, options *group.Options) *Accumulator {
 		acc.StoreAvgValue = helpers.InStringSlice(t, group.StoreAvgValueTypes)
 	}
 
+	if options.StoreMinMaxAvgValue && options.StoreSumAndDeviation {
+		acc.StoreSum = acc.StoreAvgValue
+		if helpers.InStringSlice(t, group.StoreDeviationValueTypes) {
+			acc.Deviation = &helpers.RunningStats{}
+		}
+	}
+
 	if options.StoreMinMaxAvgLength || acc.StoreLengthDistribution {
 		acc.StoreMinMaxAvgLength = helpers.InStringSlice(t, group.StoreLengthTypes)
 		if acc.StoreMinMaxAvgLength {
@@ -75,6 +105,22 @@ func createAccumulator(id GroupId, options *group.Options) *Accumulator {
 		}
 	}
 
+	if options.StoreQuantiles && options.StoreMinMaxAvgValue && helpers.InStringSlice(t, group.StoreQuantileValueTypes) {
+		acc.ValueQuantiles = helpers.NewTDigest(helpers.TDigestCompression)
+	}
+
+	if options.StoreQuantiles && options.StoreMinMaxAvgLength && helpers.InStringSlice(t, group.StoreLengthTypes) {
+		acc.LengthQuantiles = helpers.NewTDigest(helpers.TDigestCompression)
+	}
+
+	if options.StoreStringFormats && t == "string" {
+		acc.StringFormats = &analysis.StringFormats{}
+	}
+
+	if options.StoreArrayStats && t == "array" {
+		acc.ArrayStats = &analysis.ArrayStats{}
+	}
+
 	if options.StoreWeekdayHistogram && t == "date" {
 		acc.StoreDateWeekdayDistribution = true
 	}
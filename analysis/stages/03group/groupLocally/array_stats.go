@@ -0,0 +1,30 @@
+package groupLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+)
+
+// Count the array by its flags, see expand.Value.ArrayFlags.
+func countArrayStats(stats *analysis.ArrayStats, flags uint) {
+	if flags&expand.ArrayEmpty != 0 {
+		stats.Empty++
+	}
+	if flags&expand.ArrayHomogeneous != 0 {
+		stats.Homogeneous++
+	}
+	if flags&expand.ArrayDuplicates != 0 {
+		stats.Duplicates++
+	}
+	if flags&expand.ArraySorted != 0 {
+		stats.Sorted++
+	}
+}
+
+// Add counts from other stats.
+func mergeArrayStats(stats *analysis.ArrayStats, other *analysis.ArrayStats) {
+	stats.Empty += other.Empty
+	stats.Homogeneous += other.Homogeneous
+	stats.Duplicates += other.Duplicates
+	stats.Sorted += other.Sorted
+}
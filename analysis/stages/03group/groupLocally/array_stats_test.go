@@ -0,0 +1,21 @@
+package groupLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_countArrayStats(t *testing.T) {
+	stats := analysis.ArrayStats{}
+	countArrayStats(&stats, expand.ArrayEmpty)
+	countArrayStats(&stats, expand.ArrayHomogeneous|expand.ArraySorted)
+	countArrayStats(&stats, expand.ArrayHomogeneous|expand.ArrayDuplicates)
+	countArrayStats(&stats, 0)
+
+	assert.Equal(t, analysis.ArrayStats{Empty: 1, Homogeneous: 2, Duplicates: 1, Sorted: 1}, stats)
+
+	mergeArrayStats(&stats, &analysis.ArrayStats{Empty: 1, Sorted: 2})
+	assert.Equal(t, analysis.ArrayStats{Empty: 2, Homogeneous: 2, Duplicates: 1, Sorted: 3}, stats)
+}
@@ -3,6 +3,7 @@ package groupLocally
 import (
 	"github.com/mongoeye/mongoeye/analysis/stages/03group"
 	"sync"
+	"sync/atomic"
 )
 
 type dataProcesses struct {
@@ -78,9 +79,15 @@ func (p *lengthFreqProcess) closeInput() {
 }
 
 type valueFreqProcess struct {
-	Input  chan value
-	Output valueFreqMap
-	wg     *sync.WaitGroup
+	Input    chan value
+	Output   valueFreqMap
+	wg       *sync.WaitGroup
+	exceeded int32
+}
+
+// IsExceeded returns true if frequency tables were dropped because of memory limit.
+func (p *valueFreqProcess) IsExceeded() bool {
+	return atomic.LoadInt32(&p.exceeded) == 1
 }
 
 func (p *valueFreqProcess) wait() {
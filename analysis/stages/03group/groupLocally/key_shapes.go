@@ -0,0 +1,31 @@
+package groupLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+)
+
+// Increment count of the key shape.
+func countKeyShape(shapes *analysis.KeyShapes, shape string) {
+	switch shape {
+	case expand.KeyShapeDate:
+		shapes.Date++
+	case expand.KeyShapeNumber:
+		shapes.Number++
+	case expand.KeyShapeObjectId:
+		shapes.ObjectId++
+	case expand.KeyShapeUUID:
+		shapes.UUID++
+	default:
+		shapes.Other++
+	}
+}
+
+// Add counts from other shapes.
+func mergeKeyShapes(shapes *analysis.KeyShapes, other *analysis.KeyShapes) {
+	shapes.Date += other.Date
+	shapes.Number += other.Number
+	shapes.ObjectId += other.ObjectId
+	shapes.UUID += other.UUID
+	shapes.Other += other.Other
+}
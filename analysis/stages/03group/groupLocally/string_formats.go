@@ -0,0 +1,33 @@
+package groupLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/03group"
+	"regexp"
+)
+
+var stringFormatRegexps []*regexp.Regexp
+
+func init() {
+	stringFormatRegexps = make([]*regexp.Regexp, len(group.StringFormatPatterns))
+	for i, pattern := range group.StringFormatPatterns {
+		stringFormatRegexps[i] = regexp.MustCompile(pattern)
+	}
+}
+
+// Increment count of each format that the value matches.
+func countStringFormats(formats *analysis.StringFormats, value string) {
+	for i, count := range formats.Values() {
+		if stringFormatRegexps[i].MatchString(value) {
+			*count++
+		}
+	}
+}
+
+// Add counts from other formats.
+func mergeStringFormats(formats *analysis.StringFormats, other *analysis.StringFormats) {
+	otherValues := other.Values()
+	for i, count := range formats.Values() {
+		*count += *otherValues[i]
+	}
+}
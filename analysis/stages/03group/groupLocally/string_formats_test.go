@@ -0,0 +1,49 @@
+package groupLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_countStringFormats(t *testing.T) {
+	cases := map[string]analysis.StringFormats{
+		"":                                     {Empty: 1},
+		" \t\n":                                {Empty: 1},
+		"abc":                                  {},
+		"123":                                  {Integer: 1, Decimal: 1},
+		"-42":                                  {Integer: 1, Decimal: 1},
+		"123\n":                                {},
+		"1.5":                                  {Decimal: 1},
+		".5e-3":                                {Decimal: 1},
+		"1.2.3":                                {},
+		"2017-04-01":                           {Date: 1},
+		"2017-04-01T12:30:00.123Z":             {Date: 1},
+		"2017-04-01 12:30+02:00":               {Date: 1},
+		"2017-04-01T":                          {},
+		"123e4567-e89b-12d3-a456-426655440000": {UUID: 1},
+		"58e20d849d3ae7e1f8eac9c0":             {ObjectId: 1},
+		"123456789012345678901234":             {Integer: 1, Decimal: 1, ObjectId: 1},
+		"john.doe@example.com":                 {Email: 1},
+		"john@localhost":                       {},
+		"https://example.com/a?b=c":            {URL: 1},
+		"192.168.0.1":                          {IP: 1},
+		"256.1.1.1":                            {},
+		"2001:db8::1":                          {IP: 1},
+		"::":                                   {IP: 1},
+		"12:30:45":                             {},
+	}
+
+	for value, expected := range cases {
+		formats := analysis.StringFormats{}
+		countStringFormats(&formats, value)
+		assert.Equal(t, expected, formats, "value %q", value)
+	}
+}
+
+func Test_mergeStringFormats(t *testing.T) {
+	a := &analysis.StringFormats{Empty: 1, Integer: 2}
+	b := &analysis.StringFormats{Integer: 3, IP: 4}
+	mergeStringFormats(a, b)
+	assert.Equal(t, &analysis.StringFormats{Empty: 1, Integer: 5, IP: 4}, a)
+}
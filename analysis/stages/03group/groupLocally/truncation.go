@@ -0,0 +1,29 @@
+package groupLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+)
+
+// Count content of the object or array skipped because of limits.
+func countTruncation(truncation *analysis.Truncation, value *expand.Value) {
+	truncation.Depth += uint64(value.Truncated)
+
+	if value.SkippedItems > 0 {
+		truncation.Arrays++
+		truncation.Items += uint64(value.SkippedItems)
+		if uint64(value.SkippedItems) > truncation.MaxItems {
+			truncation.MaxItems = uint64(value.SkippedItems)
+		}
+	}
+}
+
+// Add counts from other truncation.
+func mergeTruncation(truncation *analysis.Truncation, other *analysis.Truncation) {
+	truncation.Depth += other.Depth
+	truncation.Arrays += other.Arrays
+	truncation.Items += other.Items
+	if other.MaxItems > truncation.MaxItems {
+		truncation.MaxItems = other.MaxItems
+	}
+}
@@ -3,37 +3,76 @@ package groupLocally
 import (
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/analysis/stages/03group"
+	"reflect"
 	"sync"
+	"sync/atomic"
 )
 
+// Estimated memory of one item in frequency table without value data.
+const freqTableItemSize = 48
+
+// Estimated memory of value data referenced by frequency table item.
+// Values larger than a pointer are stored in the interface indirectly.
+func valueMemory(v interface{}) uint64 {
+	if v == nil {
+		return 0
+	}
+
+	rv := reflect.ValueOf(v)
+	memory := uint64(rv.Type().Size())
+	if rv.Kind() == reflect.String {
+		memory += uint64(rv.Len())
+	}
+
+	return memory
+}
+
 func runValueFreqWorkers(groupOptions *group.Options, analysisOptions *analysis.Options) *valueFreqProcess {
-	ch := make(chan value, analysisOptions.BufferSize)
-	wg := &sync.WaitGroup{}
-	m := make(valueFreqMap)
+	p := &valueFreqProcess{
+		Input:  make(chan value, analysisOptions.BufferSize),
+		Output: make(valueFreqMap),
+		wg:     &sync.WaitGroup{},
+	}
 
 	if groupOptions.IsNecessaryToCalcValueFreq() {
-		wg.Add(1)
-		go valueFreqWorker(ch, m, wg)
+		p.wg.Add(1)
+		go valueFreqWorker(p, groupOptions)
 	}
 
-	return &valueFreqProcess{
-		Input:  ch,
-		Output: m,
-		wg:     wg,
-	}
+	return p
 }
 
-func valueFreqWorker(ch <-chan value, m valueFreqMap, wg *sync.WaitGroup) {
-	defer wg.Done()
+// If the memory limit is exceeded in AutoUnique method, tables are dropped and the estimate of unique values is used.
+// Tables are never dropped if they are needed for other stats.
+func valueFreqWorker(p *valueFreqProcess, options *group.Options) {
+	defer p.wg.Done()
+
+	canDrop := options.UniqueMethod == group.AutoUnique && !options.IsValueFreqNecessaryForOtherStats()
+	memory := uint64(0)
+
+	for v := range p.Input {
+		if p.IsExceeded() {
+			continue
+		}
 
-	for v := range ch {
 		// Load or create frequency distribution table
-		table := m[v.Id]
+		table := p.Output[v.Id]
 		if table == nil {
 			table = make(commonFreqTable)
-			m[v.Id] = table
+			p.Output[v.Id] = table
+		}
+
+		if _, found := table[v.Value]; !found {
+			memory += freqTableItemSize + valueMemory(v.Value)
 		}
 
 		table[v.Value]++
+
+		if canDrop && memory > options.UniqueMemoryLimit {
+			for id := range p.Output {
+				delete(p.Output, id)
+			}
+			atomic.StoreInt32(&p.exceeded, 1)
+		}
 	}
 }
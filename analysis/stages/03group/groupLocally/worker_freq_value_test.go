@@ -0,0 +1,94 @@
+package groupLocally
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/03group"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func runValueFreqWorkersWithValues(options *group.Options, n int) *valueFreqProcess {
+	p := runValueFreqWorkers(options, &analysis.Options{BufferSize: 10})
+	id := GroupId{Name: "str", Type: "string"}
+	for i := 0; i < n; i++ {
+		p.Input <- value{Id: id, Value: "value" + strconv.Itoa(i)}
+	}
+	close(p.Input)
+	p.wg.Wait()
+	return p
+}
+
+func Test_valueFreqWorker_MemoryLimit(t *testing.T) {
+	options := &group.Options{
+		StoreCountOfUnique: true,
+		UniqueMethod:       group.AutoUnique,
+		UniqueMemoryLimit:  1000,
+	}
+
+	// Under the limit
+	p := runValueFreqWorkersWithValues(options, 10)
+	assert.False(t, p.IsExceeded())
+	assert.Equal(t, 10, len(p.Output[GroupId{Name: "str", Type: "string"}]))
+
+	// Over the limit, tables are dropped
+	p = runValueFreqWorkersWithValues(options, 100)
+	assert.True(t, p.IsExceeded())
+	assert.Equal(t, 0, len(p.Output))
+}
+
+func Test_valueFreqWorker_MemoryLimitOtherStats(t *testing.T) {
+	// Tables are needed for most frequent values
+	options := &group.Options{
+		StoreCountOfUnique: true,
+		StoreMostFrequent:  5,
+		UniqueMethod:       group.AutoUnique,
+		UniqueMemoryLimit:  1000,
+	}
+
+	p := runValueFreqWorkersWithValues(options, 100)
+	assert.False(t, p.IsExceeded())
+	assert.Equal(t, 100, len(p.Output[GroupId{Name: "str", Type: "string"}]))
+
+	// Exact method ignores the limit
+	options = &group.Options{
+		StoreCountOfUnique: true,
+		UniqueMethod:       group.ExactUnique,
+		UniqueMemoryLimit:  1000,
+	}
+
+	p = runValueFreqWorkersWithValues(options, 100)
+	assert.False(t, p.IsExceeded())
+	assert.Equal(t, 100, len(p.Output[GroupId{Name: "str", Type: "string"}]))
+}
+
+func Test_valueFreqWorker_MemoryLimitDates(t *testing.T) {
+	options := &group.Options{
+		StoreCountOfUnique: true,
+		UniqueMethod:       group.AutoUnique,
+		UniqueMemoryLimit:  1000,
+	}
+
+	// Memory of all value types is counted, not only strings
+	p := runValueFreqWorkers(options, &analysis.Options{BufferSize: 10})
+	id := GroupId{Name: "date", Type: "date"}
+	for i := 0; i < 20; i++ {
+		p.Input <- value{Id: id, Value: time.Unix(int64(i), 0)}
+	}
+	close(p.Input)
+	p.wg.Wait()
+
+	assert.True(t, p.IsExceeded())
+}
+
+func Test_valueMemory(t *testing.T) {
+	assert.Equal(t, uint64(0), valueMemory(nil))
+	assert.Equal(t, uint64(16+3), valueMemory("abc"))
+	assert.Equal(t, uint64(8), valueMemory(1.5))
+	assert.Equal(t, uint64(8), valueMemory(int64(1)))
+	assert.Equal(t, uint64(24), valueMemory(time.Unix(0, 0)))
+	assert.Equal(t, uint64(16+12), valueMemory(bson.NewObjectId()))
+	assert.True(t, valueMemory(bson.Decimal128{}) >= 16)
+}
@@ -1,21 +1,23 @@
 package groupLocally
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
 	"github.com/mongoeye/mongoeye/analysis/stages/03group"
 	"github.com/mongoeye/mongoeye/helpers"
 	"reflect"
 	"sync"
+	"time"
 )
 
-func runGroupWorkers(input <-chan expand.Value, dataProcesses *dataProcesses, groupOptions *group.Options, analysisOptions *analysis.Options) *groupProcess {
+func runGroupWorkers(ctx context.Context, input <-chan expand.Value, dataProcesses *dataProcesses, groupOptions *group.Options, analysisOptions *analysis.Options) *groupProcess {
 	wg := &sync.WaitGroup{}
 	wg.Add(analysisOptions.Concurrency)
 	results := make([]GroupResults, analysisOptions.Concurrency)
 	for i := 0; i < analysisOptions.Concurrency; i++ {
 		results[i] = make(GroupResults)
-		go groupWorker(input, results[i], dataProcesses, groupOptions, analysisOptions, wg)
+		go groupWorker(ctx, input, results[i], dataProcesses, groupOptions, analysisOptions, wg)
 	}
 
 	go func() {
@@ -31,10 +33,15 @@ func runGroupWorkers(input <-chan expand.Value, dataProcesses *dataProcesses, gr
 	}
 }
 
-func groupWorker(input <-chan expand.Value, results GroupResults, dataProcesses *dataProcesses, groupOptions *group.Options, analysisOptions *analysis.Options, wg *sync.WaitGroup) {
+// When the context is done, the input is drained without processing.
+func groupWorker(ctx context.Context, input <-chan expand.Value, results GroupResults, dataProcesses *dataProcesses, groupOptions *group.Options, analysisOptions *analysis.Options, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for fieldValue := range input {
+		if ctx.Err() != nil {
+			continue
+		}
+
 		// Group by id
 		id := GroupId{
 			Name: fieldValue.Name,
@@ -63,14 +70,57 @@ func groupWorker(input <-chan expand.Value, results GroupResults, dataProcesses
 
 		// Count
 		acc.Count++
+		acc.DocsCount += uint64(fieldValue.Doc)
+		acc.ParentsCount += uint64(fieldValue.Parent)
+
+		// Shapes of keys of collapsed objects
+		if fieldValue.KeyShape != "" {
+			if acc.KeyShapes == nil {
+				acc.KeyShapes = &analysis.KeyShapes{}
+			}
+			countKeyShape(acc.KeyShapes, fieldValue.KeyShape)
+		}
+
+		// Content skipped because of limits
+		if fieldValue.Truncated > 0 || fieldValue.SkippedItems > 0 {
+			if acc.Truncation == nil {
+				acc.Truncation = &analysis.Truncation{}
+			}
+			countTruncation(acc.Truncation, &fieldValue)
+		}
+
+		// Arrays by their items
+		if acc.ArrayStats != nil {
+			countArrayStats(acc.ArrayStats, fieldValue.ArrayFlags)
+		}
 
 		// Value extremes
 		if acc.StoreMinMaxValue {
 			storeMinMaxSum(acc, t, fieldValue.Value)
 		}
 
-		// Value freq
-		if acc.StoreValueDistribution {
+		// Standard deviation
+		if acc.Deviation != nil && fieldValue.Value != nil {
+			acc.Deviation.Add(deviationValue(fieldValue.Value))
+		}
+
+		// Value quantiles
+		if acc.ValueQuantiles != nil && fieldValue.Value != nil {
+			acc.ValueQuantiles.Add(helpers.ToDouble(fieldValue.Value))
+		}
+
+		// Unique values estimate
+		if acc.UniqueSketch != nil {
+			acc.UniqueSketch.Add(fieldValue.Value)
+		}
+
+		// String formats
+		if acc.StringFormats != nil && fieldValue.Value != nil {
+			countStringFormats(acc.StringFormats, helpers.SafeToString(fieldValue.Value))
+		}
+
+		// Value freq, exact count of unique values can be replaced by estimate
+		if acc.StoreValueDistribution && !dataProcesses.valueFreq.IsExceeded() {
 			dataProcesses.valueFreq.Input <- value{
 				Id:    id,
 				Value: fieldValue.Value,
@@ -88,6 +138,11 @@ func groupWorker(input <-chan expand.Value, results GroupResults, dataProcesses
 			acc.LengthSum += uint64(fieldValue.Length)
 		}
 
+		// Length quantiles
+		if acc.LengthQuantiles != nil {
+			acc.LengthQuantiles.Add(float64(fieldValue.Length))
+		}
+
 		// Length freq
 		if acc.StoreLengthDistribution {
 			dataProcesses.lengthFreq.Input <- length{
@@ -331,3 +386,18 @@ func storeMinMaxSum(acc *Accumulator, t string, value interface{}) {
 		acc.ValuesSum += valueDouble
 	}
 }
+
+// Value for standard deviation, dates are converted to milliseconds as in MongoDB date arithmetic.
+func deviationValue(value interface{}) float64 {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case time.Time:
+		return float64(v.UnixNano() / int64(time.Millisecond))
+	}
+
+	return helpers.ToDouble(value)
+}
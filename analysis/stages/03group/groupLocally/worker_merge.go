@@ -38,8 +38,45 @@ func runMergeWorker(groupProcess *groupProcess, groupOptions *group.Options, ana
 						t.ValueStats.Avg = avg
 					}
 				}
+
+				if acc.StoreSum {
+					if id.Type == "decimal" {
+						t.ValueStats.Sum = helpers.DoubleToDecimal(acc.ValuesSum)
+					} else {
+						t.ValueStats.Sum = acc.ValuesSum
+					}
+				}
+
+				// Variance is computed from standard deviation in the same way as in database
+				if acc.Deviation != nil && acc.Deviation.Count() > 0 {
+					stdDev := acc.Deviation.StdDev()
+					if acc.ConvertObjectIdToDate || id.Type == "date" {
+						stdDev /= 1000
+					}
+					t.ValueStats.StdDev = stdDev
+					t.ValueStats.Variance = stdDev * stdDev
+				}
+
+				if acc.ValueQuantiles != nil && acc.ValueQuantiles.Count() > 0 {
+					valueType := id.Type
+					if acc.ConvertObjectIdToDate {
+						valueType = "date"
+					}
+					t.ValueStats.Quantiles = quantiles(acc.ValueQuantiles, valueType, analysisOptions)
+				}
+			}
+
+			// Unique values estimate, replaced by exact count in stats worker if available
+			if acc.UniqueSketch != nil {
+				t.CountUnique = acc.UniqueSketch.Estimate()
+				t.CountUniqueError = acc.UniqueSketch.RelativeError()
 			}
 
+			t.StringFormats = acc.StringFormats
+			t.KeyShapes = acc.KeyShapes
+			t.Truncation = acc.Truncation
+			t.ArrayStats = acc.ArrayStats
+
 			// Length extremes
 			if acc.StoreMinMaxAvgLength {
 				t.LengthStats = &analysis.LengthStats{
@@ -47,11 +84,17 @@ func runMergeWorker(groupProcess *groupProcess, groupOptions *group.Options, ana
 					Max: acc.MaxLength,
 					Avg: float64(acc.LengthSum) / float64(acc.Count),
 				}
+
+				if acc.LengthQuantiles != nil {
+					t.LengthStats.Quantiles = quantiles(acc.LengthQuantiles, "double", analysisOptions)
+				}
 			}
 
 			ch <- group.Result{
-				Name: id.Name,
-				Type: t,
+				Name:         id.Name,
+				DocsCount:    acc.DocsCount,
+				ParentsCount: acc.ParentsCount,
+				Type:         t,
 			}
 		}
 
@@ -76,6 +119,8 @@ func mergeResults(partialResults []GroupResults, options *group.Options) GroupRe
 
 			// Count
 			final.Count += acc.Count
+			final.DocsCount += acc.DocsCount
+			final.ParentsCount += acc.ParentsCount
 
 			// Value extremes
 			if final.StoreMinMaxValue {
@@ -99,6 +144,49 @@ func mergeResults(partialResults []GroupResults, options *group.Options) GroupRe
 				if final.StoreAvgValue {
 					final.ValuesSum += acc.ValuesSum
 				}
+
+				if final.Deviation != nil {
+					final.Deviation.Merge(acc.Deviation)
+				}
+			}
+
+			// Quantiles
+			if final.ValueQuantiles != nil {
+				final.ValueQuantiles.Merge(acc.ValueQuantiles)
+			}
+			if final.LengthQuantiles != nil {
+				final.LengthQuantiles.Merge(acc.LengthQuantiles)
+			}
+
+			// Unique values estimate
+			if final.UniqueSketch != nil {
+				final.UniqueSketch.Merge(acc.UniqueSketch)
+			}
+
+			// Key shapes
+			if acc.KeyShapes != nil {
+				if final.KeyShapes == nil {
+					final.KeyShapes = &analysis.KeyShapes{}
+				}
+				mergeKeyShapes(final.KeyShapes, acc.KeyShapes)
+			}
+
+			// Truncation
+			if acc.Truncation != nil {
+				if final.Truncation == nil {
+					final.Truncation = &analysis.Truncation{}
+				}
+				mergeTruncation(final.Truncation, acc.Truncation)
+			}
+
+			// Arrays by their items
+			if final.ArrayStats != nil {
+				mergeArrayStats(final.ArrayStats, acc.ArrayStats)
+			}
+
+			// String formats
+			if final.StringFormats != nil {
+				mergeStringFormats(final.StringFormats, acc.StringFormats)
 			}
 
 			// Length extremes
@@ -112,3 +200,18 @@ func mergeResults(partialResults []GroupResults, options *group.Options) GroupRe
 
 	return finalResults
 }
+
+// Quantiles of dates and decimals have the same type as values, other quantiles are float64.
+func quantiles(digest *helpers.TDigest, t string, analysisOptions *analysis.Options) *analysis.Quantiles {
+	q := &analysis.Quantiles{}
+	for i, v := range q.Values() {
+		value := digest.Quantile(analysis.QuantileProbabilities[i])
+		switch t {
+		case "date", "decimal":
+			*v = helpers.FromDoubleTo(t, value, analysisOptions.Location)
+		default:
+			*v = value
+		}
+	}
+	return q
+}
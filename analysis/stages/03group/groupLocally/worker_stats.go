@@ -56,9 +56,10 @@ func processField(field *group.Result, dataProcesses *dataProcesses, groupOption
 	hourHistogram(field, freq, groupOptions, wg)
 	topLeastFrequent(field, t, freq, groupOptions, wg)
 
-	// Number of unique values
-	if groupOptions.StoreCountOfUnique {
+	// Number of unique values, estimate from merge worker is kept if the exact count is not available
+	if groupOptions.IsNecessaryToCalcExactUnique() && !dataProcesses.valueFreq.IsExceeded() {
 		field.Type.CountUnique = uint64(len(freq.Value))
+		field.Type.CountUniqueError = 0
 	}
 
 	// Wait for statistics to be calculated
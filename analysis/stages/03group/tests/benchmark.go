@@ -12,7 +12,7 @@ import (
 func RunBenchmarkStageMin(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -24,7 +24,7 @@ func RunBenchmarkStageMin(b *testing.B, stageFactory group.StageFactory) {
 func RunBenchmarkStageMinMaxAvgValue(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -37,7 +37,7 @@ func RunBenchmarkStageMinMaxAvgValue(b *testing.B, stageFactory group.StageFacto
 func RunBenchmarkStageMinMaxAvgLength(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -50,7 +50,7 @@ func RunBenchmarkStageMinMaxAvgLength(b *testing.B, stageFactory group.StageFact
 func RunBenchmarkStageCountOfUnique(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -63,7 +63,7 @@ func RunBenchmarkStageCountOfUnique(b *testing.B, stageFactory group.StageFactor
 func RunBenchmarkStageMostFrequent(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -76,7 +76,7 @@ func RunBenchmarkStageMostFrequent(b *testing.B, stageFactory group.StageFactory
 func RunBenchmarkStageLeastFrequent(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -89,7 +89,7 @@ func RunBenchmarkStageLeastFrequent(b *testing.B, stageFactory group.StageFactor
 func RunBenchmarkStageValuesHistogram(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -102,7 +102,7 @@ func RunBenchmarkStageValuesHistogram(b *testing.B, stageFactory group.StageFact
 func RunBenchmarkStageLengthsHistogram(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -115,7 +115,7 @@ func RunBenchmarkStageLengthsHistogram(b *testing.B, stageFactory group.StageFac
 func RunBenchmarkStageDateWeekdayHistogram(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -128,7 +128,7 @@ func RunBenchmarkStageDateWeekdayHistogram(b *testing.B, stageFactory group.Stag
 func RunBenchmarkStageDateHourHistogram(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -141,7 +141,7 @@ func RunBenchmarkStageDateHourHistogram(b *testing.B, stageFactory group.StageFa
 func RunBenchmarkStageObjectIdAsDate(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -154,7 +154,7 @@ func RunBenchmarkStageObjectIdAsDate(b *testing.B, stageFactory group.StageFacto
 func RunBenchmarkStageObjectIdAsDateHistograms(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
@@ -170,7 +170,7 @@ func RunBenchmarkStageObjectIdAsDateHistograms(b *testing.B, stageFactory group.
 func RunBenchmarkStageFull(b *testing.B, stageFactory group.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := group.Options{}
 	copier.Copy(&options, &testGroupOptions)
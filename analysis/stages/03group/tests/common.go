@@ -2,6 +2,7 @@
 package groupTests
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/analysis/stages/01sample"
 	"github.com/mongoeye/mongoeye/analysis/stages/01sample/sampleInDB"
@@ -11,7 +12,6 @@ import (
 	"github.com/mongoeye/mongoeye/analysis/stages/03group"
 	"github.com/mongoeye/mongoeye/tests"
 	"github.com/mongoeye/mongoeye/tests/analysis"
-	"gopkg.in/mgo.v2"
 	"runtime"
 	"testing"
 	"time"
@@ -48,7 +48,7 @@ func init() {
 	expandLocallyStage = expandLocally.NewStage(expandOptions)
 }
 
-func testStage(t *testing.T, c *mgo.Collection, location *time.Location, groupStage *analysis.Stage, expected []interface{}) []interface{} {
+func testStage(t *testing.T, c *tests.TestCollection, location *time.Location, groupStage *analysis.Stage, expected []interface{}) []interface{} {
 	numCpu := runtime.NumCPU()
 	runtime.GOMAXPROCS(numCpu)
 
@@ -59,20 +59,23 @@ func testStage(t *testing.T, c *mgo.Collection, location *time.Location, groupSt
 		expandStage = expandLocallyStage
 	}
 
-	out := analysisTests.RunStages(c, location, []*analysis.Stage{
+	out, err := analysisTests.RunStages(c, location, []*analysis.Stage{
 		sampleInDbStage,
 		expandStage,
 		groupStage,
 	})
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	results := group.ResultChannelToSlice(group.ToResultChannel(out, location, numCpu, 100))
+	results := group.ResultChannelToSlice(group.ToResultChannel(context.Background(), out, location, numCpu, 100))
 
 	tests.AssertEqualSet(t, expected, results)
 
 	return results
 }
 
-func benchmarkStage(b *testing.B, c *mgo.Collection, location *time.Location, groupStage *analysis.Stage, loadResults bool) {
+func benchmarkStage(b *testing.B, c *tests.TestCollection, location *time.Location, groupStage *analysis.Stage, loadResults bool) {
 	numCpu := runtime.NumCPU()
 	runtime.GOMAXPROCS(numCpu)
 
@@ -85,14 +88,17 @@ func benchmarkStage(b *testing.B, c *mgo.Collection, location *time.Location, gr
 
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		out := analysisTests.RunStages(c, location, []*analysis.Stage{
+		out, err := analysisTests.RunStages(c, location, []*analysis.Stage{
 			sampleInDbStage,
 			expandStage,
 			groupStage,
 		})
+		if err != nil {
+			b.Fatal(err)
+		}
 
 		if loadResults {
-			group.ResultChannelToSlice(group.ToResultChannel(out, location, numCpu, 100))
+			group.ResultChannelToSlice(group.ToResultChannel(context.Background(), out, location, numCpu, 100))
 			//b.Logf("Count: %d", len(s))
 		} else {
 			if ch, ok := out.(chan group.Result); ok {
@@ -108,11 +114,11 @@ func benchmarkStage(b *testing.B, c *mgo.Collection, location *time.Location, gr
 	}
 }
 
-func setup() *mgo.Collection {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+func setup() *tests.TestCollection {
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	return c
 }
 
-func tearDown(c *mgo.Collection) {
+func tearDown(c *tests.TestCollection) {
 	tests.DropTestCollection(c)
 }
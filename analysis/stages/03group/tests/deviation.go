@@ -0,0 +1,167 @@
+package groupTests
+
+import (
+	"github.com/jinzhu/copier"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/03group"
+	"gopkg.in/mgo.v2/bson"
+	"math"
+	"testing"
+	"time"
+)
+
+// RunTestValueSumAndDeviation tests group stage with StoreSumAndDeviation option.
+// Values are chosen so that all partial means are exact, so both plans produce the same numbers.
+func RunTestValueSumAndDeviation(t *testing.T, stageFactory group.StageFactory) {
+	c := setup()
+	defer tearDown(c)
+
+	c.Insert(bson.M{
+		"_id":     bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c0"),
+		"_double": 1.5,
+		"_int":    1,
+		"_long":   int64(1),
+		"_bool":   false,
+		"_date":   time.Unix(1000, 0),
+		"_string": "a",
+	})
+	c.Insert(bson.M{
+		"_id":     bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1"),
+		"_double": 5.5,
+		"_int":    5,
+		"_long":   int64(5),
+		"_bool":   true,
+		"_date":   time.Unix(5000, 0),
+		"_string": "c",
+	})
+	c.Insert(bson.M{
+		"_id":     bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+		"_double": 3.5,
+		"_int":    3,
+		"_long":   int64(3),
+		"_date":   time.Unix(3000, 0),
+		"_string": "b",
+	})
+
+	options := group.Options{}
+	copier.Copy(&options, &testGroupOptions)
+	options.StoreMinMaxAvgValue = true
+	options.StoreSumAndDeviation = true
+
+	stdDev := math.Sqrt(8.0 / 3)
+	dateStdDev := math.Sqrt(8e12/3) / 1000
+
+	expected := []interface{}{
+		group.Result{
+			Name:         "_id",
+			DocsCount:    3,
+			ParentsCount: 3,
+			Type: analysis.Type{
+				Name:  "objectId",
+				Count: 3,
+				ValueStats: &analysis.ValueStats{
+					Min: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c0"),
+					Max: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2"),
+				},
+			},
+		},
+		group.Result{
+			Name:         "_double",
+			DocsCount:    3,
+			ParentsCount: 3,
+			Type: analysis.Type{
+				Name:  "double",
+				Count: 3,
+				ValueStats: &analysis.ValueStats{
+					Min:      1.5,
+					Max:      5.5,
+					Avg:      3.5,
+					Sum:      10.5,
+					StdDev:   stdDev,
+					Variance: stdDev * stdDev,
+				},
+			},
+		},
+		group.Result{
+			Name:         "_int",
+			DocsCount:    3,
+			ParentsCount: 3,
+			Type: analysis.Type{
+				Name:  "int",
+				Count: 3,
+				ValueStats: &analysis.ValueStats{
+					Min:      1,
+					Max:      5,
+					Avg:      float64(3),
+					Sum:      float64(9),
+					StdDev:   stdDev,
+					Variance: stdDev * stdDev,
+				},
+			},
+		},
+		group.Result{
+			Name:         "_long",
+			DocsCount:    3,
+			ParentsCount: 3,
+			Type: analysis.Type{
+				Name:  "long",
+				Count: 3,
+				ValueStats: &analysis.ValueStats{
+					Min:      int64(1),
+					Max:      int64(5),
+					Avg:      float64(3),
+					Sum:      float64(9),
+					StdDev:   stdDev,
+					Variance: stdDev * stdDev,
+				},
+			},
+		},
+		group.Result{
+			Name:         "_bool",
+			DocsCount:    2,
+			ParentsCount: 2,
+			Type: analysis.Type{
+				Name:  "bool",
+				Count: 2,
+				ValueStats: &analysis.ValueStats{
+					Min:      false,
+					Max:      true,
+					Avg:      0.5,
+					Sum:      float64(1),
+					StdDev:   0.5,
+					Variance: 0.25,
+				},
+			},
+		},
+		group.Result{
+			Name:         "_date",
+			DocsCount:    3,
+			ParentsCount: 3,
+			Type: analysis.Type{
+				Name:  "date",
+				Count: 3,
+				ValueStats: &analysis.ValueStats{
+					Min:      time.Unix(1000, 0),
+					Max:      time.Unix(5000, 0),
+					StdDev:   dateStdDev,
+					Variance: dateStdDev * dateStdDev,
+				},
+			},
+		},
+		group.Result{
+			Name:         "_string",
+			DocsCount:    3,
+			ParentsCount: 3,
+			Type: analysis.Type{
+				Name:  "string",
+				Count: 3,
+				ValueStats: &analysis.ValueStats{
+					Min: "a",
+					Max: "c",
+				},
+			},
+		},
+	}
+
+	testStage(t, c, time.UTC, stageFactory(&options), expected)
+}
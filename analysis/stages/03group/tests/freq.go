@@ -130,14 +130,18 @@ func RunTestValueHistogram(t *testing.T, stageFactory group.StageFactory) {
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_double",
+			Name:         "_double",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "double",
 				Count: 3,
@@ -161,35 +165,45 @@ func RunTestValueHistogram(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_string",
+			Name:         "_string",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_binData",
+			Name:         "_binData",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "binData",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_undefined",
+			Name:         "_undefined",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "undefined",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_bool",
+			Name:         "_bool",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "bool",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "date",
 				Count: 3,
@@ -217,49 +231,63 @@ func RunTestValueHistogram(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_null",
+			Name:         "_null",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "null",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_regex",
+			Name:         "_regex",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "regex",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_dbPointer",
+			Name:         "_dbPointer",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "dbPointer",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_javascript",
+			Name:         "_javascript",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "javascript",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_symbol",
+			Name:         "_symbol",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "symbol",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_javascriptWithScope",
+			Name:         "_javascriptWithScope",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "javascriptWithScope",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_int",
+			Name:         "_int",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 3,
@@ -283,14 +311,18 @@ func RunTestValueHistogram(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_timestamp",
+			Name:         "_timestamp",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "timestamp",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_long",
+			Name:         "_long",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "long",
 				Count: 3,
@@ -314,7 +346,9 @@ func RunTestValueHistogram(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_decimal",
+			Name:         "_decimal",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:           decimalType,
 				Count:          3,
@@ -322,63 +356,81 @@ func RunTestValueHistogram(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_minKey",
+			Name:         "_minKey",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "minKey",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_maxKey",
+			Name:         "_maxKey",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "maxKey",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_object",
+			Name:         "_object",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "object",
 				Count: 2,
 			},
 		},
 		group.Result{
-			Name: "_object.f1",
+			Name:         "_object.f1",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "_object.f1",
+			Name:         "_object.f1",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "_object.f2",
+			Name:         "_object.f2",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "_object.f2",
+			Name:         "_object.f2",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "_array",
+			Name:         "_array",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "array",
 				Count: 2,
 			},
 		},
 		group.Result{
-			Name: "_array.[]",
+			Name:         "_array.[]",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 4,
@@ -450,14 +502,18 @@ func RunTestValueHistogramMaxRes(t *testing.T, stageFactory group.StageFactory)
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    6,
+			ParentsCount: 6,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 6,
 			},
 		},
 		group.Result{
-			Name: "_int",
+			Name:         "_int",
+			DocsCount:    6,
+			ParentsCount: 6,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 6,
@@ -584,21 +640,27 @@ func RunTestLengthHistogram(t *testing.T, stageFactory group.StageFactory) {
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_double",
+			Name:         "_double",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "double",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_string",
+			Name:         "_string",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 3,
@@ -622,154 +684,198 @@ func RunTestLengthHistogram(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_binData",
+			Name:         "_binData",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "binData",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_undefined",
+			Name:         "_undefined",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "undefined",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_bool",
+			Name:         "_bool",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "bool",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "date",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_null",
+			Name:         "_null",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "null",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_regex",
+			Name:         "_regex",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "regex",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_dbPointer",
+			Name:         "_dbPointer",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "dbPointer",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_javascript",
+			Name:         "_javascript",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "javascript",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_symbol",
+			Name:         "_symbol",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "symbol",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_javascriptWithScope",
+			Name:         "_javascriptWithScope",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "javascriptWithScope",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_int",
+			Name:         "_int",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_timestamp",
+			Name:         "_timestamp",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "timestamp",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_long",
+			Name:         "_long",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "long",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_decimal",
+			Name:         "_decimal",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  decimalType,
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_minKey",
+			Name:         "_minKey",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "minKey",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_maxKey",
+			Name:         "_maxKey",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "maxKey",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_object",
+			Name:         "_object",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "object",
 				Count: 2,
 			},
 		},
 		group.Result{
-			Name: "_object.f1",
+			Name:         "_object.f1",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "_object.f1",
+			Name:         "_object.f1",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "_object.f2",
+			Name:         "_object.f2",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "_object.f2",
+			Name:         "_object.f2",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "_array",
+			Name:         "_array",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "array",
 				Count: 2,
@@ -793,7 +899,9 @@ func RunTestLengthHistogram(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_array.[]",
+			Name:         "_array.[]",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 4,
@@ -840,14 +948,18 @@ func RunTestLengthHistogramMaxRes(t *testing.T, stageFactory group.StageFactory)
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    6,
+			ParentsCount: 6,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 6,
 			},
 		},
 		group.Result{
-			Name: "_str",
+			Name:         "_str",
+			DocsCount:    6,
+			ParentsCount: 6,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 6,
@@ -921,14 +1033,18 @@ func RunTestWeekdayHistogram(t *testing.T, stageFactory group.StageFactory) {
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    8,
+			ParentsCount: 8,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 8,
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    8,
+			ParentsCount: 8,
 			Type: analysis.Type{
 				Name:  "date",
 				Count: 8,
@@ -992,14 +1108,18 @@ func RunTestHourHistogram(t *testing.T, stageFactory group.StageFactory) {
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    8,
+			ParentsCount: 8,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 8,
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    8,
+			ParentsCount: 8,
 			Type: analysis.Type{
 				Name:  "date",
 				Count: 8,
@@ -1065,7 +1185,9 @@ func RunTestDateStatsTimezone(t *testing.T, stageFactory group.StageFactory) {
 	// UTC timezone
 	expectedUTC := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 3,
@@ -1076,7 +1198,9 @@ func RunTestDateStatsTimezone(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "date",
 				Count: 3,
@@ -1156,7 +1280,9 @@ func RunTestDateStatsTimezone(t *testing.T, stageFactory group.StageFactory) {
 
 	expectedNY := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 3,
@@ -1167,7 +1293,9 @@ func RunTestDateStatsTimezone(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "date",
 				Count: 3,
@@ -0,0 +1,60 @@
+package groupTests
+
+import (
+	"github.com/jinzhu/copier"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/03group"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+// RunTestEscapedKeys tests group stage with keys containing dots, dollars or empty keys.
+func RunTestEscapedKeys(t *testing.T, stageFactory group.StageFactory) {
+	c := setup()
+	defer tearDown(c)
+
+	err := c.Insert(bson.M{
+		"_id": bson.NewObjectId(),
+		"a.b": bson.M{"c": 1},
+		"a":   bson.M{"b": 1},
+		"":    "x",
+		"obj": bson.M{"$x": 1},
+	})
+	if err != nil {
+		t.Skipf("MongoDB does not support the keys: %s", err)
+	}
+	c.Insert(bson.M{
+		"_id": bson.NewObjectId(),
+		"a.b": bson.M{"c": 2},
+		"":    "y",
+	})
+
+	options := group.Options{}
+	copier.Copy(&options, &testGroupOptions)
+
+	result := func(name string, docs uint64, typeName string) group.Result {
+		return group.Result{
+			Name:         name,
+			DocsCount:    docs,
+			ParentsCount: docs,
+			Type: analysis.Type{
+				Name:  typeName,
+				Count: docs,
+			},
+		}
+	}
+
+	expected := []interface{}{
+		result("_id", 2, "objectId"),
+		result(`"a.b"`, 2, "object"),
+		result(`"a.b".c`, 2, "int"),
+		result("a", 1, "object"),
+		result("a.b", 1, "int"),
+		result(`""`, 2, "string"),
+		result("obj", 1, "object"),
+		result("obj.$x", 1, "int"),
+	}
+
+	testStage(t, c, time.UTC, stageFactory(&options), expected)
+}
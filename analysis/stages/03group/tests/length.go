@@ -33,14 +33,18 @@ func RunTestStringLengthMinMaxAvg(t *testing.T, stageFactory group.StageFactory)
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "field",
+			Name:         "field",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 3,
@@ -92,14 +96,18 @@ func RunTestArrayLengthMinMaxAvg(t *testing.T, stageFactory group.StageFactory)
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    4,
+			ParentsCount: 4,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 4,
 			},
 		},
 		group.Result{
-			Name: "field",
+			Name:         "field",
+			DocsCount:    4,
+			ParentsCount: 4,
 			Type: analysis.Type{
 				Name:  "array",
 				Count: 4,
@@ -111,14 +119,18 @@ func RunTestArrayLengthMinMaxAvg(t *testing.T, stageFactory group.StageFactory)
 			},
 		},
 		group.Result{
-			Name: "field.[]",
+			Name:         "field.[]",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 4,
 			},
 		},
 		group.Result{
-			Name: "field.[]",
+			Name:         "field.[]",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 2,
@@ -168,14 +180,18 @@ func RunTestObjectLengthMinMaxAvg(t *testing.T, stageFactory group.StageFactory)
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    4,
+			ParentsCount: 4,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 4,
 			},
 		},
 		group.Result{
-			Name: "field",
+			Name:         "field",
+			DocsCount:    4,
+			ParentsCount: 4,
 			Type: analysis.Type{
 				Name:  "object",
 				Count: 4,
@@ -187,14 +203,18 @@ func RunTestObjectLengthMinMaxAvg(t *testing.T, stageFactory group.StageFactory)
 			},
 		},
 		group.Result{
-			Name: "field.f1",
+			Name:         "field.f1",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 2,
 			},
 		},
 		group.Result{
-			Name: "field.f2",
+			Name:         "field.f2",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
@@ -206,21 +226,27 @@ func RunTestObjectLengthMinMaxAvg(t *testing.T, stageFactory group.StageFactory)
 			},
 		},
 		group.Result{
-			Name: "field.f3",
+			Name:         "field.f3",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "field.f4",
+			Name:         "field.f4",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "field.f5",
+			Name:         "field.f5",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
@@ -31,35 +31,45 @@ func RunTestMinimal(t *testing.T, stageFactory group.StageFactory) {
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 2,
 			},
 		},
 		group.Result{
-			Name: "f1",
+			Name:         "f1",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 2,
 			},
 		},
 		group.Result{
-			Name: "f2",
+			Name:         "f2",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "f2",
+			Name:         "f2",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "f3",
+			Name:         "f3",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
@@ -97,28 +107,36 @@ func RunTestDifferentTypesInField(t *testing.T, stageFactory group.StageFactory)
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    4,
+			ParentsCount: 4,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 4,
 			},
 		},
 		group.Result{
-			Name: "field",
+			Name:         "field",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "double",
 				Count: 2,
 			},
 		},
 		group.Result{
-			Name: "field",
+			Name:         "field",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "field",
+			Name:         "field",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
@@ -0,0 +1,107 @@
+package groupTests
+
+import (
+	"github.com/jinzhu/copier"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/03group"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+// RunTestQuantiles tests group stage with StoreQuantiles option.
+func RunTestQuantiles(t *testing.T, stageFactory group.StageFactory) {
+	c := setup()
+	defer tearDown(c)
+
+	ids := []string{
+		"58e20d849d3ae7e1f8eac9c0",
+		"58e20d849d3ae7e1f8eac9c1",
+		"58e20d849d3ae7e1f8eac9c2",
+		"58e20d849d3ae7e1f8eac9c3",
+		"58e20d849d3ae7e1f8eac9c4",
+	}
+	strings := []string{"abcdef", "", "abcdefgh", "ab", "abcd"}
+	ints := []int{4, 2, 5, 1, 3}
+	for i := range ids {
+		c.Insert(bson.M{
+			"_id": bson.ObjectIdHex(ids[i]),
+			"int": ints[i],
+			"str": strings[i],
+		})
+	}
+
+	options := group.Options{}
+	copier.Copy(&options, &testGroupOptions)
+	options.StoreMinMaxAvgValue = true
+	options.StoreMinMaxAvgLength = true
+	options.StoreQuantiles = true
+
+	expected := []interface{}{
+		group.Result{
+			Name:         "_id",
+			DocsCount:    5,
+			ParentsCount: 5,
+			Type: analysis.Type{
+				Name:  "objectId",
+				Count: 5,
+				ValueStats: &analysis.ValueStats{
+					Min: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c0"),
+					Max: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c4"),
+				},
+			},
+		},
+		group.Result{
+			Name:         "int",
+			DocsCount:    5,
+			ParentsCount: 5,
+			Type: analysis.Type{
+				Name:  "int",
+				Count: 5,
+				ValueStats: &analysis.ValueStats{
+					Min: 1,
+					Max: 5,
+					Avg: float64(3),
+					Quantiles: &analysis.Quantiles{
+						P5:     1.2,
+						P25:    2.0,
+						Median: 3.0,
+						P75:    4.0,
+						P90:    4.6,
+						P95:    4.8,
+						P99:    4.96,
+					},
+				},
+			},
+		},
+		group.Result{
+			Name:         "str",
+			DocsCount:    5,
+			ParentsCount: 5,
+			Type: analysis.Type{
+				Name:  "string",
+				Count: 5,
+				ValueStats: &analysis.ValueStats{
+					Min: "",
+					Max: "abcdefgh",
+				},
+				LengthStats: &analysis.LengthStats{
+					Min: 0,
+					Max: 8,
+					Avg: 4,
+					Quantiles: &analysis.Quantiles{
+						P5:     0.4,
+						P25:    2.0,
+						Median: 4.0,
+						P75:    6.0,
+						P90:    7.2,
+						P95:    7.6,
+						P99:    7.92,
+					},
+				},
+			},
+		},
+	}
+
+	testStage(t, c, time.UTC, stageFactory(&options), expected)
+}
@@ -0,0 +1,68 @@
+package groupTests
+
+import (
+	"github.com/jinzhu/copier"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/03group"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+// RunTestStringFormats tests group stage with StoreStringFormats option.
+func RunTestStringFormats(t *testing.T, stageFactory group.StageFactory) {
+	c := setup()
+	defer tearDown(c)
+
+	values := []interface{}{"", " ", "123", "1.5", "2017-04-01T12:30:00Z", "john@example.com", "192.168.0.1", "abc", 123}
+	for _, v := range values {
+		c.Insert(bson.M{
+			"_id":   bson.NewObjectId(),
+			"value": v,
+		})
+	}
+
+	options := group.Options{}
+	copier.Copy(&options, &testGroupOptions)
+	options.StoreStringFormats = true
+
+	expected := []interface{}{
+		group.Result{
+			Name:         "_id",
+			DocsCount:    9,
+			ParentsCount: 9,
+			Type: analysis.Type{
+				Name:  "objectId",
+				Count: 9,
+			},
+		},
+		group.Result{
+			Name:         "value",
+			DocsCount:    8,
+			ParentsCount: 8,
+			Type: analysis.Type{
+				Name:  "string",
+				Count: 8,
+				StringFormats: &analysis.StringFormats{
+					Empty:   2,
+					Integer: 1,
+					Decimal: 2,
+					Date:    1,
+					Email:   1,
+					IP:      1,
+				},
+			},
+		},
+		group.Result{
+			Name:         "value",
+			DocsCount:    1,
+			ParentsCount: 1,
+			Type: analysis.Type{
+				Name:  "int",
+				Count: 1,
+			},
+		},
+	}
+
+	testStage(t, c, time.UTC, stageFactory(&options), expected)
+}
@@ -0,0 +1,89 @@
+package groupTests
+
+import (
+	"github.com/jinzhu/copier"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/03group"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+// RunTestTruncation tests group stage with objects and arrays beyond the limits of expand stage.
+func RunTestTruncation(t *testing.T, stageFactory group.StageFactory) {
+	c := setup()
+	defer tearDown(c)
+
+	c.Insert(bson.M{
+		"_id":  bson.NewObjectId(),
+		"tags": []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+		"a":    bson.M{"b": bson.M{"c": bson.M{"d": bson.M{"e": bson.M{"f": bson.M{"g": 1}}}}}},
+	})
+	c.Insert(bson.M{
+		"_id":  bson.NewObjectId(),
+		"tags": []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	})
+	c.Insert(bson.M{
+		"_id":  bson.NewObjectId(),
+		"tags": []int{1, 2},
+	})
+
+	options := group.Options{}
+	copier.Copy(&options, &testGroupOptions)
+
+	object := func(name string, truncation *analysis.Truncation) group.Result {
+		return group.Result{
+			Name:         name,
+			DocsCount:    1,
+			ParentsCount: 1,
+			Type: analysis.Type{
+				Name:       "object",
+				Count:      1,
+				Truncation: truncation,
+			},
+		}
+	}
+
+	expected := []interface{}{
+		group.Result{
+			Name:         "_id",
+			DocsCount:    3,
+			ParentsCount: 3,
+			Type: analysis.Type{
+				Name:  "objectId",
+				Count: 3,
+			},
+		},
+		group.Result{
+			Name:         "tags",
+			DocsCount:    3,
+			ParentsCount: 3,
+			Type: analysis.Type{
+				Name:  "array",
+				Count: 3,
+				Truncation: &analysis.Truncation{
+					Arrays:   2,
+					Items:    7,
+					MaxItems: 5,
+				},
+			},
+		},
+		group.Result{
+			Name:         "tags.[]",
+			DocsCount:    3,
+			ParentsCount: 3,
+			Type: analysis.Type{
+				Name:  "int",
+				Count: 22,
+			},
+		},
+		object("a", nil),
+		object("a.b", nil),
+		object("a.b.c", nil),
+		object("a.b.c.d", nil),
+		object("a.b.c.d.e", nil),
+		object("a.b.c.d.e.f", &analysis.Truncation{Depth: 1}),
+	}
+
+	testStage(t, c, time.UTC, stageFactory(&options), expected)
+}
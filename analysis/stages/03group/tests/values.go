@@ -110,7 +110,9 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 3,
@@ -121,7 +123,9 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_double",
+			Name:         "_double",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "double",
 				Count: 3,
@@ -133,7 +137,9 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_string",
+			Name:         "_string",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 3,
@@ -145,21 +151,27 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_binData",
+			Name:         "_binData",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "binData",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_undefined",
+			Name:         "_undefined",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "undefined",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_bool",
+			Name:         "_bool",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "bool",
 				Count: 3,
@@ -171,7 +183,9 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "date",
 				Count: 3,
@@ -182,49 +196,63 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_null",
+			Name:         "_null",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "null",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_regex",
+			Name:         "_regex",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "regex",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_dbPointer",
+			Name:         "_dbPointer",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "dbPointer",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_javascript",
+			Name:         "_javascript",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "javascript",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_symbol",
+			Name:         "_symbol",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "symbol",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_javascriptWithScope",
+			Name:         "_javascriptWithScope",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "javascriptWithScope",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_int",
+			Name:         "_int",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 3,
@@ -236,7 +264,9 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_timestamp",
+			Name:         "_timestamp",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "timestamp",
 				Count: 3,
@@ -247,7 +277,9 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_long",
+			Name:         "_long",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "long",
 				Count: 3,
@@ -259,7 +291,9 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_decimal",
+			Name:         "_decimal",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  decimalType,
 				Count: 3,
@@ -271,28 +305,36 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_minKey",
+			Name:         "_minKey",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "minKey",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_maxKey",
+			Name:         "_maxKey",
+			DocsCount:    3,
+			ParentsCount: 3,
 			Type: analysis.Type{
 				Name:  "maxKey",
 				Count: 3,
 			},
 		},
 		group.Result{
-			Name: "_object",
+			Name:         "_object",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "object",
 				Count: 2,
 			},
 		},
 		group.Result{
-			Name: "_object.f1",
+			Name:         "_object.f1",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
@@ -304,7 +346,9 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_object.f1",
+			Name:         "_object.f1",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
@@ -315,7 +359,9 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_object.f2",
+			Name:         "_object.f2",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
@@ -326,7 +372,9 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_object.f2",
+			Name:         "_object.f2",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
@@ -338,14 +386,18 @@ func RunTestValueMinMaxAvg(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_array",
+			Name:         "_array",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "array",
 				Count: 2,
 			},
 		},
 		group.Result{
-			Name: "_array.[]",
+			Name:         "_array.[]",
+			DocsCount:    2,
+			ParentsCount: 2,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 4,
@@ -457,14 +509,18 @@ func RunTestValueTopValues(t *testing.T, stageFactory group.StageFactory) {
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 7,
 			},
 		},
 		group.Result{
-			Name: "_double",
+			Name:         "_double",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "double",
 				Count: 7,
@@ -481,7 +537,9 @@ func RunTestValueTopValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_string",
+			Name:         "_string",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 7,
@@ -498,7 +556,9 @@ func RunTestValueTopValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "date",
 				Count: 7,
@@ -515,7 +575,9 @@ func RunTestValueTopValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_int",
+			Name:         "_int",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 7,
@@ -532,7 +594,9 @@ func RunTestValueTopValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_timestamp",
+			Name:         "_timestamp",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "timestamp",
 				Count: 7,
@@ -549,7 +613,9 @@ func RunTestValueTopValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_long",
+			Name:         "_long",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "long",
 				Count: 7,
@@ -566,7 +632,9 @@ func RunTestValueTopValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_decimal",
+			Name:         "_decimal",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  decimalType,
 				Count: 7,
@@ -617,14 +685,18 @@ func RunTestValueTopValuesNGreaterThanNumberOfValues(t *testing.T, stageFactory
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "_double",
+			Name:         "_double",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "double",
 				Count: 1,
@@ -637,7 +709,9 @@ func RunTestValueTopValuesNGreaterThanNumberOfValues(t *testing.T, stageFactory
 			},
 		},
 		group.Result{
-			Name: "_string",
+			Name:         "_string",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
@@ -650,7 +724,9 @@ func RunTestValueTopValuesNGreaterThanNumberOfValues(t *testing.T, stageFactory
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "date",
 				Count: 1,
@@ -663,7 +739,9 @@ func RunTestValueTopValuesNGreaterThanNumberOfValues(t *testing.T, stageFactory
 			},
 		},
 		group.Result{
-			Name: "_int",
+			Name:         "_int",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
@@ -676,7 +754,9 @@ func RunTestValueTopValuesNGreaterThanNumberOfValues(t *testing.T, stageFactory
 			},
 		},
 		group.Result{
-			Name: "_timestamp",
+			Name:         "_timestamp",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "timestamp",
 				Count: 1,
@@ -689,7 +769,9 @@ func RunTestValueTopValuesNGreaterThanNumberOfValues(t *testing.T, stageFactory
 			},
 		},
 		group.Result{
-			Name: "_long",
+			Name:         "_long",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "long",
 				Count: 1,
@@ -702,7 +784,9 @@ func RunTestValueTopValuesNGreaterThanNumberOfValues(t *testing.T, stageFactory
 			},
 		},
 		group.Result{
-			Name: "_decimal",
+			Name:         "_decimal",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  decimalType,
 				Count: 1,
@@ -815,14 +899,18 @@ func RunTestValueBottomValues(t *testing.T, stageFactory group.StageFactory) {
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 7,
 			},
 		},
 		group.Result{
-			Name: "_double",
+			Name:         "_double",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "double",
 				Count: 7,
@@ -839,7 +927,9 @@ func RunTestValueBottomValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_string",
+			Name:         "_string",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 7,
@@ -856,7 +946,9 @@ func RunTestValueBottomValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "date",
 				Count: 7,
@@ -873,7 +965,9 @@ func RunTestValueBottomValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_int",
+			Name:         "_int",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 7,
@@ -890,7 +984,9 @@ func RunTestValueBottomValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_timestamp",
+			Name:         "_timestamp",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "timestamp",
 				Count: 7,
@@ -907,7 +1003,9 @@ func RunTestValueBottomValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_long",
+			Name:         "_long",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "long",
 				Count: 7,
@@ -924,7 +1022,9 @@ func RunTestValueBottomValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_decimal",
+			Name:         "_decimal",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  decimalType,
 				Count: 7,
@@ -975,14 +1075,18 @@ func RunTestValueBottomValuesNGreaterThanNumberOfValues(t *testing.T, stageFacto
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 1,
 			},
 		},
 		group.Result{
-			Name: "_double",
+			Name:         "_double",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "double",
 				Count: 1,
@@ -995,7 +1099,9 @@ func RunTestValueBottomValuesNGreaterThanNumberOfValues(t *testing.T, stageFacto
 			},
 		},
 		group.Result{
-			Name: "_string",
+			Name:         "_string",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "string",
 				Count: 1,
@@ -1008,7 +1114,9 @@ func RunTestValueBottomValuesNGreaterThanNumberOfValues(t *testing.T, stageFacto
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "date",
 				Count: 1,
@@ -1021,7 +1129,9 @@ func RunTestValueBottomValuesNGreaterThanNumberOfValues(t *testing.T, stageFacto
 			},
 		},
 		group.Result{
-			Name: "_int",
+			Name:         "_int",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "int",
 				Count: 1,
@@ -1034,7 +1144,9 @@ func RunTestValueBottomValuesNGreaterThanNumberOfValues(t *testing.T, stageFacto
 			},
 		},
 		group.Result{
-			Name: "_timestamp",
+			Name:         "_timestamp",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "timestamp",
 				Count: 1,
@@ -1047,7 +1159,9 @@ func RunTestValueBottomValuesNGreaterThanNumberOfValues(t *testing.T, stageFacto
 			},
 		},
 		group.Result{
-			Name: "_long",
+			Name:         "_long",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  "long",
 				Count: 1,
@@ -1060,7 +1174,9 @@ func RunTestValueBottomValuesNGreaterThanNumberOfValues(t *testing.T, stageFacto
 			},
 		},
 		group.Result{
-			Name: "_decimal",
+			Name:         "_decimal",
+			DocsCount:    1,
+			ParentsCount: 1,
 			Type: analysis.Type{
 				Name:  decimalType,
 				Count: 1,
@@ -1173,14 +1289,18 @@ func RunTestCountOfUniqueValues(t *testing.T, stageFactory group.StageFactory) {
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 7,
 			},
 		},
 		group.Result{
-			Name: "_double",
+			Name:         "_double",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:        "double",
 				Count:       7,
@@ -1188,7 +1308,9 @@ func RunTestCountOfUniqueValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_string",
+			Name:         "_string",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:        "string",
 				Count:       7,
@@ -1196,7 +1318,9 @@ func RunTestCountOfUniqueValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_date",
+			Name:         "_date",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:        "date",
 				Count:       7,
@@ -1204,7 +1328,9 @@ func RunTestCountOfUniqueValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_int",
+			Name:         "_int",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:        "int",
 				Count:       7,
@@ -1212,7 +1338,9 @@ func RunTestCountOfUniqueValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_timestamp",
+			Name:         "_timestamp",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:        "timestamp",
 				Count:       7,
@@ -1220,7 +1348,9 @@ func RunTestCountOfUniqueValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_long",
+			Name:         "_long",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:        "long",
 				Count:       7,
@@ -1228,7 +1358,9 @@ func RunTestCountOfUniqueValues(t *testing.T, stageFactory group.StageFactory) {
 			},
 		},
 		group.Result{
-			Name: "_decimal",
+			Name:         "_decimal",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:        decimalType,
 				Count:       7,
@@ -1279,7 +1411,9 @@ func RunTestObjectIdAsDate(t *testing.T, stageFactory group.StageFactory) {
 
 	expected := []interface{}{
 		group.Result{
-			Name: "_id",
+			Name:         "_id",
+			DocsCount:    7,
+			ParentsCount: 7,
 			Type: analysis.Type{
 				Name:  "objectId",
 				Count: 7,
@@ -7,9 +7,11 @@ import (
 
 // Abbreviations for aggregation pipeline.f
 var (
-	BsonFieldName string
-	BsonCount     string
-	BsonTypes     string
+	BsonFieldName    string
+	BsonCount        string
+	BsonDocsCount    string
+	BsonParentsCount string
+	BsonTypes        string
 )
 
 func init() {
@@ -17,5 +19,7 @@ func init() {
 
 	BsonFieldName = helpers.GetBSONFieldName(r, "Name")
 	BsonCount = helpers.GetBSONFieldName(r, "Count")
+	BsonDocsCount = helpers.GetBSONFieldName(r, "DocsCount")
+	BsonParentsCount = helpers.GetBSONFieldName(r, "ParentsCount")
 	BsonTypes = helpers.GetBSONFieldName(r, "Types")
 }
@@ -1,19 +1,21 @@
 package merge
 
 import (
+	"context"
+	"fmt"
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/analysis/stages/03group"
 	"gopkg.in/mgo.v2/bson"
 	"reflect"
 	"sort"
-	"strings"
 	"sync"
 	"time"
 )
 
 // ToFieldChannel converts input channel to Field channel.
 // The input may by raw []byte channel or Field channel (no conversion).
-func ToFieldChannel(inCh interface{}, location *time.Location, concurrency int, bufferSize int) <-chan analysis.Field {
+// Invalid results from the database stop the analysis, see analysis.Fail.
+func ToFieldChannel(ctx context.Context, inCh interface{}, location *time.Location, concurrency int, bufferSize int) <-chan analysis.Field {
 	if ch, ok := inCh.(chan analysis.Field); ok {
 		// local results
 		return ch
@@ -26,7 +28,7 @@ func ToFieldChannel(inCh interface{}, location *time.Location, concurrency int,
 
 		// Run workers
 		for i := 0; i < concurrency; i++ {
-			go convertWorker(inCh, outCh, location, wg)
+			go convertWorker(ctx, inCh, outCh, location, wg)
 		}
 
 		// Wait for workers
@@ -41,14 +43,17 @@ func ToFieldChannel(inCh interface{}, location *time.Location, concurrency int,
 	panic("Invalid input. Expected 'chan expand.Value' or 'chan []byte'. Given: " + reflect.TypeOf(inCh).String())
 }
 
-func convertWorker(inCh <-chan []byte, outCh chan<- analysis.Field, location *time.Location, wg *sync.WaitGroup) {
+func convertWorker(ctx context.Context, inCh <-chan []byte, outCh chan<- analysis.Field, location *time.Location, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for bin := range inCh {
 		f := analysis.Field{}
-		bson.Unmarshal(bin, &f)
+		if err := bson.Unmarshal(bin, &f); err != nil {
+			analysis.Fail(ctx, fmt.Errorf("Invalid result of merge stage: %s.", err))
+			continue
+		}
 
-		f.Level = uint(strings.Count(f.Name, analysis.NameSeparator))
+		f.Level = analysis.NameLevel(f.Name)
 
 		for _, t := range f.Types {
 			group.NormalizeType(t, location)
@@ -1,6 +1,7 @@
 package merge
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/mgo.v2/bson"
@@ -10,7 +11,7 @@ import (
 
 func TestToFieldChannel(t *testing.T) {
 	rawCh := make(chan []byte, 50)
-	grCh := ToFieldChannel(rawCh, time.UTC, 1, 50)
+	grCh := ToFieldChannel(context.Background(), rawCh, time.UTC, 1, 50)
 
 	bsonData := bson.M{
 		BsonFieldName: "name",
@@ -49,7 +50,7 @@ func TestToFieldChannel(t *testing.T) {
 
 func TestToFieldChannel_Full(t *testing.T) {
 	rawCh := make(chan []byte, 50)
-	grCh := ToFieldChannel(rawCh, time.UTC, 1, 50)
+	grCh := ToFieldChannel(context.Background(), rawCh, time.UTC, 1, 50)
 
 	bsonData := bson.M{
 		BsonFieldName: "name",
@@ -250,14 +251,14 @@ func TestToFieldChannel_Full(t *testing.T) {
 
 func TestToFieldChannel_FieldChannel(t *testing.T) {
 	ch := make(chan analysis.Field, 50)
-	outCh := ToFieldChannel(ch, time.UTC, 1, 50)
+	outCh := ToFieldChannel(context.Background(), ch, time.UTC, 1, 50)
 	assert.Equal(t, (<-chan analysis.Field)(ch), outCh)
 }
 
 func TestToFieldChannel_InvalidType(t *testing.T) {
 	ch := make(chan int, 50)
 	assert.Panics(t, func() {
-		ToFieldChannel(ch, time.UTC, 1, 50)
+		ToFieldChannel(context.Background(), ch, time.UTC, 1, 50)
 	})
 }
 
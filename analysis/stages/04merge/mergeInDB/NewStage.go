@@ -12,7 +12,7 @@ import (
 // NewStage - MergeInDB stage factory
 func NewStage(options *merge.Options) *analysis.Stage {
 	return &analysis.Stage{
-		PipelineFactory: func(analysisOptions *analysis.Options) *expr.Pipeline {
+		PipelineFactory: func(analysisOptions *analysis.Options) (*expr.Pipeline, error) {
 			// Create pipeline
 			p := expr.NewPipeline()
 
@@ -24,24 +24,28 @@ func NewStage(options *merge.Options) *analysis.Stage {
 				analysis.BsonId: bson.M{
 					merge.BsonFieldName: expr.Field(group.BsonFieldName),
 				},
-				merge.BsonCount: bson.M{"$sum": expr.Field(analysis.BsonCount)},
+				merge.BsonCount:        bson.M{"$sum": expr.Field(analysis.BsonCount)},
+				merge.BsonDocsCount:    bson.M{"$sum": expr.Field(group.BsonDocsCount)},
+				merge.BsonParentsCount: bson.M{"$sum": expr.Field(group.BsonParentsCount)},
 				merge.BsonTypes: bson.M{
 					"$push": expr.Var("ROOT"),
 				},
 			})
 
 			p.AddStage("project", bson.M{
-				analysis.BsonId:     0,
-				merge.BsonFieldName: expr.Field(analysis.BsonId, merge.BsonFieldName),
-				merge.BsonCount:     1,
-				merge.BsonTypes:     1,
+				analysis.BsonId:        0,
+				merge.BsonFieldName:    expr.Field(analysis.BsonId, merge.BsonFieldName),
+				merge.BsonCount:        1,
+				merge.BsonDocsCount:    1,
+				merge.BsonParentsCount: 1,
+				merge.BsonTypes:        1,
 			})
 
 			p.AddStage("sort", bson.M{
 				merge.BsonFieldName: 1,
 			})
 
-			return p
+			return p, nil
 		},
 	}
 }
@@ -2,19 +2,19 @@
 package mergeLocally
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/analysis/stages/03group"
 	"github.com/mongoeye/mongoeye/analysis/stages/04merge"
 	"sort"
-	"strings"
 )
 
 // NewStage - MergeLocally stage factory
 func NewStage(mergeOptions *merge.Options) *analysis.Stage {
 	return &analysis.Stage{
-		Processor: func(_input interface{}, analysisOptions *analysis.Options) interface{} {
+		Processor: func(ctx context.Context, _input interface{}, analysisOptions *analysis.Options) (interface{}, error) {
 			// Input channel
-			input := group.ToResultChannel(_input, analysisOptions.Location, analysisOptions.Concurrency, analysisOptions.BufferSize)
+			input := group.ToResultChannel(ctx, _input, analysisOptions.Location, analysisOptions.Concurrency, analysisOptions.BufferSize)
 
 			// Create output channel and wait group
 			output := make(chan analysis.Field, analysisOptions.BufferSize)
@@ -24,6 +24,11 @@ func NewStage(mergeOptions *merge.Options) *analysis.Stage {
 				m := make(map[string]*analysis.Field)
 
 				for gr := range input {
+					// Drain input when the context is done
+					if ctx.Err() != nil {
+						continue
+					}
+
 					// Load or create
 					r := m[gr.Name]
 					if r == nil {
@@ -36,11 +41,13 @@ func NewStage(mergeOptions *merge.Options) *analysis.Stage {
 					t := gr.Type
 
 					r.Count += t.Count
+					r.DocsCount += gr.DocsCount
+					r.ParentsCount += gr.ParentsCount
 					r.Types = append(r.Types, &t)
 				}
 
 				for _, f := range m {
-					f.Level = uint(strings.Count(f.Name, analysis.NameSeparator))
+					f.Level = analysis.NameLevel(f.Name)
 					sort.Sort(f.Types)
 
 					output <- *f
@@ -49,7 +56,7 @@ func NewStage(mergeOptions *merge.Options) *analysis.Stage {
 				close(output)
 			}()
 
-			return output
+			return output, nil
 		},
 	}
 }
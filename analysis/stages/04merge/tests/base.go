@@ -138,9 +138,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 
 	expected := []interface{}{
 		&analysis.Field{
-			Name:  "_id",
-			Level: 0,
-			Count: 3,
+			Name:         "_id",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "objectId",
@@ -154,9 +156,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_double",
-			Level: 0,
-			Count: 3,
+			Name:         "_double",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:        "double",
@@ -220,9 +224,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_string",
-			Level: 0,
-			Count: 3,
+			Name:         "_string",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:        "string",
@@ -287,9 +293,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_binData",
-			Level: 0,
-			Count: 3,
+			Name:         "_binData",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "binData",
@@ -298,9 +306,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_undefined",
-			Level: 0,
-			Count: 3,
+			Name:         "_undefined",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "undefined",
@@ -309,9 +319,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_bool",
-			Level: 0,
-			Count: 3,
+			Name:         "_bool",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "bool",
@@ -325,9 +337,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_date",
-			Level: 0,
-			Count: 3,
+			Name:         "_date",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:        "date",
@@ -426,9 +440,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_null",
-			Level: 0,
-			Count: 3,
+			Name:         "_null",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "null",
@@ -437,9 +453,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_regex",
-			Level: 0,
-			Count: 3,
+			Name:         "_regex",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "regex",
@@ -448,9 +466,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_dbPointer",
-			Level: 0,
-			Count: 3,
+			Name:         "_dbPointer",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "dbPointer",
@@ -459,9 +479,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_javascript",
-			Level: 0,
-			Count: 3,
+			Name:         "_javascript",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "javascript",
@@ -470,9 +492,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_symbol",
-			Level: 0,
-			Count: 3,
+			Name:         "_symbol",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "symbol",
@@ -481,9 +505,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_javascriptWithScope",
-			Level: 0,
-			Count: 3,
+			Name:         "_javascriptWithScope",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "javascriptWithScope",
@@ -492,9 +518,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_int",
-			Level: 0,
-			Count: 3,
+			Name:         "_int",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:        "int",
@@ -546,9 +574,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_timestamp",
-			Level: 0,
-			Count: 3,
+			Name:         "_timestamp",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:        "timestamp",
@@ -583,9 +613,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_long",
-			Level: 0,
-			Count: 3,
+			Name:         "_long",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:        "long",
@@ -637,9 +669,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_decimal",
-			Level: 0,
-			Count: 3,
+			Name:         "_decimal",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:        decimalType,
@@ -684,8 +718,10 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_minKey",
-			Count: 3,
+			Name:         "_minKey",
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "minKey",
@@ -694,9 +730,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_maxKey",
-			Level: 0,
-			Count: 3,
+			Name:         "_maxKey",
+			Level:        0,
+			Count:        3,
+			DocsCount:    3,
+			ParentsCount: 3,
 			Types: analysis.Types{
 				{
 					Name:  "maxKey",
@@ -705,9 +743,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_array",
-			Level: 0,
-			Count: 2,
+			Name:         "_array",
+			Level:        0,
+			Count:        2,
+			DocsCount:    2,
+			ParentsCount: 2,
 			Types: analysis.Types{
 				{
 					Name:  "array",
@@ -721,9 +761,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_array.[]",
-			Level: 1,
-			Count: 4,
+			Name:         "_array.[]",
+			Level:        1,
+			Count:        4,
+			DocsCount:    2,
+			ParentsCount: 2,
 			Types: analysis.Types{
 				{
 					Name:        "int",
@@ -799,9 +841,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_object",
-			Level: 0,
-			Count: 2,
+			Name:         "_object",
+			Level:        0,
+			Count:        2,
+			DocsCount:    2,
+			ParentsCount: 2,
 			Types: analysis.Types{
 				{
 					Name:  "object",
@@ -815,9 +859,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_object.f1",
-			Level: 1,
-			Count: 2,
+			Name:         "_object.f1",
+			Level:        1,
+			Count:        2,
+			DocsCount:    2,
+			ParentsCount: 2,
 			Types: analysis.Types{
 				{
 					Name:        "string",
@@ -871,9 +917,11 @@ func RunTestAllTypes(t *testing.T, stageFactory merge.StageFactory) {
 			},
 		},
 		&analysis.Field{
-			Name:  "_object.f2",
-			Level: 1,
-			Count: 2,
+			Name:         "_object.f2",
+			Level:        1,
+			Count:        2,
+			DocsCount:    2,
+			ParentsCount: 2,
 			Types: analysis.Types{
 				{
 					Name:        "string",
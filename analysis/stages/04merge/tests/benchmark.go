@@ -12,7 +12,7 @@ import (
 func RunBenchmarkStageFull(b *testing.B, stageFactory merge.StageFactory) {
 	b.StopTimer()
 
-	c := tests.BenchmarkDbSession.DB(tests.BenchmarkDb).C(tests.BenchmarkCol)
+	c := tests.GetBenchmarkCol()
 
 	options := merge.Options{}
 	copier.Copy(&options, testMergeOptions)
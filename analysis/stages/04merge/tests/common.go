@@ -2,6 +2,7 @@
 package mergeTests
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/analysis"
 	"github.com/mongoeye/mongoeye/analysis/stages/01sample"
 	"github.com/mongoeye/mongoeye/analysis/stages/01sample/sampleInDB"
@@ -15,7 +16,6 @@ import (
 	"github.com/mongoeye/mongoeye/helpers"
 	"github.com/mongoeye/mongoeye/tests"
 	"github.com/mongoeye/mongoeye/tests/analysis"
-	"gopkg.in/mgo.v2"
 	"runtime"
 	"testing"
 	"time"
@@ -58,7 +58,7 @@ func init() {
 	groupLocallyStage = groupLocally.NewStage(groupOptions)
 }
 
-func testStage(t *testing.T, c *mgo.Collection, location *time.Location, mergeStage *analysis.Stage, expected []interface{}) []interface{} {
+func testStage(t *testing.T, c *tests.TestCollection, location *time.Location, mergeStage *analysis.Stage, expected []interface{}) []interface{} {
 	numCpu := runtime.NumCPU()
 	runtime.GOMAXPROCS(numCpu)
 
@@ -76,14 +76,17 @@ func testStage(t *testing.T, c *mgo.Collection, location *time.Location, mergeSt
 		groupStage = groupLocallyStage
 	}
 
-	out := analysisTests.RunStages(c, location, []*analysis.Stage{
+	out, err := analysisTests.RunStages(c, location, []*analysis.Stage{
 		sampleInDbStage,
 		expandStage,
 		groupStage,
 		mergeStage,
 	})
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	results := merge.FieldChannelToSlice(merge.ToFieldChannel(out, location, numCpu, 100))
+	results := merge.FieldChannelToSlice(merge.ToFieldChannel(context.Background(), out, location, numCpu, 100))
 
 	// Convert []Field -> []interface, for tests.AssertEqualSet function
 	results2 := []interface{}{}
@@ -96,7 +99,7 @@ func testStage(t *testing.T, c *mgo.Collection, location *time.Location, mergeSt
 	return results2
 }
 
-func benchmarkStage(b *testing.B, c *mgo.Collection, location *time.Location, mergeStage *analysis.Stage, loadFields bool) {
+func benchmarkStage(b *testing.B, c *tests.TestCollection, location *time.Location, mergeStage *analysis.Stage, loadFields bool) {
 	numCpu := runtime.NumCPU()
 	runtime.GOMAXPROCS(numCpu)
 
@@ -116,15 +119,18 @@ func benchmarkStage(b *testing.B, c *mgo.Collection, location *time.Location, me
 
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		out := analysisTests.RunStages(c, location, []*analysis.Stage{
+		out, err := analysisTests.RunStages(c, location, []*analysis.Stage{
 			sampleInDbStage,
 			expandStage,
 			groupStage,
 			mergeStage,
 		})
+		if err != nil {
+			b.Fatal(err)
+		}
 
 		if loadFields {
-			s := merge.FieldChannelToSlice(merge.ToFieldChannel(out, location, numCpu, 100))
+			s := merge.FieldChannelToSlice(merge.ToFieldChannel(context.Background(), out, location, numCpu, 100))
 			b.Logf("Count: %d", len(s))
 		} else {
 			if ch, ok := out.(chan analysis.Field); ok {
@@ -140,11 +146,11 @@ func benchmarkStage(b *testing.B, c *mgo.Collection, location *time.Location, me
 	}
 }
 
-func setup() *mgo.Collection {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+func setup() *tests.TestCollection {
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	return c
 }
 
-func tearDown(c *mgo.Collection) {
+func tearDown(c *tests.TestCollection) {
 	tests.DropTestCollection(c)
 }
@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"context"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+)
+
+// Analyze runs analysis of documents from the source and returns result.
+// It never prints, errors of the analysis are returned.
+// If the context is done, then partial result is returned together with the context error.
+// If the analysis fails, eg. on corrupted document, then only the error is returned.
+// Documents from sources other than MongoDB collection are sampled locally.
+// Server info is used only to check whether analysis in database is available.
+// If more plans are available, the fastest one on a probe sample is chosen (see Result.PlanReason).
+func Analyze(ctx context.Context, src analysis.Source, count int, server driver.BuildInfo, opts *Options) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	_, inDB := src.(*analysis.CollectionSource)
+	p, reason, err := choosePlan(ctx, server, count, opts, src, !inDB)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := p.Run(ctx, src)
+	if err != nil && err != ctx.Err() {
+		return nil, err
+	}
+	r.PlanReason = reason
+	return &r, err
+}
@@ -0,0 +1,228 @@
+package analyzer
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/helpers"
+	"gopkg.in/mgo.v2/bson"
+	"math"
+	"unicode/utf8"
+)
+
+// Types with value limits (minimum, maximum).
+var numberTypes = []string{"int", "long", "double"}
+
+// Types whose values can be listed in enum.
+var enumTypes = []string{"null", "bool", "int", "long", "double", "string"}
+
+// Node of schema tree, flat field names are split to segments, see analysis.SplitName.
+type schemaNode struct {
+	field      *analysis.Field
+	names      []string
+	properties map[string]*schemaNode
+	items      *schemaNode
+	anyKey     *schemaNode // values of collapsed object with dynamic keys
+}
+
+// JsonSchema converts results of analysis to MongoDB $jsonSchema validator.
+//
+// Types of field are converted to bsonType, fields present in all analyzed documents are required.
+// Limits (minimum, maximum, minLength, maxLength, minItems, maxItems) are derived from value and length statistics.
+// Enum is generated if the most frequent values contain all unique values of the field.
+// Statistics are included only if they were computed, see 'value', 'length', 'count-unique' and 'most-freq' options.
+func JsonSchema(result *Result, opts *Options) bson.M {
+	root := &schemaNode{}
+	for _, f := range result.Fields {
+		root.add(analysis.SplitName(f.Name), f)
+	}
+
+	schema := bson.M{"bsonType": "object"}
+	root.addProperties(schema, result.DocsCount, opts)
+
+	return schema
+}
+
+func (n *schemaNode) add(path []string, f *analysis.Field) {
+	// Positional fields are already described by the array items
+	if analysis.IsArrayPositionMark(path[0]) {
+		return
+	}
+
+	var child *schemaNode
+	if path[0] == analysis.ArrayItemMark {
+		if n.items == nil {
+			n.items = &schemaNode{}
+		}
+		child = n.items
+	} else if path[0] == analysis.MapKeyMark {
+		if n.anyKey == nil {
+			n.anyKey = &schemaNode{}
+		}
+		child = n.anyKey
+	} else {
+		if n.properties == nil {
+			n.properties = make(map[string]*schemaNode)
+		}
+		child = n.properties[path[0]]
+		if child == nil {
+			child = &schemaNode{}
+			n.properties[path[0]] = child
+			n.names = append(n.names, path[0])
+		}
+	}
+
+	if len(path) == 1 {
+		child.field = f
+	} else {
+		child.add(path[1:], f)
+	}
+}
+
+// Add properties of object, count is number of analyzed objects.
+func (n *schemaNode) addProperties(schema bson.M, count uint64, opts *Options) {
+	if len(n.names) == 0 {
+		return
+	}
+
+	properties := bson.M{}
+	required := []string{}
+	for _, name := range n.names {
+		child := n.properties[name]
+		key := analysis.UnescapeKey(name)
+		properties[key] = child.fieldSchema(opts)
+		if child.field != nil && child.field.Count == count {
+			required = append(required, key)
+		}
+	}
+
+	schema["properties"] = properties
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+}
+
+func (n *schemaNode) fieldSchema(opts *Options) bson.M {
+	schema := bson.M{}
+	if n.field == nil {
+		return schema
+	}
+
+	types := n.field.Types
+	if len(types) == 1 {
+		schema["bsonType"] = types[0].Name
+	} else {
+		bsonTypes := make([]string, len(types))
+		for i, t := range types {
+			bsonTypes[i] = t.Name
+		}
+		schema["bsonType"] = bsonTypes
+	}
+
+	addValueLimits(schema, types)
+	addLengthLimits(schema, types)
+	addEnum(schema, types, opts)
+
+	for _, t := range types {
+		if t.Name == "object" {
+			n.addProperties(schema, t.Count, opts)
+		}
+	}
+
+	if n.items != nil {
+		schema["items"] = n.items.fieldSchema(opts)
+	}
+
+	if n.anyKey != nil {
+		schema["additionalProperties"] = n.anyKey.fieldSchema(opts)
+	}
+
+	return schema
+}
+
+// Minimum and maximum of all number types.
+func addValueLimits(schema bson.M, types analysis.Types) {
+	var min, max interface{}
+	for _, t := range types {
+		if !helpers.InStringSlice(t.Name, numberTypes) {
+			continue
+		}
+
+		if t.ValueStats == nil || !isFinite(t.ValueStats.Min) || !isFinite(t.ValueStats.Max) {
+			return
+		}
+
+		if min == nil || helpers.ToDouble(t.ValueStats.Min) < helpers.ToDouble(min) {
+			min = t.ValueStats.Min
+		}
+		if max == nil || helpers.ToDouble(t.ValueStats.Max) > helpers.ToDouble(max) {
+			max = t.ValueStats.Max
+		}
+	}
+
+	if min != nil {
+		schema["minimum"] = min
+		schema["maximum"] = max
+	}
+}
+
+// Length limits of strings and arrays.
+func addLengthLimits(schema bson.M, types analysis.Types) {
+	for _, t := range types {
+		if t.LengthStats == nil {
+			continue
+		}
+
+		switch t.Name {
+		case "string":
+			schema["minLength"] = t.LengthStats.Min
+			schema["maxLength"] = t.LengthStats.Max
+		case "array":
+			schema["minItems"] = t.LengthStats.Min
+			schema["maxItems"] = t.LengthStats.Max
+		}
+	}
+}
+
+// Enum is added only if all values of all types are known.
+func addEnum(schema bson.M, types analysis.Types, opts *Options) {
+	values := []interface{}{}
+	for _, t := range types {
+		if !helpers.InStringSlice(t.Name, enumTypes) {
+			return
+		}
+
+		if t.Name == "null" {
+			values = append(values, nil)
+			continue
+		}
+
+		// Estimated count of unique values cannot prove that all values are known
+		if t.CountUnique == 0 || t.CountUniqueError > 0 || uint64(len(t.MostFrequent)) < t.CountUnique {
+			return
+		}
+
+		for _, v := range t.MostFrequent {
+			// Value may be truncated
+			if s, ok := v.Value.(string); ok && uint(utf8.RuneCountInString(s)) >= opts.StringMaxLength {
+				return
+			}
+
+			if !isFinite(v.Value) {
+				return
+			}
+
+			values = append(values, v.Value)
+		}
+	}
+
+	if len(values) > 0 {
+		schema["enum"] = values
+	}
+}
+
+// NaN and Inf cannot be represented in JSON, other types than float64 are finite.
+func isFinite(v interface{}) bool {
+	if f, ok := v.(float64); ok {
+		return !math.IsNaN(f) && !math.IsInf(f, 0)
+	}
+	return true
+}
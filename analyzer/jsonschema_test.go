@@ -0,0 +1,218 @@
+package analyzer
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"math"
+	"testing"
+)
+
+func jsonSchemaTestResult() Result {
+	return Result{
+		DocsCount: 10,
+		Fields: analysis.Fields{
+			{
+				Name:  "_id",
+				Count: 10,
+				Types: analysis.Types{
+					{Name: "objectId", Count: 10},
+				},
+			},
+			{
+				Name:  "age",
+				Count: 8,
+				Types: analysis.Types{
+					{Name: "int", Count: 6, ValueStats: &analysis.ValueStats{Min: 18, Max: 60}},
+					{Name: "double", Count: 2, ValueStats: &analysis.ValueStats{Min: 17.5, Max: 40.5}},
+				},
+			},
+			{
+				Name:  "status",
+				Count: 10,
+				Types: analysis.Types{
+					{
+						Name:        "string",
+						Count:       10,
+						CountUnique: 2,
+						LengthStats: &analysis.LengthStats{Min: 3, Max: 6},
+						MostFrequent: analysis.ValueFreqSlice{
+							{Value: "active", Count: 7},
+							{Value: "new", Count: 3},
+						},
+					},
+				},
+			},
+			{
+				Name:  "address",
+				Count: 5,
+				Types: analysis.Types{
+					{Name: "null", Count: 1},
+					{Name: "object", Count: 4},
+				},
+			},
+			{
+				Name:  "address.city",
+				Count: 4,
+				Types: analysis.Types{
+					{Name: "string", Count: 4},
+				},
+			},
+			{
+				Name:  "address.zip",
+				Count: 3,
+				Types: analysis.Types{
+					{Name: "string", Count: 3},
+				},
+			},
+			{
+				Name:  "tags",
+				Count: 10,
+				Types: analysis.Types{
+					{Name: "array", Count: 10, LengthStats: &analysis.LengthStats{Min: 0, Max: 3}},
+				},
+			},
+			{
+				Name:  "tags.[]",
+				Count: 12,
+				Types: analysis.Types{
+					{Name: "object", Count: 12},
+				},
+			},
+			{
+				Name:  "tags.[].name",
+				Count: 12,
+				Types: analysis.Types{
+					{Name: "string", Count: 12},
+				},
+			},
+		},
+	}
+}
+
+func TestJsonSchema(t *testing.T) {
+	result := jsonSchemaTestResult()
+	config := &Options{StringMaxLength: 100}
+
+	assert.Equal(t, bson.M{
+		"bsonType": "object",
+		"required": []string{"_id", "status", "tags"},
+		"properties": bson.M{
+			"_id": bson.M{"bsonType": "objectId"},
+			"age": bson.M{
+				"bsonType": []string{"int", "double"},
+				"minimum":  17.5,
+				"maximum":  60,
+			},
+			"status": bson.M{
+				"bsonType":  "string",
+				"minLength": uint(3),
+				"maxLength": uint(6),
+				"enum":      []interface{}{"active", "new"},
+			},
+			"address": bson.M{
+				"bsonType": []string{"null", "object"},
+				"required": []string{"city"},
+				"properties": bson.M{
+					"city": bson.M{"bsonType": "string"},
+					"zip":  bson.M{"bsonType": "string"},
+				},
+			},
+			"tags": bson.M{
+				"bsonType": "array",
+				"minItems": uint(0),
+				"maxItems": uint(3),
+				"items": bson.M{
+					"bsonType": "object",
+					"required": []string{"name"},
+					"properties": bson.M{
+						"name": bson.M{"bsonType": "string"},
+					},
+				},
+			},
+		},
+	}, JsonSchema(&result, config))
+}
+
+func TestJsonSchema_EnumIncomplete(t *testing.T) {
+	result := jsonSchemaTestResult()
+	result.Fields[2].Types[0].CountUnique = 3
+
+	schema := JsonSchema(&result, &Options{StringMaxLength: 100})
+	status := schema["properties"].(bson.M)["status"].(bson.M)
+	assert.Nil(t, status["enum"])
+}
+
+func TestJsonSchema_EnumTruncated(t *testing.T) {
+	result := jsonSchemaTestResult()
+
+	schema := JsonSchema(&result, &Options{StringMaxLength: 6})
+	status := schema["properties"].(bson.M)["status"].(bson.M)
+	assert.Nil(t, status["enum"])
+}
+
+func TestJsonSchema_InfiniteValue(t *testing.T) {
+	result := jsonSchemaTestResult()
+	result.Fields[1].Types[1].ValueStats.Max = math.Inf(1)
+
+	schema := JsonSchema(&result, &Options{StringMaxLength: 100})
+	age := schema["properties"].(bson.M)["age"].(bson.M)
+	assert.Nil(t, age["minimum"])
+	assert.Nil(t, age["maximum"])
+}
+
+func TestJsonSchema_Map(t *testing.T) {
+	result := Result{
+		DocsCount: 2,
+		Fields: analysis.Fields{
+			{Name: "stats", Count: 2, Types: analysis.Types{{Name: "object", Count: 2}}},
+			{Name: "stats.{key}", Count: 10, Types: analysis.Types{{Name: "object", Count: 10}}},
+			{Name: "stats.{key}.views", Count: 10, Types: analysis.Types{{Name: "int", Count: 10}}},
+		},
+	}
+
+	schema := JsonSchema(&result, &Options{StringMaxLength: 100})
+	stats := schema["properties"].(bson.M)["stats"].(bson.M)
+	assert.Nil(t, stats["properties"])
+	assert.Equal(t, bson.M{
+		"bsonType":   "object",
+		"properties": bson.M{"views": bson.M{"bsonType": "int"}},
+		"required":   []string{"views"},
+	}, stats["additionalProperties"])
+}
+
+func TestJsonSchema_ArrayPositions(t *testing.T) {
+	result := Result{
+		DocsCount: 2,
+		Fields: analysis.Fields{
+			{Name: "point", Count: 2, Types: analysis.Types{{Name: "array", Count: 2}}},
+			{Name: "point.[0]", Count: 2, Types: analysis.Types{{Name: "double", Count: 2}}},
+			{Name: "point.[]", Count: 4, Types: analysis.Types{{Name: "double", Count: 4}}},
+		},
+	}
+
+	schema := JsonSchema(&result, &Options{StringMaxLength: 100})
+	point := schema["properties"].(bson.M)["point"].(bson.M)
+	assert.Equal(t, bson.M{"bsonType": "double"}, point["items"])
+	assert.Nil(t, point["properties"])
+}
+
+func TestJsonSchema_EscapedKeys(t *testing.T) {
+	result := Result{
+		DocsCount: 2,
+		Fields: analysis.Fields{
+			{Name: `""`, Count: 2, Types: analysis.Types{{Name: "string", Count: 2}}},
+			{Name: `"a.b"`, Count: 2, Types: analysis.Types{{Name: "object", Count: 2}}},
+			{Name: `"a.b"."\"q"`, Count: 1, Types: analysis.Types{{Name: "int", Count: 1}}},
+			{Name: "a", Count: 1, Types: analysis.Types{{Name: "object", Count: 1}}},
+			{Name: "a.b", Count: 1, Types: analysis.Types{{Name: "int", Count: 1}}},
+		},
+	}
+
+	schema := JsonSchema(&result, &Options{StringMaxLength: 100})
+	properties := schema["properties"].(bson.M)
+	assert.Equal(t, []string{"", "a.b"}, schema["required"])
+	assert.Equal(t, bson.M{"bsonType": "string"}, properties[""])
+	assert.Equal(t, bson.M{"bsonType": "int"}, properties["a.b"].(bson.M)["properties"].(bson.M)[`"q`])
+	assert.Equal(t, bson.M{"bsonType": "int"}, properties["a"].(bson.M)["properties"].(bson.M)["b"])
+}
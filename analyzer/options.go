@@ -0,0 +1,342 @@
+// Package analyzer chooses the plan of analysis and runs it, it has no dependency on the command line interface.
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/01sample"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/mongoeye/mongoeye/analysis/stages/03group"
+	"github.com/mongoeye/mongoeye/analysis/stages/04merge"
+	"github.com/mongoeye/mongoeye/helpers"
+	"gopkg.in/mgo.v2/bson"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Options of analysis, they have the same meaning as flags of command line interface.
+type Options struct {
+	// names reported in the result
+	Database   string
+	Collection string
+
+	// sample options
+	Match        bson.M
+	Project      bson.M
+	SampleMethod string // all, first, last, random
+	Limit        uint64
+
+	// expansion options
+	Depth         uint
+	DepthFor      map[string]uint // path => depth
+	MapMinKeys    uint
+	MapMaxKeys    uint
+	MapPaths      []string
+	NoMapPaths    []string
+	IncludeFields []string
+	ExcludeFields []string
+
+	// statistics options
+	MinMaxAvgValue       bool
+	MinMaxAvgLength      bool
+	Quantiles            bool
+	ValueHistogram       bool
+	ValueHistogramSteps  uint
+	LengthHistogram      bool
+	LengthHistogramSteps uint
+	WeekdayHistogram     bool
+	HourHistogram        bool
+	StringFormats        bool
+	CountUnique          bool
+	UniqueMethod         string // exact, approx, auto
+	UniqueMemory         uint   // MB
+	MostFrequentValues   uint
+	LeastFrequentValues  uint
+	Shapes               uint
+	ArrayStats           bool
+	ArrayPositions       uint
+
+	// other options
+	Location        *time.Location
+	Plan            string // auto, local, db, db-seq, hybrid
+	StringMaxLength uint
+	ArrayMaxLength  uint
+	ArrayMaxFor     map[string]uint // path => max length
+	Concurrency     uint            // zero = number of CPUs
+	BufferSize      uint
+	BatchSize       uint
+	SkipCorrupted   bool
+}
+
+// DefaultOptions returns options with the same default values as command line interface.
+func DefaultOptions() *Options {
+	return &Options{
+		Match:                bson.M{},
+		Project:              bson.M{},
+		SampleMethod:         "random",
+		Limit:                1000,
+		Depth:                2,
+		DepthFor:             map[string]uint{},
+		MapPaths:             []string{},
+		NoMapPaths:           []string{},
+		IncludeFields:        []string{},
+		ExcludeFields:        []string{},
+		ValueHistogramSteps:  100,
+		LengthHistogramSteps: 100,
+		UniqueMethod:         "auto",
+		UniqueMemory:         256,
+		Location:             time.Local,
+		Plan:                 "auto",
+		StringMaxLength:      100,
+		ArrayMaxLength:       20,
+		ArrayMaxFor:          map[string]uint{},
+		BufferSize:           5000,
+		BatchSize:            500,
+	}
+}
+
+// CreateAnalysisOptions generates analysis options.
+func (o *Options) CreateAnalysisOptions() *analysis.Options {
+	concurrency := int(o.Concurrency)
+	if concurrency == 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	return &analysis.Options{
+		Location:      o.Location,
+		Concurrency:   concurrency,
+		BufferSize:    int(o.BufferSize),
+		BatchSize:     int(o.BatchSize),
+		SkipCorrupted: o.SkipCorrupted,
+	}
+}
+
+// CreateSampleStageOptions generates sample options.
+func (o *Options) CreateSampleStageOptions() *sample.Options {
+	var sampleMethod sample.SampleMethod
+	switch o.SampleMethod {
+	case "all":
+		sampleMethod = sample.AllDocuments
+	case "first":
+		sampleMethod = sample.FirstNDocuments
+	case "last":
+		sampleMethod = sample.LastNDocuments
+	case "random":
+		sampleMethod = sample.RandomNDocuments
+	default:
+		panic("Unexpected sample.")
+	}
+
+	return &sample.Options{
+		Match:   o.Match,
+		Project: o.Project,
+		Method:  sampleMethod,
+		Limit:   uint64(o.Limit),
+	}
+}
+
+// CreateExpandStageOptions generates expand options.
+func (o *Options) CreateExpandStageOptions() *expand.Options {
+	var shapes *expand.Shapes
+	if o.Shapes > 0 {
+		shapes = expand.NewShapes()
+	}
+
+	return &expand.Options{
+		StringMaxLength: o.StringMaxLength,
+		ArrayMaxLength:  o.ArrayMaxLength,
+		MaxDepth:        o.Depth,
+		MapMinKeys:      o.MapMinKeys,
+		MapMaxKeys:      o.MapMaxKeys,
+		MapPaths:        o.MapPaths,
+		NoMapPaths:      o.NoMapPaths,
+		StoreValue: o.MinMaxAvgValue ||
+			o.CountUnique ||
+			o.MostFrequentValues > 0 ||
+			o.LeastFrequentValues > 0 ||
+			o.WeekdayHistogram ||
+			o.HourHistogram ||
+			o.StringFormats ||
+			o.ValueHistogram,
+		StoreStringLength: o.MinMaxAvgLength || o.LengthHistogram,
+		StoreArrayLength:  o.MinMaxAvgLength || o.LengthHistogram,
+		StoreObjectLength: o.MinMaxAvgLength || o.LengthHistogram,
+		Shapes:            shapes,
+		Fields:            o.FieldFilter(),
+		DepthFor:          expand.PathLimits(o.DepthFor),
+		ArrayMaxLengthFor: expand.PathLimits(o.ArrayMaxFor),
+		StoreArrayStats:   o.ArrayStats,
+		ArrayPositions:    o.ArrayPositions,
+	}
+}
+
+// Limits returns limits of expansion reported in the result.
+func (o *Options) Limits() *Limits {
+	return &Limits{
+		Depth:          o.Depth,
+		DepthFor:       o.DepthFor,
+		ArrayMaxLength: o.ArrayMaxLength,
+		ArrayMaxFor:    o.ArrayMaxFor,
+	}
+}
+
+// FieldFilter returns filter from 'include-fields' and 'exclude-fields' options, nil = all fields.
+func (o *Options) FieldFilter() *expand.FieldFilter {
+	if len(o.IncludeFields) == 0 && len(o.ExcludeFields) == 0 {
+		return nil
+	}
+
+	// Patterns are checked by Validate
+	fields, _ := expand.NewFieldFilter(o.IncludeFields, o.ExcludeFields)
+	return fields
+}
+
+// CreateGroupStageOptions generates group options.
+func (o *Options) CreateGroupStageOptions() *group.Options {
+	options := &group.Options{
+		ProcessObjectIdAsDate: true,
+		StoreMinMaxAvgValue:   o.MinMaxAvgValue,
+		StoreSumAndDeviation:  o.MinMaxAvgValue,
+		StoreMinMaxAvgLength:  o.MinMaxAvgLength,
+		StoreQuantiles:        o.Quantiles,
+		StoreCountOfUnique:    o.CountUnique,
+		StoreMostFrequent:     o.MostFrequentValues,
+		StoreLeastFrequent:    o.LeastFrequentValues,
+		StoreWeekdayHistogram: o.WeekdayHistogram,
+		StoreHourHistogram:    o.HourHistogram,
+		StoreStringFormats:    o.StringFormats,
+		StoreArrayStats:       o.ArrayStats,
+		ValueHistogramMaxRes:  0,
+		LengthHistogramMaxRes: 0,
+	}
+
+	switch o.UniqueMethod {
+	case "approx":
+		options.UniqueMethod = group.ApproxUnique
+	case "auto":
+		options.UniqueMethod = group.AutoUnique
+		options.UniqueMemoryLimit = uint64(o.UniqueMemory) * 1024 * 1024
+	default:
+		options.UniqueMethod = group.ExactUnique
+	}
+
+	if o.ValueHistogram {
+		options.ValueHistogramMaxRes = o.ValueHistogramSteps
+	}
+
+	if o.LengthHistogram {
+		options.LengthHistogramMaxRes = o.LengthHistogramSteps
+	}
+
+	return options
+}
+
+// CreateMergeStageOptions generates merge options.
+func (o *Options) CreateMergeStageOptions() *merge.Options {
+	return &merge.Options{}
+}
+
+// Shapes, array stats, positions and collapsing of maps are supported only by the local expand stage.
+func (o *Options) requiresLocalExpand() bool {
+	return o.Shapes > 0 || o.ArrayStats || o.ArrayPositions > 0 || o.collapsesMaps()
+}
+
+// Objects with dynamic keys are collapsed to {key}.
+func (o *Options) collapsesMaps() bool {
+	return o.MapMinKeys > 0 || o.MapMaxKeys > 0 || len(o.MapPaths) > 0
+}
+
+// Validate checks values of options.
+func (o *Options) Validate() error {
+	if !helpers.InStringSlice(o.SampleMethod, []string{"all", "first", "last", "random"}) {
+		return errors.New(
+			"Invalid value of 'sample' option.\nAllowed values are: 'all', 'first:N', 'last:N', 'random:N'.",
+		)
+	}
+
+	if o.SampleMethod != "all" && o.Limit < 1 {
+		return errors.New(
+			"Limit (N) in 'sample' option must be >= 1.",
+		)
+	}
+
+	if o.ValueHistogramSteps < 3 {
+		return errors.New(
+			"Option 'value-histogram-steps' must be >= 3",
+		)
+	}
+
+	if o.LengthHistogramSteps < 3 {
+		return errors.New(
+			"Option 'length-histogram-max-steps' must be >= 3",
+		)
+	}
+
+	if o.Quantiles && !o.MinMaxAvgValue && !o.MinMaxAvgLength {
+		return errors.New(
+			"Option 'quantiles' requires 'value' or 'length' option.",
+		)
+	}
+
+	if !helpers.InStringSlice(o.UniqueMethod, []string{"exact", "approx", "auto"}) {
+		return errors.New(
+			"Invalid value of 'unique-method' option.\nAllowed values are: 'exact', 'approx', 'auto'.",
+		)
+	}
+
+	if o.Location == nil {
+		return errors.New(
+			"Option 'timezone' must be set.",
+		)
+	}
+
+	if o.BatchSize < 1 {
+		return errors.New(
+			"Option 'batch-size' must be >= 1",
+		)
+	}
+
+	if o.Plan != "auto" && !helpers.InStringSlice(o.Plan, PlanNames()) {
+		return fmt.Errorf(
+			"Invalid value of 'plan' option.\nAllowed values are: 'auto', '%s'.", strings.Join(PlanNames(), "', '"),
+		)
+	}
+
+	if o.Shapes > 0 && IsInDBPlan(o.Plan) {
+		return fmt.Errorf(
+			"Option 'shapes' cannot be used together with plan '%s'.", o.Plan,
+		)
+	}
+
+	if o.ArrayStats && IsInDBPlan(o.Plan) {
+		return fmt.Errorf(
+			"Option 'array-stats' cannot be used together with plan '%s'.", o.Plan,
+		)
+	}
+
+	if o.ArrayPositions > 0 && IsInDBPlan(o.Plan) {
+		return fmt.Errorf(
+			"Option 'array-positions' cannot be used together with plan '%s'.", o.Plan,
+		)
+	}
+
+	if o.collapsesMaps() && IsInDBPlan(o.Plan) {
+		return fmt.Errorf(
+			"Options 'map-min-keys', 'map-max-keys' and 'map-paths' cannot be used together with plan '%s'.", o.Plan,
+		)
+	}
+
+	for _, patterns := range [][]string{o.IncludeFields, o.ExcludeFields} {
+		for _, pattern := range patterns {
+			if _, err := expand.NewFieldFilter([]string{pattern}, nil); err != nil {
+				return fmt.Errorf(
+					"Invalid pattern '%s' in 'include-fields' or 'exclude-fields' option.", pattern,
+				)
+			}
+		}
+	}
+
+	return nil
+}
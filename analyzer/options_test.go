@@ -0,0 +1,377 @@
+package analyzer
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/01sample"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/mongoeye/mongoeye/analysis/stages/03group"
+	"github.com/mongoeye/mongoeye/analysis/stages/04merge"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestOptions_CreateAnalysisOptions(t *testing.T) {
+	opts := Options{
+		Location:    time.Local,
+		Concurrency: 12,
+		BufferSize:  100,
+		BatchSize:   200,
+	}
+
+	assert.Equal(t, &analysis.Options{
+		Location:    time.Local,
+		Concurrency: 12,
+		BufferSize:  100,
+		BatchSize:   200,
+	}, opts.CreateAnalysisOptions())
+}
+
+func TestOptions_CreateAnalysisOptions_ConcurrencyAuto(t *testing.T) {
+	opts := Options{
+		Location:    time.Local,
+		Concurrency: 0,
+		BufferSize:  100,
+		BatchSize:   200,
+	}
+
+	assert.Equal(t, &analysis.Options{
+		Location:    time.Local,
+		Concurrency: runtime.NumCPU(),
+		BufferSize:  100,
+		BatchSize:   200,
+	}, opts.CreateAnalysisOptions())
+}
+
+func TestOptions_CreateSampleStageOptions(t *testing.T) {
+	c := Options{
+		Match:        bson.M{"key": "value"},
+		Project:      bson.M{"key": 1},
+		SampleMethod: "all",
+		Limit:        0,
+	}
+
+	assert.Equal(t, &sample.Options{
+		Match:   bson.M{"key": "value"},
+		Project: bson.M{"key": 1},
+		Method:  sample.AllDocuments,
+		Limit:   0,
+	}, c.CreateSampleStageOptions())
+
+	// sample: first
+	c.SampleMethod = "first"
+	c.Limit = 12345
+	assert.Equal(t, &sample.Options{
+		Match:   bson.M{"key": "value"},
+		Project: bson.M{"key": 1},
+		Method:  sample.FirstNDocuments,
+		Limit:   12345,
+	}, c.CreateSampleStageOptions())
+
+	// sample: last
+	c.SampleMethod = "last"
+	assert.Equal(t, &sample.Options{
+		Match:   bson.M{"key": "value"},
+		Project: bson.M{"key": 1},
+		Method:  sample.LastNDocuments,
+		Limit:   12345,
+	}, c.CreateSampleStageOptions())
+
+	// sample: random
+	c.SampleMethod = "random"
+	assert.Equal(t, &sample.Options{
+		Match:   bson.M{"key": "value"},
+		Project: bson.M{"key": 1},
+		Method:  sample.RandomNDocuments,
+		Limit:   12345,
+	}, c.CreateSampleStageOptions())
+
+	// invalid sample
+	c.SampleMethod = "abc"
+	assert.Panics(t, func() {
+		c.CreateSampleStageOptions()
+	})
+}
+
+func TestOptions_CreateExpandStageOptions(t *testing.T) {
+	newOptions := func() Options {
+		return Options{
+			StringMaxLength:     123,
+			ArrayMaxLength:      456,
+			Depth:               4,
+			MinMaxAvgValue:      false,
+			MinMaxAvgLength:     false,
+			CountUnique:         false,
+			MostFrequentValues:  0,
+			LeastFrequentValues: 0,
+			ValueHistogram:      false,
+			LengthHistogram:     false,
+			WeekdayHistogram:    false,
+			HourHistogram:       false,
+		}
+	}
+
+	// All off
+	opts := newOptions()
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        false,
+		StoreStringLength: false,
+		StoreArrayLength:  false,
+		StoreObjectLength: false,
+	}, opts.CreateExpandStageOptions())
+
+	// MinMaxAvgValue
+	opts = newOptions()
+	opts.MinMaxAvgValue = true
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        true,
+		StoreStringLength: false,
+		StoreArrayLength:  false,
+		StoreObjectLength: false,
+	}, opts.CreateExpandStageOptions())
+
+	// MinMaxAvgLength
+	opts = newOptions()
+	opts.MinMaxAvgLength = true
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        false,
+		StoreStringLength: true,
+		StoreArrayLength:  true,
+		StoreObjectLength: true,
+	}, opts.CreateExpandStageOptions())
+
+	// CountUnique
+	opts = newOptions()
+	opts.CountUnique = true
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        true,
+		StoreStringLength: false,
+		StoreArrayLength:  false,
+		StoreObjectLength: false,
+	}, opts.CreateExpandStageOptions())
+
+	// MostFrequentValues
+	opts = newOptions()
+	opts.MostFrequentValues = 20
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        true,
+		StoreStringLength: false,
+		StoreArrayLength:  false,
+		StoreObjectLength: false,
+	}, opts.CreateExpandStageOptions())
+
+	// LeastFrequentValues
+	opts = newOptions()
+	opts.LeastFrequentValues = 20
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        true,
+		StoreStringLength: false,
+		StoreArrayLength:  false,
+		StoreObjectLength: false,
+	}, opts.CreateExpandStageOptions())
+
+	// ValueHistogram
+	opts = newOptions()
+	opts.ValueHistogram = true
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        true,
+		StoreStringLength: false,
+		StoreArrayLength:  false,
+		StoreObjectLength: false,
+	}, opts.CreateExpandStageOptions())
+
+	// LengthHistogram
+	opts = newOptions()
+	opts.LengthHistogram = true
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        false,
+		StoreStringLength: true,
+		StoreArrayLength:  true,
+		StoreObjectLength: true,
+	}, opts.CreateExpandStageOptions())
+
+	// WeekdayHistogram
+	opts = newOptions()
+	opts.WeekdayHistogram = true
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        true,
+		StoreStringLength: false,
+		StoreArrayLength:  false,
+		StoreObjectLength: false,
+	}, opts.CreateExpandStageOptions())
+
+	// HourHistogram
+	opts = newOptions()
+	opts.HourHistogram = true
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        true,
+		StoreStringLength: false,
+		StoreArrayLength:  false,
+		StoreObjectLength: false,
+	}, opts.CreateExpandStageOptions())
+
+	// StringFormats
+	opts = newOptions()
+	opts.StringFormats = true
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        true,
+		StoreStringLength: false,
+		StoreArrayLength:  false,
+		StoreObjectLength: false,
+	}, opts.CreateExpandStageOptions())
+
+	// Maps
+	opts = newOptions()
+	opts.MapMinKeys = 3
+	opts.MapMaxKeys = 100
+	opts.MapPaths = []string{"a"}
+	opts.NoMapPaths = []string{"b"}
+	assert.Equal(t, &expand.Options{
+		StringMaxLength:   123,
+		ArrayMaxLength:    456,
+		MaxDepth:          4,
+		StoreValue:        false,
+		StoreStringLength: false,
+		StoreArrayLength:  false,
+		StoreObjectLength: false,
+		MapMinKeys:        3,
+		MapMaxKeys:        100,
+		MapPaths:          []string{"a"},
+		NoMapPaths:        []string{"b"},
+	}, opts.CreateExpandStageOptions())
+
+	// Shapes
+	opts = newOptions()
+	opts.Shapes = 5
+	assert.Equal(t, expand.NewShapes(), opts.CreateExpandStageOptions().Shapes)
+
+	// Fields
+	opts = newOptions()
+	assert.Nil(t, opts.CreateExpandStageOptions().Fields)
+	opts.ExcludeFields = []string{"**.raw"}
+	fields := opts.CreateExpandStageOptions().Fields
+	assert.Equal(t, expand.FieldExcluded, fields.Match("a.raw", expand.FieldIncluded))
+	assert.Equal(t, expand.FieldIncluded, fields.Match("a.b", expand.FieldIncluded))
+}
+
+func TestOptions_CreateGroupStageOptions_HistogramsOn(t *testing.T) {
+	opts := Options{
+		MinMaxAvgValue:       true,
+		ValueHistogramSteps:  56,
+		MinMaxAvgLength:      true,
+		LengthHistogramSteps: 78,
+		CountUnique:          true,
+		MostFrequentValues:   12,
+		LeastFrequentValues:  34,
+		WeekdayHistogram:     true,
+		HourHistogram:        true,
+		ValueHistogram:       true,
+		LengthHistogram:      true,
+	}
+
+	assert.Equal(t, &group.Options{
+		ProcessObjectIdAsDate: true,
+		StoreMinMaxAvgValue:   true,
+		StoreSumAndDeviation:  true,
+		StoreMinMaxAvgLength:  true,
+		StoreCountOfUnique:    true,
+		StoreMostFrequent:     12,
+		StoreLeastFrequent:    34,
+		StoreWeekdayHistogram: true,
+		StoreHourHistogram:    true,
+		ValueHistogramMaxRes:  56,
+		LengthHistogramMaxRes: 78,
+	}, opts.CreateGroupStageOptions())
+}
+
+func TestOptions_CreateGroupStageOptions_HistogramsOff(t *testing.T) {
+	opts := Options{
+		MinMaxAvgValue:       true,
+		ValueHistogramSteps:  56,
+		MinMaxAvgLength:      true,
+		LengthHistogramSteps: 78,
+		CountUnique:          true,
+		MostFrequentValues:   12,
+		LeastFrequentValues:  34,
+		WeekdayHistogram:     true,
+		HourHistogram:        true,
+		ValueHistogram:       false,
+		LengthHistogram:      false,
+	}
+
+	assert.Equal(t, &group.Options{
+		ProcessObjectIdAsDate: true,
+		StoreMinMaxAvgValue:   true,
+		StoreSumAndDeviation:  true,
+		StoreMinMaxAvgLength:  true,
+		StoreCountOfUnique:    true,
+		StoreMostFrequent:     12,
+		StoreLeastFrequent:    34,
+		StoreWeekdayHistogram: true,
+		StoreHourHistogram:    true,
+		ValueHistogramMaxRes:  0,
+		LengthHistogramMaxRes: 0,
+	}, opts.CreateGroupStageOptions())
+}
+
+func TestOptions_CreateGroupStageOptions_UniqueMethod(t *testing.T) {
+	opts := Options{
+		CountUnique:  true,
+		UniqueMethod: "auto",
+		UniqueMemory: 3,
+	}
+
+	options := opts.CreateGroupStageOptions()
+	assert.Equal(t, group.AutoUnique, options.UniqueMethod)
+	assert.Equal(t, uint64(3*1024*1024), options.UniqueMemoryLimit)
+
+	opts.UniqueMethod = "approx"
+	options = opts.CreateGroupStageOptions()
+	assert.Equal(t, group.ApproxUnique, options.UniqueMethod)
+	assert.Equal(t, uint64(0), options.UniqueMemoryLimit)
+
+	opts.UniqueMethod = "exact"
+	options = opts.CreateGroupStageOptions()
+	assert.Equal(t, group.ExactUnique, options.UniqueMethod)
+}
+
+func TestOptions_CreateMergeStageOptions(t *testing.T) {
+	opts := Options{}
+
+	assert.Equal(t, &merge.Options{}, opts.CreateMergeStageOptions())
+}
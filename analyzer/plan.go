@@ -0,0 +1,320 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analysis/stages/01sample"
+	"github.com/mongoeye/mongoeye/analysis/stages/01sample/sampleInDB"
+	"github.com/mongoeye/mongoeye/analysis/stages/01sample/sampleLocally"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand/expandInDBDepth"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand/expandInDBSeq"
+	"github.com/mongoeye/mongoeye/analysis/stages/02expand/expandLocally"
+	"github.com/mongoeye/mongoeye/analysis/stages/03group/groupInDB"
+	"github.com/mongoeye/mongoeye/analysis/stages/03group/groupLocally"
+	"github.com/mongoeye/mongoeye/analysis/stages/04merge"
+	"github.com/mongoeye/mongoeye/analysis/stages/04merge/mergeInDB"
+	"github.com/mongoeye/mongoeye/analysis/stages/04merge/mergeLocally"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PlanUsages - plans that can be forced by 'plan' option, their trade-offs are printed in help.
+var PlanUsages = []struct{ Name, Usage string }{
+	{"local", "transfer documents and analyze them locally, all features (shapes, dynamic keys, array stats)"},
+	{"db", "analyze in database, only results are transferred, nested fields expanded recursively"},
+	{"db-seq", "same as db, nested fields expanded level by level (more stages, smaller expressions)"},
+	{"hybrid", "expand fields in database and group them locally, values are transferred, less server load"},
+}
+
+// PlanNames returns names of plans in order of preference.
+func PlanNames() []string {
+	names := make([]string, len(PlanUsages))
+	for i, u := range PlanUsages {
+		names[i] = u.Name
+	}
+	return names
+}
+
+// Plans are benchmarked on the first ProbeDocs documents, each probe is limited by ProbeMaxTime.
+// Benchmark runs only if more than benchmarkMinDocs documents are analyzed, so it takes only a small part of the analysis.
+const (
+	ProbeDocs        = 200
+	ProbeMaxTime     = 5 * time.Second
+	benchmarkMinDocs = 1000
+)
+
+// Plans of analysis.
+type plans []*plan
+
+func (p plans) Len() int           { return len(p) }
+func (p plans) Less(i, j int) bool { return p[i].TestDuration < p[j].TestDuration }
+func (p plans) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// Plan of analysis.
+type plan struct {
+	Name            string
+	Options         *Options
+	AnalysisOptions *analysis.Options
+	SampleStage     *analysis.Stage
+	ExpandStage     *analysis.Stage
+	GroupStage      *analysis.Stage
+	MergeStage      *analysis.Stage
+	AllDocsCount    uint64 // zero for offline sources, they are counted while reading
+	Offline         bool
+	Limit           uint64
+	TestDuration    time.Duration
+	TestError       error          // error of the benchmark, the plan is sorted last
+	Shapes          *expand.Shapes // collector of shapes filled by the expand stage, nil = disabled
+}
+
+// Run the plan. If the context is done, then partial result is returned together with the context error.
+// If the analysis fails, then only the error is returned.
+func (p *plan) Run(ctx context.Context, src analysis.Source) (Result, error) {
+	a := analysis.NewAnalysis(p.AnalysisOptions)
+	a.SetSampleStage(p.SampleStage)
+	a.SetExpandStage(p.ExpandStage)
+	a.SetGroupStage(p.GroupStage)
+	a.SetMergeStage(p.MergeStage)
+	a.SetSource(src)
+
+	start := time.Now()
+	out, err := a.Run(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	ch := merge.ToFieldChannel(a.Context(), out, p.AnalysisOptions.Location, p.AnalysisOptions.Concurrency, p.AnalysisOptions.BufferSize)
+	fields := merge.FieldChannelToSlice(ch)
+	duration := time.Since(start)
+
+	if err := a.Err(); err != nil {
+		return Result{}, err
+	}
+
+	// Offline source has been read, so documents are not read again to count them
+	allDocs := p.AllDocsCount
+	if c, ok := src.(countedSource); ok && p.Offline {
+		count, err := c.Count()
+		if err != nil {
+			return Result{}, err
+		}
+		allDocs = uint64(count)
+	}
+
+	sort.Sort(fields)
+
+	// Skipped corrupted documents are not analyzed
+	corruptedDocs := a.CorruptedCount()
+	validDocs := allDocs
+	if corruptedDocs < validDocs {
+		validDocs -= corruptedDocs
+	} else {
+		validDocs = 0
+	}
+
+	analyzedDocs := p.Limit
+	if analyzedDocs == 0 || analyzedDocs > validDocs {
+		analyzedDocs = validDocs
+	}
+
+	fields.ComputePresence(analyzedDocs)
+
+	result := Result{
+		Database:           p.Options.Database,
+		Collection:         p.Options.Collection,
+		Plan:               p.Name,
+		Duration:           duration,
+		AllDocsCount:       allDocs,
+		DocsCount:          analyzedDocs,
+		CorruptedDocsCount: corruptedDocs,
+		Limits:             p.Options.Limits(),
+		FieldsCount:        uint64(len(fields)),
+		Fields:             fields,
+	}
+
+	if p.Shapes != nil {
+		result.Shapes, result.ShapesCount = p.Shapes.Top(p.Options.Shapes)
+	}
+
+	return result, ctx.Err()
+}
+
+// Source that can count its documents.
+type countedSource interface {
+	Count() (int, error)
+}
+
+// SampleSize returns number of documents that will be analyzed.
+func (p *plan) SampleSize() uint64 {
+	if p.Limit == 0 || p.Limit > p.AllDocsCount {
+		return p.AllDocsCount
+	}
+	return p.Limit
+}
+
+// Benchmark runs each plan on the source, stores its duration and sorts plans from the fastest.
+// Plans that fail or exceed ProbeMaxTime are sorted last.
+// Only the error of the parent context is returned.
+func (p plans) Benchmark(ctx context.Context, src analysis.Source) error {
+	for _, pl := range p {
+		probeCtx, cancel := context.WithTimeout(ctx, ProbeMaxTime)
+		start := time.Now()
+		_, err := pl.Run(probeCtx, src)
+		pl.TestDuration = time.Since(start)
+		cancel()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		pl.TestError = err
+		if err != nil {
+			pl.TestDuration = math.MaxInt64
+		}
+	}
+
+	sort.Stable(p)
+	return nil
+}
+
+// Durations returns the benchmark results, eg. "local 0.012s, db 0.035s".
+func (p plans) Durations() string {
+	parts := make([]string, len(p))
+	for i, pl := range p {
+		if pl.TestError == context.DeadlineExceeded {
+			parts[i] = fmt.Sprintf("%s >%s", pl.Name, ProbeMaxTime)
+		} else if pl.TestError != nil {
+			parts[i] = fmt.Sprintf("%s failed", pl.Name)
+		} else {
+			parts[i] = fmt.Sprintf("%s %.3fs", pl.Name, pl.TestDuration.Seconds())
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Choose plan of analysis and return the reason of the choice.
+// If more plans are available, then each of them analyzes the probe sample and the fastest one is chosen.
+func choosePlan(ctx context.Context, server driver.BuildInfo, count int, opts *Options, src analysis.Source, offline bool) (*plan, string, error) {
+	allPlans := generatePlans(server, count, opts, offline)
+	switch {
+	case len(allPlans) == 0:
+		return nil, "", errors.New("No plan of analysis is available for given configuration and server version.")
+	case opts.Plan != "auto":
+		return allPlans[0], "set by 'plan' option", nil
+	case len(allPlans) == 1:
+		return allPlans[0], "the only available plan", nil
+	case allPlans[0].SampleSize() <= benchmarkMinDocs:
+		return allPlans[0], fmt.Sprintf("sample of %d docs is too small for benchmark", allPlans[0].SampleSize()), nil
+	}
+
+	// Probe sample keeps match and project of the analysis
+	probeOpts := *opts
+	probeOpts.SampleMethod = "first"
+	probeOpts.Limit = ProbeDocs
+	probePlans := generatePlans(server, count, &probeOpts, offline)
+	if err := probePlans.Benchmark(ctx, src); err != nil {
+		return nil, "", err
+	}
+
+	reason := fmt.Sprintf("fastest on probe sample of %d docs (%s)", ProbeDocs, probePlans.Durations())
+	for _, p := range allPlans {
+		if p.Name == probePlans[0].Name {
+			return p, reason, nil
+		}
+	}
+	return allPlans[0], reason, nil
+}
+
+// Generate plans, offline source cannot run stages in the database.
+func generatePlans(server driver.BuildInfo, count int, opts *Options, offline bool) plans {
+	// Options for individual stages
+	analysisOptions := opts.CreateAnalysisOptions()
+	sampleOptions := opts.CreateSampleStageOptions()
+	expandOptions := opts.CreateExpandStageOptions()
+	groupOptions := opts.CreateGroupStageOptions()
+	groupOptions.UsePercentileOperator = server.VersionAtLeast(analysis.PercentileMinVersion...)
+	mergeOptions := opts.CreateMergeStageOptions()
+
+	// Optimize sample stage, count of offline source is not known before reading
+	if !offline && sampleOptions.Method != sample.AllDocuments && sampleOptions.Limit > uint64(count) {
+		sampleOptions.Method = sample.AllDocuments
+		sampleOptions.Limit = 0
+	}
+
+	// Offline sources are sampled locally
+	sampleStage := sampleInDB.NewStage(sampleOptions)
+	if offline {
+		sampleStage = sampleLocally.NewStage(sampleOptions)
+	}
+
+	newPlan := func(name string, expandStage, groupStage, mergeStage *analysis.Stage) *plan {
+		return &plan{
+			Name:            name,
+			Options:         opts,
+			AnalysisOptions: analysisOptions,
+			SampleStage:     sampleStage,
+			ExpandStage:     expandStage,
+			GroupStage:      groupStage,
+			MergeStage:      mergeStage,
+			AllDocsCount:    uint64(count),
+			Offline:         offline,
+			Limit:           sampleOptions.Limit,
+		}
+	}
+
+	// Possible plans, in order of preference
+	candidates := make(plans, 0)
+
+	local := newPlan("local", expandLocally.NewStage(expandOptions), groupLocally.NewStage(groupOptions), mergeLocally.NewStage(mergeOptions))
+	local.Shapes = expandOptions.Shapes
+	candidates = append(candidates, local)
+
+	// Some options are supported only by the local expand stage.
+	// Other plans are not candidates, so auto mode never chooses between plans with different results.
+	if !offline && !opts.requiresLocalExpand() && server.VersionAtLeast(analysis.AggregationMinVersion...) && expandInDBAvailable(server, expandOptions) {
+		if groupInDBAvailable(server, opts) {
+			candidates = append(candidates,
+				newPlan("db", expandInDBDepth.NewStage(expandOptions), groupInDB.NewStage(groupOptions), mergeInDB.NewStage(mergeOptions)),
+				newPlan("db-seq", expandInDBSeq.NewStage(expandOptions), groupInDB.NewStage(groupOptions), mergeInDB.NewStage(mergeOptions)),
+			)
+		}
+
+		candidates = append(candidates,
+			newPlan("hybrid", expandInDBDepth.NewStage(expandOptions), groupLocally.NewStage(groupOptions), mergeLocally.NewStage(mergeOptions)),
+		)
+	}
+
+	// Plan can be forced by the option
+	plans := make(plans, 0)
+	for _, p := range candidates {
+		if opts.Plan == "auto" || opts.Plan == p.Name {
+			plans = append(plans, p)
+		}
+	}
+
+	return plans
+}
+
+// String formats are counted in database by $regexMatch (MongoDB 4.2+).
+func groupInDBAvailable(server driver.BuildInfo, opts *Options) bool {
+	return !opts.StringFormats || server.VersionAtLeast(analysis.RegexMatchMinVersion...)
+}
+
+// Fields are filtered in database by $regexMatch (MongoDB 4.2+), if patterns contain partial wildcards.
+func expandInDBAvailable(server driver.BuildInfo, expandOptions *expand.Options) bool {
+	return expandOptions.Fields == nil || !expandOptions.Fields.NeedsRegexMatch() || server.VersionAtLeast(analysis.RegexMatchMinVersion...)
+}
+
+// IsInDBPlan returns true if the plan runs at least the expand stage in database.
+func IsInDBPlan(name string) bool {
+	return name != "auto" && name != "local"
+}
+
+// IsGroupInDBPlan returns true if the plan runs the group stage in database.
+func IsGroupInDBPlan(name string) bool {
+	return name == "db" || name == "db-seq"
+}
@@ -0,0 +1,344 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"github.com/mongoeye/mongoeye/source"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPlans_Sort(t *testing.T) {
+	plans := make(plans, 0)
+
+	d1, _ := time.ParseDuration("21ms")
+	d2, _ := time.ParseDuration("36ms")
+	d3, _ := time.ParseDuration("14ms")
+	p1 := &plan{TestDuration: d1}
+	p2 := &plan{TestDuration: d2}
+	p3 := &plan{TestDuration: d3}
+
+	plans = append(plans, p1, p2, p3)
+	sort.Sort(plans)
+
+	assert.Equal(t, p3, plans[0])
+	assert.Equal(t, p1, plans[1])
+	assert.Equal(t, p2, plans[2])
+}
+
+func TestGenerateAnalysisPlans_Db(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Plan = "db"
+
+	server := driver.BuildInfo{
+		Version:      "3.6.0",
+		VersionArray: []int{3, 6, 0, 0},
+	}
+
+	plans := generatePlans(server, 1, opts, false)
+
+	assert.Equal(t, 1, len(plans))
+	assert.Equal(t, "db", plans[0].Name)
+}
+
+func TestGenerateAnalysisPlans_Local(t *testing.T) {
+	opts := DefaultOptions()
+
+	server := driver.BuildInfo{
+		Version:      "3.2.0",
+		VersionArray: []int{3, 2, 0, 0},
+	}
+
+	plans := generatePlans(server, 1, opts, false)
+
+	assert.Equal(t, 1, len(plans))
+	assert.Equal(t, "local", plans[0].Name)
+}
+
+func TestGenerateAnalysisPlans_Db_InadequateVersion(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Plan = "db"
+
+	server := driver.BuildInfo{
+		Version:      "3.2.0",
+		VersionArray: []int{3, 2, 0, 0},
+	}
+
+	plans := generatePlans(server, 1, opts, false)
+	assert.Equal(t, 0, len(plans))
+}
+
+func TestPlans_Limit(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SampleMethod = "first"
+	opts.Limit = 20
+
+	server := driver.BuildInfo{
+		Version:      "3.6.0",
+		VersionArray: []int{3, 6, 0, 0},
+	}
+
+	plans := generatePlans(server, 30, opts, false)
+	sampleStage := plans[0].SampleStage
+	p, err := sampleStage.PipelineFactory(opts.CreateAnalysisOptions())
+	assert.Nil(t, err)
+	pipeline := p.GetStages()
+	assert.Equal(t, 2, len(pipeline))
+	assert.NotEqual(t, nil, pipeline[0]["$sort"])
+	assert.NotEqual(t, nil, pipeline[1]["$limit"])
+}
+
+func TestPlans_LimitOptimization(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SampleMethod = "first"
+	opts.Limit = 20
+
+	server := driver.BuildInfo{
+		Version:      "3.6.0",
+		VersionArray: []int{3, 6, 0, 0},
+	}
+
+	plans := generatePlans(server, 10, opts, false)
+	sampleStage := plans[0].SampleStage
+	p, err := sampleStage.PipelineFactory(opts.CreateAnalysisOptions())
+	assert.Nil(t, err)
+	pipeline := p.GetStages()
+	assert.Equal(t, 0, len(pipeline))
+}
+
+func TestGenerateAnalysisPlans_Dump(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SampleMethod = "first"
+	opts.Limit = 5
+
+	plans := generatePlans(driver.BuildInfo{}, 10, opts, true)
+
+	assert.Equal(t, 1, len(plans))
+	assert.Equal(t, "local", plans[0].Name)
+	assert.Nil(t, plans[0].SampleStage.PipelineFactory)
+	assert.NotNil(t, plans[0].SampleStage.Processor)
+}
+
+func TestGenerateAnalysisPlans_Auto(t *testing.T) {
+	opts := DefaultOptions()
+
+	server := driver.BuildInfo{
+		Version:      "3.6.0",
+		VersionArray: []int{3, 6, 0, 0},
+	}
+
+	plans := generatePlans(server, 1, opts, false)
+
+	names := []string{}
+	for _, p := range plans {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"local", "db", "db-seq", "hybrid"}, names)
+}
+
+func TestGenerateAnalysisPlans_AutoFull(t *testing.T) {
+	opts := DefaultOptions()
+	setFull(opts)
+
+	server := driver.BuildInfo{
+		Version:      "7.0.0",
+		VersionArray: []int{7, 0, 0, 0},
+	}
+
+	names := []string{}
+	for _, p := range generatePlans(server, 1, opts, false) {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"local", "db", "db-seq", "hybrid"}, names)
+}
+
+func TestGenerateAnalysisPlans_AutoLocalOnly(t *testing.T) {
+	server := driver.BuildInfo{
+		Version:      "7.0.0",
+		VersionArray: []int{7, 0, 0, 0},
+	}
+
+	cases := map[string]func(o *Options){
+		"map-min-keys":    func(o *Options) { o.MapMinKeys = 3 },
+		"map-max-keys":    func(o *Options) { o.MapMaxKeys = 100 },
+		"map-paths":       func(o *Options) { o.MapPaths = []string{"stats"} },
+		"shapes":          func(o *Options) { o.Shapes = 5 },
+		"array-stats":     func(o *Options) { o.ArrayStats = true },
+		"array-positions": func(o *Options) { o.ArrayPositions = 2 },
+	}
+
+	for name, set := range cases {
+		opts := DefaultOptions()
+		set(opts)
+
+		plans := generatePlans(server, 1, opts, false)
+		assert.Equal(t, 1, len(plans), name)
+		assert.Equal(t, "local", plans[0].Name, name)
+
+		// Only one candidate, benchmark is skipped
+		p, reason, err := choosePlan(context.Background(), server, 100000, opts, nil, false)
+		assert.Nil(t, err)
+		assert.Equal(t, "local", p.Name)
+		assert.Equal(t, "the only available plan", reason)
+	}
+}
+
+func TestGenerateAnalysisPlans_Auto_Unavailable(t *testing.T) {
+	server := driver.BuildInfo{
+		Version:      "4.0.0",
+		VersionArray: []int{4, 0, 0, 0},
+	}
+
+	cases := []struct {
+		name     string
+		set      func(o *Options)
+		expected []string
+	}{
+		{"shapes", func(o *Options) { o.Shapes = 5 }, []string{"local"}},
+		{"full", setFull, []string{"local", "hybrid"}},
+		{"array-stats", func(o *Options) { o.ArrayStats = true }, []string{"local"}},
+		{"array-positions", func(o *Options) { o.ArrayPositions = 2 }, []string{"local"}},
+		{"string-formats", func(o *Options) { o.StringFormats = true }, []string{"local", "hybrid"}},
+		{"exclude-fields tmp*", func(o *Options) { o.ExcludeFields = []string{"tmp*"} }, []string{"local"}},
+		{"exclude-fields **.tmp", func(o *Options) { o.ExcludeFields = []string{"**.tmp"} }, []string{"local", "db", "db-seq", "hybrid"}},
+	}
+
+	for _, c := range cases {
+		opts := DefaultOptions()
+		c.set(opts)
+
+		names := []string{}
+		for _, p := range generatePlans(server, 1, opts, false) {
+			names = append(names, p.Name)
+		}
+		assert.Equal(t, c.expected, names, c.name)
+	}
+}
+
+func TestGenerateAnalysisPlans_Stages(t *testing.T) {
+	server := driver.BuildInfo{
+		Version:      "3.6.0",
+		VersionArray: []int{3, 6, 0, 0},
+	}
+
+	// Each plan: expand in database, group in database
+	cases := map[string][2]bool{
+		"local":  {false, false},
+		"db":     {true, true},
+		"db-seq": {true, true},
+		"hybrid": {true, false},
+	}
+
+	for name, inDB := range cases {
+		opts := DefaultOptions()
+		opts.Plan = name
+
+		plans := generatePlans(server, 1, opts, false)
+		assert.Equal(t, 1, len(plans), name)
+		assert.Equal(t, name, plans[0].Name)
+		assert.Equal(t, inDB[0], plans[0].ExpandStage.PipelineFactory != nil, name)
+		assert.Equal(t, inDB[1], plans[0].GroupStage.PipelineFactory != nil, name)
+		assert.Equal(t, inDB[1], plans[0].MergeStage.PipelineFactory != nil, name)
+	}
+}
+
+func TestPlans_Benchmark(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Plan = "local"
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+
+	src, _ := source.NewDocuments(bson.M{"a": 1}, bson.M{"a": "b"})
+	p1 := generatePlans(driver.BuildInfo{}, 2, opts, true)[0]
+	p2 := generatePlans(driver.BuildInfo{}, 2, opts, true)[0]
+	p1.Name = "first"
+	p2.Name = "second"
+
+	plans := plans{p1, p2}
+	err := plans.Benchmark(context.Background(), src)
+	assert.Nil(t, err)
+
+	assert.Nil(t, p1.TestError)
+	assert.Nil(t, p2.TestError)
+	assert.True(t, p1.TestDuration > 0)
+	assert.True(t, p2.TestDuration > 0)
+	assert.True(t, plans[0].TestDuration <= plans[1].TestDuration)
+}
+
+func TestPlans_Benchmark_Canceled(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	src, _ := source.NewDocuments(bson.M{"a": 1})
+	plans := generatePlans(driver.BuildInfo{}, 1, opts, true)
+	assert.Equal(t, context.Canceled, plans.Benchmark(ctx, src))
+}
+
+func TestPlans_Durations(t *testing.T) {
+	plans := plans{
+		&plan{Name: "local", TestDuration: 12 * time.Millisecond},
+		&plan{Name: "db", TestDuration: ProbeMaxTime, TestError: context.DeadlineExceeded},
+		&plan{Name: "other", TestError: errors.New("error")},
+	}
+
+	assert.Equal(t, "local 0.012s, db >5s, other failed", plans.Durations())
+}
+
+func TestChoosePlan(t *testing.T) {
+	server := driver.BuildInfo{
+		Version:      "3.6.0",
+		VersionArray: []int{3, 6, 0, 0},
+	}
+
+	choose := func(count int, offline bool, set func(o *Options)) (*plan, string, error) {
+		opts := DefaultOptions()
+		set(opts)
+
+		src, _ := source.NewDocuments(bson.M{"a": 1})
+		return choosePlan(context.Background(), server, count, opts, src, offline)
+	}
+
+	p, reason, err := choose(5000, false, func(o *Options) { o.Plan = "db" })
+	assert.Nil(t, err)
+	assert.Equal(t, "db", p.Name)
+	assert.Equal(t, "set by 'plan' option", reason)
+
+	p, reason, err = choose(5000, true, func(o *Options) {})
+	assert.Nil(t, err)
+	assert.Equal(t, "local", p.Name)
+	assert.Equal(t, "the only available plan", reason)
+
+	p, reason, err = choose(5000, false, func(o *Options) {
+		o.SampleMethod = "first"
+		o.Limit = 1000
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "local", p.Name)
+	assert.Equal(t, "sample of 1000 docs is too small for benchmark", reason)
+
+	_, _, err = choose(5000, true, func(o *Options) { o.Plan = "db" })
+	assert.NotNil(t, err)
+}
+
+// setFull enables all available analyzes, as --full flag of command line interface.
+func setFull(o *Options) {
+	o.MinMaxAvgValue = true
+	o.MinMaxAvgLength = true
+	o.Quantiles = true
+	o.ValueHistogram = true
+	o.LengthHistogram = true
+	o.WeekdayHistogram = true
+	o.HourHistogram = true
+	o.StringFormats = true
+	o.CountUnique = true
+	o.MostFrequentValues = 20
+	o.LeastFrequentValues = 20
+}
@@ -0,0 +1,31 @@
+package analyzer
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"time"
+)
+
+// Result of analysis.
+type Result struct {
+	Database           string          `json:"database"                 yaml:"database"`
+	Collection         string          `json:"collection"               yaml:"collection"`
+	Plan               string          `json:"plan"                     yaml:"plan"`
+	PlanReason         string          `json:"-"                        yaml:"-"` // why the plan was chosen, printed in the footer
+	Duration           time.Duration   `json:"duration"                 yaml:"duration"`
+	AllDocsCount       uint64          `json:"allDocs"                  yaml:"allDocs"`
+	DocsCount          uint64          `json:"analyzedDocs"             yaml:"analyzedDocs"`
+	CorruptedDocsCount uint64          `json:"corruptedDocs,omitempty"  yaml:"corruptedDocs,omitempty"`
+	Limits             *Limits         `json:"limits,omitempty"         yaml:"limits,omitempty"`
+	FieldsCount        uint64          `json:"fieldsCount"              yaml:"fieldsCount"`
+	Fields             analysis.Fields `json:"fields"                   yaml:"fields"`
+	ShapesCount        uint64          `json:"shapesCount,omitempty"    yaml:"shapesCount,omitempty"`
+	Shapes             analysis.Shapes `json:"shapes,omitempty"         yaml:"shapes,omitempty"`
+}
+
+// Limits of expansion used by the analysis, overrides are listed by path.
+type Limits struct {
+	Depth          uint            `json:"depth"                       yaml:"depth"`
+	DepthFor       map[string]uint `json:"depthFor,omitempty"          yaml:"depthFor,omitempty"`
+	ArrayMaxLength uint            `json:"arrayMaxLength"              yaml:"arrayMaxLength"`
+	ArrayMaxFor    map[string]uint `json:"arrayMaxLengthFor,omitempty" yaml:"arrayMaxLengthFor,omitempty"`
+}
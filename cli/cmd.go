@@ -14,7 +14,6 @@ func NewCmd(cmdName string, envPrefix string, appName string, appVersion string,
 	// Viper = Configuration store
 	v := viper.New()
 	v.Set("cmdName", cmdName)
-	v.Set("envPrefix", envPrefix)
 	v.Set("appName", appName)
 	v.Set("appVersion", appVersion)
 	v.Set("appSubtitle", appSubtitle)
@@ -4,166 +4,68 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/mongoeye/mongoeye/analysis"
-	"github.com/mongoeye/mongoeye/analysis/stages/01sample"
-	"github.com/mongoeye/mongoeye/analysis/stages/02expand"
-	"github.com/mongoeye/mongoeye/analysis/stages/03group"
-	"github.com/mongoeye/mongoeye/analysis/stages/04merge"
+	"github.com/mongoeye/mongoeye/analyzer"
 	"github.com/mongoeye/mongoeye/helpers"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"github.com/mongoeye/mongoeye/source"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
-	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
-	"runtime"
+	"os"
+	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// Config - app configuration.
+// Config - app configuration, options of analysis are embedded.
 type Config struct {
+	analyzer.Options
+
 	// connection options
-	ConnectionMode    mgo.Mode
-	ConnectionTimeout time.Duration
-	SyncTimeout       time.Duration
-	SocketTimeout     time.Duration
-	Host              string
-	User              string
-	Password          string
-	AuthDatabase      string
-	AuthMechanism     string
-
-	// analysis options
-	Database     string
-	Collection   string
-	Match        bson.M
-	Project      bson.M
-	SampleMethod string
-	Limit        uint64
-	Depth        uint
-
-	// statistics options
-	MinMaxAvgValue       bool
-	MinMaxAvgLength      bool
-	ValueHistogram       bool
-	ValueHistogramSteps  uint
-	LengthHistogram      bool
-	LengthHistogramSteps uint
-	WeekdayHistogram     bool
-	HourHistogram        bool
-	CountUnique          bool
-	MostFrequentValues   uint
-	LeastFrequentValues  uint
-	Format               string
-	FilePath             string
+	URI                      string // connection string for the driver with options not parsed to other fields
+	ConnectionMode           driver.Mode
+	ConnectionTimeout        time.Duration
+	SyncTimeout              time.Duration
+	SocketTimeout            time.Duration
+	Host                     string // comma separated list of hosts
+	ReplicaSet               string
+	ReadPreferenceTags       []bson.D
+	MaxStaleness             time.Duration // zero = no maximum
+	AppName                  string
+	TLS                      bool
+	TLSCAFile                string
+	TLSCertKeyFile           string
+	TLSAllowInvalidHostnames bool
+	User                     string
+	Password                 string
+	AuthDatabase             string
+	AuthMechanism            string
+
+	// input options
+	AllCollections    bool
+	AllDatabases      bool
+	IncludeNamespaces []string
+	ExcludeNamespaces []string
+	IncludeSystem     bool
+	IncludeViews      bool
+	DumpFiles         []string
+	JsonFiles         []string
+
+	// output options
+	Format      string
+	FilePath    string
+	ApplySchema bool
 
 	// other options
-	Location        *time.Location
-	UseAggregation  bool
-	StringMaxLength uint
-	ArrayMaxLength  uint
-	Concurrency     uint
-	BufferSize      uint
-	BatchSize       uint
-	NoColor         bool
-}
-
-// CreateAnalysisOptions generates analysis options from config.
-func (c *Config) CreateAnalysisOptions() *analysis.Options {
-	concurrency := int(c.Concurrency)
-	if concurrency == 0 {
-		concurrency = runtime.NumCPU()
-	}
-
-	return &analysis.Options{
-		Location:    c.Location,
-		Concurrency: concurrency,
-		BufferSize:  int(c.BufferSize),
-		BatchSize:   int(c.BatchSize),
-	}
-}
-
-// CreateSampleStageOptions generates sample options from config.
-func (c *Config) CreateSampleStageOptions() *sample.Options {
-	var sampleMethod sample.SampleMethod
-	switch c.SampleMethod {
-	case "all":
-		sampleMethod = sample.AllDocuments
-	case "first":
-		sampleMethod = sample.FirstNDocuments
-	case "last":
-		sampleMethod = sample.LastNDocuments
-	case "random":
-		sampleMethod = sample.RandomNDocuments
-	default:
-		panic("Unexpected sample.")
-	}
-
-	return &sample.Options{
-		Match:   c.Match,
-		Project: c.Project,
-		Method:  sampleMethod,
-		Limit:   uint64(c.Limit),
-	}
-}
-
-// CreateExpandStageOptions generates expand options from config.
-func (c *Config) CreateExpandStageOptions() *expand.Options {
-	return &expand.Options{
-		StringMaxLength: c.StringMaxLength,
-		ArrayMaxLength:  c.ArrayMaxLength,
-		MaxDepth:        c.Depth,
-		StoreValue: c.MinMaxAvgValue ||
-			c.CountUnique ||
-			c.MostFrequentValues > 0 ||
-			c.LeastFrequentValues > 0 ||
-			c.WeekdayHistogram ||
-			c.HourHistogram ||
-			c.ValueHistogram,
-		StoreStringLength: c.MinMaxAvgLength || c.LengthHistogram,
-		StoreArrayLength:  c.MinMaxAvgLength || c.LengthHistogram,
-		StoreObjectLength: c.MinMaxAvgLength || c.LengthHistogram,
-	}
-}
-
-// CreateGroupStageOptions generates group options from config.
-func (c *Config) CreateGroupStageOptions() *group.Options {
-	options := &group.Options{
-		ProcessObjectIdAsDate: true,
-		StoreMinMaxAvgValue:   c.MinMaxAvgValue,
-		StoreMinMaxAvgLength:  c.MinMaxAvgLength,
-		StoreCountOfUnique:    c.CountUnique,
-		StoreMostFrequent:     c.MostFrequentValues,
-		StoreLeastFrequent:    c.LeastFrequentValues,
-		StoreWeekdayHistogram: c.WeekdayHistogram,
-		StoreHourHistogram:    c.HourHistogram,
-		ValueHistogramMaxRes:  0,
-		LengthHistogramMaxRes: 0,
-	}
-
-	if c.ValueHistogram {
-		options.ValueHistogramMaxRes = c.ValueHistogramSteps
-	}
-
-	if c.LengthHistogram {
-		options.LengthHistogramMaxRes = c.LengthHistogramSteps
-	}
-
-	return options
-}
-
-// CreateMergeStageOptions generates merge options from config.
-func (c *Config) CreateMergeStageOptions() *merge.Options {
-	return &merge.Options{}
+	Parallel uint
+	MaxTime  time.Duration
+	NoColor  bool
 }
 
 // GetConfig - returns configuration according Viper values.
 func GetConfig(v *viper.Viper) (*Config, error) {
-	// Connection mode
-	connectionMode, err := parseConnectionMode(v)
-	if err != nil {
-		return nil, err
-	}
-
 	// Parse sample
 	sampleMethod, limit, err := parseSample(v)
 	if err != nil {
@@ -188,55 +90,102 @@ func GetConfig(v *viper.Viper) (*Config, error) {
 		return nil, err
 	}
 
+	// Parse per-path limits
+	depthFor, err := parsePathLimits(v, "depth-for")
+	if err != nil {
+		return nil, err
+	}
+
+	arrayMaxFor, err := parsePathLimits(v, "array-max-for")
+	if err != nil {
+		return nil, err
+	}
+
 	// Create config
 	config := &Config{
-		ConnectionMode:       connectionMode,
-		ConnectionTimeout:    time.Duration(v.GetFloat64("connection-timeout") * float64(time.Second)),
-		SocketTimeout:        time.Duration(v.GetFloat64("socket-timeout") * float64(time.Second)),
-		SyncTimeout:          time.Duration(v.GetFloat64("sync-timeout") * float64(time.Second)),
-		Host:                 v.GetString("host"),
-		User:                 v.GetString("user"),
-		Password:             v.GetString("password"),
-		AuthDatabase:         v.GetString("auth-db"),
-		AuthMechanism:        v.GetString("auth-mech"),
-		Database:             v.GetString("db"),
-		Collection:           v.GetString("col"),
-		Match:                match,
-		Project:              project,
-		SampleMethod:         sampleMethod,
-		Limit:                limit,
-		Depth:                uint(v.GetInt("depth")),
-		MinMaxAvgValue:       v.GetBool("value"),
-		MinMaxAvgLength:      v.GetBool("length"),
-		ValueHistogram:       v.GetBool("value-hist"),
-		ValueHistogramSteps:  uint(v.GetInt("value-hist-steps")),
-		LengthHistogram:      v.GetBool("length-hist"),
-		LengthHistogramSteps: uint(v.GetInt("length-hist-steps")),
-		WeekdayHistogram:     v.GetBool("weekday-hist"),
-		HourHistogram:        v.GetBool("hour-hist"),
-		CountUnique:          v.GetBool("count-unique"),
-		MostFrequentValues:   uint(v.GetInt("most-freq")),
-		LeastFrequentValues:  uint(v.GetInt("least-freq")),
-		Format:               v.GetString("format"),
-		FilePath:             v.GetString("file"),
-		Location:             location,
-		UseAggregation:       v.GetBool("use-aggregation"),
-		StringMaxLength:      uint(v.GetInt("string-max-length")),
-		ArrayMaxLength:       uint(v.GetInt("array-max-length")),
-		Concurrency:          uint(v.GetInt("concurrency")),
-		BufferSize:           uint(v.GetInt("buffer")),
-		BatchSize:            uint(v.GetInt("batch")),
-		NoColor:              v.GetBool("no-color"),
+		Options: analyzer.Options{
+			Database:             v.GetString("db"),
+			Collection:           v.GetString("col"),
+			Match:                match,
+			Project:              project,
+			SampleMethod:         sampleMethod,
+			Limit:                limit,
+			Depth:                uint(v.GetInt("depth")),
+			DepthFor:             depthFor,
+			MapMinKeys:           uint(v.GetInt("map-min-keys")),
+			MapMaxKeys:           uint(v.GetInt("map-max-keys")),
+			MapPaths:             v.GetStringSlice("map-paths"),
+			NoMapPaths:           v.GetStringSlice("no-map-paths"),
+			IncludeFields:        v.GetStringSlice("include-fields"),
+			ExcludeFields:        v.GetStringSlice("exclude-fields"),
+			MinMaxAvgValue:       v.GetBool("value"),
+			MinMaxAvgLength:      v.GetBool("length"),
+			Quantiles:            v.GetBool("quantiles"),
+			ValueHistogram:       v.GetBool("value-hist"),
+			ValueHistogramSteps:  uint(v.GetInt("value-hist-steps")),
+			LengthHistogram:      v.GetBool("length-hist"),
+			LengthHistogramSteps: uint(v.GetInt("length-hist-steps")),
+			WeekdayHistogram:     v.GetBool("weekday-hist"),
+			HourHistogram:        v.GetBool("hour-hist"),
+			StringFormats:        v.GetBool("string-formats"),
+			CountUnique:          v.GetBool("count-unique"),
+			UniqueMethod:         v.GetString("unique-method"),
+			UniqueMemory:         uint(v.GetInt("unique-memory")),
+			MostFrequentValues:   uint(v.GetInt("most-freq")),
+			LeastFrequentValues:  uint(v.GetInt("least-freq")),
+			Shapes:               uint(v.GetInt("shapes")),
+			ArrayStats:           v.GetBool("array-stats"),
+			ArrayPositions:       uint(v.GetInt("array-positions")),
+			Location:             location,
+			Plan:                 strings.ToLower(v.GetString("plan")),
+			StringMaxLength:      uint(v.GetInt("string-max-length")),
+			ArrayMaxLength:       uint(v.GetInt("array-max-length")),
+			ArrayMaxFor:          arrayMaxFor,
+			Concurrency:          uint(v.GetInt("concurrency")),
+			BufferSize:           uint(v.GetInt("buffer")),
+			BatchSize:            uint(v.GetInt("batch")),
+			SkipCorrupted:        v.GetBool("skip-corrupted"),
+		},
+		ConnectionTimeout: time.Duration(v.GetFloat64("connection-timeout") * float64(time.Second)),
+		SocketTimeout:     time.Duration(v.GetFloat64("socket-timeout") * float64(time.Second)),
+		SyncTimeout:       time.Duration(v.GetFloat64("sync-timeout") * float64(time.Second)),
+		Host:              v.GetString("host"),
+		User:              v.GetString("user"),
+		Password:          v.GetString("password"),
+		AuthDatabase:      v.GetString("auth-db"),
+		AuthMechanism:     v.GetString("auth-mech"),
+		DumpFiles:         v.GetStringSlice("dump"),
+		JsonFiles:         v.GetStringSlice("json"),
+		AllCollections:    v.GetBool("all-collections"),
+		AllDatabases:      v.GetBool("all-databases"),
+		IncludeNamespaces: v.GetStringSlice("include-ns"),
+		ExcludeNamespaces: v.GetStringSlice("exclude-ns"),
+		IncludeSystem:     v.GetBool("include-system"),
+		IncludeViews:      v.GetBool("include-views"),
+		Format:            v.GetString("format"),
+		ApplySchema:       v.GetBool("apply-schema"),
+		FilePath:          v.GetString("file"),
+		Parallel:          uint(v.GetInt("parallel")),
+		MaxTime:           time.Duration(v.GetFloat64("max-time") * float64(time.Second)),
+		NoColor:           v.GetBool("no-color"),
+	}
+
+	// Connection string and connection mode
+	err = parseConnection(v, config)
+	if err != nil {
+		return nil, err
 	}
 
 	// --full = perform all available analyzes
 	if v.GetBool("full") {
 		config.MinMaxAvgValue = true
 		config.MinMaxAvgLength = true
+		config.Quantiles = true
 		config.ValueHistogram = true
 		config.LengthHistogram = true
 		config.WeekdayHistogram = true
 		config.HourHistogram = true
+		config.StringFormats = true
 		config.CountUnique = true
 
 		if config.MostFrequentValues == 0 {
@@ -248,12 +197,19 @@ func GetConfig(v *viper.Viper) (*Config, error) {
 		}
 	}
 
+	// Default database and collection name to the name of the input file
+	if len(config.DumpFiles) > 0 {
+		setFileNames(config, config.DumpFiles[0], ".bson")
+	} else if len(config.JsonFiles) > 0 {
+		setFileNames(config, config.JsonFiles[0], ".json")
+	}
+
 	// Default auth database to working database
 	if config.AuthDatabase == "" {
 		config.AuthDatabase = config.Database
 	}
 
-	err = config.validate()
+	err = config.Validate()
 	if err != nil {
 		return nil, err
 	}
@@ -261,24 +217,146 @@ func GetConfig(v *viper.Viper) (*Config, error) {
 	return config, nil
 }
 
-func parseConnectionMode(v *viper.Viper) (mode mgo.Mode, err error) {
-	switch strings.ToLower(v.GetString("connection-mode")) {
+// HasFileInput returns true if documents are read from files instead of database.
+func (c *Config) HasFileInput() bool {
+	return len(c.DumpFiles) > 0 || len(c.JsonFiles) > 0
+}
+
+// HasMoreCollections returns true if more collections are analyzed in one run.
+func (c *Config) HasMoreCollections() bool {
+	return c.AllCollections || c.AllDatabases
+}
+
+func setFileNames(config *Config, path string, ext string) {
+	if path == source.StdinPath {
+		return
+	}
+
+	if config.Collection == "" {
+		name := filepath.Base(path)
+		name = strings.TrimSuffix(name, ".gz")
+		name = strings.TrimSuffix(name, ext)
+		config.Collection = name
+	}
+
+	if config.Database == "" {
+		config.Database = filepath.Base(filepath.Dir(path))
+	}
+}
+
+// Parse connection string and connection mode.
+// Options set by flags or environment variables override parts of the connection string.
+// Options not used by mongoeye are kept in the URI, so they are passed to the driver.
+func parseConnection(v *viper.Viper, config *Config) (err error) {
+	mode := v.GetString("connection-mode")
+	config.TLS = v.GetBool("tls")
+	config.TLSCAFile = v.GetString("tls-ca-file")
+	config.TLSCertKeyFile = v.GetString("tls-cert-key-file")
+	config.TLSAllowInvalidHostnames = v.GetBool("tls-allow-invalid-hostnames")
+
+	// Connection string can be also used as host
+	uri := v.GetString("uri")
+	if uri == "" && IsConnectionString(config.Host) {
+		uri, config.Host = config.Host, ""
+	}
+
+	var cs *ConnectionString
+	if uri != "" {
+		cs, err = ParseURI(uri, DefaultResolver)
+		if err != nil {
+			return
+		}
+
+		authSource := cs.AuthSource
+		if authSource == "" {
+			authSource = cs.Database
+		}
+
+		setIfEmpty(&config.Host, strings.Join(cs.Hosts, ","))
+		setIfEmpty(&config.User, cs.User)
+		setIfEmpty(&config.Password, cs.Password)
+		setIfEmpty(&config.AuthDatabase, authSource)
+		setIfEmpty(&config.AuthMechanism, cs.AuthMechanism)
+		setIfEmpty(&config.Database, cs.Database)
+		setIfEmpty(&mode, cs.ReadPreference)
+		config.ReplicaSet = cs.ReplicaSet
+		config.ReadPreferenceTags = cs.ReadPreferenceTags
+		config.MaxStaleness = cs.MaxStaleness
+		config.AppName = cs.AppName
+		setIfEmpty(&config.TLSCAFile, cs.TLSCAFile)
+		setIfEmpty(&config.TLSCertKeyFile, cs.TLSCertKeyFile)
+
+		// Flags with default values are used only if they are set explicitly
+		if !isExplicit(v, "tls") {
+			config.TLS = cs.TLS
+		}
+		if !isExplicit(v, "tls-allow-invalid-hostnames") {
+			config.TLSAllowInvalidHostnames = cs.TLSAllowInvalidHostnames
+		}
+		if cs.ConnectTimeout > 0 && !isExplicit(v, "connection-timeout") {
+			config.ConnectionTimeout = cs.ConnectTimeout
+		}
+		if cs.ServerSelectionTimeout > 0 && !isExplicit(v, "sync-timeout") {
+			config.SyncTimeout = cs.ServerSelectionTimeout
+		}
+		if cs.SocketTimeout > 0 && !isExplicit(v, "socket-timeout") {
+			config.SocketTimeout = cs.SocketTimeout
+		}
+	}
+
+	// Defaults, username for MONGODB-X509 is taken by the server from the client certificate
+	setIfEmpty(&config.Host, "localhost:27017")
+	if config.AuthMechanism != X509Mechanism {
+		setIfEmpty(&config.User, "admin")
+	}
+	setIfEmpty(&mode, "SecondaryPreferred")
+
+	// Connection string for the driver, parsed options are replaced by the other fields of config
+	if cs != nil {
+		config.URI = uriScheme + config.Host + "/"
+		if len(cs.Options) > 0 {
+			config.URI += "?" + strings.Join(cs.Options, "&")
+		}
+	}
+
+	config.ConnectionMode, err = parseConnectionMode(mode)
+	return
+}
+
+// Flag is explicit if it is set on the command line or by the environment variable.
+func isExplicit(v *viper.Viper, name string) bool {
+	if flags, ok := v.Get("flagSet").(*pflag.FlagSet); ok && flags.Changed(name) {
+		return true
+	}
+
+	_, found := os.LookupEnv(strings.ToUpper(v.GetString("envPrefix") + "_" + name))
+	return found
+}
+
+func setIfEmpty(target *string, value string) {
+	if *target == "" {
+		*target = value
+	}
+}
+
+func parseConnectionMode(name string) (mode driver.Mode, err error) {
+	switch strings.ToLower(name) {
 	case "primary":
-		mode = mgo.Primary
+		mode = driver.Primary
 	case "primarypreferred":
-		mode = mgo.PrimaryPreferred
+		mode = driver.PrimaryPreferred
 	case "secondary":
-		mode = mgo.Secondary
+		mode = driver.Secondary
 	case "secondarypreferred":
-		mode = mgo.SecondaryPreferred
+		mode = driver.SecondaryPreferred
 	case "nearest":
-		mode = mgo.Nearest
+		mode = driver.Nearest
 	case "eventual":
-		mode = mgo.Eventual
+		mode = driver.Eventual
 	case "monotonic":
-		mode = mgo.Monotonic
+		mode = driver.Monotonic
 	case "strong":
-		mode = mgo.Strong
+		mode = driver.Strong
 	default:
 		err = errors.New(
 			"Invalid value in 'connection-mode' option. Allowed values: 'Primary', 'PrimaryPreferred', 'Secondary', 'SecondaryPreferred', 'Nearest', 'Eventual', 'Monotonic', 'Strong'.",
@@ -320,6 +398,26 @@ func parseLocation(v *viper.Viper) (location *time.Location, err error) {
 	return
 }
 
+// Parse limits in format path=N, eg. "order.items=6".
+func parsePathLimits(v *viper.Viper, argument string) (map[string]uint, error) {
+	out := map[string]uint{}
+	for _, item := range v.GetStringSlice(argument) {
+		i := strings.LastIndex(item, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("Invalid value '%s' of '%s' option.\nPlease enter a path and a limit, eg. 'order.items=6'.", item, argument)
+		}
+
+		limit, err := strconv.ParseUint(item[i+1:], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value '%s' of '%s' option.\nPlease enter a path and a limit, eg. 'order.items=6'.", item, argument)
+		}
+
+		out[item[:i]] = uint(limit)
+	}
+
+	return out, nil
+}
+
 func parseJsonArgument(v *viper.Viper, argument string) (out bson.M, err error) {
 	out = bson.M{}
 	raw := v.GetString(argument)
@@ -333,42 +431,83 @@ func parseJsonArgument(v *viper.Viper, argument string) (out bson.M, err error)
 	return
 }
 
-func (c *Config) validate() error {
-	if !helpers.InStringSlice(c.SampleMethod, []string{"all", "first", "last", "random"}) {
+// Validate checks values of configuration.
+func (c *Config) Validate() error {
+	if err := c.Options.Validate(); err != nil {
+		return err
+	}
+
+	if c.MaxTime < 0 {
 		return errors.New(
-			"Invalid value of 'sample' option.\nAllowed values are: 'all', 'first:N', 'last:N', 'random:N'.",
+			"Option 'max-time' must be >= 0",
 		)
 	}
 
-	if c.SampleMethod != "all" && c.Limit < 1 {
+	if len(c.DumpFiles) > 0 && len(c.JsonFiles) > 0 {
 		return errors.New(
-			"Limit (N) in 'sample' option must be >= 1.",
+			"Options 'dump' and 'json' cannot be used together.",
+		)
+	}
+
+	if c.HasFileInput() && analyzer.IsInDBPlan(c.Plan) {
+		return fmt.Errorf(
+			"Plan '%s' cannot be used together with 'dump' or 'json' option.", c.Plan,
 		)
 	}
 
-	if c.ValueHistogramSteps < 3 {
+	if !helpers.InStringSlice(c.Format, []string{"table", "json", "yaml", "jsonschema"}) {
 		return errors.New(
-			"Option 'value-histogram-steps' must be >= 3",
+			"Invalid value of 'format' option.\nAllowed values are: 'table', 'json', 'yaml', 'jsonschema'.",
 		)
 	}
 
-	if c.LengthHistogramSteps < 3 {
+	if c.HasFileInput() && c.ApplySchema {
 		return errors.New(
-			"Option 'length-histogram-max-steps' must be >= 3",
+			"Option 'apply-schema' cannot be used together with 'dump' or 'json' option.",
 		)
 	}
 
-	if c.BatchSize < 1 {
+	if !c.TLS && (c.TLSCAFile != "" || c.TLSCertKeyFile != "" || c.TLSAllowInvalidHostnames) {
 		return errors.New(
-			"Option 'batch-size' must be >= 1",
+			"Options 'tls-ca-file', 'tls-cert-key-file' and 'tls-allow-invalid-hostnames' require 'tls' option.",
 		)
 	}
 
-	if !helpers.InStringSlice(c.Format, []string{"table", "json", "yaml"}) {
+	if c.AuthMechanism == X509Mechanism && (!c.TLS || c.TLSCertKeyFile == "") {
 		return errors.New(
-			"Invalid value of 'format' option.\nAllowed values are: 'table', 'json', 'yaml'.",
+			"Authentication mechanism 'MONGODB-X509' requires 'tls' and 'tls-cert-key-file' options.",
 		)
 	}
 
+	if c.HasMoreCollections() {
+		if c.HasFileInput() {
+			return errors.New(
+				"Options 'all-collections' and 'all-databases' cannot be used together with 'dump' or 'json' option.",
+			)
+		}
+
+		if c.ApplySchema || c.Format == "jsonschema" {
+			return errors.New(
+				"Options 'all-collections' and 'all-databases' cannot be used together with 'apply-schema' option or 'jsonschema' format.",
+			)
+		}
+
+		if c.Parallel < 1 {
+			return errors.New(
+				"Option 'parallel' must be >= 1",
+			)
+		}
+	}
+
+	for _, patterns := range [][]string{c.IncludeNamespaces, c.ExcludeNamespaces} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf(
+					"Invalid pattern '%s' in 'include-ns' or 'exclude-ns' option.", pattern,
+				)
+			}
+		}
+	}
+
 	return nil
 }
@@ -4,22 +4,59 @@ import (
 	"errors"
 	"fmt"
 	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analyzer"
 	"github.com/mongoeye/mongoeye/helpers"
-	"gopkg.in/mgo.v2"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"github.com/mongoeye/mongoeye/mongo/driver/official"
+	"github.com/mongoeye/mongoeye/source"
+	"net"
 	"strings"
 )
 
 // Connect to MongoDB database and returns server info and session.
-func Connect(config *Config) (info mgo.BuildInfo, session *mgo.Session, collection *mgo.Collection, count int, err error) {
+func Connect(config *Config) (info driver.BuildInfo, session driver.Session, collection driver.Collection, count int, err error) {
+	info, session, err = Dial(config)
+	if err != nil {
+		return
+	}
+
+	// Database and collection
+	collection = session.Collection(config.Database, config.Collection)
+
+	// Count documents in collection
+	count, err = countDocuments(collection, config)
+	if err != nil {
+		return
+	}
+
+	// Check number of documents
+	if count == 0 {
+		err = fmt.Errorf("Collection '%s.%s' does not exist or is empty.\n", collection.Database(), collection.Name())
+		return
+	}
+
+	return info, session, collection, count, nil
+}
+
+// Dial MongoDB server, log in and check compatibility with the server version.
+// The session can be shared by analyses of more collections.
+func Dial(config *Config) (info driver.BuildInfo, session driver.Session, err error) {
+	dialInfo, err := NewDialInfo(config)
+	if err != nil {
+		return
+	}
+
+	credential, err := newCredential(config)
+	if err != nil {
+		return
+	}
+
 	// Session
-	session, err = mgo.DialWithTimeout(config.Host, config.ConnectionTimeout)
+	session, err = official.Dial(dialInfo)
 	if err != nil {
 		err = fmt.Errorf("Connection failed: %s.\n", err)
 		return
 	}
-	session.SetMode(config.ConnectionMode, true)
-	session.SetSyncTimeout(config.SyncTimeout)
-	session.SetSocketTimeout(config.SocketTimeout)
 
 	// Get server info
 	info, err = session.BuildInfo()
@@ -29,56 +66,116 @@ func Connect(config *Config) (info mgo.BuildInfo, session *mgo.Session, collecti
 	}
 
 	// Login
-	useAuth := config.User != "" && config.Password != ""
-	if useAuth {
-		err = session.Login(&mgo.Credential{
-			Username:  config.User,
-			Password:  config.Password,
-			Source:    config.AuthDatabase,
-			Mechanism: config.AuthMechanism,
-		})
+	if credential != nil {
+		err = session.Login(credential)
 		if err != nil {
 			err = errors.New("Failed to authenticate.\nPlease make sure you have entered the correct credentials.\n")
 			return
 		}
 	}
 
-	// Database and collection
-	database := session.DB(config.Database)
-	collection = database.C(config.Collection)
-
 	// Check compatibility
 	err = checkCompatibility(config, info)
 	if err != nil {
 		return
 	}
 
-	// Count documents in collection
+	return info, session, nil
+}
+
+// NewDialInfo creates dial info from config, TLS connections use custom dialer.
+func NewDialInfo(config *Config) (*driver.DialInfo, error) {
+	dialInfo := &driver.DialInfo{
+		Mode:          config.ConnectionMode,
+		Tags:          config.ReadPreferenceTags,
+		MaxStaleness:  config.MaxStaleness,
+		AppName:       config.AppName,
+		Timeout:       config.ConnectionTimeout,
+		SyncTimeout:   config.SyncTimeout,
+		SocketTimeout: config.SocketTimeout,
+		ReplicaSet:    config.ReplicaSet,
+	}
+
+	if config.URI != "" {
+		// Hosts and options not parsed by mongoeye are read from the connection string by the driver
+		dialInfo.URI = config.URI
+	} else {
+		dialInfo.Addrs = strings.Split(config.Host, ",")
+	}
+
+	if config.TLS {
+		tlsConfig, err := NewTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+
+		dialInfo.DialServer = func(addr string) (net.Conn, error) {
+			return dialTLS(addr, tlsConfig, config.ConnectionTimeout)
+		}
+	}
+
+	return dialInfo, nil
+}
+
+// Create credential for login, nil means no authentication.
+// Empty username for MONGODB-X509 means the server takes the subject of the client certificate (MongoDB 3.4+).
+func newCredential(config *Config) (*driver.Credential, error) {
+	if config.AuthMechanism == X509Mechanism {
+		return &driver.Credential{
+			Username:  config.User,
+			Source:    "$external",
+			Mechanism: X509Mechanism,
+		}, nil
+	}
+
+	if config.User == "" || config.Password == "" {
+		return nil, nil
+	}
+
+	return &driver.Credential{
+		Username:  config.User,
+		Password:  config.Password,
+		Source:    config.AuthDatabase,
+		Mechanism: config.AuthMechanism,
+	}, nil
+}
+
+// Count documents in collection.
+func countDocuments(collection driver.Collection, config *Config) (count int, err error) {
 	count, err = collection.Count()
 	if err != nil {
+		useAuth := config.User != "" && config.Password != ""
 		if useAuth && strings.Contains(err.Error(), "not authorized") {
-			err = fmt.Errorf("User '%s' is not authorized to access database '%s'.\nPlease make sure you have entered the correct credentials.\n", config.User, config.Database)
+			err = fmt.Errorf("User '%s' is not authorized to access database '%s'.\nPlease make sure you have entered the correct credentials.\n", config.User, collection.Database())
 		} else {
 			err = fmt.Errorf("Cannot count documents in collection: %s.\n", err)
 		}
-		return
 	}
 
-	// Check number of documents
-	if count == 0 {
-		err = fmt.Errorf("Collection '%s.%s' does not exist or is empty.\n", database.Name, collection.Name)
-		return
+	return
+}
+
+// OpenFiles opens BSON files created by mongodump or JSON files created by mongoexport.
+// Documents are counted while they are analyzed, so compressed files are read only once.
+func OpenFiles(config *Config) (src source.Files, err error) {
+	if len(config.DumpFiles) > 0 {
+		src, err = source.NewBsonFiles(config.DumpFiles)
+	} else {
+		src, err = source.NewJsonFiles(config.JsonFiles)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s\n", err)
 	}
 
-	return info, session, collection, count, nil
+	return src, nil
 }
 
 // Check compatibility between given configuration and MongoDB version
-func checkCompatibility(config *Config, info mgo.BuildInfo) error {
-	// Aggregation framework require MongoDB 3.5.6+
-	if config.UseAggregation && !info.VersionAtLeast(analysis.AggregationMinVersion...) {
+func checkCompatibility(config *Config, info driver.BuildInfo) error {
+	// Aggregation framework require MongoDB 3.5.10+, plan 'auto' falls back to the local analysis
+	if analyzer.IsInDBPlan(config.Plan) && !info.VersionAtLeast(analysis.AggregationMinVersion...) {
 		version := helpers.VersionToString(analysis.AggregationMinVersion...)
-		return fmt.Errorf("Option 'use-aggregation' require MongoDB version >= %s.\n", version)
+		return fmt.Errorf("Plan '%s' require MongoDB version >= %s.\n", config.Plan, version)
 
 	}
 
@@ -89,5 +186,23 @@ func checkCompatibility(config *Config, info mgo.BuildInfo) error {
 
 	}
 
+	// Counting string formats in database require MongoDB 4.2+
+	if analyzer.IsGroupInDBPlan(config.Plan) && config.StringFormats && !info.VersionAtLeast(analysis.RegexMatchMinVersion...) {
+		version := helpers.VersionToString(analysis.RegexMatchMinVersion...)
+		return fmt.Errorf("Option 'string-formats' with plan '%s' require MongoDB version >= %s.\n", config.Plan, version)
+	}
+
+	// Filtering fields by partial wildcards in database require MongoDB 4.2+
+	if fields := config.FieldFilter(); analyzer.IsInDBPlan(config.Plan) && fields != nil && fields.NeedsRegexMatch() && !info.VersionAtLeast(analysis.RegexMatchMinVersion...) {
+		version := helpers.VersionToString(analysis.RegexMatchMinVersion...)
+		return fmt.Errorf("Options 'include-fields' and 'exclude-fields' with plan '%s' require MongoDB version >= %s.\n", config.Plan, version)
+	}
+
+	// $jsonSchema validator require MongoDB 3.6+
+	if config.ApplySchema && !info.VersionAtLeast(JsonSchemaMinVersion...) {
+		version := helpers.VersionToString(JsonSchemaMinVersion...)
+		return fmt.Errorf("Option 'apply-schema' require MongoDB version >= %s.\n", version)
+	}
+
 	return nil
 }
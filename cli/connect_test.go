@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"github.com/mongoeye/mongoeye/analyzer"
 	"github.com/mongoeye/mongoeye/tests"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -10,15 +11,17 @@ import (
 )
 
 func TestConnect(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	c.Insert(bson.M{})
 
 	config := &Config{
-		Host:       tests.TestDbUri,
-		Database:   c.Database.Name,
-		Collection: c.Name,
+		Options: analyzer.Options{
+			Database:   c.Database.Name,
+			Collection: c.Name,
+		},
+		URI: tests.TestDbUri,
 	}
 
 	info, session, collection, count, err := Connect(config)
@@ -62,7 +65,7 @@ func TestConnect_InvalidDb(t *testing.T) {
 }
 
 func TestConnect_InvalidCol(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	c.Insert(bson.M{})
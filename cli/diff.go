@@ -0,0 +1,360 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/helpers"
+	"gopkg.in/mgo.v2/bson"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Diff - changes between two results of analysis.
+type Diff struct {
+	AddedFields   []*FieldChange `json:"addedFields"`
+	RemovedFields []*FieldChange `json:"removedFields"`
+	ChangedFields []*FieldDiff   `json:"changedFields"`
+	Violations    []string       `json:"violations,omitempty"`
+}
+
+// FieldChange - added or removed field.
+type FieldChange struct {
+	Name  string   `json:"name"`
+	Count uint64   `json:"count"`
+	Types []string `json:"types"`
+}
+
+// FieldDiff - changes of field present in both results.
+type FieldDiff struct {
+	Name         string      `json:"name"`
+	AddedTypes   []string    `json:"addedTypes,omitempty"`
+	RemovedTypes []string    `json:"removedTypes,omitempty"`
+	Types        []*TypeDiff `json:"types,omitempty"`
+}
+
+// TypeDiff - changes of type present in both results.
+// Share is percentage of field occurrences with the type.
+// Histogram distance is maximal difference of cumulative distributions in percent (0 = same, 100 = disjoint).
+type TypeDiff struct {
+	Name               string     `json:"type"`
+	OldShare           float64    `json:"oldShare"`
+	NewShare           float64    `json:"newShare"`
+	ShareShift         float64    `json:"shareShift"`
+	Min                *ValueMove `json:"min,omitempty"`
+	Max                *ValueMove `json:"max,omitempty"`
+	ValueHistDistance  float64    `json:"valueHistDistance,omitempty"`
+	LengthHistDistance float64    `json:"lengthHistDistance,omitempty"`
+}
+
+// ValueMove - old and new value of min or max.
+type ValueMove struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// DiffThresholds - limits of changes, zero value disables the limit.
+type DiffThresholds struct {
+	FailOnAdded     bool
+	FailOnRemoved   bool
+	FailOnRange     bool
+	MaxShareShift   float64
+	MaxHistDistance float64
+}
+
+// LoadResult reads result of analysis from JSON file, see --format json.
+func LoadResult(path string) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	result := &Result{}
+	if err := json.NewDecoder(file).Decode(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DiffResults compares two results of analysis.
+func DiffResults(old *Result, new *Result) *Diff {
+	diff := &Diff{
+		AddedFields:   []*FieldChange{},
+		RemovedFields: []*FieldChange{},
+		ChangedFields: []*FieldDiff{},
+	}
+
+	oldFields := fieldsByName(old.Fields)
+	newFields := fieldsByName(new.Fields)
+
+	for _, f := range new.Fields {
+		oldField, ok := oldFields[f.Name]
+		if !ok {
+			diff.AddedFields = append(diff.AddedFields, newFieldChange(f))
+			continue
+		}
+
+		if d := diffField(oldField, f); d != nil {
+			diff.ChangedFields = append(diff.ChangedFields, d)
+		}
+	}
+
+	for _, f := range old.Fields {
+		if _, ok := newFields[f.Name]; !ok {
+			diff.RemovedFields = append(diff.RemovedFields, newFieldChange(f))
+		}
+	}
+
+	return diff
+}
+
+// Empty returns true if there are no changes.
+func (d *Diff) Empty() bool {
+	return len(d.AddedFields) == 0 && len(d.RemovedFields) == 0 && len(d.ChangedFields) == 0
+}
+
+// Check returns list of changes that exceed the thresholds.
+func (d *Diff) Check(t DiffThresholds) []string {
+	violations := []string{}
+
+	if t.FailOnAdded {
+		for _, f := range d.AddedFields {
+			violations = append(violations, fmt.Sprintf("field '%s' was added", f.Name))
+		}
+	}
+
+	if t.FailOnRemoved {
+		for _, f := range d.RemovedFields {
+			violations = append(violations, fmt.Sprintf("field '%s' was removed", f.Name))
+		}
+	}
+
+	for _, f := range d.ChangedFields {
+		if t.FailOnAdded {
+			for _, name := range f.AddedTypes {
+				violations = append(violations, fmt.Sprintf("type '%s' of field '%s' was added", name, f.Name))
+			}
+		}
+
+		if t.FailOnRemoved {
+			for _, name := range f.RemovedTypes {
+				violations = append(violations, fmt.Sprintf("type '%s' of field '%s' was removed", name, f.Name))
+			}
+		}
+
+		for _, td := range f.Types {
+			if t.MaxShareShift > 0 && math.Abs(td.ShareShift) > t.MaxShareShift {
+				violations = append(violations, fmt.Sprintf("share of type '%s' of field '%s' shifted by %.1f%% (max %.1f%%)", td.Name, f.Name, td.ShareShift, t.MaxShareShift))
+			}
+
+			if t.MaxHistDistance > 0 && td.ValueHistDistance > t.MaxHistDistance {
+				violations = append(violations, fmt.Sprintf("value distribution of type '%s' of field '%s' changed by %.1f%% (max %.1f%%)", td.Name, f.Name, td.ValueHistDistance, t.MaxHistDistance))
+			}
+
+			if t.MaxHistDistance > 0 && td.LengthHistDistance > t.MaxHistDistance {
+				violations = append(violations, fmt.Sprintf("length distribution of type '%s' of field '%s' changed by %.1f%% (max %.1f%%)", td.Name, f.Name, td.LengthHistDistance, t.MaxHistDistance))
+			}
+
+			if t.FailOnRange && td.Min != nil && compareValues(td.Min.New, td.Min.Old) < 0 {
+				violations = append(violations, fmt.Sprintf("min of type '%s' of field '%s' moved from %v to %v", td.Name, f.Name, td.Min.Old, td.Min.New))
+			}
+
+			if t.FailOnRange && td.Max != nil && compareValues(td.Max.New, td.Max.Old) > 0 {
+				violations = append(violations, fmt.Sprintf("max of type '%s' of field '%s' moved from %v to %v", td.Name, f.Name, td.Max.Old, td.Max.New))
+			}
+		}
+	}
+
+	return violations
+}
+
+func fieldsByName(fields analysis.Fields) map[string]*analysis.Field {
+	m := make(map[string]*analysis.Field, len(fields))
+	for _, f := range fields {
+		m[f.Name] = f
+	}
+	return m
+}
+
+func newFieldChange(f *analysis.Field) *FieldChange {
+	types := make([]string, len(f.Types))
+	for i, t := range f.Types {
+		types[i] = t.Name
+	}
+	return &FieldChange{Name: f.Name, Count: f.Count, Types: types}
+}
+
+// Returns nil if field is not changed.
+func diffField(old *analysis.Field, new *analysis.Field) *FieldDiff {
+	diff := &FieldDiff{Name: new.Name}
+
+	oldTypes := typesByName(old.Types)
+	newTypes := typesByName(new.Types)
+
+	for _, t := range new.Types {
+		oldType, ok := oldTypes[t.Name]
+		if !ok {
+			diff.AddedTypes = append(diff.AddedTypes, t.Name)
+			continue
+		}
+
+		if d := diffType(old, oldType, new, t); d != nil {
+			diff.Types = append(diff.Types, d)
+		}
+	}
+
+	for _, t := range old.Types {
+		if _, ok := newTypes[t.Name]; !ok {
+			diff.RemovedTypes = append(diff.RemovedTypes, t.Name)
+		}
+	}
+
+	if len(diff.AddedTypes) == 0 && len(diff.RemovedTypes) == 0 && len(diff.Types) == 0 {
+		return nil
+	}
+
+	return diff
+}
+
+func typesByName(types analysis.Types) map[string]*analysis.Type {
+	m := make(map[string]*analysis.Type, len(types))
+	for _, t := range types {
+		m[t.Name] = t
+	}
+	return m
+}
+
+// Returns nil if type is not changed.
+func diffType(oldField *analysis.Field, old *analysis.Type, newField *analysis.Field, new *analysis.Type) *TypeDiff {
+	diff := &TypeDiff{
+		Name:     new.Name,
+		OldShare: share(old.Count, oldField.Count),
+		NewShare: share(new.Count, newField.Count),
+	}
+	diff.ShareShift = diff.NewShare - diff.OldShare
+
+	if old.ValueStats != nil && new.ValueStats != nil {
+		if !reflect.DeepEqual(old.ValueStats.Min, new.ValueStats.Min) {
+			diff.Min = &ValueMove{Old: old.ValueStats.Min, New: new.ValueStats.Min}
+		}
+		if !reflect.DeepEqual(old.ValueStats.Max, new.ValueStats.Max) {
+			diff.Max = &ValueMove{Old: old.ValueStats.Max, New: new.ValueStats.Max}
+		}
+	}
+
+	diff.ValueHistDistance = histogramDistance(old.ValueHistogram, new.ValueHistogram)
+	diff.LengthHistDistance = histogramDistance(old.LengthHistogram, new.LengthHistogram)
+
+	if diff.ShareShift == 0 && diff.Min == nil && diff.Max == nil && diff.ValueHistDistance == 0 && diff.LengthHistDistance == 0 {
+		return nil
+	}
+
+	return diff
+}
+
+func share(count uint64, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total) * 100
+}
+
+// Kolmogorov-Smirnov distance of two histograms in percent.
+// Histograms can have different start and step, values in intervals are considered uniformly distributed.
+// Returns 0 if one of histograms is missing.
+func histogramDistance(a *analysis.Histogram, b *analysis.Histogram) float64 {
+	if a == nil || b == nil {
+		return 0
+	}
+
+	startA, okA := toNumber(a.Start)
+	startB, okB := toNumber(b.Start)
+	if !okA || !okB {
+		return 0
+	}
+
+	// Maximum is reached at some boundary of intervals
+	points := make([]float64, 0, a.NumberOfSteps+b.NumberOfSteps+2)
+	for i := uint(0); i <= a.NumberOfSteps; i++ {
+		points = append(points, startA+float64(i)*a.Step)
+	}
+	for i := uint(0); i <= b.NumberOfSteps; i++ {
+		points = append(points, startB+float64(i)*b.Step)
+	}
+
+	distance := 0.0
+	for _, x := range points {
+		distance = math.Max(distance, math.Abs(cumulative(a, startA, x)-cumulative(b, startB, x)))
+	}
+
+	return distance * 100
+}
+
+// Share of values lower or equal to x.
+func cumulative(h *analysis.Histogram, start float64, x float64) float64 {
+	total, sum := 0.0, 0.0
+	for _, interval := range h.Intervals {
+		count := float64(interval.Count)
+		total += count
+
+		low := start + float64(interval.Interval)*h.Step
+		if x >= low+h.Step {
+			sum += count
+		} else if x > low {
+			sum += count * (x - low) / h.Step
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return sum / total
+}
+
+// Convert numbers and dates to float64, dates are loaded from JSON as strings.
+func toNumber(v interface{}) (float64, bool) {
+	switch value := v.(type) {
+	case float64, int, int32, int64, uint, time.Time, bson.Decimal128:
+		return helpers.ToDouble(value), true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return 0, false
+		}
+		return helpers.ToDouble(t), true
+	}
+
+	return 0, false
+}
+
+// Compare min or max values, returns 0 if values cannot be compared.
+func compareValues(a interface{}, b interface{}) int {
+	numA, okA := toNumber(a)
+	numB, okB := toNumber(b)
+	if okA && okB {
+		return compareFloats(numA, numB)
+	}
+
+	strA, okA := a.(string)
+	strB, okB := b.(string)
+	if okA && okB {
+		return strings.Compare(strA, strB)
+	}
+
+	return 0
+}
+
+func compareFloats(a float64, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
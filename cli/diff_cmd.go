@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mongoeye/mongoeye/helpers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// DiffCmdName - name of diff sub-command.
+const DiffCmdName = "diff"
+
+// ExitCodeDrift - exit code of diff command if schema drift exceeds the thresholds.
+// Other errors exit with code 1.
+const ExitCodeDrift = 2
+
+// DriftError is returned by diff command if some change exceeds the thresholds.
+type DriftError struct {
+	Violations []string
+}
+
+func (e *DriftError) Error() string {
+	return fmt.Sprintf("Schema drift exceeds the thresholds:\n  %s\n", strings.Join(e.Violations, "\n  "))
+}
+
+// ExitCode returns exit code of the application for the error returned by a command.
+func ExitCode(err error) int {
+	switch err.(type) {
+	case nil:
+		return 0
+	case *DriftError:
+		return ExitCodeDrift
+	}
+	return 1
+}
+
+// DiffConfig - configuration of diff command.
+type DiffConfig struct {
+	OldFile    string
+	NewFile    string
+	Format     string
+	Thresholds DiffThresholds
+}
+
+// NewDiffCmd creates command that compares two results of analysis.
+// Cobra does not allow sub-commands together with positional arguments of the root command,
+// so the command is selected by the first argument, see IsDiffCmd.
+func NewDiffCmd(cmdName string, envPrefix string, appName string, appVersion string, appSubtitle string) (*cobra.Command, *viper.Viper) {
+	title := fmt.Sprintf("%s %s - %s", appName, appVersion, appSubtitle)
+
+	v := viper.New()
+	v.Set("appName", appName)
+	v.Set("appVersion", appVersion)
+
+	cmd := &cobra.Command{
+		Use:           fmt.Sprintf("%s %s old.json new.json", cmdName, DiffCmdName),
+		Short:         title,
+		Long:          title,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return PreRunDiff(cmd, v, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := GetDiffConfig(v)
+			if err != nil {
+				return err
+			}
+
+			return RunDiff(cmd, config)
+		},
+	}
+
+	InitDiffFlags(cmd, v, envPrefix+"_"+DiffCmdName)
+
+	return cmd, v
+}
+
+// IsDiffCmd returns true if diff command is called.
+func IsDiffCmd(osArgs []string) bool {
+	return len(osArgs) > 1 && osArgs[1] == DiffCmdName
+}
+
+// InitDiffFlags initializes flags of diff command and their default values.
+func InitDiffFlags(cmd *cobra.Command, v *viper.Viper, envPrefix string) {
+	flags := &Flags{}
+
+	var s *pflag.FlagSet
+
+	s = flags.AddSection("thresholds").Set
+	s.Bool("fail-on-added", false, "fail if a field or type was added")
+	s.Bool("fail-on-removed", false, "fail if a field or type was removed")
+	s.Bool("fail-on-range", false, "fail if min or max value moved out of the old range")
+	s.Float64("max-share-shift", 0, "max shift of type share in percentage points (default 0 = unlimited)")
+	s.Float64("max-hist-distance", 0, "max distance of value/length histograms in percent (default 0 = unlimited)")
+
+	s = flags.AddSection("other options").Set
+	s.StringP("format", "f", "table", "output format: table, json")
+	s.Bool("version", false, "show version")
+	s.BoolP("help", "h", false, "show this help")
+
+	all := cmd.Flags()
+	all.SortFlags = false
+	flags.ExportAll(all)
+
+	v.BindPFlags(all)
+	v.SetEnvPrefix(envPrefix)
+	v.AutomaticEnv()
+
+	cmd.SetUsageFunc(usageFunc(flags, usageSection{
+		Name: "Exit codes",
+		Text: fmt.Sprintf("  0  no change exceeds the thresholds\n  1  error\n  %d  schema drift exceeds the thresholds\n", ExitCodeDrift),
+	}))
+}
+
+// PreRunDiff prints help, version and validate arguments of diff command.
+func PreRunDiff(cmd *cobra.Command, v *viper.Viper, args []string) error {
+	if v.GetBool("help") {
+		cmd.Help()
+		cmd.RunE = noOp
+		return nil
+	}
+
+	if v.GetBool("version") {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", v.GetString("appName"), v.GetString("appVersion"))
+		cmd.RunE = noOp
+		return nil
+	}
+
+	if len(args) != 2 {
+		return errors.New("Please specify two JSON files with results of analysis (--format json).\n")
+	}
+
+	v.Set("old", args[0])
+	v.Set("new", args[1])
+
+	return nil
+}
+
+// GetDiffConfig - returns configuration of diff command according Viper values.
+func GetDiffConfig(v *viper.Viper) (*DiffConfig, error) {
+	config := &DiffConfig{
+		OldFile: v.GetString("old"),
+		NewFile: v.GetString("new"),
+		Format:  v.GetString("format"),
+		Thresholds: DiffThresholds{
+			FailOnAdded:     v.GetBool("fail-on-added"),
+			FailOnRemoved:   v.GetBool("fail-on-removed"),
+			FailOnRange:     v.GetBool("fail-on-range"),
+			MaxShareShift:   v.GetFloat64("max-share-shift"),
+			MaxHistDistance: v.GetFloat64("max-hist-distance"),
+		},
+	}
+
+	if !helpers.InStringSlice(config.Format, []string{"table", "json"}) {
+		return nil, errors.New(
+			"Invalid value of 'format' option.\nAllowed values are: 'table', 'json'.",
+		)
+	}
+
+	if config.Thresholds.MaxShareShift < 0 || config.Thresholds.MaxHistDistance < 0 {
+		return nil, errors.New(
+			"Options 'max-share-shift' and 'max-hist-distance' must be >= 0",
+		)
+	}
+
+	return config, nil
+}
+
+// RunDiff compares two results of analysis.
+// DriftError is returned if some change exceeds the thresholds.
+func RunDiff(cmd *cobra.Command, config *DiffConfig) error {
+	oldResult, err := LoadResult(config.OldFile)
+	if err != nil {
+		return fmt.Errorf("Cannot load analysis result '%s': %s.\n", config.OldFile, err)
+	}
+
+	newResult, err := LoadResult(config.NewFile)
+	if err != nil {
+		return fmt.Errorf("Cannot load analysis result '%s': %s.\n", config.NewFile, err)
+	}
+
+	diff := DiffResults(oldResult, newResult)
+	diff.Violations = diff.Check(config.Thresholds)
+
+	output, err := FormatDiff(diff, config)
+	if err != nil {
+		return fmt.Errorf("Cannot format results: %s.\n", err)
+	}
+
+	out := cmd.OutOrStdout()
+	out.Write(output)
+
+	if len(diff.Violations) > 0 {
+		return &DriftError{Violations: diff.Violations}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeDiffTestResults(t *testing.T) (dir string, oldPath string, newPath string) {
+	dir, _ = ioutil.TempDir("", "mongoeye")
+	oldPath = filepath.Join(dir, "old.json")
+	newPath = filepath.Join(dir, "new.json")
+
+	old, new := diffTestResults()
+	for path, result := range map[string]*Result{oldPath: old, newPath: new} {
+		out, err := json.Marshal(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.WriteFile(path, out, 0644)
+	}
+
+	return
+}
+
+func TestIsDiffCmd(t *testing.T) {
+	assert.True(t, IsDiffCmd([]string{"cmd", "diff", "a.json", "b.json"}))
+	assert.False(t, IsDiffCmd([]string{"cmd", "db", "diff"}))
+	assert.False(t, IsDiffCmd([]string{"cmd"}))
+}
+
+func TestLoadResult(t *testing.T) {
+	dir, oldPath, _ := writeDiffTestResults(t)
+	defer os.RemoveAll(dir)
+
+	result, err := LoadResult(oldPath)
+	assert.Nil(t, err)
+
+	old, _ := diffTestResults()
+	assert.Equal(t, old.Fields[1].Types[0].ValueHistogram, result.Fields[1].Types[0].ValueHistogram)
+	assert.True(t, DiffResults(old, result).Empty())
+
+	_, err = LoadResult(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestDiffCmd(t *testing.T) {
+	dir, oldPath, newPath := writeDiffTestResults(t)
+	defer os.RemoveAll(dir)
+
+	out := bytes.NewBuffer(nil)
+	cmd, _ := NewDiffCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(out)
+	cmd.SetArgs([]string{oldPath, newPath})
+
+	err := cmd.Execute()
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "field added")
+	assert.Contains(t, out.String(), "1 fields added, 1 fields removed, 1 fields changed")
+}
+
+func TestDiffCmd_Thresholds(t *testing.T) {
+	dir, oldPath, newPath := writeDiffTestResults(t)
+	defer os.RemoveAll(dir)
+
+	out := bytes.NewBuffer(nil)
+	cmd, _ := NewDiffCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(out)
+	cmd.SetArgs([]string{oldPath, newPath, "--format", "json", "--fail-on-removed"})
+
+	err := cmd.Execute()
+	assert.Equal(t, "Schema drift exceeds the thresholds:\n  field 'name' was removed\n", err.Error())
+	assert.Equal(t, ExitCodeDrift, ExitCode(err))
+
+	diff := Diff{}
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &diff))
+	assert.Equal(t, []string{"field 'name' was removed"}, diff.Violations)
+}
+
+func TestDiffCmd_InvalidArgs(t *testing.T) {
+	cmd, _ := NewDiffCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(bytes.NewBuffer(nil))
+	cmd.SetArgs([]string{"old.json"})
+	assert.Error(t, cmd.Execute())
+
+	cmd, _ = NewDiffCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(bytes.NewBuffer(nil))
+	cmd.SetArgs([]string{"old.json", "new.json", "--format", "yaml"})
+	assert.Equal(t, "Invalid value of 'format' option.\nAllowed values are: 'table', 'json'.", cmd.Execute().Error())
+
+	cmd, _ = NewDiffCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(bytes.NewBuffer(nil))
+	cmd.SetArgs([]string{"old.json", "new.json"})
+	assert.Contains(t, cmd.Execute().Error(), "Cannot load analysis result 'old.json'")
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, 1, ExitCode(errors.New("Other error.")))
+	assert.Equal(t, 2, ExitCode(&DriftError{Violations: []string{"field 'a' was added"}}))
+}
+
+func TestDiffCmd_Usage(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	cmd, _ := NewDiffCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(out)
+	cmd.SetArgs([]string{"-h"})
+
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, out.String(), "Exit codes:\n  0  no change exceeds the thresholds\n  1  error\n  2  schema drift")
+	assert.NotContains(t, out.String(), "Plans:")
+}
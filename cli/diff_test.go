@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func diffTestResults() (*Result, *Result) {
+	old := &Result{
+		DocsCount: 10,
+		Fields: analysis.Fields{
+			{
+				Name:  "_id",
+				Count: 10,
+				Types: analysis.Types{
+					{Name: "objectId", Count: 10},
+				},
+			},
+			{
+				Name:  "age",
+				Count: 10,
+				Types: analysis.Types{
+					{
+						Name:       "int",
+						Count:      10,
+						ValueStats: &analysis.ValueStats{Min: float64(18), Max: float64(60)},
+						ValueHistogram: &analysis.Histogram{
+							Start:         float64(0),
+							Step:          10,
+							NumberOfSteps: 10,
+							Intervals: analysis.Intervals{
+								{Interval: 1, Count: 5},
+								{Interval: 5, Count: 5},
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "name",
+				Count: 10,
+				Types: analysis.Types{
+					{Name: "string", Count: 10},
+				},
+			},
+		},
+	}
+
+	new := &Result{
+		DocsCount: 10,
+		Fields: analysis.Fields{
+			{
+				Name:  "_id",
+				Count: 10,
+				Types: analysis.Types{
+					{Name: "objectId", Count: 10},
+				},
+			},
+			{
+				Name:  "age",
+				Count: 10,
+				Types: analysis.Types{
+					{Name: "null", Count: 2},
+					{
+						Name:       "int",
+						Count:      8,
+						ValueStats: &analysis.ValueStats{Min: float64(18), Max: float64(70)},
+						ValueHistogram: &analysis.Histogram{
+							Start:         float64(10),
+							Step:          5,
+							NumberOfSteps: 14,
+							Intervals: analysis.Intervals{
+								{Interval: 1, Count: 8},
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "email",
+				Count: 4,
+				Types: analysis.Types{
+					{Name: "string", Count: 4},
+				},
+			},
+		},
+	}
+
+	return old, new
+}
+
+func TestDiffResults(t *testing.T) {
+	old, new := diffTestResults()
+	diff := DiffResults(old, new)
+
+	assert.Equal(t, []*FieldChange{{Name: "email", Count: 4, Types: []string{"string"}}}, diff.AddedFields)
+	assert.Equal(t, []*FieldChange{{Name: "name", Count: 10, Types: []string{"string"}}}, diff.RemovedFields)
+	assert.Equal(t, []*FieldDiff{
+		{
+			Name:       "age",
+			AddedTypes: []string{"null"},
+			Types: []*TypeDiff{
+				{
+					Name:              "int",
+					OldShare:          100,
+					NewShare:          80,
+					ShareShift:        -20,
+					Max:               &ValueMove{Old: float64(60), New: float64(70)},
+					ValueHistDistance: 50,
+				},
+			},
+		},
+	}, diff.ChangedFields)
+	assert.False(t, diff.Empty())
+}
+
+func TestDiffResults_Same(t *testing.T) {
+	old, _ := diffTestResults()
+	diff := DiffResults(old, old)
+
+	assert.True(t, diff.Empty())
+	assert.Equal(t, []string{}, diff.Check(DiffThresholds{
+		FailOnAdded:     true,
+		FailOnRemoved:   true,
+		FailOnRange:     true,
+		MaxShareShift:   0.1,
+		MaxHistDistance: 0.1,
+	}))
+}
+
+func TestDiff_Check(t *testing.T) {
+	old, new := diffTestResults()
+	diff := DiffResults(old, new)
+
+	assert.Equal(t, []string{}, diff.Check(DiffThresholds{}))
+
+	assert.Equal(t, []string{
+		"field 'email' was added",
+		"type 'null' of field 'age' was added",
+	}, diff.Check(DiffThresholds{FailOnAdded: true}))
+
+	assert.Equal(t, []string{
+		"field 'name' was removed",
+	}, diff.Check(DiffThresholds{FailOnRemoved: true}))
+
+	assert.Equal(t, []string{
+		"max of type 'int' of field 'age' moved from 60 to 70",
+	}, diff.Check(DiffThresholds{FailOnRange: true}))
+
+	assert.Equal(t, []string{
+		"share of type 'int' of field 'age' shifted by -20.0% (max 10.0%)",
+		"value distribution of type 'int' of field 'age' changed by 50.0% (max 40.0%)",
+	}, diff.Check(DiffThresholds{MaxShareShift: 10, MaxHistDistance: 40}))
+
+	assert.Equal(t, []string{}, diff.Check(DiffThresholds{MaxShareShift: 20, MaxHistDistance: 50}))
+}
+
+func TestHistogramDistance(t *testing.T) {
+	a := &analysis.Histogram{
+		Start:         float64(0),
+		Step:          1,
+		NumberOfSteps: 4,
+		Intervals: analysis.Intervals{
+			{Interval: 0, Count: 1},
+			{Interval: 1, Count: 1},
+			{Interval: 2, Count: 1},
+			{Interval: 3, Count: 1},
+		},
+	}
+
+	// Same distribution with different step
+	b := &analysis.Histogram{
+		Start:         float64(0),
+		Step:          2,
+		NumberOfSteps: 2,
+		Intervals: analysis.Intervals{
+			{Interval: 0, Count: 5},
+			{Interval: 1, Count: 5},
+		},
+	}
+
+	// Disjoint distribution
+	c := &analysis.Histogram{
+		Start:         float64(10),
+		Step:          1,
+		NumberOfSteps: 1,
+		Intervals: analysis.Intervals{
+			{Interval: 0, Count: 1},
+		},
+	}
+
+	assert.Equal(t, float64(0), histogramDistance(a, a))
+	assert.Equal(t, float64(0), histogramDistance(a, b))
+	assert.Equal(t, float64(100), histogramDistance(a, c))
+	assert.Equal(t, float64(0), histogramDistance(a, nil))
+}
+
+func TestHistogramDistance_Dates(t *testing.T) {
+	a := &analysis.Histogram{
+		Start:         "2017-01-01T00:00:00Z",
+		Step:          3600,
+		NumberOfSteps: 2,
+		Intervals: analysis.Intervals{
+			{Interval: 0, Count: 1},
+			{Interval: 1, Count: 1},
+		},
+	}
+
+	b := &analysis.Histogram{
+		Start:         "2017-01-01T01:00:00+01:00",
+		Step:          3600,
+		NumberOfSteps: 2,
+		Intervals: analysis.Intervals{
+			{Interval: 0, Count: 1},
+			{Interval: 1, Count: 1},
+		},
+	}
+
+	assert.Equal(t, float64(0), histogramDistance(a, b))
+
+	b.Start = "2017-01-01T01:00:00Z"
+	assert.Equal(t, float64(50), histogramDistance(a, b))
+}
+
+func TestCompareValues(t *testing.T) {
+	assert.Equal(t, -1, compareValues(float64(1), float64(2)))
+	assert.Equal(t, 1, compareValues("2017-01-02T00:00:00Z", "2017-01-01T00:00:00Z"))
+	assert.Equal(t, -1, compareValues("abc", "abd"))
+	assert.Equal(t, 0, compareValues(true, float64(1)))
+}
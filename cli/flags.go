@@ -1,11 +1,15 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/csv"
 	"fmt"
 	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analyzer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"strings"
 )
 
 // Flags consist from multiple sections
@@ -46,54 +50,86 @@ func InitFlags(cmd *cobra.Command, v *viper.Viper, envPrefix string) {
 
 	// connection options
 	s = flags.AddSection("connection options").Set
-	s.String("host", "localhost:27017", "mongodb host")
-	s.String("connection-mode", "SecondaryPreferred", "connection mode")
+	s.String("uri", "", "connection string, mongodb:// or mongodb+srv://")
+	s.String("host", "", "mongodb host (default \"localhost:27017\")")
+	s.String("connection-mode", "", "connection mode (default \"SecondaryPreferred\")")
 	s.Float64("connection-timeout", 5, "connection timeout")
 	s.Float64("socket-timeout", 5*60, "socket timeout")
 	s.Float64("sync-timeout", 5*60, "sync timeout")
+	s.Bool("tls", false, "use TLS connection")
+	s.String("tls-ca-file", "", "certificate authority file (default: system roots)")
+	s.String("tls-cert-key-file", "", "client certificate and private key file (PEM)")
+	s.Bool("tls-allow-invalid-hostnames", false, "do not verify hostname in the server certificate")
 
 	// authentication
 	s = flags.AddSection("authentication").Set
-	s.StringP("user", "u", "admin", "username for authentication")
+	s.StringP("user", "u", "", "username for authentication (default \"admin\")")
 	s.StringP("password", "p", "", "password for authentication")
 	s.String("auth-db", "", "auth database (default: same as the working db)")
-	s.String("auth-mech", "", "auth mechanism")
+	s.String("auth-mech", "", "auth mechanism, eg. SCRAM-SHA-1, MONGODB-X509")
 
 	// analysis options
 	s = flags.AddSection("input options").Set
 	s.String("db", "", "database for analysis")
 	s.String("col", "", "collection for analysis")
+	s.Bool("all-collections", false, "analyze all collections of the database")
+	s.Bool("all-databases", false, "analyze all collections of all databases")
+	s.StringSlice("include-ns", []string{}, "analyze only namespaces matching these globs, eg. shop.order*")
+	s.StringSlice("exclude-ns", []string{}, "skip namespaces matching these globs")
+	s.Bool("include-system", false, "analyze also system collections and databases")
+	s.Bool("include-views", false, "analyze also views")
+	s.StringSlice("dump", []string{}, "analyze BSON files created by mongodump (.bson, .bson.gz)")
+	s.StringSlice("json", []string{}, "analyze NDJSON files created by mongoexport (.json, .json.gz, - for stdin)")
 	s.StringP("match", "", "", "filter documents before analysis (json, $match aggregation)")
 	s.StringP("sample", "s", "random:1000", "all, first:N, last:N, random:N")
 	s.StringP("project", "", "", "filter/project fields before analysis (json, $project aggregation)")
 	s.UintP("depth", "d", 2, "max depth in nested documents")
+	s.Var(&pathsValue{}, "depth-for", "max depth in nested documents of the paths, eg. order.items=6")
+	s.Uint("map-min-keys", 0, "collapse objects with N+ date, number or id keys to {key}, 0 = disabled (local plan)")
+	s.Uint("map-max-keys", 0, "collapse objects with N+ keys of any shape to {key}, 0 = disabled (local plan)")
+	s.Var(&pathsValue{}, "map-paths", "always collapse objects at these paths to {key} (local plan)")
+	s.Var(&pathsValue{}, "no-map-paths", "never collapse objects at these paths")
+	s.Var(&pathsValue{}, "include-fields", "analyze only fields matching these globs, eg. items.[].price, **.id")
+	s.Var(&pathsValue{}, "exclude-fields", "skip fields matching these globs, eg. audit, **.raw")
 
 	// statistics options
 	s = flags.AddSection("output options").Set
 	s.Bool("full", false, "all available analyzes")
-	s.BoolP("value", "v", false, "get min, max, avg value")
+	s.BoolP("value", "v", false, "get min, max, avg, sum and deviation of value")
 	s.BoolP("length", "l", false, "get min, max, avg length")
+	s.Bool("quantiles", false, "get quantiles of values and lengths (with --value, --length)")
 	s.BoolP("value-hist", "V", false, "get value histogram")
 	s.Uint("value-hist-steps", 100, "max steps of value histogram >=3")
 	s.BoolP("length-hist", "L", false, "get length histogram")
 	s.Uint("length-hist-steps", 100, "max steps of length histogram >=3")
 	s.BoolP("weekday-hist", "W", false, "get weekday histogram for dates")
 	s.BoolP("hour-hist", "H", false, "get hour histogram for dates")
+	s.Bool("string-formats", false, "count strings that look like dates, numbers, emails, ...")
 	s.Bool("count-unique", false, "get count of unique values")
+	s.String("unique-method", "auto", "count unique values: exact, approx, auto")
+	s.Uint("unique-memory", 256, "memory limit in MB for exact count in auto method")
 	s.Uint("most-freq", 0, "get the N most frequent values")
 	s.Uint("least-freq", 0, "get the N least frequent values")
-	s.StringP("format", "f", "table", "output format: table, json, yaml")
+	s.Uint("shapes", 0, "get the N most frequent shapes of documents (only with local plan)")
+	s.Bool("array-stats", false, "count empty, homogeneous, sorted arrays and arrays with duplicates (only with local plan)")
+	s.Uint("array-positions", 0, "analyze the first N array items also by position, eg. tags.[0] (only with local plan)")
+	s.StringP("format", "f", "table", "output format: table, json, yaml, jsonschema")
 	s.StringP("file", "F", "", "path to the output file")
+	s.Bool("apply-schema", false, "set $jsonSchema validator of the collection (collMod)")
 
 	// other options
 	s = flags.AddSection("other options").Set
 	s.StringP("timezone", "t", "local", "timezone, eg. UTC, Europe/Berlin")
-	s.Bool("use-aggregation", false, fmt.Sprintf("analyze with aggregation framework (mongodb %s+)", analysis.AggregationMinVersionStr))
+	s.String("plan", "auto", fmt.Sprintf("plan of analysis: auto, %s (see Plans, in database mongodb %s+)", strings.Join(analyzer.PlanNames(), ", "), analysis.AggregationMinVersionStr))
 	s.Uint("string-max-length", 100, "max string length")
 	s.Uint("array-max-length", 20, "analyze only first N array elements")
+	s.Var(&pathsValue{}, "array-max-for", "analyze only first N elements of arrays at the paths, eg. tags=1000")
 	s.Uint("concurrency", 0, "number of local processes (default 0 = auto)")
 	s.Uint("buffer", 5000, "size of the buffer between local stages")
 	s.Uint("batch", 500, "size of batch from database")
+	s.Uint("parallel", 4, "number of collections analyzed concurrently")
+	s.Float64("max-time", 0, "time limit of analysis in seconds (default 0 = unlimited)")
+	s.Bool("skip-corrupted", false, "skip and count corrupted documents instead of stopping analysis")
 	s.Bool("no-color", false, "disable color output")
 	s.Bool("version", false, "show version")
 	s.BoolP("help", "h", false, "show this help")
@@ -105,9 +141,71 @@ func InitFlags(cmd *cobra.Command, v *viper.Viper, envPrefix string) {
 
 	// Init Viper
 	v.BindPFlags(all)
+	v.Set("flagSet", all)
+	v.Set("envPrefix", envPrefix)
 	v.SetEnvPrefix(envPrefix)
 	v.AutomaticEnv()
 
 	// Flags usage
-	cmd.SetUsageFunc(usageFunc(flags))
+	cmd.SetUsageFunc(usageFunc(flags, usageSection{Name: "Plans", Text: plansUsage()}))
+}
+
+// Value of flags with comma-separated paths, eg. --include-fields 'a,"b.c".d'.
+// Unlike pflag's stringSlice, quoted keys of the paths are not parsed as CSV, see analysis.EscapeKey.
+// The type is reported as stringSlice, so viper reads the value in the same way.
+type pathsValue struct {
+	value   []string
+	changed bool
+}
+
+func (p *pathsValue) Set(val string) error {
+	paths := splitPaths(val)
+	if !p.changed {
+		p.value = paths
+	} else {
+		p.value = append(p.value, paths...)
+	}
+	p.changed = true
+	return nil
+}
+
+func (p *pathsValue) Type() string {
+	return "stringSlice"
+}
+
+// Empty value is empty string, so no default is printed in usage.
+func (p *pathsValue) String() string {
+	if len(p.value) == 0 {
+		return ""
+	}
+
+	b := &bytes.Buffer{}
+	w := csv.NewWriter(b)
+	w.Write(p.value)
+	w.Flush()
+	return "[" + strings.TrimSuffix(b.String(), "\n") + "]"
+}
+
+// Split comma-separated paths, commas in quoted keys are not separators.
+func splitPaths(val string) []string {
+	paths := []string{}
+	if val == "" {
+		return paths
+	}
+
+	start := 0
+	quoted := false
+	for i := 0; i < len(val); i++ {
+		switch {
+		case quoted && val[i] == analysis.KeyEscape[0]:
+			i++
+		case val[i] == analysis.KeyQuote[0]:
+			quoted = !quoted
+		case !quoted && val[i] == ',':
+			paths = append(paths, strings.TrimSpace(val[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(paths, strings.TrimSpace(val[start:]))
 }
@@ -34,3 +34,11 @@ func TestFlags_ExportAll(t *testing.T) {
 
 	assert.Equal(t, names, []string{"flag1", "flag2", "flag3", "flag4"})
 }
+
+func TestSplitPaths(t *testing.T) {
+	assert.Equal(t, []string{}, splitPaths(""))
+	assert.Equal(t, []string{"a.b"}, splitPaths("a.b"))
+	assert.Equal(t, []string{"a", "b.[].c"}, splitPaths("a, b.[].c"))
+	assert.Equal(t, []string{`"a.b".c`, `"x,y"`}, splitPaths(`"a.b".c,"x,y"`))
+	assert.Equal(t, []string{`"a\",b"`, "c"}, splitPaths(`"a\",b",c`))
+}
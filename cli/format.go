@@ -2,22 +2,15 @@ package cli
 
 import (
 	"encoding/json"
-	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analyzer"
 	"gopkg.in/yaml.v2"
-	"time"
 )
 
 // Result of analysis.
-type Result struct {
-	Database     string          `json:"database"         yaml:"database"`
-	Collection   string          `json:"collection"       yaml:"collection"`
-	Plan         string          `json:"plan"             yaml:"plan"`
-	Duration     time.Duration   `json:"duration"         yaml:"duration"`
-	AllDocsCount uint64          `json:"allDocs"          yaml:"allDocs"`
-	DocsCount    uint64          `json:"analyzedDocs"     yaml:"analyzedDocs"`
-	FieldsCount  uint64          `json:"fieldsCount"      yaml:"fieldsCount"`
-	Fields       analysis.Fields `json:"fields"           yaml:"fields"`
-}
+type Result = analyzer.Result
+
+// Limits of expansion used by the analysis.
+type Limits = analyzer.Limits
 
 // Format result of analysis.
 func Format(result Result, config *Config) ([]byte, error) {
@@ -28,6 +21,8 @@ func Format(result Result, config *Config) ([]byte, error) {
 		return formatJson(result, config)
 	case "yaml":
 		return formatYaml(result, config)
+	case "jsonschema":
+		return formatJsonSchema(result, config)
 	default:
 		panic("Unexpected format.")
 	}
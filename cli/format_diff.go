@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/olekukonko/tablewriter"
+	"strings"
+)
+
+// FormatDiff formats changes between two results of analysis.
+func FormatDiff(diff *Diff, config *DiffConfig) ([]byte, error) {
+	switch config.Format {
+	case "table":
+		return formatDiffTable(diff), nil
+	case "json":
+		return formatDiffJson(diff)
+	default:
+		panic("Unexpected format.")
+	}
+}
+
+func formatDiffJson(diff *Diff) ([]byte, error) {
+	out, err := json.MarshalIndent(diff, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+func formatDiffTable(diff *Diff) []byte {
+	out := bytes.NewBuffer(nil)
+
+	if diff.Empty() {
+		out.WriteString("No changes.\n")
+		return out.Bytes()
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowSeparator("─")
+	table.SetColumnSeparator("│")
+	table.SetCenterSeparator("─")
+	table.SetHeader([]string{"FIELD", "TYPE", "CHANGE", "DETAIL"})
+
+	for _, f := range diff.AddedFields {
+		table.Append([]string{f.Name, strings.Join(f.Types, ", "), "field added", fmt.Sprintf("count %d", f.Count)})
+	}
+
+	for _, f := range diff.RemovedFields {
+		table.Append([]string{f.Name, strings.Join(f.Types, ", "), "field removed", fmt.Sprintf("count %d", f.Count)})
+	}
+
+	for _, f := range diff.ChangedFields {
+		for _, name := range f.AddedTypes {
+			table.Append([]string{f.Name, name, "type added", ""})
+		}
+
+		for _, name := range f.RemovedTypes {
+			table.Append([]string{f.Name, name, "type removed", ""})
+		}
+
+		for _, t := range f.Types {
+			if t.ShareShift != 0 {
+				table.Append([]string{f.Name, t.Name, "share", fmt.Sprintf("%.1f%% -> %.1f%%", t.OldShare, t.NewShare)})
+			}
+			if t.Min != nil {
+				table.Append([]string{f.Name, t.Name, "min", fmt.Sprintf("%v -> %v", t.Min.Old, t.Min.New)})
+			}
+			if t.Max != nil {
+				table.Append([]string{f.Name, t.Name, "max", fmt.Sprintf("%v -> %v", t.Max.Old, t.Max.New)})
+			}
+			if t.ValueHistDistance != 0 {
+				table.Append([]string{f.Name, t.Name, "value distribution", fmt.Sprintf("distance %.1f%%", t.ValueHistDistance)})
+			}
+			if t.LengthHistDistance != 0 {
+				table.Append([]string{f.Name, t.Name, "length distribution", fmt.Sprintf("distance %.1f%%", t.LengthHistDistance)})
+			}
+		}
+	}
+
+	table.Render()
+
+	fmt.Fprintf(
+		out,
+		"\n%d fields added, %d fields removed, %d fields changed\n",
+		len(diff.AddedFields),
+		len(diff.RemovedFields),
+		len(diff.ChangedFields),
+	)
+
+	return out.Bytes()
+}
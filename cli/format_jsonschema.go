@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"encoding/json"
+	"github.com/mongoeye/mongoeye/analyzer"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// JsonSchemaMinVersion is minimal MongoDB version that supports $jsonSchema validator
+var JsonSchemaMinVersion = []int{3, 6, 0}
+
+// ApplyJsonSchema sets $jsonSchema validator of the collection using collMod command.
+func ApplyJsonSchema(c driver.Collection, schema bson.M) error {
+	return c.Session().Run(c.Database(), bson.D{
+		{Name: "collMod", Value: c.Name()},
+		{Name: "validator", Value: bson.M{"$jsonSchema": schema}},
+	}, nil)
+}
+
+func formatJsonSchema(result Result, config *Config) (out []byte, err error) {
+	validator := bson.M{"$jsonSchema": analyzer.JsonSchema(&result, &config.Options)}
+
+	// JSON output is pretty printed to console and compressed to file
+	if config.FilePath == "" {
+		out, err = json.MarshalIndent(validator, "", "\t")
+	} else {
+		out, err = json.Marshal(validator)
+	}
+
+	return
+}
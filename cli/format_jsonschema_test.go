@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestFormat_JsonSchema(t *testing.T) {
+	result := Result{
+		DocsCount: 1,
+		Fields: analysis.Fields{
+			{
+				Name:  "_id",
+				Count: 1,
+				Types: analysis.Types{
+					{Name: "int", Count: 1},
+				},
+			},
+		},
+	}
+
+	cmd := &cobra.Command{}
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+
+	cmd.ParseFlags([]string{"cmd", "--format", "jsonschema"})
+	config, err := GetConfig(v)
+	assert.Equal(t, nil, err)
+
+	out, err := Format(result, config)
+	assert.Equal(t, nil, err)
+
+	expected := `{
+	"$jsonSchema": {
+		"bsonType": "object",
+		"properties": {
+			"_id": {
+				"bsonType": "int"
+			}
+		},
+		"required": [
+			"_id"
+		]
+	}
+}`
+
+	assert.Equal(t, expected, string(out))
+
+	config.FilePath = "/tmp/out.json"
+	out, err = Format(result, config)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, strings.Join(strings.Fields(expected), ""), string(out))
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v2"
+	"sort"
+	"time"
+)
+
+// Results of analysis of more collections keyed by namespace, see 'all-collections' and 'all-databases' options.
+type Results struct {
+	Duration    time.Duration      `json:"duration"         yaml:"duration"`
+	Collections map[string]*Result `json:"collections"      yaml:"collections"`
+	Errors      map[string]string  `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Namespaces returns sorted namespaces of analyzed and failed collections.
+func (r *Results) Namespaces() []string {
+	namespaces := make([]string, 0, len(r.Collections)+len(r.Errors))
+	for ns := range r.Collections {
+		namespaces = append(namespaces, ns)
+	}
+	for ns := range r.Errors {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// FormatResults formats results of analysis of more collections.
+// Table format contains only the summary, JSON and YAML contain results of all collections.
+func FormatResults(results *Results, config *Config) (out []byte, err error) {
+	switch config.Format {
+	case "table":
+		return formatSummaryTable(results), nil
+	case "json":
+		if config.FilePath == "" {
+			return json.MarshalIndent(results, "", "\t")
+		}
+		return json.Marshal(results)
+	case "yaml":
+		return yaml.Marshal(results)
+	default:
+		panic("Unexpected format.")
+	}
+}
+
+func formatSummaryTable(results *Results) []byte {
+	out := bytes.NewBuffer(nil)
+
+	table := tablewriter.NewWriter(out)
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowSeparator("─")
+	table.SetColumnSeparator("│")
+	table.SetCenterSeparator("─")
+	table.SetHeader([]string{"NAMESPACE", "ALL DOCS", "ANALYZED", "FIELDS", "TIME", "STATUS"})
+
+	for _, ns := range results.Namespaces() {
+		if msg, found := results.Errors[ns]; found {
+			table.Append([]string{ns, "", "", "", "", msg})
+			continue
+		}
+
+		r := results.Collections[ns]
+		table.Append([]string{
+			ns,
+			fmt.Sprintf("%d", r.AllDocsCount),
+			fmt.Sprintf("%d", r.DocsCount),
+			fmt.Sprintf("%d", r.FieldsCount),
+			fmt.Sprintf("%.3fs", r.Duration.Seconds()),
+			"OK",
+		})
+	}
+
+	table.Render()
+	return out.Bytes()
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func testResults() *Results {
+	return &Results{
+		Duration: 2 * time.Second,
+		Collections: map[string]*Result{
+			"shop.users": {
+				Database:     "shop",
+				Collection:   "users",
+				Plan:         "local",
+				Duration:     1500 * time.Millisecond,
+				AllDocsCount: 10,
+				DocsCount:    10,
+				FieldsCount:  1,
+				Fields:       analysis.Fields{{Name: "_id", Count: 10, Types: analysis.Types{{Name: "int", Count: 10}}}},
+			},
+		},
+		Errors: map[string]string{
+			"shop.orders": "Cannot count documents in collection: timeout.",
+		},
+	}
+}
+
+func TestResults_Namespaces(t *testing.T) {
+	assert.Equal(t, []string{"shop.orders", "shop.users"}, testResults().Namespaces())
+}
+
+func TestFormatResults_Table(t *testing.T) {
+	out, err := FormatResults(testResults(), &Config{Format: "table"})
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), "NAMESPACE")
+	assert.Contains(t, string(out), "shop.orders │")
+	assert.Contains(t, string(out), "Cannot count documents in collection: timeout.")
+	assert.Contains(t, string(out), "1.500s")
+	assert.Regexp(t, "shop.users +│ 10 +│ 10 +│ 1 +│ 1.500s +│ OK", string(out))
+}
+
+func TestFormatResults_Json(t *testing.T) {
+	out, err := FormatResults(testResults(), &Config{Format: "json", FilePath: "out.json"})
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), `"collections":{"shop.users":{"database":"shop","collection":"users"`)
+	assert.Contains(t, string(out), `"errors":{"shop.orders":"Cannot count documents in collection: timeout."}`)
+}
+
+func TestFormatResults_Yaml(t *testing.T) {
+	out, err := FormatResults(testResults(), &Config{Format: "yaml"})
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), "collections:\n  shop.users:\n    database: shop\n")
+	assert.Contains(t, string(out), "errors:\n  shop.orders: 'Cannot count documents in collection: timeout.'\n")
+}
@@ -9,10 +9,13 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 const allDocumentsTitle = "all documents"
 const analyzedDocumentsTitle = "analyzed documents"
+const shapesTitle = "document shapes"
+const truncatedMark = " [truncated]"
 
 type style struct {
 	line      func(a ...interface{}) string
@@ -25,6 +28,7 @@ type style struct {
 	typeCount func(a ...interface{}) string
 	pct       func(a ...interface{}) string
 	typePct   func(a ...interface{}) string
+	truncated func(a ...interface{}) string
 
 	objectName  func(a ...interface{}) string
 	objectCount func(a ...interface{}) string
@@ -85,6 +89,7 @@ func NewTableFormatter(colorOutput bool) *TableFormatter {
 			typeCount: color.New(color.FgGreen).SprintFunc(),
 			pct:       color.New(color.Bold).SprintFunc(),
 			typePct:   color.New(color.FgGreen).SprintFunc(),
+			truncated: color.New(color.FgRed).SprintFunc(),
 
 			objectName:  color.New(color.FgYellow).SprintFunc(),
 			objectCount: color.New(color.Bold, color.FgYellow).SprintFunc(),
@@ -104,6 +109,7 @@ func NewTableFormatter(colorOutput bool) *TableFormatter {
 			typeCount: noOp,
 			pct:       noOp,
 			typePct:   noOp,
+			truncated: noOp,
 
 			objectName:  noOp,
 			objectCount: noOp,
@@ -173,22 +179,63 @@ func (f *TableFormatter) RenderResults(result *Result) []byte {
 		f.processField(previous, result.Fields[i], next)
 	}
 
+	// Append shapes
+	if len(result.Shapes) > 0 {
+		f.appendShapeRows(result)
+	}
+
 	f.table.Render()
 	return f.out.Bytes()
 }
 
+// Shapes are printed with differences from the first (dominant) shape.
+func (f *TableFormatter) appendShapeRows(result *Result) {
+	f.table.Append([]string{"", "", ""})
+	f.table.Append([]string{
+		f.style.infoKey(shapesTitle),
+		f.style.count(f.format.count(result.ShapesCount)),
+		"",
+	})
+
+	for i, shape := range result.Shapes {
+		name := fmt.Sprintf("shape %d", i+1)
+		if i == 0 {
+			name += " (dominant)"
+		}
+
+		f.table.Append([]string{
+			f.style.key(name),
+			f.style.count(f.format.count(shape.Count)),
+			f.style.pct(f.format.pct(shape.Count, result.DocsCount)),
+		})
+
+		diff := make([]string, 0, len(shape.Added)+len(shape.Removed))
+		for _, field := range shape.Added {
+			diff = append(diff, "+ "+field)
+		}
+		for _, field := range shape.Removed {
+			diff = append(diff, "- "+field)
+		}
+
+		for j, line := range diff {
+			symbol := f.symbols.lineLast
+			if j == len(diff)-1 {
+				symbol = f.symbols.lineEndLastS
+			}
+			f.table.Append([]string{f.style.line(symbol) + line, "", ""})
+		}
+	}
+}
+
 func (f *TableFormatter) processField(previous *analysis.Field, field *analysis.Field, next *analysis.Field) {
 	// Fields are sorted so that the parent field is processed first
 	f.countMap[field.Name] = field.Count
 
 	// Get short field name and parent count
-	parts := strings.Split(field.Name, analysis.NameSeparator)
-	l := len(parts)
-	shortKey := parts[l-1]
-	parentKey := strings.Join(parts[:(l-1)], analysis.NameSeparator)
+	parentKey, shortKey := analysis.SplitParentName(field.Name)
 	parentCount := f.countMap[parentKey]
 
-	f.appendFieldRow(previous, field, next, shortKey, parentCount)
+	f.appendFieldRow(previous, field, next, displayKey(shortKey), parentCount)
 	if len(field.Types) > 1 {
 		f.appendTypeRows(previous, field, next, field.Count)
 	}
@@ -207,9 +254,10 @@ func (f *TableFormatter) appendFieldRow(previous *analysis.Field, field *analysi
 		}
 
 		typeStr = fmt.Sprintf(
-			"%s%s",
+			"%s%s%s",
 			f.style.typeArrow(" "+f.symbols.typeArrow),
 			typeNameStr,
+			f.truncatedMark(field.Types[0]),
 		)
 	}
 
@@ -221,6 +269,17 @@ func (f *TableFormatter) appendFieldRow(previous *analysis.Field, field *analysi
 		pctStr = ""
 	}
 
+	// Positional array item, eg. [0] => [item 0]
+	if analysis.IsArrayPositionMark(shortKey) {
+		keyStr = f.style.arrayItem("[item " + shortKey[1:len(shortKey)-1] + "]")
+	}
+
+	// Values of collapsed object with dynamic keys too
+	if shortKey == analysis.MapKeyMark {
+		keyStr = f.style.arrayItem("[any key]")
+		pctStr = ""
+	}
+
 	// Append field name, count and percentage
 	f.table.Append([]string{
 		fmt.Sprintf("%s%s%s",
@@ -248,10 +307,11 @@ func (f *TableFormatter) appendTypeRows(previous *analysis.Field, field *analysi
 
 		// Append type name, count and percentage
 		f.table.Append([]string{
-			fmt.Sprintf("%s%s%s",
+			fmt.Sprintf("%s%s%s%s",
 				f.style.line(f.generateTypeLine(previous, field, next, i+1, t)),
 				f.symbols.typeArrow,
 				typeStr,
+				f.truncatedMark(t),
 			),
 			countStr,
 			f.style.typePct(f.format.pct(t.Count, fieldCount)),
@@ -259,6 +319,22 @@ func (f *TableFormatter) appendTypeRows(previous *analysis.Field, field *analysi
 	}
 }
 
+// Objects or arrays not fully analyzed because of limits are marked.
+func (f *TableFormatter) truncatedMark(t *analysis.Type) string {
+	if t.Truncation == nil {
+		return ""
+	}
+	return f.style.truncated(truncatedMark)
+}
+
+// Keys with control characters would break the table, so they are printed as quoted Go strings.
+func displayKey(segment string) string {
+	if strings.IndexFunc(segment, unicode.IsControl) < 0 {
+		return segment
+	}
+	return strconv.Quote(analysis.UnescapeKey(segment))
+}
+
 func (f *TableFormatter) generateFieldLine(previous *analysis.Field, field *analysis.Field, next *analysis.Field) string {
 	// Line end bound
 	levelDiff := int(field.Level)
@@ -14,6 +14,70 @@ import (
 )
 
 // https://github.com/mongoeye/mongoeye/issues/11
+func TestFormat_TABLE_Map(t *testing.T) {
+	color.NoColor = true
+
+	result := Result{
+		Plan:         "local",
+		Duration:     20 * time.Millisecond,
+		AllDocsCount: 2,
+		DocsCount:    2,
+		FieldsCount:  3,
+		Fields: analysis.Fields{
+			{Name: "stats", Count: 2, Level: 0, Types: analysis.Types{{Name: "object", Count: 2}}},
+			{Name: "stats.{key}", Count: 10, Level: 1, Types: analysis.Types{{Name: "object", Count: 10}}},
+			{Name: "stats.{key}.views", Count: 10, Level: 2, Types: analysis.Types{{Name: "int", Count: 10}}},
+		},
+	}
+
+	cmd := &cobra.Command{}
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+
+	cmd.ParseFlags([]string{"cmd", "--format", "table"})
+	config, err := GetConfig(v)
+	assert.Equal(t, nil, err)
+
+	out, _ := Format(result, config)
+	assert.Contains(t, string(out), "[any key] ➜ object")
+	assert.NotContains(t, string(out), "500.0")
+}
+
+func TestFormat_TABLE_Shapes(t *testing.T) {
+	color.NoColor = true
+
+	result := Result{
+		Plan:         "local",
+		Duration:     20 * time.Millisecond,
+		AllDocsCount: 4,
+		DocsCount:    4,
+		FieldsCount:  2,
+		Fields: analysis.Fields{
+			{Name: "a", Count: 4, Level: 0, Types: analysis.Types{{Name: "int", Count: 4}}},
+			{Name: "b", Count: 1, Level: 0, Types: analysis.Types{{Name: "int", Count: 1}}},
+		},
+		ShapesCount: 2,
+		Shapes: analysis.Shapes{
+			{Count: 3, Examples: []interface{}{1, 2, 3}},
+			{Count: 1, Examples: []interface{}{4}, Added: []string{"b (int)"}},
+		},
+	}
+
+	cmd := &cobra.Command{}
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+
+	cmd.ParseFlags([]string{"cmd", "--format", "table"})
+	config, err := GetConfig(v)
+	assert.Equal(t, nil, err)
+
+	out, _ := Format(result, config)
+	assert.Contains(t, string(out), "document shapes")
+	assert.Contains(t, string(out), "shape 1 (dominant)")
+	assert.Contains(t, string(out), "75.0")
+	assert.Contains(t, string(out), "└╴+ b (int)")
+}
+
 func TestFormat_TABLE_OneItem(t *testing.T) {
 	color.NoColor = true
 
@@ -642,3 +706,104 @@ func TestFormat_TABLE_FileOutput(t *testing.T) {
 
 	assert.Equal(t, strings.Join(expected, "\n"), string(out))
 }
+
+func TestFormat_TABLE_Truncated(t *testing.T) {
+	color.NoColor = true
+
+	result := Result{
+		Plan:         "local",
+		Duration:     20 * time.Millisecond,
+		AllDocsCount: 2,
+		DocsCount:    2,
+		FieldsCount:  2,
+		Fields: analysis.Fields{
+			{Name: "a", Count: 2, Level: 0, Types: analysis.Types{{Name: "int", Count: 2}}},
+			{Name: "tags", Count: 2, Level: 0, Types: analysis.Types{
+				{Name: "array", Count: 1, Truncation: &analysis.Truncation{Arrays: 1, Items: 5, MaxItems: 5}},
+				{Name: "null", Count: 1},
+			}},
+		},
+	}
+
+	cmd := &cobra.Command{}
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+
+	cmd.ParseFlags([]string{"cmd", "--format", "table"})
+	config, err := GetConfig(v)
+	assert.Equal(t, nil, err)
+
+	out, _ := Format(result, config)
+	assert.Contains(t, string(out), "array [truncated]")
+	assert.NotContains(t, string(out), "int [truncated]")
+	assert.Equal(t, 1, truncatedFieldsCount(result.Fields))
+}
+
+func TestFormat_TABLE_ArrayPositions(t *testing.T) {
+	color.NoColor = true
+
+	result := Result{
+		Plan:         "local",
+		Duration:     20 * time.Millisecond,
+		AllDocsCount: 2,
+		DocsCount:    2,
+		FieldsCount:  3,
+		Fields: analysis.Fields{
+			{Name: "point", Count: 2, Level: 0, Types: analysis.Types{{Name: "array", Count: 2}}},
+			{Name: "point.[0]", Count: 1, Level: 1, Types: analysis.Types{{Name: "double", Count: 1}}},
+			{Name: "point.[]", Count: 3, Level: 1, Types: analysis.Types{{Name: "double", Count: 3}}},
+		},
+	}
+
+	cmd := &cobra.Command{}
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+
+	cmd.ParseFlags([]string{"cmd", "--format", "table"})
+	config, err := GetConfig(v)
+	assert.Equal(t, nil, err)
+
+	out, _ := Format(result, config)
+	assert.Contains(t, string(out), "[item 0] ➜ double")
+	assert.Contains(t, string(out), "50.0")
+}
+
+func TestFormat_TABLE_EscapedKeys(t *testing.T) {
+	color.NoColor = true
+
+	result := Result{
+		Plan:         "local",
+		Duration:     20 * time.Millisecond,
+		AllDocsCount: 2,
+		DocsCount:    2,
+		FieldsCount:  4,
+		Fields: analysis.Fields{
+			{Name: `""`, Count: 2, Level: 0, Types: analysis.Types{{Name: "string", Count: 2}}},
+			{Name: `"a.b"`, Count: 2, Level: 0, Types: analysis.Types{{Name: "object", Count: 2}}},
+			{Name: `"a.b".c`, Count: 2, Level: 1, Types: analysis.Types{{Name: "int", Count: 2}}},
+			{Name: "line\nbreak", Count: 1, Level: 0, Types: analysis.Types{{Name: "int", Count: 1}}},
+		},
+	}
+
+	cmd := &cobra.Command{}
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+
+	cmd.ParseFlags([]string{"cmd", "--format", "table"})
+	config, err := GetConfig(v)
+	assert.Equal(t, nil, err)
+
+	out, _ := Format(result, config)
+	assert.Contains(t, string(out), `"" ➜ string`)
+	assert.Contains(t, string(out), `"a.b" ➜ object`)
+	assert.Contains(t, string(out), `c ➜ int`)
+	assert.Contains(t, string(out), `"line\nbreak" ➜ int`)
+	assert.NotContains(t, string(out), "line\nbreak")
+}
+
+func TestDisplayKey(t *testing.T) {
+	assert.Equal(t, "abc", displayKey("abc"))
+	assert.Equal(t, `"a.b"`, displayKey(`"a.b"`))
+	assert.Equal(t, `"a\tb"`, displayKey("a\tb"))
+	assert.Equal(t, `"a.\nb"`, displayKey(analysis.EscapeKey("a.\nb")))
+}
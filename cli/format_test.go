@@ -22,9 +22,12 @@ func TestFormat_JSON(t *testing.T) {
 		FieldsCount:  1,
 		Fields: analysis.Fields{
 			{
-				Name:  "_id",
-				Level: 0,
-				Count: 1000,
+				Name:            "_id",
+				Level:           0,
+				Count:           1000,
+				DocsCount:       1000,
+				ParentsCount:    1000,
+				PresentInParent: 100,
 				Types: analysis.Types{
 					{
 						Name:  "objectId",
@@ -84,6 +87,10 @@ func TestFormat_JSON(t *testing.T) {
 			"name": "_id",
 			"level": 0,
 			"count": 1000,
+			"docs": 1000,
+			"parents": 1000,
+			"missing": 0,
+			"presentInParent": 100,
 			"types": [
 				{
 					"type": "objectId",
@@ -130,9 +137,12 @@ func TestFormat_JSON_FileOutput(t *testing.T) {
 		FieldsCount:  1,
 		Fields: analysis.Fields{
 			{
-				Name:  "_id",
-				Level: 0,
-				Count: 1000,
+				Name:            "_id",
+				Level:           0,
+				Count:           1000,
+				DocsCount:       1000,
+				ParentsCount:    1000,
+				PresentInParent: 100,
 			},
 		},
 	}
@@ -148,7 +158,7 @@ func TestFormat_JSON_FileOutput(t *testing.T) {
 	out, err := Format(result, config)
 	assert.Equal(t, nil, err)
 
-	expected := `{"database":"db","collection":"col","plan":"local","duration":20000000,"allDocs":10000,"analyzedDocs":1000,"fieldsCount":1,"fields":[{"name":"_id","level":0,"count":1000,"types":null}]}`
+	expected := `{"database":"db","collection":"col","plan":"local","duration":20000000,"allDocs":10000,"analyzedDocs":1000,"fieldsCount":1,"fields":[{"name":"_id","level":0,"count":1000,"docs":1000,"parents":1000,"missing":0,"presentInParent":100,"types":null}]}`
 	assert.Equal(t, expected, string(out))
 }
 
@@ -163,9 +173,12 @@ func TestFormat_YAML(t *testing.T) {
 		FieldsCount:  1,
 		Fields: analysis.Fields{
 			{
-				Name:  "_id",
-				Count: 1000,
-				Level: 0,
+				Name:            "_id",
+				Count:           1000,
+				DocsCount:       1000,
+				ParentsCount:    1000,
+				PresentInParent: 100,
+				Level:           0,
 				Types: analysis.Types{
 					{
 						Name:  "objectId",
@@ -223,6 +236,10 @@ fields:
 - name: _id
   level: 0
   count: 1000
+  docs: 1000
+  parents: 1000
+  missing: 0
+  presentInParent: 100
   types:
   - type: objectId
     count: 1000
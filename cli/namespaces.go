@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/mongoeye/mongoeye/helpers"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"path"
+	"strings"
+)
+
+// Databases that are skipped by 'all-databases' option, unless 'include-system' is used.
+var systemDatabases = []string{"admin", "config", "local"}
+
+// Namespace of collection.
+type Namespace struct {
+	Database   string
+	Collection string
+}
+
+// String returns namespace in the form "database.collection".
+func (n Namespace) String() string {
+	return n.Database + "." + n.Collection
+}
+
+// ListNamespaces returns collections selected for analysis by 'all-collections' or 'all-databases' option.
+// Namespaces are sorted by database and collection name.
+func ListNamespaces(session driver.Session, config *Config) ([]Namespace, error) {
+	databases := []string{config.Database}
+	if config.AllDatabases {
+		names, err := session.DatabaseNames()
+		if err != nil {
+			return nil, fmt.Errorf("Cannot list databases: %s.\n", err)
+		}
+
+		databases = databases[:0]
+		for _, name := range names {
+			if config.IncludeSystem || !helpers.InStringSlice(name, systemDatabases) {
+				databases = append(databases, name)
+			}
+		}
+	}
+
+	namespaces := []Namespace{}
+	for _, dbName := range databases {
+		names, err := session.CollectionNames(dbName)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot list collections of database '%s': %s.\n", dbName, err)
+		}
+
+		views := map[string]bool{}
+		if !config.IncludeViews {
+			views, err = listViews(session, dbName)
+			if err != nil {
+				return nil, fmt.Errorf("Cannot list views of database '%s': %s.\n", dbName, err)
+			}
+		}
+
+		for _, name := range names {
+			ns := Namespace{Database: dbName, Collection: name}
+			if views[name] {
+				continue
+			}
+			if !config.IncludeSystem && strings.HasPrefix(name, "system.") {
+				continue
+			}
+			if !matchNamespace(ns.String(), config.IncludeNamespaces, config.ExcludeNamespaces) {
+				continue
+			}
+			namespaces = append(namespaces, ns)
+		}
+	}
+
+	return namespaces, nil
+}
+
+// List names of views in database.
+func listViews(session driver.Session, db string) (map[string]bool, error) {
+	views := map[string]bool{}
+
+	names, err := session.ViewNames(db)
+	for _, name := range names {
+		views[name] = true
+	}
+
+	return views, err
+}
+
+// Namespace matches if it matches any include pattern (or there is none) and it does not match any exclude pattern.
+// Patterns are globs, eg. "shop.order*", see path.Match.
+func matchNamespace(ns string, include []string, exclude []string) bool {
+	matched := len(include) == 0
+	for _, pattern := range include {
+		if ok, _ := path.Match(pattern, ns); ok {
+			matched = true
+			break
+		}
+	}
+
+	if !matched {
+		return false
+	}
+
+	for _, pattern := range exclude {
+		if ok, _ := path.Match(pattern, ns); ok {
+			return false
+		}
+	}
+
+	return true
+}
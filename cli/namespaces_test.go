@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"github.com/mongoeye/mongoeye/analyzer"
+	"github.com/mongoeye/mongoeye/tests"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+func TestNamespace_String(t *testing.T) {
+	assert.Equal(t, "shop.orders", Namespace{Database: "shop", Collection: "orders"}.String())
+}
+
+func Test_matchNamespace(t *testing.T) {
+	assert.True(t, matchNamespace("shop.orders", nil, nil))
+	assert.True(t, matchNamespace("shop.orders", []string{"shop.*"}, nil))
+	assert.True(t, matchNamespace("shop.orders", []string{"blog.*", "shop.order*"}, nil))
+	assert.False(t, matchNamespace("shop.orders", []string{"blog.*"}, nil))
+	assert.False(t, matchNamespace("shop.orders", nil, []string{"*.orders"}))
+	assert.False(t, matchNamespace("shop.orders", []string{"shop.*"}, []string{"shop.orders"}))
+	assert.True(t, matchNamespace("shop.orders", []string{"shop.*"}, []string{"shop.users"}))
+}
+
+func TestListNamespaces(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{"a": 1})
+
+	config := &Config{
+		Options:           analyzer.Options{Database: c.Database.Name},
+		AllCollections:    true,
+		IncludeNamespaces: []string{c.FullName},
+	}
+
+	namespaces, err := ListNamespaces(tests.TestDbDriver, config)
+	assert.Nil(t, err)
+	assert.Equal(t, []Namespace{{Database: c.Database.Name, Collection: c.Name}}, namespaces)
+
+	config.ExcludeNamespaces = []string{c.Database.Name + ".*"}
+	namespaces, err = ListNamespaces(tests.TestDbDriver, config)
+	assert.Nil(t, err)
+	assert.Equal(t, []Namespace{}, namespaces)
+}
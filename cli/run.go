@@ -1,17 +1,30 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analyzer"
+	"github.com/mongoeye/mongoeye/decoder"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"github.com/mongoeye/mongoeye/source"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"gopkg.in/mgo.v2"
 	"io"
 	"os"
+	"os/signal"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
 )
 
 var noOp = func(cmd *cobra.Command, args []string) error { return nil }
 
+// Connect to MongoDB collection, it is replaced in tests.
+var connectCollection = Connect
+
 // Run command.
 func Run(cmd *cobra.Command, config *Config) error {
 	var out io.Writer = cmd.OutOrStdout()
@@ -40,19 +53,34 @@ func Run(cmd *cobra.Command, config *Config) error {
 		fmt.Fprintf(out, "%s\n\n", cmd.Short)
 	}
 
-	// Connect to MongoDB
-	info, collection, count, err := connect(out, printInfo, config)
+	// Analysis of all collections of the database or of all databases
+	if config.HasMoreCollections() {
+		return runMoreCollections(out, outFile, printInfo, config)
+	}
+
+	// Connect to MongoDB or open input files
+	info, src, count, err := connect(out, printInfo, config)
 	if err != nil {
 		return err
 	}
+	if files, ok := src.(source.Files); ok {
+		defer files.Close()
+	}
 
-	// Generate possible plans of analysis
-	allPlans := generateAnalysisPlans(info, count, config)
+	// Analysis can be interrupted by Ctrl+C or by time limit
+	ctx, cancel := newAnalysisContext(config.MaxTime)
+	defer cancel()
 
 	// Run analysis
-	result, err := runAnalysis(out, printInfo, allPlans, collection)
-	if err != nil {
-		return err
+	result, err := runAnalysis(ctx, out, printInfo, info, count, config, src)
+	if err == context.DeadlineExceeded {
+		return fmt.Errorf("Analysis exceeded the time limit %s set by 'max-time' option.\n", config.MaxTime)
+	} else if err == context.Canceled {
+		return errors.New("Analysis was interrupted.\n")
+	} else if _, ok := err.(*decoder.CorruptedError); ok {
+		return fmt.Errorf("%s.\nUse 'skip-corrupted' option to skip corrupted documents.\n", err)
+	} else if err != nil {
+		return fmt.Errorf("%s\n", err)
 	}
 
 	// Format results
@@ -68,6 +96,14 @@ func Run(cmd *cobra.Command, config *Config) error {
 		outFile.Write(output)
 	}
 
+	// Set validator of the collection
+	if config.ApplySchema {
+		c := src.(*analysis.CollectionSource).Collection
+		if err := ApplyJsonSchema(c, analyzer.JsonSchema(&result, &config.Options)); err != nil {
+			return fmt.Errorf("Cannot apply $jsonSchema validator: %s.\n", err)
+		}
+	}
+
 	// Footer
 	if printInfo {
 		if outFile != nil {
@@ -80,29 +116,90 @@ func Run(cmd *cobra.Command, config *Config) error {
 
 		// Print statistics
 		plan := "analysis in database"
-		if result.Plan == "local" {
+		switch result.Plan {
+		case "local":
 			plan = "local analysis"
+		case "hybrid":
+			plan = "hybrid analysis"
 		}
 		fmt.Fprintf(
 			out,
-			"\nOK  %.3fs (%s)\n    %d/%d docs (%.1f%%)\n    %d fields, depth %d\n",
+			"\nOK  %.3fs (%s)\n    %d/%d docs (%.1f%%)\n    %d fields, depth %s\n",
 			result.Duration.Seconds(),
 			plan,
 			result.DocsCount,
 			result.AllDocsCount,
 			(float64(result.DocsCount)/float64(result.AllDocsCount))*100.0,
 			result.FieldsCount,
-			config.Depth,
+			depthInfo(config),
 		)
+
+		if result.PlanReason != "" {
+			fmt.Fprintf(out, "    plan %s: %s\n", result.Plan, result.PlanReason)
+		}
+
+		if result.CorruptedDocsCount > 0 {
+			fmt.Fprintf(out, "    %d corrupted docs skipped\n", result.CorruptedDocsCount)
+		}
+
+		if count := truncatedFieldsCount(result.Fields); count > 0 {
+			fmt.Fprintf(out, "    %d fields truncated, see --depth and --array-max-length\n", count)
+		}
+
+		if config.ApplySchema {
+			fmt.Fprintf(out, "    $jsonSchema validator applied to %s.%s\n", config.Database, config.Collection)
+		}
 	}
 
 	return nil
 }
 
-// Connect to MongoDB, show spinner
-func connect(out io.Writer, printInfo bool, config *Config) (info mgo.BuildInfo, collection *mgo.Collection, count int, err error) {
+// Depth printed after the analysis, overrides are listed by path, eg. "2 (order.items=6)".
+func depthInfo(config *Config) string {
+	if len(config.DepthFor) == 0 {
+		return fmt.Sprint(config.Depth)
+	}
+
+	overrides := make([]string, 0, len(config.DepthFor))
+	for path, depth := range config.DepthFor {
+		overrides = append(overrides, fmt.Sprintf("%s=%d", path, depth))
+	}
+	sort.Strings(overrides)
+
+	return fmt.Sprintf("%d (%s)", config.Depth, strings.Join(overrides, ", "))
+}
+
+// Number of fields with objects or arrays not fully analyzed because of limits.
+func truncatedFieldsCount(fields analysis.Fields) int {
+	count := 0
+	for _, field := range fields {
+		for _, t := range field.Types {
+			if t.Truncation != nil {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// Connect to MongoDB or open input files, show spinner
+func connect(out io.Writer, printInfo bool, config *Config) (info driver.BuildInfo, src analysis.Source, count int, err error) {
 	task := func() {
-		info, _, collection, count, err = Connect(config)
+		if config.HasFileInput() {
+			var files source.Files
+			files, err = OpenFiles(config)
+			if err == nil {
+				src = files
+			}
+			return
+		}
+
+		var collection driver.Collection
+		info, _, collection, count, err = connectCollection(config)
+		if err == nil {
+			src = analysis.NewCollectionSource(collection)
+		}
 	}
 
 	if printInfo {
@@ -119,15 +216,57 @@ func connect(out io.Writer, printInfo bool, config *Config) (info mgo.BuildInfo,
 	return
 }
 
-// Run analysis, show spinner
-func runAnalysis(out io.Writer, printInfo bool, allPlans plans, collection *mgo.Collection) (result Result, err error) {
+// Create context of analysis, it is canceled on interrupt signal or when time limit is reached.
+// Zero time limit means unlimited.
+func newAnalysisContext(maxTime time.Duration) (context.Context, context.CancelFunc) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if maxTime > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), maxTime)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigCh)
+	}()
+
+	return ctx, cancel
+}
+
+// Choose plan and run analysis, show spinner
+func runAnalysis(ctx context.Context, out io.Writer, printInfo bool, info driver.BuildInfo, count int, config *Config, src analysis.Source) (result Result, err error) {
 	task := func() {
-		result = allPlans[0].Run(collection)
+		runtime.GOMAXPROCS(config.CreateAnalysisOptions().Concurrency)
+
+		var r *Result
+		r, err = analyzer.Analyze(ctx, src, count, info, &config.Options)
+		if r == nil {
+			return
+		}
+		result = *r
+
+		// Input files are counted while reading
+		if err == nil && config.HasFileInput() && result.AllDocsCount == 0 {
+			err = errors.New("Input files do not contain any document.")
+		}
 	}
 
 	if printInfo {
 		RunWithSpinner(out, "Analyzing:", task)
-		fmt.Fprint(out, "OK\n\n")
+		if err == nil {
+			fmt.Fprint(out, "OK\n\n")
+		} else {
+			fmt.Fprint(out, "Error\n\n")
+		}
 	} else {
 		task()
 	}
@@ -153,6 +292,11 @@ func PreRun(cmd *cobra.Command, v *viper.Viper, osArgs []string, args []string)
 		return nil
 	}
 
+	// Analysis of more collections has different arguments
+	if v.GetBool("all-databases") || v.GetBool("all-collections") {
+		return preRunMoreCollections(v, args)
+	}
+
 	// Arguments
 	if len(args) == 1 {
 		v.Set("col", args[0])
@@ -171,8 +315,45 @@ func PreRun(cmd *cobra.Command, v *viper.Viper, osArgs []string, args []string)
 	}
 
 	// Validate arguments
-	if v.GetString("db") == "" || v.GetString("col") == "" || v.GetString("host") == "" {
-		return errors.New("Please specify the name of the database, collection, and host,\nusing arguments, flags, or environment variables.\n")
+	if len(v.GetStringSlice("dump")) > 0 || len(v.GetStringSlice("json")) > 0 {
+		return nil
+	}
+	if !hasDatabase(v) || v.GetString("col") == "" {
+		return errors.New("Please specify the name of the database and collection,\nusing arguments, flags, connection string, or environment variables.\n")
+	}
+
+	return nil
+}
+
+// Database can be also set in the path of connection string.
+func hasDatabase(v *viper.Viper) bool {
+	return v.GetString("db") != "" || databaseFromURI(v.GetString("uri")) != "" || databaseFromURI(v.GetString("host")) != ""
+}
+
+// Arguments are [host] with 'all-databases' option and [[host] db] with 'all-collections' option.
+func preRunMoreCollections(v *viper.Viper, args []string) error {
+	if v.GetBool("all-databases") {
+		if len(args) == 1 {
+			v.Set("host", args[0])
+		}
+		if len(args) > 1 {
+			return errors.New("Too many arguments.\n")
+		}
+		return nil
+	}
+
+	if len(args) == 1 {
+		v.Set("db", args[0])
+	}
+	if len(args) == 2 {
+		v.Set("host", args[0])
+		v.Set("db", args[1])
+	}
+	if len(args) > 2 {
+		return errors.New("Too many arguments.\n")
+	}
+	if !hasDatabase(v) {
+		return errors.New("Please specify the name of the database,\nusing arguments, flags, connection string, or environment variables.\n")
 	}
 
 	return nil
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analyzer"
+	"github.com/mongoeye/mongoeye/decoder"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Run analysis of all collections of the database or of all databases.
+// Collections are analyzed concurrently, failed analysis of one collection does not stop the others.
+func runMoreCollections(out io.Writer, outFile *os.File, printInfo bool, config *Config) error {
+	// Connect to MongoDB and list collections
+	var info driver.BuildInfo
+	var session driver.Session
+	var namespaces []Namespace
+	var err error
+	task := func() {
+		info, session, err = Dial(config)
+		if err == nil {
+			namespaces, err = ListNamespaces(session, config)
+		}
+	}
+
+	if printInfo {
+		RunWithSpinner(out, "Connecting:", task)
+		if err == nil {
+			fmt.Fprint(out, "OK\n\n")
+		} else {
+			fmt.Fprint(out, "Error\n\n")
+		}
+	} else {
+		task()
+	}
+
+	if session != nil {
+		defer session.Close()
+	}
+	if err != nil {
+		return err
+	}
+	if len(namespaces) == 0 {
+		return errors.New("No collection matches given options.\n")
+	}
+
+	// Analysis can be interrupted by Ctrl+C, time limit is applied to each collection
+	ctx, cancel := newAnalysisContext(0)
+	defer cancel()
+
+	// Run analyses
+	var results *Results
+	task = func() {
+		runtime.GOMAXPROCS(int(config.Concurrency))
+		results = analyzeNamespaces(ctx, session, info, namespaces, config)
+	}
+
+	if printInfo {
+		RunWithSpinner(out, fmt.Sprintf("Analyzing %d collections:", len(namespaces)), task)
+		if len(results.Errors) == 0 {
+			fmt.Fprint(out, "OK\n\n")
+		} else {
+			fmt.Fprint(out, "Error\n\n")
+		}
+	} else {
+		task()
+	}
+
+	if ctx.Err() == context.Canceled {
+		return errors.New("Analysis was interrupted.\n")
+	}
+
+	// Format results
+	output, err := FormatResults(results, config)
+	if err != nil {
+		return fmt.Errorf("Cannot format results: %s.\n", err)
+	}
+
+	// Write results
+	if outFile == nil {
+		out.Write(output)
+	} else {
+		outFile.Write(output)
+	}
+
+	// Footer
+	if printInfo {
+		if outFile != nil {
+			fmt.Fprintf(
+				out,
+				"The analysis results were written to the file: %s.\n",
+				outFile.Name(),
+			)
+		}
+
+		fmt.Fprintf(
+			out,
+			"\nOK  %.3fs\n    %d collections analyzed, %d failed\n",
+			results.Duration.Seconds(),
+			len(results.Collections),
+			len(results.Errors),
+		)
+	}
+
+	return nil
+}
+
+// Analyze collections using a pool of 'parallel' workers.
+// The session is copied for each collection, so the connection is not dialled again.
+func analyzeNamespaces(ctx context.Context, session driver.Session, info driver.BuildInfo, namespaces []Namespace, config *Config) *Results {
+	results := &Results{
+		Collections: make(map[string]*Result, len(namespaces)),
+		Errors:      make(map[string]string),
+	}
+	mutex := &sync.Mutex{}
+
+	input := make(chan Namespace)
+	wg := &sync.WaitGroup{}
+	wg.Add(int(config.Parallel))
+
+	start := time.Now()
+	for i := uint(0); i < config.Parallel; i++ {
+		go func() {
+			defer wg.Done()
+			for ns := range input {
+				result, err := analyzeNamespace(ctx, session, info, ns, config)
+
+				mutex.Lock()
+				if err == nil {
+					results.Collections[ns.String()] = &result
+				} else {
+					results.Errors[ns.String()] = oneLineError(err)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for _, ns := range namespaces {
+		if ctx.Err() != nil {
+			break
+		}
+		input <- ns
+	}
+	close(input)
+	wg.Wait()
+
+	results.Duration = time.Since(start)
+
+	return results
+}
+
+// Analyze one collection with the copy of the session.
+func analyzeNamespace(ctx context.Context, session driver.Session, info driver.BuildInfo, ns Namespace, config *Config) (Result, error) {
+	s := session.Copy()
+	defer s.Close()
+
+	c := *config
+	c.Database = ns.Database
+	c.Collection = ns.Collection
+
+	collection := s.Collection(ns.Database, ns.Collection)
+	count, err := countDocuments(collection, &c)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// Empty collection has no fields
+	if count == 0 {
+		return Result{Database: ns.Database, Collection: ns.Collection, Fields: analysis.Fields{}}, nil
+	}
+
+	if c.MaxTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.MaxTime)
+		defer cancel()
+	}
+
+	var result Result
+	r, err := analyzer.Analyze(ctx, analysis.NewCollectionSource(collection), count, info, &c.Options)
+	if r != nil {
+		result = *r
+	}
+
+	if err == context.DeadlineExceeded {
+		return Result{}, fmt.Errorf("Analysis exceeded the time limit %s set by 'max-time' option.", c.MaxTime)
+	} else if err == context.Canceled {
+		return Result{}, errors.New("Analysis was interrupted.")
+	} else if _, ok := err.(*decoder.CorruptedError); ok {
+		return Result{}, fmt.Errorf("%s. Use 'skip-corrupted' option to skip corrupted documents.", err)
+	} else if err != nil {
+		return Result{}, err
+	}
+
+	return result, nil
+}
+
+// Errors are stored as one line messages, so they can be printed in the summary table.
+func oneLineError(err error) string {
+	return strings.Replace(strings.TrimSpace(err.Error()), "\n", " ", -1)
+}
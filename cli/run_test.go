@@ -2,22 +2,27 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analyzer"
 	"github.com/mongoeye/mongoeye/helpers"
+	"github.com/mongoeye/mongoeye/mongo/driver"
 	"github.com/mongoeye/mongoeye/tests"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
-	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNoOp(t *testing.T) {
@@ -217,6 +222,281 @@ func TestPreRun_MissingDb(t *testing.T) {
 	assert.NotEqual(t, nil, err)
 }
 
+func TestPreRun_DatabaseFromURI(t *testing.T) {
+	os.Clearenv()
+
+	out := bytes.NewBuffer(nil)
+
+	cmd, v := NewCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(out)
+
+	osArgs := []string{"cmd", "--uri", "mongodb://host/shop", "orders"}
+	cmd.ParseFlags(osArgs)
+	err := PreRun(cmd, v, osArgs, []string{"orders"})
+
+	assert.Equal(t, nil, err)
+}
+
+func TestPreRun_Dump(t *testing.T) {
+	os.Clearenv()
+
+	out := bytes.NewBuffer(nil)
+
+	cmd, v := NewCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(out)
+
+	osArgs := []string{"cmd", "--dump", "orders.bson"}
+	cmd.ParseFlags(osArgs)
+	err := PreRun(cmd, v, osArgs, []string{})
+
+	assert.Equal(t, nil, err)
+}
+
+func TestPreRun_AllCollections(t *testing.T) {
+	os.Clearenv()
+
+	out := bytes.NewBuffer(nil)
+
+	cmd, v := NewCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(out)
+
+	osArgs := []string{"cmd", "--all-collections", "A", "B"}
+	cmd.ParseFlags(osArgs)
+	err := PreRun(cmd, v, osArgs, []string{"A", "B"})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "A", v.GetString("host"))
+	assert.Equal(t, "B", v.GetString("db"))
+	assert.Equal(t, "", v.GetString("col"))
+}
+
+func TestPreRun_AllCollectionsMissingDb(t *testing.T) {
+	os.Clearenv()
+
+	out := bytes.NewBuffer(nil)
+
+	cmd, v := NewCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(out)
+
+	osArgs := []string{"cmd", "--all-collections"}
+	cmd.ParseFlags(osArgs)
+	err := PreRun(cmd, v, osArgs, []string{})
+
+	assert.NotEqual(t, nil, err)
+}
+
+func TestPreRun_AllDatabases(t *testing.T) {
+	os.Clearenv()
+
+	out := bytes.NewBuffer(nil)
+
+	cmd, v := NewCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(out)
+
+	osArgs := []string{"cmd", "--all-databases", "A"}
+	cmd.ParseFlags(osArgs)
+	err := PreRun(cmd, v, osArgs, []string{"A"})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "A", v.GetString("host"))
+}
+
+func TestPreRun_AllDatabasesTooManyArguments(t *testing.T) {
+	os.Clearenv()
+
+	out := bytes.NewBuffer(nil)
+
+	cmd, v := NewCmd("cmd", "env", "name", "version", "subtitle")
+	cmd.SetOutput(out)
+
+	osArgs := []string{"cmd", "--all-databases", "A", "B"}
+	cmd.ParseFlags(osArgs)
+	err := PreRun(cmd, v, osArgs, []string{"A", "B"})
+
+	assert.Equal(t, "Too many arguments.\n", err.Error())
+}
+
+func TestRun_Dump(t *testing.T) {
+	color.NoColor = true
+
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "col.bson")
+	raw1, _ := bson.Marshal(bson.M{"_id": 1, "str": "Abc"})
+	raw2, _ := bson.Marshal(bson.M{"_id": 2, "int": 5})
+	ioutil.WriteFile(path, append(raw1, raw2...), 0644)
+
+	stdout := bytes.NewBuffer(nil)
+
+	cmd := &cobra.Command{}
+	cmd.SetOutput(stdout)
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+	cmd.ParseFlags([]string{
+		"cmd",
+		"--dump", path,
+		"--sample", "first:1",
+	})
+
+	config, _ := GetConfig(v)
+	err := Run(cmd, config)
+	assert.Equal(t, nil, err)
+
+	expected := []string{
+		"         KEY         │ COUNT  │   %    ",
+		"───────────────────────────────────────",
+		"  all documents      │ 2      │        ",
+		"  analyzed documents │ 1      │  50.0  ",
+		"                     │        │        ",
+		"  _id ➜ int          │ 1      │ 100.0  ",
+		"  str ➜ string       │ 1      │ 100.0  \n",
+	}
+
+	assert.Contains(t, stdout.String(), strings.Join(expected, "\n"))
+}
+
+func TestRun_DumpCorrupted(t *testing.T) {
+	color.NoColor = true
+
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "col.bson")
+	raw1, _ := bson.Marshal(bson.M{"_id": 1})
+	raw2, _ := bson.Marshal(bson.D{{Name: "_id", Value: 2}, {Name: "str", Value: "Abc"}})
+	raw2[len(raw2)-2] = 0x01 // damage the end of string
+	ioutil.WriteFile(path, append(raw1, raw2...), 0644)
+
+	cmd := &cobra.Command{}
+	cmd.SetOutput(bytes.NewBuffer(nil))
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+	cmd.ParseFlags([]string{
+		"cmd",
+		"--dump", path,
+		"--sample", "all",
+	})
+
+	config, _ := GetConfig(v)
+	err := Run(cmd, config)
+	assert.NotEqual(t, nil, err)
+	assert.Contains(t, err.Error(), "Document is corrupted")
+	assert.Contains(t, err.Error(), "skip-corrupted")
+
+	stdout := bytes.NewBuffer(nil)
+	cmd.SetOutput(stdout)
+	config.SkipCorrupted = true
+	err = Run(cmd, config)
+	assert.Equal(t, nil, err)
+	assert.Contains(t, stdout.String(), "1/2 docs (50.0%)")
+	assert.Contains(t, stdout.String(), "1 corrupted docs skipped")
+}
+
+func TestRun_DumpMaxTime(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "col.bson")
+	raw, _ := bson.Marshal(bson.M{"_id": 1, "str": "Abc"})
+	ioutil.WriteFile(path, raw, 0644)
+
+	cmd := &cobra.Command{}
+	cmd.SetOutput(bytes.NewBuffer(nil))
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+	cmd.ParseFlags([]string{
+		"cmd",
+		"--dump", path,
+		"--max-time", "0.000001",
+	})
+
+	config, _ := GetConfig(v)
+	err := Run(cmd, config)
+	assert.NotEqual(t, nil, err)
+	assert.Contains(t, err.Error(), "max-time")
+}
+
+func TestRun_DumpMissingFile(t *testing.T) {
+	cmd := &cobra.Command{}
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+	cmd.ParseFlags([]string{"cmd", "--dump", "/non/existing/file.bson"})
+
+	config, _ := GetConfig(v)
+	err := Run(cmd, config)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestRun_DumpEmpty(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "col.bson")
+	ioutil.WriteFile(path, []byte{}, 0644)
+
+	cmd := &cobra.Command{}
+	cmd.SetOutput(bytes.NewBuffer(nil))
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+	cmd.ParseFlags([]string{"cmd", "--dump", path})
+
+	config, _ := GetConfig(v)
+	err := Run(cmd, config)
+	assert.Equal(t, errors.New("Input files do not contain any document.\n"), err)
+}
+
+func TestRun_JsonInput(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	defer os.RemoveAll(dir)
+
+	docs := []bson.D{
+		{{Name: "_id", Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c1")}, {Name: "int", Value: 5}, {Name: "long", Value: int64(1)}},
+		{{Name: "_id", Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c2")}, {Name: "dec", Value: helpers.ParseDecimal("1.5")}},
+		{{Name: "_id", Value: bson.ObjectIdHex("58e20d849d3ae7e1f8eac9c3")}, {Name: "date", Value: time.Date(2017, 5, 1, 10, 0, 0, 0, time.UTC)}},
+	}
+	lines := []string{
+		`{"_id": {"$oid": "58e20d849d3ae7e1f8eac9c1"}, "int": 5, "long": {"$numberLong": "1"}}`,
+		`{"_id": {"$oid": "58e20d849d3ae7e1f8eac9c2"}, "dec": {"$numberDecimal": "1.5"}}`,
+		`{"_id": {"$oid": "58e20d849d3ae7e1f8eac9c3"}, "date": {"$date": "2017-05-01T10:00:00Z"}}`,
+	}
+
+	bsonPath := filepath.Join(dir, "col.bson")
+	bsonData := []byte{}
+	for _, doc := range docs {
+		raw, _ := bson.Marshal(doc)
+		bsonData = append(bsonData, raw...)
+	}
+	ioutil.WriteFile(bsonPath, bsonData, 0644)
+
+	jsonPath := filepath.Join(dir, "col.json")
+	ioutil.WriteFile(jsonPath, []byte(strings.Join(lines, "\n")), 0644)
+
+	analyze := func(args ...string) Result {
+		cmd := &cobra.Command{}
+		v := viper.New()
+		InitFlags(cmd, v, "env")
+		cmd.ParseFlags(append([]string{"cmd", "--sample", "all", "--full"}, args...))
+
+		config, err := GetConfig(v)
+		assert.Equal(t, nil, err)
+
+		src, err := OpenFiles(config)
+		assert.Equal(t, nil, err)
+		defer src.Close()
+
+		result, err := analyzer.Analyze(context.Background(), src, 0, driver.BuildInfo{}, &config.Options)
+		assert.Equal(t, nil, err)
+		return *result
+	}
+
+	fromBson := analyze("--dump", bsonPath)
+	fromJson := analyze("--json", jsonPath)
+
+	assert.Equal(t, uint64(3), fromJson.AllDocsCount)
+	assert.Equal(t, fromBson.Fields, fromJson.Fields)
+}
+
 func TestRun_ConnectError(t *testing.T) {
 	cmd := &cobra.Command{}
 	v := viper.New()
@@ -277,6 +557,95 @@ func TestRun_Table(t *testing.T) {
 	assert.Contains(t, stdout.String(), "OK")
 }
 
+func TestRun_AllCollections(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{"_id": 1, "str": "Abc"})
+
+	stdout := bytes.NewBuffer(nil)
+
+	cmd := &cobra.Command{}
+	cmd.SetOutput(stdout)
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+	cmd.ParseFlags([]string{
+		"cmd",
+		"--host", tests.TestDbUri,
+		"--db", c.Database.Name,
+		"--all-collections",
+		"--include-ns", c.FullName,
+		"--sample", "all",
+		"--format", "json",
+	})
+
+	config, _ := GetConfig(v)
+	err := Run(cmd, config)
+	assert.Equal(t, nil, err)
+
+	results := Results{}
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &results))
+	assert.Equal(t, 1, len(results.Collections))
+	assert.Equal(t, 0, len(results.Errors))
+	assert.Equal(t, uint64(2), results.Collections[c.FullName].FieldsCount)
+}
+
+func TestRun_AllCollectionsNoMatch(t *testing.T) {
+	stdout := bytes.NewBuffer(nil)
+
+	cmd := &cobra.Command{}
+	cmd.SetOutput(stdout)
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+	cmd.ParseFlags([]string{
+		"cmd",
+		"--host", tests.TestDbUri,
+		"--db", "_test",
+		"--all-collections",
+		"--include-ns", "_test.missing_collection",
+	})
+
+	config, _ := GetConfig(v)
+	err := Run(cmd, config)
+	assert.Equal(t, errors.New("No collection matches given options.\n"), err)
+}
+
+func TestRun_ApplySchema(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(
+		bson.M{"_id": 1, "str": "Abc"},
+		bson.M{"_id": 2, "str": "Xyz"},
+	)
+
+	stdout := bytes.NewBuffer(nil)
+
+	cmd := &cobra.Command{}
+	cmd.SetOutput(stdout)
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+	cmd.ParseFlags([]string{
+		"cmd",
+		"--host", tests.TestDbUri,
+		"--db", c.Database.Name,
+		"--col", c.Name,
+		"--sample", "all",
+		"--format", "jsonschema",
+		"--apply-schema",
+	})
+
+	config, _ := GetConfig(v)
+	err := Run(cmd, config)
+	assert.Equal(t, nil, err)
+	assert.Contains(t, stdout.String(), `"$jsonSchema"`)
+
+	// Documents must match the schema
+	assert.Nil(t, c.Insert(bson.M{"_id": 3, "str": "Def"}))
+	assert.NotNil(t, c.Insert(bson.M{"_id": 4, "str": 5}))
+	assert.NotNil(t, c.Insert(bson.M{"_id": 5}))
+}
+
 func TestRun_Table_Color(t *testing.T) {
 	color.NoColor = false
 
@@ -431,12 +800,20 @@ func TestRun_Json(t *testing.T) {
 	"duration": <DURATION>,
 	"allDocs": 1,
 	"analyzedDocs": 1,
+	"limits": {
+		"depth": 2,
+		"arrayMaxLength": 20
+	},
 	"fieldsCount": 2,
 	"fields": [
 		{
 			"name": "_id",
 			"level": 0,
 			"count": 1,
+			"docs": 1,
+			"parents": 1,
+			"missing": 0,
+			"presentInParent": 100,
 			"types": [
 				{
 					"type": "objectId",
@@ -448,6 +825,10 @@ func TestRun_Json(t *testing.T) {
 			"name": "str",
 			"level": 0,
 			"count": 1,
+			"docs": 1,
+			"parents": 1,
+			"missing": 0,
+			"presentInParent": 100,
 			"types": [
 				{
 					"type": "string",
@@ -505,12 +886,20 @@ func TestRun_Json_File(t *testing.T) {
 	"duration": <DURATION>,
 	"allDocs": 1,
 	"analyzedDocs": 1,
+	"limits": {
+		"depth": 2,
+		"arrayMaxLength": 20
+	},
 	"fieldsCount": 2,
 	"fields": [
 		{
 			"name": "_id",
 			"level": 0,
 			"count": 1,
+			"docs": 1,
+			"parents": 1,
+			"missing": 0,
+			"presentInParent": 100,
 			"types": [
 				{
 					"type": "objectId",
@@ -522,6 +911,10 @@ func TestRun_Json_File(t *testing.T) {
 			"name": "str",
 			"level": 0,
 			"count": 1,
+			"docs": 1,
+			"parents": 1,
+			"missing": 0,
+			"presentInParent": 100,
 			"types": [
 				{
 					"type": "string",
@@ -578,6 +971,9 @@ plan: local
 duration: <DURATION>
 allDocs: 1
 analyzedDocs: 1
+limits:
+  depth: 2
+  arrayMaxLength: 20
 fieldsCount: 2
 fields:
 - name: _id
@@ -646,6 +1042,9 @@ plan: local
 duration: <DURATION>
 allDocs: 1
 analyzedDocs: 1
+limits:
+  depth: 2
+  arrayMaxLength: 20
 fieldsCount: 2
 fields:
 - name: _id
@@ -687,14 +1086,14 @@ func TestRun_IncompatibleWithAggregationAlgorithm(t *testing.T) {
 		"--db", c.Database.Name,
 		"--col", c.Name,
 		"--sample", "all",
-		"--use-aggregation",
+		"--plan", "db",
 	})
 
 	config, _ := GetConfig(v)
 	err := Run(cmd, config)
 
 	assert.Equal(t,
-		fmt.Sprintf("Option 'use-aggregation' require MongoDB version >= %s.\n", helpers.VersionToString(analysis.AggregationMinVersion...)),
+		fmt.Sprintf("Plan 'db' require MongoDB version >= %s.\n", helpers.VersionToString(analysis.AggregationMinVersion...)),
 		err.Error(),
 	)
 }
@@ -733,11 +1132,13 @@ func TestRun_IncompatibleWithSampleStage(t *testing.T) {
 
 func Test_checkCompatibility(t *testing.T) {
 	config := &Config{
-		UseAggregation: true,
-		SampleMethod:   "random",
+		Options: analyzer.Options{
+			Plan:         "db",
+			SampleMethod: "random",
+		},
 	}
 
-	info := mgo.BuildInfo{
+	info := driver.BuildInfo{
 		Version:      analysis.AggregationMinVersionStr,
 		VersionArray: analysis.AggregationMinVersion,
 	}
@@ -747,11 +1148,13 @@ func Test_checkCompatibility(t *testing.T) {
 
 func Test_checkCompatibility_UnsupportedAggregationAlgorithm(t *testing.T) {
 	config := &Config{
-		UseAggregation: true,
-		SampleMethod:   "all",
+		Options: analyzer.Options{
+			Plan:         "db",
+			SampleMethod: "all",
+		},
 	}
 
-	info := mgo.BuildInfo{
+	info := driver.BuildInfo{
 		Version:      "3.5.0",
 		VersionArray: []int{3, 5, 0},
 	}
@@ -761,14 +1164,151 @@ func Test_checkCompatibility_UnsupportedAggregationAlgorithm(t *testing.T) {
 
 func Test_checkCompatibility_UnsupportedSample(t *testing.T) {
 	config := &Config{
-		UseAggregation: false,
-		SampleMethod:   "random",
+		Options: analyzer.Options{
+			Plan:         "local",
+			SampleMethod: "random",
+		},
 	}
 
-	info := mgo.BuildInfo{
+	info := driver.BuildInfo{
 		Version:      "3.1.0",
 		VersionArray: []int{3, 1, 0},
 	}
 
 	assert.NotEqual(t, nil, checkCompatibility(config, info))
 }
+
+func Test_checkCompatibility_UnsupportedStringFormats(t *testing.T) {
+	config := &Config{
+		Options: analyzer.Options{
+			Plan:          "db",
+			SampleMethod:  "all",
+			StringFormats: true,
+		},
+	}
+
+	info := driver.BuildInfo{
+		Version:      "4.0.0",
+		VersionArray: []int{4, 0, 0},
+	}
+
+	assert.NotEqual(t, nil, checkCompatibility(config, info))
+
+	config.Plan = "auto"
+	assert.Equal(t, nil, checkCompatibility(config, info))
+}
+
+func TestAnalyze_PlansFindSameFields(t *testing.T) {
+	if !tests.TestDbInfo.VersionAtLeast(analysis.AggregationMinVersion...) {
+		t.Skip("Analysis in database is not supported by this MongoDB version.")
+	}
+
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(
+		bson.M{"str": "abc", "num": 1, "obj": bson.M{"arr": []interface{}{1, "x", bson.M{"a": true}}}},
+		bson.M{"str": "d", "num": 2.5, "obj": bson.M{"arr": []interface{}{}}},
+		bson.M{"str": nil},
+	)
+
+	analyze := func(plan string) *Result {
+		cmd := &cobra.Command{}
+		v := viper.New()
+		InitFlags(cmd, v, "env")
+		cmd.ParseFlags([]string{"cmd", "--sample", "all", "--timezone", "UTC", "--plan", plan})
+
+		config, err := GetConfig(v)
+		assert.Nil(t, err)
+
+		src := analysis.NewCollectionSource(tests.Collection(c))
+		result, err := analyzer.Analyze(context.Background(), src, 3, tests.TestDbInfo, &config.Options)
+		assert.Nil(t, err)
+		assert.Equal(t, plan, result.Plan)
+		assert.Equal(t, "set by 'plan' option", result.PlanReason)
+		return result
+	}
+
+	local := analyze("local")
+	for _, plan := range []string{"db", "db-seq", "hybrid"} {
+		assert.Equal(t, local.Fields, analyze(plan).Fields, plan)
+	}
+}
+
+// Collection that returns the documents and then fails with the error.
+type failingCollection struct {
+	docs [][]byte
+	err  error
+}
+
+func (c *failingCollection) Database() string        { return "db" }
+func (c *failingCollection) Name() string            { return "col" }
+func (c *failingCollection) Session() driver.Session { return nil }
+func (c *failingCollection) Count() (int, error)     { return 1, nil }
+
+func (c *failingCollection) Aggregate(ctx context.Context, stages []bson.M, options driver.AggregateOptions) driver.Cursor {
+	ch := make(chan []byte, len(c.docs))
+	for _, doc := range c.docs {
+		ch <- doc
+	}
+	close(ch)
+	return &failingCursor{ch: ch, err: c.err}
+}
+
+type failingCursor struct {
+	ch  chan []byte
+	err error
+}
+
+func (c *failingCursor) Next() ([]byte, bool) {
+	doc, ok := <-c.ch
+	return doc, ok
+}
+
+func (c *failingCursor) Close() error {
+	return c.err
+}
+
+func runWithCollection(t *testing.T, c driver.Collection, plan string) error {
+	defer func(fn func(config *Config) (driver.BuildInfo, driver.Session, driver.Collection, int, error)) {
+		connectCollection = fn
+	}(connectCollection)
+
+	connectCollection = func(config *Config) (driver.BuildInfo, driver.Session, driver.Collection, int, error) {
+		return driver.BuildInfo{Version: "7.0.0", VersionArray: []int{7, 0, 0}}, nil, c, 1, nil
+	}
+
+	cmd := &cobra.Command{}
+	v := viper.New()
+	InitFlags(cmd, v, "env")
+	cmd.ParseFlags([]string{"cmd", "--db", "db", "--col", "col", "--format", "json", "--plan", plan})
+
+	config, err := GetConfig(v)
+	assert.Nil(t, err)
+
+	cmd.SetOutput(ioutil.Discard)
+	return Run(cmd, config)
+}
+
+func TestRun_CursorError(t *testing.T) {
+	for _, plan := range []string{"local", "db", "hybrid"} {
+		err := runWithCollection(t, &failingCollection{err: errors.New("cursor killed")}, plan)
+		if assert.NotNil(t, err, plan) {
+			assert.Equal(t, "Cannot read documents from the database: cursor killed.\n", err.Error(), plan)
+		}
+	}
+}
+
+func TestRun_InvalidResultFromDatabase(t *testing.T) {
+	invalid := []byte{0x05, 0x00, 0x00, 0x00, 0x01}
+
+	err := runWithCollection(t, &failingCollection{docs: [][]byte{invalid}}, "db")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Invalid result of merge stage")
+	}
+
+	err = runWithCollection(t, &failingCollection{docs: [][]byte{invalid}}, "hybrid")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Invalid value of expand stage")
+	}
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+// X509Mechanism - authentication by client certificate.
+const X509Mechanism = "MONGODB-X509"
+
+// NewTLSConfig creates configuration of TLS connection from options.
+func NewTLSConfig(config *Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	// Certificate authority, system roots are used by default
+	if config.TLSCAFile != "" {
+		data, err := ioutil.ReadFile(config.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read CA file: %s.\n", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("CA file '%s' does not contain any PEM certificate.\n", config.TLSCAFile)
+		}
+	}
+
+	// Client certificate, the file contains the certificate and the private key
+	if config.TLSCertKeyFile != "" {
+		cert, err := loadCertKeyFile(config.TLSCertKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// The certificate chain is verified, but the hostname is not
+	if config.TLSAllowInvalidHostnames {
+		roots := tlsConfig.RootCAs
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyChain(rawCerts, roots)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// Dial server using TLS connection.
+func dialTLS(addr string, tlsConfig *tls.Config, timeout time.Duration) (net.Conn, error) {
+	return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", addr, tlsConfig)
+}
+
+func loadCertKeyFile(path string) (tls.Certificate, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("Cannot read certificate key file: %s.\n", err)
+	}
+
+	cert, err := tls.X509KeyPair(data, data)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("Invalid certificate key file '%s': %s.\n", path, err)
+	}
+
+	return cert, nil
+}
+
+// Verify certificate chain without the hostname, nil roots means system roots.
+func verifyChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("server did not provide a certificate")
+	}
+
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs[i] = cert
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+	return err
+}
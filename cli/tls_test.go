@@ -0,0 +1,273 @@
+package cli
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte // certificate and private key
+}
+
+// Generate certificate signed by the parent, nil parent means self-signed CA.
+func generateTestCert(t *testing.T, subject pkix.Name, hosts []string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+
+	signerCert, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})...)
+
+	return &testCert{cert: cert, key: key, pem: data}
+}
+
+// Write certificates to temporary files: ca.pem, server.pem, client.pem.
+func writeTestCerts(t *testing.T, serverHosts []string) (dir string, ca *testCert, server *testCert) {
+	dir, err := ioutil.TempDir("", "mongoeye-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca = generateTestCert(t, pkix.Name{CommonName: "Test CA"}, nil, nil)
+	server = generateTestCert(t, pkix.Name{CommonName: "server"}, serverHosts, ca)
+	client := generateTestCert(t, pkix.Name{CommonName: "client", Organization: []string{"mongoeye"}}, nil, ca)
+
+	ioutil.WriteFile(filepath.Join(dir, "ca.pem"), ca.pem, 0600)
+	ioutil.WriteFile(filepath.Join(dir, "server.pem"), server.pem, 0600)
+	ioutil.WriteFile(filepath.Join(dir, "client.pem"), client.pem, 0600)
+
+	return dir, ca, server
+}
+
+// Start TLS listener that requires client certificate signed by the CA.
+func startTestTLSListener(t *testing.T, dir string, ca *testCert) net.Listener {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if conn.(*tls.Conn).Handshake() == nil {
+					conn.Write([]byte("ok"))
+				}
+			}()
+		}
+	}()
+
+	return listener
+}
+
+func dialTestTLSListener(listener net.Listener, config *Config) error {
+	tlsConfig, err := NewTLSConfig(config)
+	if err != nil {
+		return err
+	}
+
+	conn, err := dialTLS(listener.Addr().String(), tlsConfig, time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 2)
+	_, err = conn.Read(buf)
+	return err
+}
+
+func TestDialTLS(t *testing.T) {
+	dir, ca, _ := writeTestCerts(t, []string{"127.0.0.1"})
+	defer os.RemoveAll(dir)
+
+	listener := startTestTLSListener(t, dir, ca)
+	defer listener.Close()
+
+	err := dialTestTLSListener(listener, &Config{
+		TLS:            true,
+		TLSCAFile:      filepath.Join(dir, "ca.pem"),
+		TLSCertKeyFile: filepath.Join(dir, "client.pem"),
+	})
+	assert.Nil(t, err)
+
+	// Server requires client certificate
+	err = dialTestTLSListener(listener, &Config{
+		TLS:       true,
+		TLSCAFile: filepath.Join(dir, "ca.pem"),
+	})
+	assert.NotNil(t, err)
+
+	// Server certificate is not signed by a system root
+	err = dialTestTLSListener(listener, &Config{
+		TLS:            true,
+		TLSCertKeyFile: filepath.Join(dir, "client.pem"),
+	})
+	assert.NotNil(t, err)
+}
+
+func TestDialTLS_AllowInvalidHostnames(t *testing.T) {
+	dir, ca, _ := writeTestCerts(t, []string{"mongo.example.com"})
+	defer os.RemoveAll(dir)
+
+	listener := startTestTLSListener(t, dir, ca)
+	defer listener.Close()
+
+	config := &Config{
+		TLS:            true,
+		TLSCAFile:      filepath.Join(dir, "ca.pem"),
+		TLSCertKeyFile: filepath.Join(dir, "client.pem"),
+	}
+
+	err := dialTestTLSListener(listener, config)
+	assert.NotNil(t, err)
+
+	config.TLSAllowInvalidHostnames = true
+	err = dialTestTLSListener(listener, config)
+	assert.Nil(t, err)
+
+	// The chain is still verified
+	config.TLSCAFile = ""
+	err = dialTestTLSListener(listener, config)
+	assert.NotNil(t, err)
+}
+
+func TestNewTLSConfig_InvalidFiles(t *testing.T) {
+	dir, _, _ := writeTestCerts(t, []string{"127.0.0.1"})
+	defer os.RemoveAll(dir)
+
+	_, err := NewTLSConfig(&Config{TLS: true, TLSCAFile: filepath.Join(dir, "missing.pem")})
+	assert.Contains(t, err.Error(), "Cannot read CA file")
+
+	ioutil.WriteFile(filepath.Join(dir, "empty.pem"), []byte("abc"), 0600)
+	_, err = NewTLSConfig(&Config{TLS: true, TLSCAFile: filepath.Join(dir, "empty.pem")})
+	assert.Contains(t, err.Error(), "does not contain any PEM certificate")
+
+	_, err = NewTLSConfig(&Config{TLS: true, TLSCertKeyFile: filepath.Join(dir, "empty.pem")})
+	assert.Contains(t, err.Error(), "Invalid certificate key file")
+}
+
+func Test_newCredential(t *testing.T) {
+	dir, _, _ := writeTestCerts(t, []string{"127.0.0.1"})
+	defer os.RemoveAll(dir)
+
+	// X.509 username is taken by the server from the client certificate
+	credential, err := newCredential(&Config{
+		TLS:            true,
+		TLSCertKeyFile: filepath.Join(dir, "client.pem"),
+		AuthMechanism:  X509Mechanism,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "", credential.Username)
+	assert.Equal(t, "$external", credential.Source)
+	assert.Equal(t, X509Mechanism, credential.Mechanism)
+
+	// Username and password
+	credential, err = newCredential(&Config{User: "john", Password: "secret", AuthDatabase: "shop"})
+	assert.Nil(t, err)
+	assert.Equal(t, "john", credential.Username)
+	assert.Equal(t, "shop", credential.Source)
+
+	// No authentication
+	credential, err = newCredential(&Config{User: "admin"})
+	assert.Nil(t, err)
+	assert.Nil(t, credential)
+}
+
+func TestNewDialInfo(t *testing.T) {
+	dialInfo, err := NewDialInfo(&Config{
+		Host:              "host1,host2:27018",
+		ReplicaSet:        "rs0",
+		ConnectionMode:    driver.Nearest,
+		MaxStaleness:      2 * time.Minute,
+		AppName:           "eye",
+		ConnectionTimeout: time.Second,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "", dialInfo.URI)
+	assert.Equal(t, []string{"host1", "host2:27018"}, dialInfo.Addrs)
+	assert.Equal(t, "rs0", dialInfo.ReplicaSet)
+	assert.Equal(t, driver.Nearest, dialInfo.Mode)
+	assert.Equal(t, 2*time.Minute, dialInfo.MaxStaleness)
+	assert.Equal(t, "eye", dialInfo.AppName)
+	assert.Equal(t, time.Second, dialInfo.Timeout)
+	assert.Nil(t, dialInfo.DialServer)
+
+	dialInfo, err = NewDialInfo(&Config{Host: "host1", URI: "mongodb://host1/?directConnection=true", ReplicaSet: "rs0"})
+	assert.Nil(t, err)
+	assert.Equal(t, "mongodb://host1/?directConnection=true", dialInfo.URI)
+	assert.Equal(t, "rs0", dialInfo.ReplicaSet)
+	assert.Nil(t, dialInfo.Addrs)
+
+	dialInfo, err = NewDialInfo(&Config{Host: "host1", TLS: true})
+	assert.Nil(t, err)
+	assert.NotNil(t, dialInfo.DialServer)
+}
@@ -0,0 +1,351 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/mgo.v2/bson"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schemes of connection string.
+const (
+	uriScheme    = "mongodb://"
+	uriSrvScheme = "mongodb+srv://"
+)
+
+// ConnectionString - parsed mongodb:// or mongodb+srv:// connection string.
+type ConnectionString struct {
+	Hosts                    []string
+	User                     string
+	Password                 string
+	Database                 string
+	ReplicaSet               string
+	AuthSource               string
+	AuthMechanism            string
+	ReadPreference           string
+	ReadPreferenceTags       []bson.D
+	MaxStaleness             time.Duration // zero = no maximum
+	AppName                  string
+	ConnectTimeout           time.Duration // zero = not set
+	ServerSelectionTimeout   time.Duration // zero = not set
+	SocketTimeout            time.Duration // zero = not set
+	TLS                      bool
+	TLSCAFile                string
+	TLSCertKeyFile           string
+	TLSAllowInvalidHostnames bool
+	Options                  []string // other options in key=value form, they are passed to the driver
+}
+
+// Resolver looks up DNS records of mongodb+srv:// connection string.
+type Resolver interface {
+	LookupSRV(service, proto, name string) (cname string, addrs []*net.SRV, err error)
+	LookupTXT(name string) ([]string, error)
+}
+
+type netResolver struct{}
+
+func (netResolver) LookupSRV(service, proto, name string) (string, []*net.SRV, error) {
+	return net.LookupSRV(service, proto, name)
+}
+
+func (netResolver) LookupTXT(name string) ([]string, error) {
+	return net.LookupTXT(name)
+}
+
+// DefaultResolver is used to resolve mongodb+srv:// connection strings, it can be replaced in tests.
+var DefaultResolver Resolver = netResolver{}
+
+// IsConnectionString returns true if s has scheme of connection string.
+func IsConnectionString(s string) bool {
+	return strings.HasPrefix(s, uriScheme) || strings.HasPrefix(s, uriSrvScheme)
+}
+
+// Database from the path of connection string, SRV records are not resolved.
+func databaseFromURI(uri string) string {
+	if !IsConnectionString(uri) {
+		return ""
+	}
+
+	rest := uri[strings.Index(uri, "://")+3:]
+	if i := strings.Index(rest, "?"); i >= 0 {
+		rest = rest[:i]
+	}
+
+	if i := strings.Index(rest, "/"); i >= 0 {
+		db, _ := url.PathUnescape(rest[i+1:])
+		return db
+	}
+
+	return ""
+}
+
+// RedactURI replaces password in connection string, so it can be printed.
+func RedactURI(uri string) string {
+	schemeEnd := strings.Index(uri, "://")
+	at := strings.LastIndex(uri, "@")
+	if schemeEnd < 0 || at < schemeEnd {
+		return uri
+	}
+
+	userInfo := uri[schemeEnd+3 : at]
+	colon := strings.Index(userInfo, ":")
+	if colon < 0 {
+		return uri
+	}
+
+	return uri[:schemeEnd+3] + userInfo[:colon] + ":xxxxx" + uri[at:]
+}
+
+// ParseURI parses connection string, see https://docs.mongodb.com/manual/reference/connection-string/.
+// Hosts of mongodb+srv:// connection string are resolved by the resolver.
+func ParseURI(uri string, resolver Resolver) (*ConnectionString, error) {
+	cs, err := parseURI(uri, resolver)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid connection string '%s': %s.", RedactURI(uri), err)
+	}
+
+	return cs, nil
+}
+
+func parseURI(uri string, resolver Resolver) (*ConnectionString, error) {
+	srv := strings.HasPrefix(uri, uriSrvScheme)
+	if !srv && !strings.HasPrefix(uri, uriScheme) {
+		return nil, errors.New("scheme must be 'mongodb://' or 'mongodb+srv://'")
+	}
+
+	rest := strings.TrimPrefix(strings.TrimPrefix(uri, uriSrvScheme), uriScheme)
+	cs := &ConnectionString{TLS: srv}
+
+	// Options
+	var query string
+	if i := strings.Index(rest, "?"); i >= 0 {
+		rest, query = rest[:i], rest[i+1:]
+	}
+
+	// Database
+	if i := strings.Index(rest, "/"); i >= 0 {
+		db, err := url.PathUnescape(rest[i+1:])
+		if err != nil {
+			return nil, errors.New("cannot unescape database name")
+		}
+		rest, cs.Database = rest[:i], db
+	}
+
+	// Credentials
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		if err := parseUserInfo(rest[:i], cs); err != nil {
+			return nil, err
+		}
+		rest = rest[i+1:]
+	}
+
+	// Hosts
+	if rest == "" {
+		return nil, errors.New("missing host")
+	}
+	cs.Hosts = strings.Split(rest, ",")
+
+	// Hosts and options of mongodb+srv:// are resolved from DNS, options in URI take precedence
+	if srv {
+		txt, err := resolveSrv(cs, resolver)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := parseOptions(txt, cs, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parseOptions(query, cs, false); err != nil {
+		return nil, err
+	}
+
+	if cs.MaxStaleness > 0 && strings.ToLower(cs.ReadPreference) == "primary" {
+		return nil, errors.New("maxStalenessSeconds cannot be used with primary read preference")
+	}
+
+	return cs, nil
+}
+
+func parseUserInfo(userInfo string, cs *ConnectionString) (err error) {
+	user, password := userInfo, ""
+	hasPassword := false
+	if i := strings.Index(userInfo, ":"); i >= 0 {
+		user, password, hasPassword = userInfo[:i], userInfo[i+1:], true
+	}
+
+	if cs.User, err = url.QueryUnescape(user); err != nil {
+		return errors.New("cannot unescape username")
+	}
+
+	if hasPassword {
+		if cs.Password, err = url.QueryUnescape(password); err != nil {
+			return errors.New("cannot unescape password")
+		}
+	}
+
+	return nil
+}
+
+// Resolve hosts from SRV records and returns options from TXT record.
+func resolveSrv(cs *ConnectionString, resolver Resolver) (string, error) {
+	if len(cs.Hosts) != 1 || strings.Contains(cs.Hosts[0], ":") {
+		return "", errors.New("mongodb+srv:// requires exactly one host without port")
+	}
+
+	host := cs.Hosts[0]
+	parts := strings.Split(host, ".")
+	if len(parts) < 3 {
+		return "", errors.New("mongodb+srv:// requires host with at least three parts, eg. cluster.example.com")
+	}
+	domain := "." + strings.Join(parts[1:], ".")
+
+	_, records, err := resolver.LookupSRV("mongodb", "tcp", host)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve SRV record: %s", err)
+	}
+	if len(records) == 0 {
+		return "", errors.New("no SRV record found")
+	}
+
+	cs.Hosts = make([]string, 0, len(records))
+	for _, r := range records {
+		target := strings.TrimSuffix(r.Target, ".")
+		if !strings.HasSuffix(target, domain) {
+			return "", fmt.Errorf("SRV record '%s' is not in the domain '%s'", target, domain[1:])
+		}
+		cs.Hosts = append(cs.Hosts, net.JoinHostPort(target, strconv.Itoa(int(r.Port))))
+	}
+
+	txt, err := resolver.LookupTXT(host)
+	if err != nil {
+		// TXT record is optional
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("cannot resolve TXT record: %s", err)
+	}
+	if len(txt) > 1 {
+		return "", errors.New("more TXT records found")
+	}
+	if len(txt) == 0 {
+		return "", nil
+	}
+
+	return txt[0], nil
+}
+
+func parseOptions(query string, cs *ConnectionString, fromTXT bool) error {
+	if query == "" {
+		return nil
+	}
+
+	for _, pair := range strings.FieldsFunc(query, func(r rune) bool { return r == '&' || r == ';' }) {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("option '%s' must be key=value", pair)
+		}
+
+		key := strings.ToLower(kv[0])
+		value, err := url.QueryUnescape(kv[1])
+		if err != nil {
+			return fmt.Errorf("cannot unescape value of option '%s'", kv[0])
+		}
+
+		// TXT record can contain only authSource and replicaSet
+		if fromTXT && key != "authsource" && key != "replicaset" {
+			return fmt.Errorf("option '%s' is not allowed in TXT record", kv[0])
+		}
+
+		known, err := parseOption(key, kv[0], value, cs)
+		if err != nil {
+			return err
+		}
+		if !known {
+			cs.Options = append(cs.Options, pair)
+		}
+	}
+
+	return nil
+}
+
+// Parse option used by mongoeye, false is returned for other options.
+func parseOption(key string, name string, value string, cs *ConnectionString) (known bool, err error) {
+	known = true
+	switch key {
+	case "replicaset":
+		cs.ReplicaSet = value
+	case "authsource":
+		cs.AuthSource = value
+	case "authmechanism":
+		cs.AuthMechanism = value
+	case "readpreference":
+		cs.ReadPreference = value
+	case "readpreferencetags":
+		tags := bson.D{}
+		if value != "" {
+			for _, tag := range strings.Split(value, ",") {
+				kv := strings.SplitN(tag, ":", 2)
+				if len(kv) != 2 {
+					return true, fmt.Errorf("invalid tag '%s' in readPreferenceTags", tag)
+				}
+				tags = append(tags, bson.DocElem{Name: kv[0], Value: kv[1]})
+			}
+		}
+		cs.ReadPreferenceTags = append(cs.ReadPreferenceTags, tags)
+	case "maxstalenessseconds":
+		seconds, err := strconv.Atoi(value)
+		if err != nil || (seconds < 90 && seconds != -1) {
+			return true, errors.New("maxStalenessSeconds must be -1 or >= 90")
+		}
+		cs.MaxStaleness = 0
+		if seconds > 0 {
+			cs.MaxStaleness = time.Duration(seconds) * time.Second
+		}
+	case "appname":
+		cs.AppName = value
+	case "connecttimeoutms":
+		cs.ConnectTimeout, err = parseDurationOption(name, value)
+	case "serverselectiontimeoutms":
+		cs.ServerSelectionTimeout, err = parseDurationOption(name, value)
+	case "sockettimeoutms":
+		cs.SocketTimeout, err = parseDurationOption(name, value)
+	case "tls", "ssl":
+		cs.TLS, err = parseBoolOption(name, value)
+	case "tlscafile":
+		cs.TLSCAFile = value
+	case "tlscertificatekeyfile":
+		cs.TLSCertKeyFile = value
+	case "tlsallowinvalidhostnames":
+		cs.TLSAllowInvalidHostnames, err = parseBoolOption(name, value)
+	default:
+		known = false
+	}
+
+	return
+}
+
+func parseBoolOption(name string, value string) (bool, error) {
+	switch value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	return false, fmt.Errorf("option '%s' must be true or false", name)
+}
+
+func parseDurationOption(name string, value string) (time.Duration, error) {
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms < 0 {
+		return 0, fmt.Errorf("option '%s' must be a non-negative number of milliseconds", name)
+	}
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"github.com/mongoeye/mongoeye/analyzer"
 	"github.com/spf13/cobra"
 	"regexp"
 	"strings"
@@ -58,7 +59,28 @@ func (f *Flags) Usage() string {
 	return out.String()
 }
 
-func usageFunc(flags *Flags) func(c *cobra.Command) error {
+// Usage of plans, see 'plan' option.
+func plansUsage() string {
+	out := new(bytes.Buffer)
+	out.WriteString(fmt.Sprintf("  %-6s  %s\n", "auto", "benchmark available plans on a probe sample and use the fastest"))
+	out.WriteString(fmt.Sprintf("  %-6s  %s\n", "", fmt.Sprintf(
+		"each plan analyzes %d docs (max %s) before the analysis, skipped if only one plan supports the options",
+		analyzer.ProbeDocs, analyzer.ProbeMaxTime,
+	)))
+	for _, u := range analyzer.PlanUsages {
+		out.WriteString(fmt.Sprintf("  %-6s  %s\n", u.Name, u.Usage))
+	}
+
+	return out.String()
+}
+
+// Additional section of usage, eg. plans or exit codes.
+type usageSection struct {
+	Name string
+	Text string
+}
+
+func usageFunc(flags *Flags, sections ...usageSection) func(c *cobra.Command) error {
 	return func(c *cobra.Command) error {
 		w := c.OutOrStderr()
 		t := template.New("usage")
@@ -75,16 +97,21 @@ func usageFunc(flags *Flags) func(c *cobra.Command) error {
 
 Flags:
 {{.Flags.Usage | trimTrailingWhitespaces}}
-
+{{range .Sections}}
+{{.Name}}:
+{{.Text | trimTrailingWhitespaces}}
+{{end}}
 Note: You can also use environment variables, eg. 'export MONGOEYE_COUNT-UNIQUE=true'
 `))
 
 		err := t.Execute(w, struct {
-			Cmd   *cobra.Command
-			Flags *Flags
+			Cmd      *cobra.Command
+			Flags    *Flags
+			Sections []usageSection
 		}{
-			Cmd:   c,
-			Flags: flags,
+			Cmd:      c,
+			Flags:    flags,
+			Sections: sections,
 		})
 
 		if err != nil {
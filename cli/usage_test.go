@@ -59,4 +59,6 @@ func TestFlags_CmdUsage(t *testing.T) {
 	assert.Contains(t, outStr, "Flags:\n")
 	assert.Contains(t, outStr, "  connection options:\n")
 	assert.Contains(t, outStr, "        --host")
+	assert.Contains(t, outStr, "Plans:\n  auto ")
+	assert.Contains(t, outStr, "\n  db-seq  same as db")
 }
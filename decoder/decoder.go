@@ -29,8 +29,32 @@ func NewDecoder(in []byte) *Decoder {
 // --------------------------------------------------------------------------
 // Some helper functions.
 
+// CorruptedError is the panic value of decoder when the document is corrupted.
+type CorruptedError struct {
+	Reason string
+}
+
+func (e *CorruptedError) Error() string {
+	if e.Reason == "" {
+		return "Document is corrupted"
+	}
+	return "Document is corrupted: " + e.Reason
+}
+
 func corrupted() {
-	panic("Document is corrupted")
+	panic(&CorruptedError{})
+}
+
+// Recover converts the panic of decoder to error, other panics are propagated.
+// It must be used directly in the defer statement: defer decoder.Recover(&err).
+func Recover(err *error) {
+	if r := recover(); r != nil {
+		if e, ok := r.(*CorruptedError); ok {
+			*err = e
+			return
+		}
+		panic(r)
+	}
 }
 
 // --------------------------------------------------------------------------
@@ -93,7 +117,7 @@ func (d *Decoder) ReadBool() bool {
 	if b == 1 {
 		return true
 	}
-	panic(fmt.Sprintf("encoded boolean must be 1 or 0, found %d", b))
+	panic(&CorruptedError{fmt.Sprintf("encoded boolean must be 1 or 0, found %d", b)})
 }
 
 // ReadFloat64 - read double.
@@ -191,6 +215,45 @@ func (d *Decoder) Skip(skip int) {
 	}
 }
 
+// SkipValue skips value of the element of given kind without decoding it.
+func (d *Decoder) SkipValue(kind byte) {
+	switch kind {
+	case 0x01, 0x09, 0x11, 0x12: // Float64, Date, Timestamp, Int64
+		d.Skip(8)
+	case 0x02, 0x0D, 0x0E: // String, JavaScript, Symbol
+		d.skipLength(0)
+	case 0x03, 0x04, 0x0F: // Document, Array, JavaScript with scope (length includes itself)
+		d.skipLength(-4)
+	case 0x05: // Binary (subtype is not included in length)
+		d.skipLength(1)
+	case 0x06, 0x0A, 0x7F, 0xFF: // Undefined, Null, Max key, Min key
+	case 0x07: // ObjectId
+		d.Skip(12)
+	case 0x08: // Bool
+		d.Skip(1)
+	case 0x0B: // RegEx
+		d.ReadCStr()
+		d.ReadCStr()
+	case 0x0C: // dbPointer
+		d.skipLength(12)
+	case 0x10: // Int32
+		d.Skip(4)
+	case 0x13: // Decimal128
+		d.Skip(16)
+	default:
+		panic(&CorruptedError{Reason: fmt.Sprintf("unknown element kind (0x%02X)", kind)})
+	}
+}
+
+// Read length and skip the data, the length is corrected by delta.
+func (d *Decoder) skipLength(delta int) {
+	length := int(d.ReadInt32()) + delta
+	if length < 0 {
+		corrupted()
+	}
+	d.Skip(length)
+}
+
 // Rewind N bytes.
 func (d *Decoder) Rewind(rewind int) {
 	if rewind > d.I {
@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/mgo.v2/bson"
 	"testing"
+	"time"
 )
 
 func TestDecoder_ReadRegEx(t *testing.T) {
@@ -278,3 +279,81 @@ func TestDecoder_Position(t *testing.T) {
 	d.Skip(1)
 	assert.Equal(t, 1, d.Position())
 }
+
+func TestRecover_Corrupted(t *testing.T) {
+	read := func() (err error) {
+		defer Recover(&err)
+		NewDecoder([]byte{0x01}).ReadInt32()
+		return nil
+	}
+
+	err := read()
+	assert.IsType(t, &CorruptedError{}, err)
+	assert.Equal(t, "Document is corrupted", err.Error())
+}
+
+func TestRecover_OtherPanic(t *testing.T) {
+	assert.Panics(t, func() {
+		defer Recover(new(error))
+		panic("other")
+	})
+}
+
+func TestCorruptedError_Reason(t *testing.T) {
+	err := &CorruptedError{Reason: "encoded boolean must be 1 or 0, found 2"}
+	assert.Equal(t, "Document is corrupted: encoded boolean must be 1 or 0, found 2", err.Error())
+}
+
+func TestDecoder_SkipValue(t *testing.T) {
+	values := []interface{}{
+		1.5,
+		"str",
+		bson.M{"a": bson.M{"b": 1}},
+		[]interface{}{1, "x"},
+		[]byte{0x01, 0x02},
+		bson.Undefined,
+		bson.NewObjectId(),
+		true,
+		time.Now(),
+		nil,
+		bson.RegEx{Pattern: "^a", Options: "i"},
+		bson.DBPointer{Namespace: "db.col", Id: bson.NewObjectId()},
+		bson.JavaScript{Code: "x"},
+		bson.Symbol("sym"),
+		bson.JavaScript{Code: "x", Scope: bson.M{"a": 1}},
+		123,
+		bson.MongoTimestamp(123),
+		int64(123),
+		bson.Decimal128{},
+		bson.MaxKey,
+		bson.MinKey,
+	}
+
+	for _, v := range values {
+		bytes, _ := bson.Marshal(bson.D{{Name: "v", Value: v}, {Name: "next", Value: "ok"}})
+		d := NewDecoder(bytes)
+
+		d.ReadLength()
+		kind := d.ReadByte()
+		d.ReadCStr()
+		d.SkipValue(kind)
+
+		assert.Equal(t, byte(0x02), d.ReadByte(), v)
+		assert.Equal(t, "next", d.ReadCStr(), v)
+		assert.Equal(t, "ok", d.ReadStr(), v)
+	}
+}
+
+func TestDecoder_SkipValue_Invalid(t *testing.T) {
+	assert.Panics(t, func() {
+		NewDecoder([]byte{0x00}).SkipValue(0x20)
+	})
+
+	assert.Panics(t, func() {
+		NewDecoder([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x00}).SkipValue(0x02)
+	})
+
+	assert.Panics(t, func() {
+		NewDecoder([]byte{0x10, 0x00, 0x00, 0x00, 0x00}).SkipValue(0x03)
+	})
+}
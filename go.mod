@@ -1,43 +1,59 @@
 module github.com/mongoeye/mongoeye
 
-go 1.16
+go 1.18
 
 require (
-	github.com/Bowery/prompt v0.0.0-20190916142128-fa8279994f75 // indirect
 	github.com/andrew-d/go-termutil v0.0.0-20150726205930-009166a695a2
 	github.com/briandowns/spinner v0.0.0-20170404174923-fb621c2fd72f
-	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/dchest/safefile v0.0.0-20151022103144-855e8d98f185 // indirect
 	github.com/deckarep/golang-set v0.0.0-20170202203032-fc8930a5e645
 	github.com/elgs/gostrgen v0.0.0-20161222160715-9d61ae07eeae
 	github.com/fatih/color v1.4.1
 	github.com/fatih/structs v1.0.0
+	github.com/jinzhu/copier v0.0.0-20170205151647-8bfca8a02a0c
+	github.com/olekukonko/tablewriter v0.0.0-20170128050532-febf2d34b54a
+	github.com/spf13/cobra v0.0.0-20170509201858-1362f95a8d6f
+	github.com/spf13/pflag v1.0.0
+	github.com/spf13/viper v0.0.0-20170417080815-0967fc9aceab
+	github.com/stretchr/testify v1.4.0
+	go.mongodb.org/mongo-driver/v2 v2.0.0
+	gopkg.in/mgo.v2 v2.0.0-20160818020120-3f83fa500528
+	gopkg.in/yaml.v2 v2.2.2
+)
+
+require (
+	github.com/Bowery/prompt v0.0.0-20190916142128-fa8279994f75 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dchest/safefile v0.0.0-20151022103144-855e8d98f185 // indirect
 	github.com/fsnotify/fsnotify v1.4.3-0.20170329110642-4da3e2cfbabc // indirect
+	github.com/golang/snappy v0.0.4 // indirect
 	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510 // indirect
 	github.com/hashicorp/hcl v0.0.0-20170509225359-392dba7d905e // indirect
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
-	github.com/jinzhu/copier v0.0.0-20170205151647-8bfca8a02a0c
 	github.com/kardianos/govendor v1.0.9 // indirect
+	github.com/klauspost/compress v1.16.7 // indirect
 	github.com/kyoh86/richgo v0.3.6 // indirect
 	github.com/magiconair/properties v1.7.3-0.20170321093039-51463bfca257 // indirect
 	github.com/mattn/go-colorable v0.1.8 // indirect
+	github.com/mattn/go-isatty v0.0.12 // indirect
 	github.com/mattn/go-runewidth v0.0.10 // indirect
 	github.com/mattn/goveralls v0.0.8 // indirect
 	github.com/mitchellh/mapstructure v0.0.0-20170422000251-cc8532a8e9a5 // indirect
-	github.com/olekukonko/tablewriter v0.0.0-20170128050532-febf2d34b54a
 	github.com/pelletier/go-buffruneio v0.2.0 // indirect
 	github.com/pelletier/go-toml v0.5.1-0.20170511005323-685a1f1cb7a6 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/rivo/uniseg v0.1.0 // indirect
 	github.com/spf13/afero v0.0.0-20170217164146-9be650865eab // indirect
 	github.com/spf13/cast v1.1.0 // indirect
-	github.com/spf13/cobra v0.0.0-20170509201858-1362f95a8d6f
 	github.com/spf13/jwalterweatherman v0.0.0-20170510083831-8f07c835e5cc // indirect
-	github.com/spf13/pflag v1.0.0
-	github.com/spf13/viper v0.0.0-20170417080815-0967fc9aceab
-	github.com/stretchr/testify v1.4.0
+	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
+	github.com/xdg-go/scram v1.1.2 // indirect
+	github.com/xdg-go/stringprep v1.0.4 // indirect
+	github.com/youmark/pkcs8 v0.0.0-20240726163527-a2c0da244d78 // indirect
+	golang.org/x/crypto v0.29.0 // indirect
 	golang.org/x/lint v0.0.0-20201208152925-83fdc39ff7b5 // indirect
+	golang.org/x/sync v0.9.0 // indirect
+	golang.org/x/sys v0.27.0 // indirect
+	golang.org/x/text v0.20.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
-	gopkg.in/mgo.v2 v2.0.0-20160818020120-3f83fa500528
-	gopkg.in/yaml.v2 v2.2.2
 )
@@ -19,6 +19,7 @@ func DataToString(data interface{}) string {
 
 // Replace non-printable data structures with string representation (printable)
 func replaceNonStringWithString(data interface{}) interface{} {
+	data = FromDriverValue(data)
 	if decimal, ok := data.(bson.Decimal128); ok {
 		return fmt.Sprintf("decimal(%s)", decimal.String())
 	} else if t, ok := data.(time.Time); ok {
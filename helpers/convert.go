@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"bytes"
+	driverbson "go.mongodb.org/mongo-driver/v2/bson"
 	"gopkg.in/mgo.v2/bson"
 	"reflect"
 	"strconv"
@@ -54,6 +55,8 @@ func ToDouble(input interface{}) float64 {
 		return float64(v)
 	case bson.Decimal128:
 		return DecimalToDouble(v)
+	case driverbson.Decimal128:
+		return DecimalToDouble(FromDriverDecimal(v))
 	}
 
 	if input == nil {
@@ -100,6 +103,8 @@ func ToUInt(input interface{}) uint {
 		return uint(v)
 	case bson.Decimal128:
 		return uint(DecimalToDouble(v))
+	case driverbson.Decimal128:
+		return uint(DecimalToDouble(FromDriverDecimal(v)))
 	}
 
 	if input == nil {
@@ -160,6 +165,34 @@ func ToDecimalProxy(value bson.Decimal128) DecimalProxy {
 	return *(*DecimalProxy)(unsafe.Pointer(&value))
 }
 
+// FromDecimalProxy converts DecimalProxy to bson.Decimal128.
+func FromDecimalProxy(value DecimalProxy) bson.Decimal128 {
+	return *(*bson.Decimal128)(unsafe.Pointer(&value))
+}
+
+// FromDriverDecimal converts Decimal128 of the official driver to bson.Decimal128.
+func FromDriverDecimal(value driverbson.Decimal128) bson.Decimal128 {
+	h, l := value.GetBytes()
+	return FromDecimalProxy(DecimalProxy{H: h, L: l})
+}
+
+// FromDriverObjectId converts ObjectID of the official driver to bson.ObjectId.
+func FromDriverObjectId(value driverbson.ObjectID) bson.ObjectId {
+	return bson.ObjectId(value[:])
+}
+
+// FromDriverValue converts ObjectID and Decimal128 of the official driver to bson types.
+// Other values are returned unchanged.
+func FromDriverValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case driverbson.ObjectID:
+		return FromDriverObjectId(v)
+	case driverbson.Decimal128:
+		return FromDriverDecimal(v)
+	}
+	return value
+}
+
 // CmpDecimalProxy compares DecimalProxy.
 func CmpDecimalProxy(a DecimalProxy, b DecimalProxy) int {
 	sA := a.Positive()
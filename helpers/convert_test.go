@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"github.com/stretchr/testify/assert"
+	driverbson "go.mongodb.org/mongo-driver/v2/bson"
 	"gopkg.in/mgo.v2/bson"
 	"testing"
 	"time"
@@ -31,6 +32,7 @@ func TestToDouble(t *testing.T) {
 	assert.Equal(t, float64(1234567), ToDouble(int64(1234567)))
 	assert.Equal(t, float64(1234567), ToDouble(uint(1234567)))
 	assert.Equal(t, float64(789.123), ToDouble(ParseDecimal("789.123")))
+	assert.Equal(t, float64(789.123), ToDouble(parseDriverDecimal("789.123")))
 	assert.Panics(t, func() {
 		ToDouble(nil)
 	})
@@ -122,6 +124,27 @@ func TestToDecimalProxy(t *testing.T) {
 	assert.Equal(t, uint64(123456), l)
 }
 
+func parseDriverDecimal(input string) driverbson.Decimal128 {
+	d, err := driverbson.ParseDecimal128(input)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestFromDecimalProxy(t *testing.T) {
+	d := ParseDecimal("-123.456")
+	assert.Equal(t, d, FromDecimalProxy(ToDecimalProxy(d)))
+}
+
+func TestFromDriverValue(t *testing.T) {
+	oid, _ := driverbson.ObjectIDFromHex("57605d5dc3d5a2429db0bd09")
+	assert.Equal(t, bson.ObjectIdHex("57605d5dc3d5a2429db0bd09"), FromDriverValue(oid))
+	assert.Equal(t, ParseDecimal("-123.456"), FromDriverValue(parseDriverDecimal("-123.456")))
+	assert.Equal(t, ParseDecimal("1E+10"), FromDriverValue(parseDriverDecimal("1E+10")))
+	assert.Equal(t, "abc", FromDriverValue("abc"))
+}
+
 func TestCmpDecimal(t *testing.T) {
 	assert.Equal(t, 0, CmpDecimal(ParseDecimal("123.456"), ParseDecimal("123.456")))
 	assert.Equal(t, -1, CmpDecimal(ParseDecimal("-123.456"), ParseDecimal("123.456")))
@@ -14,6 +14,7 @@ func HashData(input interface{}) uint32 {
 }
 
 func hashDataInLevel(input interface{}, level int) uint32 {
+	input = FromDriverValue(input)
 	if decimal, ok := input.(bson.Decimal128); ok {
 		input = "decimal(" + strconv.FormatFloat(DecimalToDouble(decimal), 'G', -1, 64) + ")"
 	}
@@ -0,0 +1,207 @@
+package helpers
+
+import (
+	"encoding/binary"
+	"gopkg.in/mgo.v2/bson"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// HyperLogLogPrecision - number of bits used for register index.
+// Dense sketch has 4 KB and relative error is 1.04 / sqrt(2^12) = 1.6 %.
+const HyperLogLogPrecision = 12
+
+// HyperLogLog estimates number of unique values in constant memory.
+// Sketches with the same precision can be merged.
+//
+// Sketch starts in sparse form, only non-zero registers are stored.
+// It is converted to dense form when the sparse form would be larger.
+type HyperLogLog struct {
+	precision uint8
+	sparse    map[uint32]uint8
+	registers []uint8
+}
+
+// NewHyperLogLog creates empty sketch with 2^precision registers.
+func NewHyperLogLog(precision uint8) *HyperLogLog {
+	if precision < 4 || precision > 18 {
+		panic("HyperLogLog precision must be between 4 and 18.")
+	}
+
+	return &HyperLogLog{
+		precision: precision,
+	}
+}
+
+// Add value to the sketch.
+func (h *HyperLogLog) Add(value interface{}) {
+	h.AddHash(hashValue(value))
+}
+
+// AddHash adds 64-bit hash of value to the sketch.
+func (h *HyperLogLog) AddHash(hash uint64) {
+	index := uint32(hash >> (64 - h.precision))
+	rank := uint8(bits.LeadingZeros64(hash<<h.precision|1<<(h.precision-1))) + 1
+	h.setRegister(index, rank)
+}
+
+// Merge other sketch into this sketch.
+func (h *HyperLogLog) Merge(other *HyperLogLog) {
+	if other.precision != h.precision {
+		panic("Cannot merge HyperLogLog sketches with different precision.")
+	}
+
+	if other.IsSparse() {
+		for index, rank := range other.sparse {
+			h.setRegister(index, rank)
+		}
+		return
+	}
+
+	h.toDense()
+	for i, rank := range other.registers {
+		if rank > h.registers[i] {
+			h.registers[i] = rank
+		}
+	}
+}
+
+// IsSparse returns true if only non-zero registers are stored.
+func (h *HyperLogLog) IsSparse() bool {
+	return h.registers == nil
+}
+
+// Estimate returns estimated number of unique values.
+func (h *HyperLogLog) Estimate() uint64 {
+	m := float64(uint64(1) << h.precision)
+
+	sum := 0.0
+	zeros := 0
+	if h.IsSparse() {
+		zeros = int(m) - len(h.sparse)
+		sum = float64(zeros)
+		for _, rank := range h.sparse {
+			sum += 1 / float64(uint64(1)<<rank)
+		}
+	} else {
+		for _, rank := range h.registers {
+			sum += 1 / float64(uint64(1)<<rank)
+			if rank == 0 {
+				zeros++
+			}
+		}
+	}
+
+	estimate := hyperLogLogAlpha(m) * m * m / sum
+
+	// Small cardinalities are estimated by linear counting
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(estimate + 0.5)
+}
+
+func (h *HyperLogLog) setRegister(index uint32, rank uint8) {
+	if !h.IsSparse() {
+		if rank > h.registers[index] {
+			h.registers[index] = rank
+		}
+		return
+	}
+
+	if rank <= h.sparse[index] {
+		return
+	}
+
+	if h.sparse == nil {
+		h.sparse = make(map[uint32]uint8)
+	}
+	h.sparse[index] = rank
+
+	// Map item takes approximately 16 bytes, dense register 1 byte
+	if len(h.sparse) > 1<<h.precision/16 {
+		h.toDense()
+	}
+}
+
+func (h *HyperLogLog) toDense() {
+	if !h.IsSparse() {
+		return
+	}
+
+	h.registers = make([]uint8, 1<<h.precision)
+	for index, rank := range h.sparse {
+		h.registers[index] = rank
+	}
+	h.sparse = nil
+}
+
+// RelativeError returns standard error of the estimate in percent.
+func (h *HyperLogLog) RelativeError() float64 {
+	return 104 / math.Sqrt(float64(uint64(1)<<h.precision))
+}
+
+func hyperLogLogAlpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/m)
+}
+
+// Hash of value, values of different Go types are considered different.
+func hashValue(value interface{}) uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+
+	switch v := FromDriverValue(value).(type) {
+	case string:
+		h.Write([]byte{1})
+		h.Write([]byte(v))
+	case float64:
+		h.Write([]byte{2})
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(v))
+		h.Write(buf)
+	case int:
+		h.Write([]byte{3})
+		binary.LittleEndian.PutUint64(buf, uint64(v))
+		h.Write(buf)
+	case int64:
+		h.Write([]byte{4})
+		binary.LittleEndian.PutUint64(buf, uint64(v))
+		h.Write(buf)
+	case bson.MongoTimestamp:
+		h.Write([]byte{5})
+		binary.LittleEndian.PutUint64(buf, uint64(v))
+		h.Write(buf)
+	case time.Time:
+		h.Write([]byte{6})
+		binary.LittleEndian.PutUint64(buf, uint64(v.UnixNano()))
+		h.Write(buf)
+	case bson.Decimal128:
+		h.Write([]byte{7})
+		h.Write([]byte(v.String()))
+	default:
+		h.Write([]byte{8})
+		h.Write([]byte(MarshalToJSON(v)))
+	}
+
+	return mixHash(h.Sum64())
+}
+
+// Finalizer of MurmurHash3, FNV alone does not spread short inputs to the high bits.
+func mixHash(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,125 @@
+package helpers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestHyperLogLog_Estimate(t *testing.T) {
+	for _, n := range []int{0, 1, 100, 10000, 1000000} {
+		h := NewHyperLogLog(HyperLogLogPrecision)
+		for i := 0; i < n; i++ {
+			h.Add("value" + strconv.Itoa(i))
+			h.Add("value" + strconv.Itoa(i)) // duplicates are not counted
+		}
+
+		// 4 standard errors
+		assert.InDelta(t, float64(n), float64(h.Estimate()), 4*h.RelativeError()/100*float64(n)+0.5, "n = %d", n)
+	}
+}
+
+func TestHyperLogLog_Merge(t *testing.T) {
+	a := NewHyperLogLog(HyperLogLogPrecision)
+	b := NewHyperLogLog(HyperLogLogPrecision)
+	all := NewHyperLogLog(HyperLogLogPrecision)
+
+	for i := 0; i < 50000; i++ {
+		if i%2 == 0 {
+			a.Add(i)
+		} else {
+			b.Add(i)
+		}
+		all.Add(i)
+	}
+
+	a.Merge(b)
+	assert.Equal(t, all.Estimate(), a.Estimate())
+
+	assert.Panics(t, func() {
+		a.Merge(NewHyperLogLog(10))
+	})
+}
+
+func TestHyperLogLog_Types(t *testing.T) {
+	h := NewHyperLogLog(HyperLogLogPrecision)
+	h.Add("1")
+	h.Add(1)
+	h.Add(int64(1))
+	h.Add(float64(1))
+	h.Add(bson.MongoTimestamp(1))
+	h.Add(time.Unix(1, 0))
+	h.Add(ParseDecimal("1"))
+	h.Add(time.Unix(1, 0))
+	h.Add(ParseDecimal("1"))
+
+	assert.Equal(t, uint64(7), h.Estimate())
+}
+
+func TestHyperLogLog_RelativeError(t *testing.T) {
+	assert.Equal(t, 1.625, NewHyperLogLog(HyperLogLogPrecision).RelativeError())
+	assert.Equal(t, 104/math.Sqrt(16), NewHyperLogLog(4).RelativeError())
+	assert.Panics(t, func() {
+		NewHyperLogLog(2)
+	})
+}
+
+func TestHyperLogLog_Sparse(t *testing.T) {
+	sparse := NewHyperLogLog(HyperLogLogPrecision)
+	dense := NewHyperLogLog(HyperLogLogPrecision)
+	dense.toDense()
+
+	for i := 0; i < 100; i++ {
+		sparse.Add(i)
+		dense.Add(i)
+	}
+
+	assert.True(t, sparse.IsSparse())
+	assert.Equal(t, dense.Estimate(), sparse.Estimate())
+
+	// Sparse sketch is converted to dense when it grows
+	for i := 100; i < 1000; i++ {
+		sparse.Add(i)
+		dense.Add(i)
+	}
+
+	assert.False(t, sparse.IsSparse())
+	assert.Equal(t, dense.registers, sparse.registers)
+}
+
+func TestHyperLogLog_MergeSparse(t *testing.T) {
+	a := NewHyperLogLog(HyperLogLogPrecision)
+	b := NewHyperLogLog(HyperLogLogPrecision)
+	all := NewHyperLogLog(HyperLogLogPrecision)
+
+	for i := 0; i < 5000; i++ {
+		if i < 100 {
+			a.Add(i)
+		} else {
+			b.Add(i)
+		}
+		all.Add(i)
+	}
+
+	assert.True(t, a.IsSparse())
+	assert.False(t, b.IsSparse())
+
+	// Dense into sparse
+	c := NewHyperLogLog(HyperLogLogPrecision)
+	c.Merge(a)
+	c.Merge(b)
+	assert.Equal(t, all.Estimate(), c.Estimate())
+
+	// Sparse into dense
+	b.Merge(a)
+	assert.Equal(t, all.Estimate(), b.Estimate())
+
+	// Sparse into sparse
+	d := NewHyperLogLog(HyperLogLogPrecision)
+	d.Merge(a)
+	assert.True(t, d.IsSparse())
+	assert.Equal(t, a.Estimate(), d.Estimate())
+}
@@ -0,0 +1,57 @@
+package helpers
+
+import "math"
+
+// RunningStats computes mean and variance in one pass using Welford's algorithm.
+// Partial results from concurrent workers can be merged.
+type RunningStats struct {
+	count float64
+	mean  float64
+	m2    float64 // sum of squared differences from the mean
+}
+
+// Add value to the stats.
+func (s *RunningStats) Add(value float64) {
+	s.count++
+	delta := value - s.mean
+	if delta != 0 {
+		s.mean += delta / s.count
+		s.m2 += delta * (value - s.mean)
+	}
+}
+
+// Merge other stats into this stats (Chan et al. parallel algorithm).
+func (s *RunningStats) Merge(other *RunningStats) {
+	if other.count == 0 {
+		return
+	}
+
+	if s.count == 0 {
+		*s = *other
+		return
+	}
+
+	count := s.count + other.count
+	delta := other.mean - s.mean
+	s.m2 += other.m2 + delta*delta*s.count*other.count/count
+	s.mean += delta * other.count / count
+	s.count = count
+}
+
+// Count returns number of values.
+func (s *RunningStats) Count() uint64 {
+	return uint64(s.count)
+}
+
+// Mean returns average of values.
+func (s *RunningStats) Mean() float64 {
+	return s.mean
+}
+
+// StdDev returns population standard deviation, the same as $stdDevPop in MongoDB.
+func (s *RunningStats) StdDev() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return math.Sqrt(s.m2 / s.count)
+}
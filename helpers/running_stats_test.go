@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestRunningStats(t *testing.T) {
+	s := RunningStats{}
+	assert.Equal(t, float64(0), s.StdDev())
+
+	for _, v := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		s.Add(v)
+	}
+
+	assert.Equal(t, uint64(8), s.Count())
+	assert.Equal(t, float64(5), s.Mean())
+	assert.Equal(t, float64(2), s.StdDev())
+}
+
+func TestRunningStats_Merge(t *testing.T) {
+	all := RunningStats{}
+	parts := []RunningStats{{}, {}, {}}
+	for i := 0; i < 1000; i++ {
+		v := math.Sin(float64(i)) * 100
+		all.Add(v)
+		parts[i%3].Add(v)
+	}
+
+	merged := RunningStats{}
+	for i := range parts {
+		merged.Merge(&parts[i])
+	}
+	merged.Merge(&RunningStats{})
+
+	assert.Equal(t, all.Count(), merged.Count())
+	assert.InDelta(t, all.Mean(), merged.Mean(), 1e-9)
+	assert.InDelta(t, all.StdDev(), merged.StdDev(), 1e-9)
+}
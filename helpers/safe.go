@@ -6,9 +6,9 @@ import (
 	"time"
 )
 
-// SafeToObjectId converts interface to ObjectId or fail.
+// SafeToObjectId converts interface to ObjectId or fail, ObjectID of the official driver is also accepted.
 func SafeToObjectId(value interface{}) bson.ObjectId {
-	if t, ok := value.(bson.ObjectId); ok {
+	if t, ok := FromDriverValue(value).(bson.ObjectId); ok {
 		return t
 	}
 	panic("Unexpected type. Given: " + reflect.TypeOf(value).String())
@@ -78,9 +78,9 @@ func SafeToLong(value interface{}) int64 {
 	panic("Unexpected type. Given: " + reflect.TypeOf(value).String())
 }
 
-// SafeToDecimal converts interface to decimal or fail.
+// SafeToDecimal converts interface to decimal or fail, Decimal128 of the official driver is also accepted.
 func SafeToDecimal(value interface{}) bson.Decimal128 {
-	if t, ok := value.(bson.Decimal128); ok {
+	if t, ok := FromDriverValue(value).(bson.Decimal128); ok {
 		return t
 	}
 	panic("Unexpected type. Given: " + reflect.TypeOf(value).String())
@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"github.com/stretchr/testify/assert"
+	driverbson "go.mongodb.org/mongo-driver/v2/bson"
 	"gopkg.in/mgo.v2/bson"
 	"testing"
 )
@@ -11,6 +12,12 @@ func TestSafeToObjectId(t *testing.T) {
 	assert.Equal(t, bson.ObjectIdHex("57605d5dc3d5a2429db0bd09"), SafeToObjectId(input))
 }
 
+func TestSafeToObjectId_Driver(t *testing.T) {
+	oid, _ := driverbson.ObjectIDFromHex("57605d5dc3d5a2429db0bd09")
+	var input interface{} = oid
+	assert.Equal(t, bson.ObjectIdHex("57605d5dc3d5a2429db0bd09"), SafeToObjectId(input))
+}
+
 func TestSafeToObjectId_Invalid(t *testing.T) {
 	var input interface{} = "abc"
 	assert.Panics(t, func() {
@@ -119,6 +126,12 @@ func TestSafeToDecimal(t *testing.T) {
 	assert.Equal(t, ParseDecimal("136.789"), SafeToDecimal(input))
 }
 
+func TestSafeToDecimal_Driver(t *testing.T) {
+	d, _ := driverbson.ParseDecimal128("136.789")
+	var input interface{} = d
+	assert.Equal(t, ParseDecimal("136.789"), SafeToDecimal(input))
+}
+
 func TestSafeToDecimal_Invalid(t *testing.T) {
 	var input interface{} = "abc"
 	assert.Panics(t, func() {
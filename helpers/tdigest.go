@@ -0,0 +1,143 @@
+package helpers
+
+import (
+	"math"
+	"sort"
+)
+
+// TDigestCompression - maximal number of centroids is approximately 2 * compression.
+const TDigestCompression = 100
+
+// TDigest estimates quantiles of a stream of values in bounded memory.
+// Values near the tails are stored more precisely than values near the median.
+// Digests can be merged.
+type TDigest struct {
+	compression float64
+	centroids   []centroid
+	buffer      []centroid
+	count       float64
+	min         float64
+	max         float64
+}
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// NewTDigest creates empty digest.
+func NewTDigest(compression float64) *TDigest {
+	return &TDigest{
+		compression: compression,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// Add value to the digest.
+func (d *TDigest) Add(value float64) {
+	d.add(centroid{mean: value, weight: 1})
+}
+
+// Merge other digest into this digest.
+func (d *TDigest) Merge(other *TDigest) {
+	for _, c := range other.centroids {
+		d.add(c)
+	}
+	for _, c := range other.buffer {
+		d.add(c)
+	}
+
+	// Extremes are not means of centroids
+	d.min = math.Min(d.min, other.min)
+	d.max = math.Max(d.max, other.max)
+}
+
+// Count returns number of added values.
+func (d *TDigest) Count() uint64 {
+	return uint64(d.count)
+}
+
+// Quantile returns estimated value at the given probability 0 <= q <= 1.
+// Values between centroids are linearly interpolated, exact quantiles are returned for small digests.
+func (d *TDigest) Quantile(q float64) float64 {
+	d.compress()
+
+	if d.count == 0 {
+		return math.NaN()
+	}
+
+	// Position of the value in the sorted values, centroids are located at positions of their middle values
+	target := q * (d.count - 1)
+	prevPos, prevValue := 0.0, d.min
+	pos := 0.0
+	for _, c := range d.centroids {
+		center := pos + (c.weight-1)/2
+		if target <= center {
+			return interpolate(prevPos, prevValue, center, c.mean, target)
+		}
+		prevPos, prevValue = center, c.mean
+		pos += c.weight
+	}
+
+	return interpolate(prevPos, prevValue, d.count-1, d.max, target)
+}
+
+func interpolate(x1, y1, x2, y2, x float64) float64 {
+	if x2 <= x1 {
+		return y2
+	}
+	return y1 + (y2-y1)*(x-x1)/(x2-x1)
+}
+
+func (d *TDigest) add(c centroid) {
+	d.buffer = append(d.buffer, c)
+	d.count += c.weight
+	d.min = math.Min(d.min, c.mean)
+	d.max = math.Max(d.max, c.mean)
+
+	if len(d.buffer) >= 5*int(d.compression) {
+		d.compress()
+	}
+}
+
+// Merges buffer with centroids, neighboring centroids are merged while their size is within scale function limit.
+func (d *TDigest) compress() {
+	if len(d.buffer) == 0 {
+		return
+	}
+
+	all := append(d.centroids, d.buffer...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	result := make([]centroid, 0, len(all))
+	result = append(result, all[0])
+	before := 0.0
+	limit := d.count * d.scaleInverse(d.scale(0)+1)
+	for _, c := range all[1:] {
+		last := &result[len(result)-1]
+		if before+last.weight+c.weight <= limit {
+			last.mean += (c.mean - last.mean) * c.weight / (last.weight + c.weight)
+			last.weight += c.weight
+		} else {
+			before += last.weight
+			limit = d.count * d.scaleInverse(d.scale(before/d.count)+1)
+			result = append(result, c)
+		}
+	}
+
+	d.centroids = result
+	d.buffer = nil
+}
+
+// Scale function k1 from the t-digest paper, centroids near q = 0 and q = 1 are small.
+func (d *TDigest) scale(q float64) float64 {
+	return d.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+func (d *TDigest) scaleInverse(k float64) float64 {
+	if k >= d.compression/4 {
+		return 1
+	}
+	return (math.Sin(k*2*math.Pi/d.compression) + 1) / 2
+}
@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestTDigest_Exact(t *testing.T) {
+	d := NewTDigest(TDigestCompression)
+	for _, v := range []float64{5, 1, 4, 2, 3} {
+		d.Add(v)
+	}
+
+	assert.Equal(t, uint64(5), d.Count())
+	assert.Equal(t, float64(1), d.Quantile(0))
+	assert.Equal(t, float64(2), d.Quantile(0.25))
+	assert.Equal(t, float64(3), d.Quantile(0.5))
+	assert.Equal(t, 4.6, d.Quantile(0.9))
+	assert.Equal(t, float64(5), d.Quantile(1))
+
+	d.Add(6)
+	assert.Equal(t, 3.5, d.Quantile(0.5))
+}
+
+func TestTDigest_Empty(t *testing.T) {
+	d := NewTDigest(TDigestCompression)
+	assert.True(t, math.IsNaN(d.Quantile(0.5)))
+
+	d.Add(7)
+	assert.Equal(t, float64(7), d.Quantile(0.1))
+	assert.Equal(t, float64(7), d.Quantile(0.9))
+}
+
+func TestTDigest_Large(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	values := make([]float64, 100000)
+	d := NewTDigest(TDigestCompression)
+	for i := range values {
+		values[i] = r.ExpFloat64()
+		d.Add(values[i])
+	}
+	sort.Float64s(values)
+
+	// Error in rank
+	for _, q := range []float64{0.01, 0.1, 0.5, 0.9, 0.99} {
+		rank := float64(sort.SearchFloat64s(values, d.Quantile(q))) / float64(len(values))
+		assert.InDelta(t, q, rank, 0.002, "q = %f", q)
+	}
+	assert.True(t, len(d.centroids) < 2*TDigestCompression)
+}
+
+func TestTDigest_Merge(t *testing.T) {
+	a := NewTDigest(TDigestCompression)
+	b := NewTDigest(TDigestCompression)
+	for i := 0; i < 10000; i++ {
+		if i%2 == 0 {
+			a.Add(float64(i))
+		} else {
+			b.Add(float64(i))
+		}
+	}
+
+	a.Merge(b)
+	assert.Equal(t, uint64(10000), a.Count())
+	assert.Equal(t, float64(0), a.Quantile(0))
+	assert.Equal(t, float64(9999), a.Quantile(1))
+	assert.InDelta(t, 4999.5, a.Quantile(0.5), 50)
+	assert.InDelta(t, 9899, a.Quantile(0.99), 10)
+}
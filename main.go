@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/mongoeye/mongoeye/cli"
+	"github.com/spf13/cobra"
 	"os"
 )
 
@@ -25,11 +26,17 @@ const AppVersion = "DEV"
 const AppSubtitle = "MongoDB exploration tool"
 
 func main() {
-	cmd, _ := cli.NewCmd(CmdName, EnvPrefix, AppName, AppVersion, AppSubtitle)
+	var cmd *cobra.Command
+	if cli.IsDiffCmd(os.Args) {
+		cmd, _ = cli.NewDiffCmd(CmdName, EnvPrefix, AppName, AppVersion, AppSubtitle)
+		cmd.SetArgs(os.Args[2:])
+	} else {
+		cmd, _ = cli.NewCmd(CmdName, EnvPrefix, AppName, AppVersion, AppSubtitle)
+	}
 	cmd.SetOutput(color.Output)
 
 	if err := cmd.Execute(); err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
 	}
 }
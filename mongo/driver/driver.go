@@ -0,0 +1,146 @@
+// Package driver abstracts the MongoDB driver.
+// Analysis, expressions and command line interface use only these interfaces,
+// so they do not depend on a concrete driver implementation (see package official).
+//
+// Documents are passed as raw BSON, so they can be decoded without re-encoding.
+// Commands and pipelines are built with gopkg.in/mgo.v2/bson types.
+package driver
+
+import (
+	"context"
+	"errors"
+	"gopkg.in/mgo.v2/bson"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when no document is found.
+var ErrNotFound = errors.New("not found")
+
+// Mode is read preference of the session, values are same as in mgo.
+type Mode int
+
+// Read preference modes.
+const (
+	Primary            Mode = 2 // Read from the primary.
+	PrimaryPreferred   Mode = 3 // Read from the primary if available, from a secondary otherwise.
+	Secondary          Mode = 4 // Read from a secondary.
+	SecondaryPreferred Mode = 5 // Read from a secondary if available, from the primary otherwise.
+	Nearest            Mode = 6 // Read from the nearest member.
+
+	Eventual  Mode = 0 // Same as Nearest.
+	Monotonic Mode = 1 // Same as SecondaryPreferred, analysis never writes.
+	Strong    Mode = 2 // Same as Primary.
+)
+
+// DialInfo contains options for connecting to the server.
+type DialInfo struct {
+	// URI is the connection string, it is parsed by the driver.
+	// Addrs are ignored if it is set, other options are used only if the URI does not contain them.
+	URI           string
+	Addrs         []string
+	ReplicaSet    string
+	Mode          Mode
+	Tags          []bson.D      // read preference tags
+	MaxStaleness  time.Duration // zero = none
+	AppName       string
+	Timeout       time.Duration // connection timeout
+	SyncTimeout   time.Duration // server selection timeout
+	SocketTimeout time.Duration // timeout of commands, cursors are limited by the context
+	DialServer    func(addr string) (net.Conn, error)
+}
+
+// Credential for authentication.
+type Credential struct {
+	Username  string
+	Password  string
+	Source    string
+	Mechanism string
+}
+
+// BuildInfo contains version and other details about the server.
+type BuildInfo struct {
+	Version        string
+	VersionArray   []int  `bson:"versionArray"`
+	GitVersion     string `bson:"gitVersion"`
+	OpenSSLVersion string `bson:"OpenSSLVersion"`
+	SysInfo        string `bson:"sysInfo"`
+	Bits           int
+	Debug          bool
+	MaxObjectSize  int `bson:"maxBsonObjectSize"`
+}
+
+// VersionAtLeast returns whether the server version is greater than or equal to the given version.
+func (bi *BuildInfo) VersionAtLeast(version ...int) bool {
+	for i, vi := range version {
+		if i == len(bi.VersionArray) {
+			return false
+		}
+		if bivi := bi.VersionArray[i]; bivi != vi {
+			return bivi >= vi
+		}
+	}
+	return true
+}
+
+// AggregateOptions of aggregation.
+type AggregateOptions struct {
+	BatchSize    int
+	MaxTime      time.Duration // server-side time limit, zero = no limit
+	AllowDiskUse bool
+}
+
+// Session is connection to MongoDB server, it is safe for concurrent use.
+type Session interface {
+	// BuildInfo retrieves version of the server.
+	BuildInfo() (BuildInfo, error)
+	// Login authenticates the session.
+	Login(credential *Credential) error
+	// DatabaseNames returns sorted names of databases.
+	DatabaseNames() ([]string, error)
+	// CollectionNames returns sorted names of collections and views in the database.
+	CollectionNames(db string) ([]string, error)
+	// ViewNames returns sorted names of views in the database.
+	ViewNames(db string) ([]string, error)
+	// Collection returns the named collection.
+	Collection(db string, name string) Collection
+	// Run the command in the database and unmarshal the response to result, if it is not nil.
+	Run(db string, cmd interface{}, result interface{}) error
+	// Copy returns session that shares the connection, it must be closed separately.
+	Copy() Session
+	// Close the session.
+	Close()
+}
+
+// Collection of documents.
+type Collection interface {
+	Database() string
+	Name() string
+	Session() Session
+	// Count returns number of documents in the collection.
+	Count() (int, error)
+	// Aggregate runs the pipeline. Errors are returned by Close method of the cursor.
+	Aggregate(ctx context.Context, stages []bson.M, options AggregateOptions) Cursor
+}
+
+// Cursor iterates over raw (binary) documents, it is safe for concurrent use.
+type Cursor interface {
+	// Next returns next document, false is returned at the end, on error or when the context is done.
+	Next() ([]byte, bool)
+	// Close the cursor and return the error that stopped the iteration.
+	Close() error
+}
+
+// IsTimeLimitError returns true if the error is caused by exceeded time limit (maxTimeMS).
+// Error code 50 = ExceededTimeLimit
+func IsTimeLimitError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var e interface{ HasErrorCode(int) bool }
+	if errors.As(err, &e) && e.HasErrorCode(50) {
+		return true
+	}
+	return strings.Contains(err.Error(), "exceeded time limit")
+}
@@ -0,0 +1,40 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBuildInfo_VersionAtLeast(t *testing.T) {
+	info := BuildInfo{VersionArray: []int{3, 6, 2, 0}}
+	assert.True(t, info.VersionAtLeast(3))
+	assert.True(t, info.VersionAtLeast(3, 6))
+	assert.True(t, info.VersionAtLeast(3, 5, 6))
+	assert.True(t, info.VersionAtLeast(3, 6, 2, 0))
+	assert.False(t, info.VersionAtLeast(3, 6, 3))
+	assert.False(t, info.VersionAtLeast(4, 0))
+	assert.False(t, info.VersionAtLeast(3, 6, 2, 0, 1))
+}
+
+type codeError int
+
+func (e codeError) Error() string {
+	return fmt.Sprintf("code %d", int(e))
+}
+
+func (e codeError) HasErrorCode(code int) bool {
+	return int(e) == code
+}
+
+func TestIsTimeLimitError(t *testing.T) {
+	assert.True(t, IsTimeLimitError(context.DeadlineExceeded))
+	assert.True(t, IsTimeLimitError(fmt.Errorf("aggregate: %w", context.DeadlineExceeded)))
+	assert.True(t, IsTimeLimitError(codeError(50)))
+	assert.True(t, IsTimeLimitError(fmt.Errorf("getMore: %w", codeError(50))))
+	assert.True(t, IsTimeLimitError(errors.New("operation exceeded time limit")))
+	assert.False(t, IsTimeLimitError(codeError(13)))
+	assert.False(t, IsTimeLimitError(errors.New("not authorized")))
+}
@@ -0,0 +1,70 @@
+package official
+
+import (
+	"context"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Collection on the official driver.
+type Collection struct {
+	session  *Session
+	database string
+	name     string
+}
+
+// Collection of the current client, the client is replaced by login.
+func (c *Collection) collection() *mongo.Collection {
+	return c.session.client().Database(c.database).Collection(c.name)
+}
+
+// Database returns name of the database.
+func (c *Collection) Database() string {
+	return c.database
+}
+
+// Name returns name of the collection.
+func (c *Collection) Name() string {
+	return c.name
+}
+
+// Session returns session of the collection.
+func (c *Collection) Session() driver.Session {
+	return c.session
+}
+
+// Count returns number of documents in the collection, it is estimated from metadata as in the count command.
+func (c *Collection) Count() (int, error) {
+	ctx, cancel := c.session.context()
+	defer cancel()
+
+	count, err := c.collection().EstimatedDocumentCount(ctx)
+	return int(count), err
+}
+
+// Aggregate runs the pipeline, the server-side time limit (maxTimeMS) is derived from the context deadline.
+func (c *Collection) Aggregate(ctx context.Context, stages []bson.M, opts driver.AggregateOptions) driver.Cursor {
+	cancel := context.CancelFunc(func() {})
+	if opts.MaxTime > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.MaxTime)
+	}
+
+	pipeline := make([]interface{}, len(stages))
+	for i, stage := range stages {
+		raw, err := marshal(stage)
+		if err != nil {
+			return newCursor(ctx, nil, err, cancel)
+		}
+		pipeline[i] = raw
+	}
+
+	aggregateOpts := options.Aggregate().SetAllowDiskUse(opts.AllowDiskUse)
+	if opts.BatchSize > 0 {
+		aggregateOpts.SetBatchSize(int32(opts.BatchSize))
+	}
+
+	cursor, err := c.collection().Aggregate(ctx, pipeline, aggregateOpts)
+	return newCursor(ctx, cursor, err, cancel)
+}
@@ -0,0 +1,65 @@
+package official
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"sync"
+	"time"
+)
+
+// Time limit for killing the cursor on the server.
+const closeTimeout = 5 * time.Second
+
+// Cursor on the official driver, it can be read by more goroutines.
+type Cursor struct {
+	mutex  sync.Mutex
+	ctx    context.Context
+	cursor *mongo.Cursor
+	cancel context.CancelFunc
+	err    error
+	closed bool
+}
+
+// Cursor with error is empty, the error is returned by Close method.
+func newCursor(ctx context.Context, cursor *mongo.Cursor, err error, cancel context.CancelFunc) *Cursor {
+	return &Cursor{ctx: ctx, cursor: cursor, err: err, cancel: cancel}
+}
+
+// Next returns copy of the next raw document.
+func (c *Cursor) Next() ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed || c.cursor == nil || !c.cursor.Next(c.ctx) {
+		return nil, false
+	}
+
+	raw := make([]byte, len(c.cursor.Current))
+	copy(raw, c.cursor.Current)
+	return raw, true
+}
+
+// Close the cursor and return the error that stopped the iteration.
+// It can be called more times, the same error is returned.
+func (c *Cursor) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return c.err
+	}
+	c.closed = true
+
+	if c.cursor != nil {
+		if c.err == nil {
+			c.err = c.cursor.Err()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		c.cursor.Close(ctx)
+		cancel()
+	}
+
+	c.cancel()
+	return c.err
+}
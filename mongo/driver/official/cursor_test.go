@@ -0,0 +1,101 @@
+package official
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"gopkg.in/mgo.v2/bson"
+	"sync"
+	"testing"
+)
+
+func newTestCursor(t *testing.T, count int, err error) (*Cursor, *bool) {
+	docs := make([]interface{}, count)
+	for i := range docs {
+		raw, _ := marshal(bson.M{"i": i})
+		docs[i] = raw
+	}
+
+	cursor, e := mongo.NewCursorFromDocuments(docs, err, nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	canceled := false
+	return newCursor(context.Background(), cursor, nil, func() { canceled = true }), &canceled
+}
+
+func TestCursor(t *testing.T) {
+	c, canceled := newTestCursor(t, 3, nil)
+
+	for i := 0; i < 3; i++ {
+		raw, ok := c.Next()
+		assert.True(t, ok)
+
+		out := bson.M{}
+		bson.Unmarshal(raw, &out)
+		assert.Equal(t, bson.M{"i": i}, out)
+	}
+
+	_, ok := c.Next()
+	assert.False(t, ok)
+
+	assert.Nil(t, c.Close())
+	assert.True(t, *canceled)
+
+	// Closed cursor
+	_, ok = c.Next()
+	assert.False(t, ok)
+	assert.Nil(t, c.Close())
+}
+
+func TestCursor_Concurrent(t *testing.T) {
+	c, _ := newTestCursor(t, 1000, nil)
+
+	wg := sync.WaitGroup{}
+	mutex := sync.Mutex{}
+	seen := map[int]bool{}
+
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				raw, ok := c.Next()
+				if !ok {
+					break
+				}
+
+				out := struct{ I int }{}
+				bson.Unmarshal(raw, &out)
+
+				mutex.Lock()
+				seen[out.I] = true
+				mutex.Unlock()
+			}
+			c.Close()
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 1000, len(seen))
+}
+
+func TestCursor_Error(t *testing.T) {
+	err := errors.New("cursor error")
+	c, _ := newTestCursor(t, 1, err)
+
+	_, ok := c.Next()
+	assert.False(t, ok)
+	assert.Equal(t, err, c.Close())
+	assert.Equal(t, err, c.Close())
+
+	// Error of aggregation
+	canceled := false
+	c = newCursor(context.Background(), nil, err, func() { canceled = true })
+	_, ok = c.Next()
+	assert.False(t, ok)
+	assert.Equal(t, err, c.Close())
+	assert.True(t, canceled)
+}
@@ -0,0 +1,4 @@
+package official
+
+// NewClientOptions is exported for the tests of connection string handling.
+var NewClientOptions = newClientOptions
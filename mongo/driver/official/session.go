@@ -0,0 +1,293 @@
+// Package official implements the driver interfaces on the official MongoDB Go driver.
+package official
+
+import (
+	"context"
+	"fmt"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	driverbson "go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/tag"
+	"gopkg.in/mgo.v2/bson"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Session on the official driver.
+// Copies of the session share the client, it is disconnected when the last copy is closed.
+type Session struct {
+	shared    *shared
+	closeOnce sync.Once
+}
+
+type shared struct {
+	mutex  sync.Mutex
+	info   driver.DialInfo
+	client *mongo.Client
+	refs   int
+}
+
+// Dial connects to the server and checks that it is reachable.
+func Dial(info *driver.DialInfo) (driver.Session, error) {
+	client, err := connect(info, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{shared: &shared{info: *info, client: client, refs: 1}}, nil
+}
+
+// Create the client and ping the server, so the connection and authentication are verified.
+func connect(info *driver.DialInfo, credential *driver.Credential) (*mongo.Client, error) {
+	opts, err := newClientOptions(info)
+	if err != nil {
+		return nil, err
+	}
+
+	if credential != nil {
+		auth := options.Credential{
+			Username:      credential.Username,
+			Password:      credential.Password,
+			PasswordSet:   credential.Password != "",
+			AuthSource:    credential.Source,
+			AuthMechanism: credential.Mechanism,
+		}
+		// Mechanism properties can be set only in the connection string
+		if opts.Auth != nil {
+			auth.AuthMechanismProperties = opts.Auth.AuthMechanismProperties
+		}
+		opts.SetAuth(auth)
+	}
+
+	client, err := mongo.Connect(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(info.Timeout)
+	defer cancel()
+
+	err = client.Ping(ctx, nil)
+	if err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func newClientOptions(info *driver.DialInfo) (*options.ClientOptions, error) {
+	opts := options.Client()
+	if info.URI != "" {
+		opts.ApplyURI(info.URI)
+	} else {
+		opts.SetHosts(info.Addrs)
+	}
+
+	// Options from the connection string are not overridden
+	if info.ReplicaSet != "" && opts.ReplicaSet == nil {
+		opts.SetReplicaSet(info.ReplicaSet)
+	}
+	if opts.ReadPreference == nil {
+		readPref, err := newReadPref(info)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetReadPreference(readPref)
+	}
+
+	if info.Timeout > 0 && opts.ConnectTimeout == nil {
+		opts.SetConnectTimeout(info.Timeout)
+	}
+	if info.SyncTimeout > 0 && opts.ServerSelectionTimeout == nil {
+		opts.SetServerSelectionTimeout(info.SyncTimeout)
+	}
+	if info.AppName != "" && opts.AppName == nil {
+		opts.SetAppName(info.AppName)
+	}
+	if info.DialServer != nil {
+		opts.SetDialer(dialer(info.DialServer))
+	}
+
+	return opts, nil
+}
+
+// Tags and max staleness cannot be used with the primary mode.
+func newReadPref(info *driver.DialInfo) (*readpref.ReadPref, error) {
+	mode := readPrefMode(info.Mode)
+	if mode == readpref.PrimaryMode {
+		return readpref.Primary(), nil
+	}
+
+	var opts []readpref.Option
+	if len(info.Tags) > 0 {
+		opts = append(opts, readpref.WithTagSets(tagSets(info.Tags)...))
+	}
+	if info.MaxStaleness > 0 {
+		opts = append(opts, readpref.WithMaxStaleness(info.MaxStaleness))
+	}
+
+	return readpref.New(mode, opts...)
+}
+
+func readPrefMode(mode driver.Mode) readpref.Mode {
+	switch mode {
+	case driver.PrimaryPreferred:
+		return readpref.PrimaryPreferredMode
+	case driver.Secondary:
+		return readpref.SecondaryMode
+	case driver.SecondaryPreferred, driver.Monotonic:
+		return readpref.SecondaryPreferredMode
+	case driver.Nearest, driver.Eventual:
+		return readpref.NearestMode
+	}
+	return readpref.PrimaryMode
+}
+
+func tagSets(tags []bson.D) []tag.Set {
+	sets := make([]tag.Set, len(tags))
+	for i, doc := range tags {
+		sets[i] = tag.Set{}
+		for _, e := range doc {
+			sets[i] = append(sets[i], tag.Tag{Name: e.Name, Value: fmt.Sprint(e.Value)})
+		}
+	}
+	return sets
+}
+
+// Custom dialer, eg. for TLS connections.
+type dialer func(addr string) (net.Conn, error)
+
+func (d dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d(address)
+}
+
+func (s *Session) client() *mongo.Client {
+	s.shared.mutex.Lock()
+	defer s.shared.mutex.Unlock()
+	return s.shared.client
+}
+
+// Context of commands, limited by the socket timeout.
+func (s *Session) context() (context.Context, context.CancelFunc) {
+	return withTimeout(s.shared.info.SocketTimeout)
+}
+
+// BuildInfo retrieves version of the server.
+func (s *Session) BuildInfo() (info driver.BuildInfo, err error) {
+	err = s.Run("admin", bson.D{{Name: "buildInfo", Value: 1}}, &info)
+	return
+}
+
+// Login authenticates the session.
+// Credentials of the official driver are set when the client is created, so the client is replaced.
+func (s *Session) Login(credential *driver.Credential) error {
+	s.shared.mutex.Lock()
+	defer s.shared.mutex.Unlock()
+
+	client, err := connect(&s.shared.info, credential)
+	if err != nil {
+		return err
+	}
+
+	s.shared.client.Disconnect(context.Background())
+	s.shared.client = client
+	return nil
+}
+
+// DatabaseNames returns sorted names of databases.
+func (s *Session) DatabaseNames() ([]string, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	names, err := s.client().ListDatabaseNames(ctx, driverbson.D{})
+	sort.Strings(names)
+	return names, err
+}
+
+// CollectionNames returns sorted names of collections and views in the database.
+func (s *Session) CollectionNames(db string) ([]string, error) {
+	return s.listCollectionNames(db, driverbson.D{})
+}
+
+// ViewNames returns sorted names of views in the database.
+func (s *Session) ViewNames(db string) ([]string, error) {
+	return s.listCollectionNames(db, driverbson.D{{Key: "type", Value: "view"}})
+}
+
+func (s *Session) listCollectionNames(db string, filter driverbson.D) ([]string, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	names, err := s.client().Database(db).ListCollectionNames(ctx, filter)
+	sort.Strings(names)
+	return names, err
+}
+
+// Collection returns the named collection.
+func (s *Session) Collection(db string, name string) driver.Collection {
+	return &Collection{session: s, database: db, name: name}
+}
+
+// Run the command in the database and unmarshal the response to result, if it is not nil.
+func (s *Session) Run(db string, cmd interface{}, result interface{}) error {
+	raw, err := marshal(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+
+	out, err := s.client().Database(db).RunCommand(ctx, raw).Raw()
+	if err != nil {
+		return err
+	}
+
+	if result != nil {
+		return bson.Unmarshal(out, result)
+	}
+	return nil
+}
+
+// Copy returns session that shares the client.
+func (s *Session) Copy() driver.Session {
+	s.shared.mutex.Lock()
+	defer s.shared.mutex.Unlock()
+
+	s.shared.refs++
+	return &Session{shared: s.shared}
+}
+
+// Close the session, the client is disconnected when the last copy is closed.
+func (s *Session) Close() {
+	s.closeOnce.Do(func() {
+		s.shared.mutex.Lock()
+		defer s.shared.mutex.Unlock()
+
+		s.shared.refs--
+		if s.shared.refs == 0 {
+			s.shared.client.Disconnect(context.Background())
+		}
+	})
+}
+
+// Marshal value by the bson package, so commands and pipelines can be built with its types.
+func marshal(value interface{}) (driverbson.Raw, error) {
+	data, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return driverbson.Raw(data), nil
+}
+
+func withTimeout(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
@@ -0,0 +1,149 @@
+package official
+
+import (
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/tag"
+	"gopkg.in/mgo.v2/bson"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReadPrefMode(t *testing.T) {
+	assert.Equal(t, readpref.PrimaryMode, readPrefMode(driver.Primary))
+	assert.Equal(t, readpref.PrimaryMode, readPrefMode(driver.Strong))
+	assert.Equal(t, readpref.PrimaryPreferredMode, readPrefMode(driver.PrimaryPreferred))
+	assert.Equal(t, readpref.SecondaryMode, readPrefMode(driver.Secondary))
+	assert.Equal(t, readpref.SecondaryPreferredMode, readPrefMode(driver.SecondaryPreferred))
+	assert.Equal(t, readpref.SecondaryPreferredMode, readPrefMode(driver.Monotonic))
+	assert.Equal(t, readpref.NearestMode, readPrefMode(driver.Nearest))
+	assert.Equal(t, readpref.NearestMode, readPrefMode(driver.Eventual))
+}
+
+func TestNewReadPref(t *testing.T) {
+	rp, err := newReadPref(&driver.DialInfo{
+		Mode:         driver.Nearest,
+		Tags:         []bson.D{{{Name: "dc", Value: "ny"}, {Name: "rack", Value: 1}}, {}},
+		MaxStaleness: 2 * time.Minute,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, readpref.NearestMode, rp.Mode())
+	assert.Equal(t, []tag.Set{{{Name: "dc", Value: "ny"}, {Name: "rack", Value: "1"}}, {}}, rp.TagSets())
+
+	maxStaleness, ok := rp.MaxStaleness()
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, maxStaleness)
+
+	// Tags are not used with the primary mode
+	rp, err = newReadPref(&driver.DialInfo{
+		Mode: driver.Primary,
+		Tags: []bson.D{{{Name: "dc", Value: "ny"}}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, readpref.PrimaryMode, rp.Mode())
+	assert.Empty(t, rp.TagSets())
+}
+
+func TestNewClientOptions(t *testing.T) {
+	dialed := ""
+	opts, err := newClientOptions(&driver.DialInfo{
+		Addrs:       []string{"host1", "host2:27018"},
+		ReplicaSet:  "rs0",
+		Mode:        driver.SecondaryPreferred,
+		AppName:     "eye",
+		Timeout:     time.Second,
+		SyncTimeout: time.Minute,
+		DialServer: func(addr string) (net.Conn, error) {
+			dialed = addr
+			return nil, nil
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"host1", "host2:27018"}, opts.Hosts)
+	assert.Equal(t, "rs0", *opts.ReplicaSet)
+	assert.Equal(t, "eye", *opts.AppName)
+	assert.Equal(t, time.Second, *opts.ConnectTimeout)
+	assert.Equal(t, time.Minute, *opts.ServerSelectionTimeout)
+	assert.Equal(t, readpref.SecondaryPreferredMode, opts.ReadPreference.Mode())
+
+	opts.Dialer.DialContext(nil, "tcp", "host1:27017")
+	assert.Equal(t, "host1:27017", dialed)
+
+	opts, err = newClientOptions(&driver.DialInfo{URI: "mongodb://host3/?replicaSet=rs1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"host3"}, opts.Hosts)
+	assert.Equal(t, "rs1", *opts.ReplicaSet)
+}
+
+func TestNewClientOptions_URIOverridesDialInfo(t *testing.T) {
+	info := &driver.DialInfo{
+		URI:         "mongodb://host3/?replicaSet=rs1&readPreference=primary&appName=app&connectTimeoutMS=3000&serverSelectionTimeoutMS=4000",
+		ReplicaSet:  "rs0",
+		Mode:        driver.SecondaryPreferred,
+		AppName:     "eye",
+		Timeout:     time.Second,
+		SyncTimeout: time.Minute,
+	}
+
+	opts, err := newClientOptions(info)
+	assert.Nil(t, err)
+	assert.Equal(t, "rs1", *opts.ReplicaSet)
+	assert.Equal(t, readpref.PrimaryMode, opts.ReadPreference.Mode())
+	assert.Equal(t, "app", *opts.AppName)
+	assert.Equal(t, 3*time.Second, *opts.ConnectTimeout)
+	assert.Equal(t, 4*time.Second, *opts.ServerSelectionTimeout)
+
+	// Options missing in the URI are taken from dial info
+	info.URI = "mongodb://host3/"
+	opts, err = newClientOptions(info)
+	assert.Nil(t, err)
+	assert.Equal(t, "rs0", *opts.ReplicaSet)
+	assert.Equal(t, readpref.SecondaryPreferredMode, opts.ReadPreference.Mode())
+	assert.Equal(t, "eye", *opts.AppName)
+	assert.Equal(t, time.Second, *opts.ConnectTimeout)
+	assert.Equal(t, time.Minute, *opts.ServerSelectionTimeout)
+}
+
+func TestMarshal(t *testing.T) {
+	raw, err := marshal(bson.D{{Name: "buildInfo", Value: 1}, {Name: "x", Value: "y"}})
+	assert.Nil(t, err)
+
+	elements, err := raw.Elements()
+	assert.Nil(t, err)
+	assert.Equal(t, "buildInfo", elements[0].Key())
+	assert.Equal(t, "x", elements[1].Key())
+
+	_, err = marshal("not a document")
+	assert.Error(t, err)
+}
+
+func TestSession_Copy(t *testing.T) {
+	// Client connects lazily, no server is needed
+	client, err := mongo.Connect(options.Client().SetHosts([]string{"127.0.0.1:1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Session{shared: &shared{client: client, refs: 1}}
+	c := s.Copy()
+	assert.Equal(t, 2, s.shared.refs)
+
+	c.Close()
+	c.Close()
+	assert.Equal(t, 1, s.shared.refs)
+
+	s.Close()
+	assert.Equal(t, 0, s.shared.refs)
+}
+
+func TestSession_Collection(t *testing.T) {
+	s := &Session{shared: &shared{}}
+	c := s.Collection("shop", "orders")
+	assert.Equal(t, "shop", c.Database())
+	assert.Equal(t, "orders", c.Name())
+	assert.Equal(t, s, c.Session())
+}
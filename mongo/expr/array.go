@@ -13,6 +13,17 @@ func Map(input interface{}, as interface{}, in interface{}) bson.M {
 	}
 }
 
+// Filter encapsulates MongoDB operation $filter.
+func Filter(input interface{}, as interface{}, cond interface{}) bson.M {
+	return bson.M{
+		"$filter": bson.M{
+			"input": input,
+			"as":    as,
+			"cond":  cond,
+		},
+	}
+}
+
 // Slice encapsulates MongoDB operation $slice.
 func Slice(array interface{}, length interface{}) bson.M {
 	return bson.M{
@@ -43,3 +54,29 @@ func ConcatArrays(arrays ...interface{}) bson.M {
 func ObjectToArray(obj interface{}) bson.M {
 	return bson.M{"$objectToArray": obj}
 }
+
+// Range encapsulates MongoDB operation $range.
+func Range(start interface{}, end interface{}) bson.M {
+	return bson.M{"$range": []interface{}{start, end}}
+}
+
+// ArrayElemAt encapsulates MongoDB operation $arrayElemAt.
+func ArrayElemAt(array interface{}, index interface{}) bson.M {
+	return bson.M{"$arrayElemAt": []interface{}{array, index}}
+}
+
+// ArrayToObject encapsulates MongoDB operation $arrayToObject.
+func ArrayToObject(array interface{}) bson.M {
+	return bson.M{"$arrayToObject": array}
+}
+
+// Reduce encapsulates MongoDB operation $reduce.
+func Reduce(input interface{}, initialValue interface{}, in interface{}) bson.M {
+	return bson.M{
+		"$reduce": bson.M{
+			"input":        input,
+			"initialValue": initialValue,
+			"in":           in,
+		},
+	}
+}
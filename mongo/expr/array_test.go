@@ -22,7 +22,7 @@ func TestMap(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, []interface{}{10, 20, 30, 40, 50}, out["mapped"])
 }
@@ -44,7 +44,7 @@ func TestSlice(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, []interface{}{1, 2}, out["slice"])
 }
@@ -69,7 +69,7 @@ func TestIn(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, true, out["in1"])
 	assert.Equal(t, true, out["in2"])
@@ -94,7 +94,7 @@ func TestSize(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 3, out["size1"])
 	assert.Equal(t, 6, out["size2"])
@@ -118,7 +118,7 @@ func TestConcatArrays(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, []interface{}{1, 2, 3, 1, 2, 3, 4, 5, 6}, out["concat"])
 }
@@ -142,7 +142,7 @@ func TestObjectToArray(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	expected := []interface{}{
 		bson.M{"k": "_id", "v": bson.ObjectIdHex("58ed0817344c64f7fca5847b")},
@@ -152,3 +152,110 @@ func TestObjectToArray(t *testing.T) {
 
 	tests.AssertEqualSet(t, []interface{}{expected}, []interface{}{out["array"]})
 }
+
+func TestRange(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"array": []interface{}{"a", "b", "c"},
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id":   0,
+		"range": Range(0, Size(Field("array"))),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, []interface{}{0, 1, 2}, out["range"])
+}
+
+func TestArrayElemAt(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"array": []interface{}{"a", "b", "c"},
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id":  0,
+		"elem": ArrayElemAt(Field("array"), 1),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, "b", out["elem"])
+}
+
+func TestFilter(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"array": []interface{}{1, 2, 3, 4, 5},
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id":      0,
+		"filtered": Filter(Field("array"), "i", Gt(Var("i"), 3)),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, []interface{}{4, 5}, out["filtered"])
+}
+
+func TestArrayToObject(t *testing.T) {
+	tests.SkipTIfNotSupportAggregationAlgorithm(t)
+
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"key1": "value1",
+		"key2": 100,
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id":    0,
+		"object": ArrayToObject(Filter(ObjectToArray(Var("ROOT")), "i", Ne(Var("i.k"), "_id"))),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, bson.M{"key1": "value1", "key2": 100}, out["object"])
+}
+
+func TestReduce(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"arr": []int{1, 2, 3},
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id": 0,
+		"sum": Reduce(Field("arr"), 0, Add(Var("value"), Var("this"))),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, 6, out["sum"])
+}
@@ -26,6 +26,11 @@ func Ne(a interface{}, b interface{}) bson.M {
 	return bson.M{"$ne": []interface{}{a, b}}
 }
 
+// Literal encapsulates MongoDB operation $literal.
+func Literal(value interface{}) bson.M {
+	return bson.M{"$literal": value}
+}
+
 // Sw encapsulates MongoDB operation $switch.
 type sw struct {
 	branches []bson.M
@@ -1,6 +1,7 @@
 package expr
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/tests"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/mgo.v2/bson"
@@ -26,7 +27,7 @@ func TestField(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, "data1", out["result1"])
 	assert.Equal(t, "data2", out["result2"])
@@ -47,7 +48,7 @@ func TestVar(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, "data", out["result"])
 }
@@ -70,7 +71,7 @@ func TestEq(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, true, out["A1SameA2"])
 	assert.Equal(t, false, out["A1SameB"])
@@ -94,7 +95,7 @@ func TestNe(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, false, out["A1NotSameA2"])
 	assert.Equal(t, true, out["A1NotSameB"])
@@ -141,7 +142,7 @@ func TestSwitch(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, "=a", out["a"])
 	assert.Equal(t, "=b", out["b"])
@@ -172,7 +173,7 @@ func TestLet(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, true, out["a"])
 	assert.Equal(t, true, out["b"])
@@ -204,7 +205,7 @@ func TestCond(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 1, out["trueInt"])
 	assert.Equal(t, 0, out["falseInt"])
@@ -231,7 +232,7 @@ func TestType(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, "string", out["string"])
 	assert.Equal(t, "int", out["int"])
@@ -276,7 +277,11 @@ func TestFacet(t *testing.T) {
 	})
 
 	out := []bson.M{}
-	p.GetPipe(c).All(&out)
+	ch := make(chan bson.M)
+	p.ToBsonChannel(context.Background(), tests.Collection(c), ch, 1, 0, 10)
+	for m := range ch {
+		out = append(out, m)
+	}
 
 	assert.Equal(t, []bson.M{{"sum": 3}, {"sub": -1}}, out)
 }
@@ -336,7 +341,7 @@ func TestMergeObjects(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	expected := bson.M{
 		"f1": "1",
@@ -347,3 +352,25 @@ func TestMergeObjects(t *testing.T) {
 
 	assert.Equal(t, expected, out["m"])
 }
+
+func TestLiteral(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"str": "abc",
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id":     0,
+		"literal": Literal("$str"),
+		"field":   Field("str"),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, "$str", out["literal"])
+	assert.Equal(t, "abc", out["field"])
+}
@@ -26,7 +26,7 @@ func TestYear(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 2006, out["year"])
 }
@@ -47,7 +47,7 @@ func TestYear_ObjectId(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 2006, out["year"])
 }
@@ -69,7 +69,7 @@ func TestMonth(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 5, out["month"])
 }
@@ -90,7 +90,7 @@ func TestMonth_ObjectId(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 5, out["month"])
 }
@@ -126,7 +126,7 @@ func TestDayOfWeek(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 0, out["sun"])
 	assert.Equal(t, 1, out["mon"])
@@ -164,7 +164,7 @@ func TestDayOfWeekLocation(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 0, out["sun"])
 	assert.Equal(t, 0, out["sun2"])
@@ -202,7 +202,7 @@ func TestDayOfWeek_ObjectId(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 0, out["sun"])
 	assert.Equal(t, 1, out["mon"])
@@ -237,7 +237,7 @@ func TestDayOfYear(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 1, out["1a"])
 	assert.Equal(t, 1, out["1b"])
@@ -268,7 +268,7 @@ func TestDayOfYear_ObjectId(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 1, out["1a"])
 	assert.Equal(t, 1, out["1b"])
@@ -299,7 +299,7 @@ func TestHour(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 0, out["00"])
 	assert.Equal(t, 19, out["19"])
@@ -339,7 +339,7 @@ func TestHourLocation(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 19, out["19"])
 	assert.Equal(t, 14, out["14"])
@@ -372,7 +372,7 @@ func TestHour_ObjectId(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 0, out["00"])
 	assert.Equal(t, 19, out["19"])
@@ -399,7 +399,7 @@ func TestMinute(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 12, out["12"])
 	assert.Equal(t, 48, out["48"])
@@ -424,7 +424,7 @@ func TestMinute_ObjectId(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 12, out["12"])
 	assert.Equal(t, 48, out["48"])
@@ -449,7 +449,7 @@ func TestSecond(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 17, out["17"])
 	assert.Equal(t, 51, out["51"])
@@ -474,7 +474,7 @@ func TestSecond_ObjectId(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 17, out["17"])
 	assert.Equal(t, 51, out["51"])
@@ -504,7 +504,7 @@ func TestMillisecond(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 123, out["123"])
 	assert.Equal(t, 456, out["456"])
@@ -536,7 +536,7 @@ func TestMillisecond_ObjectId(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 123, out["123"])
 	assert.Equal(t, 456, out["456"])
@@ -545,7 +545,7 @@ func TestMillisecond_ObjectId(t *testing.T) {
 func TestDateToTimestamp(t *testing.T) {
 	tests.SkipTIfNotSupportAggregationAlgorithm(t)
 
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	c.Insert(bson.M{
@@ -559,8 +559,7 @@ func TestDateToTimestamp(t *testing.T) {
 	})
 
 	result := bson.M{}
-	pipe := p.GetPipe(c)
-	err := pipe.One(&result)
+	err := p.One(tests.Collection(c), &result)
 	if err != nil {
 		panic(err)
 	}
@@ -571,7 +570,7 @@ func TestDateToTimestamp(t *testing.T) {
 func TestTimestampToDate(t *testing.T) {
 	tests.SkipTIfNotSupportAggregationAlgorithm(t)
 
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	c.Insert(bson.M{
@@ -585,8 +584,7 @@ func TestTimestampToDate(t *testing.T) {
 	})
 
 	result := bson.M{}
-	pipe := p.GetPipe(c)
-	err := pipe.One(&result)
+	err := p.One(tests.Collection(c), &result)
 	if err != nil {
 		panic(err)
 	}
@@ -619,7 +617,7 @@ func TestObjectIdToDate(t *testing.T) {
 	})
 
 	out := bson.M{}
-	err := p.GetPipe(c).One(&out)
+	err := p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, nil, err)
 	assert.Equal(t, date1.Unix(), helpers.SafeToDate(out["date1"]).Unix())
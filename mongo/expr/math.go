@@ -81,6 +81,11 @@ func And(items ...interface{}) bson.M {
 	return bson.M{"$and": items}
 }
 
+// Not encapsulates MongoDB operation $not.
+func Not(item interface{}) bson.M {
+	return bson.M{"$not": []interface{}{item}}
+}
+
 // Mod encapsulates MongoDB operation $mod.
 func Mod(a interface{}, b interface{}) bson.M {
 	return bson.M{"$mod": []interface{}{a, b}}
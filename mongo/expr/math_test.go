@@ -24,7 +24,7 @@ func TestAdd(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 133.45, out["sum"])
 }
@@ -45,7 +45,7 @@ func TestSubtract(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, -113.45, out["diff"])
 }
@@ -68,7 +68,7 @@ func TestSubtract_Decimal(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, helpers.ParseDecimal("667.00000000000054"), out["diff"])
 }
@@ -91,7 +91,7 @@ func TestMultiply(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 1234.5, out["result"])
 }
@@ -114,7 +114,7 @@ func TestDivide(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 12.345, out["result"])
 }
@@ -137,7 +137,7 @@ func TestDivideInt(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 12.0, out["result"])
 }
@@ -159,7 +159,7 @@ func TestLog10(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 3.0, out["result"])
 }
@@ -182,7 +182,7 @@ func TestPow(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 13824, out["result"])
 }
@@ -204,7 +204,7 @@ func TestPow10(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 100000, out["result"])
 }
@@ -231,7 +231,7 @@ func TestLt(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, true, out["a<b"])
 	assert.Equal(t, false, out["b<a"])
@@ -261,7 +261,7 @@ func TestLte(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, true, out["a<=b"])
 	assert.Equal(t, false, out["b<=a"])
@@ -291,7 +291,7 @@ func TestGt(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, false, out["a>b"])
 	assert.Equal(t, true, out["b>a"])
@@ -321,7 +321,7 @@ func TestGte(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, false, out["a>=b"])
 	assert.Equal(t, true, out["b>=a"])
@@ -350,13 +350,36 @@ func TestOr(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, true, out["a||b||c"])
 	assert.Equal(t, true, out["a||c"])
 	assert.Equal(t, false, out["b||c"])
 }
 
+func TestNot(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"a": true,
+		"b": false,
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id": 0,
+		"!a":  Not(Field("a")),
+		"!b":  Not(Field("b")),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, false, out["!a"])
+	assert.Equal(t, true, out["!b"])
+}
+
 func TestAnd(t *testing.T) {
 	tests.SkipTIfNotSupportAggregationAlgorithm(t)
 
@@ -378,7 +401,7 @@ func TestAnd(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, false, out["a&&b&&c"])
 	assert.Equal(t, true, out["a&&b"])
@@ -403,7 +426,7 @@ func TestMod(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 3, out["result"])
 }
@@ -427,7 +450,7 @@ func TestFloor(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 99.0, out["a"])
 	assert.Equal(t, 70, out["b"])
@@ -454,7 +477,7 @@ func TestFloorWithStep(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 99.75, out["a"])
 	assert.Equal(t, 71.85, out["b"])
@@ -479,7 +502,7 @@ func TestCeil(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 100.0, out["a"])
 	assert.Equal(t, 70, out["b"])
@@ -506,7 +529,7 @@ func TestCeilWithStep(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 100.2, out["a"])
 	assert.Equal(t, 71.5, out["b"])
@@ -547,7 +570,7 @@ func TestCeilIn60System(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 1, out["05"])
 	assert.Equal(t, 1, out["10"])
@@ -604,7 +627,7 @@ func TestCeilIn24System(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, 1, out["05"])
 	assert.Equal(t, 1, out["10"])
@@ -699,7 +722,7 @@ func TestCeilDateInSeconds(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	// Seconds
 	assert.Equal(t, 1, out["01"])
@@ -1,14 +1,21 @@
 package expr
 
 import (
-	"gopkg.in/mgo.v2"
+	"context"
+	"fmt"
+	"github.com/mongoeye/mongoeye/mongo/driver"
 	"gopkg.in/mgo.v2/bson"
 	"sync"
+	"time"
 )
 
 // Pipeline represents stages of aggregation pipeline in MongoDB.
 type Pipeline struct {
-	stages []bson.M
+	stages       []bson.M
+	maxTime      time.Duration
+	errorHandler func(err error)
+	errMutex     sync.Mutex
+	err          error // the first error, if no handler is set
 }
 
 // NewPipeline creates new Pipeline.
@@ -39,28 +46,65 @@ func (p *Pipeline) GetStages() []bson.M {
 	return p.stages
 }
 
-// GetPipe gets MongoDB aggregation pipe.
-func (p *Pipeline) GetPipe(c *mgo.Collection) *mgo.Pipe {
-	pipe := c.Pipe(p.GetStages())
-	pipe.AllowDiskUse()
-	return pipe
+// SetMaxTime sets server-side time limit for the aggregation (maxTimeMS).
+// Zero value means no limit.
+func (p *Pipeline) SetMaxTime(d time.Duration) {
+	p.maxTime = d
+}
+
+// GetMaxTime gets server-side time limit for the aggregation.
+func (p *Pipeline) GetMaxTime() time.Duration {
+	return p.maxTime
+}
+
+// SetErrorHandler sets function that handles errors of cursor, eg. a cursor timeout or lost authentication.
+// If no handler is set, then the first error is kept and returned by Err method.
+func (p *Pipeline) SetErrorHandler(fn func(err error)) {
+	p.errorHandler = fn
+}
+
+// Err returns the first error of cursor, if no error handler is set.
+// It must be called after the output channel has been read.
+func (p *Pipeline) Err() error {
+	p.errMutex.Lock()
+	defer p.errMutex.Unlock()
+	return p.err
 }
 
 // Iter - gets iterator over Pipeline results.
-func (p *Pipeline) Iter(c *mgo.Collection, batchSize int) *mgo.Iter {
+// The iterator stops when the context is done.
+func (p *Pipeline) Iter(ctx context.Context, c driver.Collection, batchSize int) driver.Cursor {
 	if batchSize < 1 {
 		panic("Value of 'batchSize' argument must be at least 1.")
 	}
 
-	pipe := p.GetPipe(c)
-	if batchSize > 0 {
-		pipe.Batch(batchSize)
+	return c.Aggregate(ctx, p.GetStages(), driver.AggregateOptions{
+		BatchSize:    batchSize,
+		MaxTime:      p.maxTime,
+		AllowDiskUse: true,
+	})
+}
+
+// One - unmarshals the first result of Pipeline, driver.ErrNotFound is returned if there is none.
+func (p *Pipeline) One(c driver.Collection, result interface{}) error {
+	iterator := p.Iter(context.Background(), c, 1)
+	raw, ok := iterator.Next()
+
+	err := iterator.Close()
+	if err != nil {
+		return err
 	}
-	return pipe.Iter()
+
+	if !ok {
+		return driver.ErrNotFound
+	}
+
+	return bson.Unmarshal(raw, result)
 }
 
 // ToRawChannel - gets pipeline results as raw ([]byte) channel.
-func (p *Pipeline) ToRawChannel(c *mgo.Collection, outCh chan<- []byte, concurrency int, bufferSize int, batchSize int) {
+// When the context is done, reading stops, the iterator is closed and the channel is closed.
+func (p *Pipeline) ToRawChannel(ctx context.Context, c driver.Collection, outCh chan<- []byte, concurrency int, bufferSize int, batchSize int) {
 	if concurrency < 1 {
 		panic("Value of 'concurrency' argument must be at least 1.")
 	}
@@ -69,7 +113,12 @@ func (p *Pipeline) ToRawChannel(c *mgo.Collection, outCh chan<- []byte, concurre
 		panic("Value of 'bufferSize' argument must be at least 0.")
 	}
 
-	iterator := p.Iter(c, batchSize)
+	if ctx.Err() != nil {
+		close(outCh)
+		return
+	}
+
+	iterator := p.Iter(ctx, c, batchSize)
 
 	wg := sync.WaitGroup{}
 	wg.Add(concurrency)
@@ -78,17 +127,19 @@ func (p *Pipeline) ToRawChannel(c *mgo.Collection, outCh chan<- []byte, concurre
 		go func() {
 			defer wg.Done()
 
-			raw := new(bson.Raw)
-
-			for {
-				if iterator.Next(raw) {
-					outCh <- raw.Data
-				} else {
+			for ctx.Err() == nil {
+				raw, ok := iterator.Next()
+				if !ok {
 					break
 				}
+
+				select {
+				case outCh <- raw:
+				case <-ctx.Done():
+				}
 			}
 
-			closeIterator(iterator)
+			p.closeIterator(ctx, iterator)
 		}()
 	}
 
@@ -100,7 +151,8 @@ func (p *Pipeline) ToRawChannel(c *mgo.Collection, outCh chan<- []byte, concurre
 }
 
 // ToBsonChannel - gets pipeline results as BSON channel.
-func (p *Pipeline) ToBsonChannel(c *mgo.Collection, outCh chan<- bson.M, concurrency int, bufferSize int, batchSize int) {
+// When the context is done, reading stops, the iterator is closed and the channel is closed.
+func (p *Pipeline) ToBsonChannel(ctx context.Context, c driver.Collection, outCh chan<- bson.M, concurrency int, bufferSize int, batchSize int) {
 	if concurrency < 1 {
 		panic("Value of 'concurrency' argument must be at least 1.")
 	}
@@ -109,7 +161,12 @@ func (p *Pipeline) ToBsonChannel(c *mgo.Collection, outCh chan<- bson.M, concurr
 		panic("Value of 'bufferSize' argument must be at least 0.")
 	}
 
-	iterator := p.Iter(c, batchSize)
+	if ctx.Err() != nil {
+		close(outCh)
+		return
+	}
+
+	iterator := p.Iter(ctx, c, batchSize)
 
 	wg := sync.WaitGroup{}
 	wg.Add(concurrency)
@@ -118,16 +175,25 @@ func (p *Pipeline) ToBsonChannel(c *mgo.Collection, outCh chan<- bson.M, concurr
 		go func() {
 			defer wg.Done()
 
-			for {
+			for ctx.Err() == nil {
+				raw, ok := iterator.Next()
+				if !ok {
+					break
+				}
+
 				m := make(bson.M)
-				if iterator.Next(m) {
-					outCh <- m
-				} else {
+				if err := bson.Unmarshal(raw, m); err != nil {
+					p.handleError(ctx, err)
 					break
 				}
+
+				select {
+				case outCh <- m:
+				case <-ctx.Done():
+				}
 			}
 
-			closeIterator(iterator)
+			p.closeIterator(ctx, iterator)
 		}()
 	}
 
@@ -139,8 +205,35 @@ func (p *Pipeline) ToBsonChannel(c *mgo.Collection, outCh chan<- bson.M, concurr
 }
 
 // Properly close iterator
-func closeIterator(iterator *mgo.Iter) {
-	if err := iterator.Close(); err != nil {
-		panic(err)
+func (p *Pipeline) closeIterator(ctx context.Context, iterator driver.Cursor) {
+	err := iterator.Close()
+	if err == nil {
+		return
+	}
+
+	p.handleError(ctx, err)
+}
+
+// Errors after the context is done are expected, the context error is returned by the caller.
+func (p *Pipeline) handleError(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Server-side time limit is derived from the deadline of the context,
+	// but the server can stop the cursor before the context is done.
+	if driver.IsTimeLimitError(err) {
+		err = fmt.Errorf("Time limit exceeded on the server: %w", err)
+	}
+
+	if p.errorHandler != nil {
+		p.errorHandler(err)
+		return
+	}
+
+	p.errMutex.Lock()
+	if p.err == nil {
+		p.err = err
 	}
+	p.errMutex.Unlock()
 }
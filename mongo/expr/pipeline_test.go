@@ -1,11 +1,14 @@
 package expr
 
 import (
+	"context"
+	"errors"
+	"github.com/mongoeye/mongoeye/mongo/driver"
 	"github.com/mongoeye/mongoeye/tests"
 	"github.com/stretchr/testify/assert"
-	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"testing"
+	"time"
 )
 
 func TestNewPipeline(t *testing.T) {
@@ -60,61 +63,68 @@ func TestPipeline_AddStages(t *testing.T) {
 	})
 }
 
-func TestPipeline_GetPipe(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+func TestPipeline_One(t *testing.T) {
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	p := NewPipeline()
 
-	pipe := p.GetPipe(c)
-	assert.IsType(t, &mgo.Pipe{}, pipe)
+	out := bson.M{}
+	err := p.One(tests.Collection(c), &out)
+	assert.Equal(t, driver.ErrNotFound, err)
+
+	c.Insert(bson.M{"_id": 1})
+	err = p.One(tests.Collection(c), &out)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, bson.M{"_id": 1}, out)
 }
 
 func TestPipeline_Iter(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
-	bulk := c.Bulk()
+	docs := []interface{}{}
 	for i := 1; i < 50; i++ {
-		bulk.Insert(bson.M{
+		docs = append(docs, bson.M{
 			"_id": i,
 			"i":   i * 10,
 		})
 	}
-	bulk.Run()
+	c.Insert(docs...)
 
 	p := NewPipeline()
 	p.AddStage("project", bson.M{
 		"i": "$i",
 	})
 
-	iter := p.Iter(c, 2)
-	assert.IsType(t, &mgo.Iter{}, iter)
+	iter := p.Iter(context.Background(), tests.Collection(c), 2)
 
-	out := bson.M{}
-	ok := iter.Next(&out)
+	raw, ok := iter.Next()
 	assert.Equal(t, true, ok)
 
 	if !ok {
-		panic(iter.Err())
+		panic(iter.Close())
 	}
 
+	out := bson.M{}
+	bson.Unmarshal(raw, &out)
 	assert.Equal(t, bson.M{"_id": 1, "i": 10}, out)
+	assert.Equal(t, nil, iter.Close())
 }
 
 func TestPipeline_Iter_InvalidBatchSize(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	p := NewPipeline()
 
 	assert.Panics(t, func() {
-		p.Iter(c, -1)
+		p.Iter(context.Background(), tests.Collection(c), -1)
 	})
 }
 
 func TestPipeline_ToRawChannel(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	for i := 0; i < 50; i++ {
@@ -131,7 +141,7 @@ func TestPipeline_ToRawChannel(t *testing.T) {
 	})
 
 	ch := make(chan []byte)
-	p.ToRawChannel(c, ch, 1, 10, 10)
+	p.ToRawChannel(context.Background(), tests.Collection(c), ch, 1, 10, 10)
 
 	i := 0
 	for r := range ch {
@@ -140,8 +150,83 @@ func TestPipeline_ToRawChannel(t *testing.T) {
 	}
 }
 
+func TestPipeline_ToRawChannel_Canceled(t *testing.T) {
+	c := tests.CreateTestCollection(tests.TestDbDriver)
+	defer tests.DropTestCollection(c)
+
+	for i := 0; i < 50; i++ {
+		c.Insert(bson.M{
+			"i": i,
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := NewPipeline()
+	ch := make(chan []byte)
+	p.ToRawChannel(ctx, tests.Collection(c), ch, 2, 0, 5)
+
+	// Read one document and cancel, channel must be closed
+	<-ch
+	cancel()
+
+	count := 0
+	for range ch {
+		count++
+	}
+	assert.True(t, count < 49)
+}
+
+func TestPipeline_ToRawChannel_MaxTime(t *testing.T) {
+	c := tests.CreateTestCollection(tests.TestDbDriver)
+	defer tests.DropTestCollection(c)
+
+	for i := 0; i < 50; i++ {
+		c.Insert(bson.M{
+			"i": i,
+		})
+	}
+
+	p := NewPipeline()
+	p.AddStage("sort", bson.M{"i": 1})
+	p.SetMaxTime(10 * time.Second)
+	assert.Equal(t, 10*time.Second, p.GetMaxTime())
+
+	ch := make(chan []byte)
+	p.ToRawChannel(context.Background(), tests.Collection(c), ch, 1, 10, 10)
+
+	count := 0
+	for range ch {
+		count++
+	}
+	assert.Equal(t, 50, count)
+}
+
+func TestPipeline_handleError_TimeLimit(t *testing.T) {
+	var reported error
+	p := NewPipeline()
+	p.SetErrorHandler(func(err error) {
+		reported = err
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	// Server stopped the cursor before the deadline, the error is reported right away
+	p.handleError(ctx, errors.New("operation exceeded time limit"))
+	if assert.NotNil(t, reported) {
+		assert.Equal(t, "Time limit exceeded on the server: operation exceeded time limit", reported.Error())
+	}
+
+	// Context is done, the caller returns the context error
+	reported = nil
+	cancel()
+	p.handleError(ctx, errors.New("operation exceeded time limit"))
+	assert.Nil(t, reported)
+}
+
 func TestPipeline_ToRawChannel_InvalidConcurrencyParam(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	p := NewPipeline()
@@ -149,12 +234,12 @@ func TestPipeline_ToRawChannel_InvalidConcurrencyParam(t *testing.T) {
 	ch := make(chan []byte)
 
 	assert.Panics(t, func() {
-		p.ToRawChannel(c, ch, 0, 1, 1)
+		p.ToRawChannel(context.Background(), tests.Collection(c), ch, 0, 1, 1)
 	})
 }
 
 func TestPipeline_ToRawChannel_InvalidBufferParam(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	p := NewPipeline()
@@ -162,12 +247,12 @@ func TestPipeline_ToRawChannel_InvalidBufferParam(t *testing.T) {
 	ch := make(chan []byte)
 
 	assert.Panics(t, func() {
-		p.ToRawChannel(c, ch, 1, -1, 1)
+		p.ToRawChannel(context.Background(), tests.Collection(c), ch, 1, -1, 1)
 	})
 }
 
 func TestPipeline_ToRawChannel_InvalidBatchSizeParam(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	p := NewPipeline()
@@ -175,12 +260,12 @@ func TestPipeline_ToRawChannel_InvalidBatchSizeParam(t *testing.T) {
 	ch := make(chan []byte)
 
 	assert.Panics(t, func() {
-		p.ToRawChannel(c, ch, 1, 1, -1)
+		p.ToRawChannel(context.Background(), tests.Collection(c), ch, 1, 1, -1)
 	})
 }
 
 func TestPipeline_ToBsonChannel(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	for i := 0; i < 50; i++ {
@@ -197,7 +282,7 @@ func TestPipeline_ToBsonChannel(t *testing.T) {
 	})
 
 	ch := make(chan bson.M)
-	p.ToBsonChannel(c, ch, 1, 10, 10)
+	p.ToBsonChannel(context.Background(), tests.Collection(c), ch, 1, 10, 10)
 
 	i := 0
 	for r := range ch {
@@ -207,7 +292,7 @@ func TestPipeline_ToBsonChannel(t *testing.T) {
 }
 
 func TestPipeline_ToBsonChannel_InvalidConcurrencyParam(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	p := NewPipeline()
@@ -215,12 +300,12 @@ func TestPipeline_ToBsonChannel_InvalidConcurrencyParam(t *testing.T) {
 	ch := make(chan bson.M)
 
 	assert.Panics(t, func() {
-		p.ToBsonChannel(c, ch, 0, 1, 1)
+		p.ToBsonChannel(context.Background(), tests.Collection(c), ch, 0, 1, 1)
 	})
 }
 
 func TestPipeline_ToBsonChannel_InvalidBufferParam(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	p := NewPipeline()
@@ -228,12 +313,12 @@ func TestPipeline_ToBsonChannel_InvalidBufferParam(t *testing.T) {
 	ch := make(chan bson.M)
 
 	assert.Panics(t, func() {
-		p.ToBsonChannel(c, ch, 1, -1, 1)
+		p.ToBsonChannel(context.Background(), tests.Collection(c), ch, 1, -1, 1)
 	})
 }
 
 func TestPipeline_ToBsonChannel_InvalidBatchSizeParam(t *testing.T) {
-	c := tests.CreateTestCollection(tests.TestDbSession)
+	c := tests.CreateTestCollection(tests.TestDbDriver)
 	defer tests.DropTestCollection(c)
 
 	p := NewPipeline()
@@ -241,6 +326,6 @@ func TestPipeline_ToBsonChannel_InvalidBatchSizeParam(t *testing.T) {
 	ch := make(chan bson.M)
 
 	assert.Panics(t, func() {
-		p.ToBsonChannel(c, ch, 1, 1, -1)
+		p.ToBsonChannel(context.Background(), tests.Collection(c), ch, 1, 1, -1)
 	})
 }
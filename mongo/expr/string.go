@@ -6,3 +6,32 @@ import "gopkg.in/mgo.v2/bson"
 func Concat(parts ...interface{}) bson.M {
 	return bson.M{"$concat": parts}
 }
+
+// RegexMatch encapsulates MongoDB operation $regexMatch (MongoDB 4.2+).
+func RegexMatch(input interface{}, regex string) bson.M {
+	return bson.M{"$regexMatch": bson.M{"input": input, "regex": regex}}
+}
+
+// SubstrCP encapsulates MongoDB operation $substrCP.
+func SubstrCP(str interface{}, start interface{}, length interface{}) bson.M {
+	return bson.M{"$substrCP": []interface{}{str, start, length}}
+}
+
+// IndexOfBytes encapsulates MongoDB operation $indexOfBytes.
+func IndexOfBytes(str interface{}, substr interface{}) bson.M {
+	return bson.M{"$indexOfBytes": []interface{}{str, substr}}
+}
+
+// Split encapsulates MongoDB operation $split.
+func Split(str interface{}, delimiter interface{}) bson.M {
+	return bson.M{"$split": []interface{}{str, delimiter}}
+}
+
+// Replace returns MongoDB operation that replaces all occurrences of old substring by new, $replaceAll is emulated by $split and $reduce.
+func Replace(str interface{}, old string, new string) bson.M {
+	return Reduce(
+		Split(str, old),
+		nil,
+		Cond(Eq(Var("value"), nil), Var("this"), Concat(Var("value"), new, Var("this"))),
+	)
+}
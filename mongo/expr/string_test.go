@@ -23,7 +23,115 @@ func TestConcat(t *testing.T) {
 	})
 
 	out := bson.M{}
-	p.GetPipe(c).One(&out)
+	p.One(tests.Collection(c), &out)
 
 	assert.Equal(t, "ABCdef", out["concat"])
 }
+
+func TestRegexMatch(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"str1": "123",
+		"str2": "12a",
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id":    0,
+		"match1": RegexMatch(Field("str1"), `^[0-9]+\z`),
+		"match2": RegexMatch(Field("str2"), `^[0-9]+\z`),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, true, out["match1"])
+	assert.Equal(t, false, out["match2"])
+}
+
+func TestSubstrCP(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"str": "čaj.x",
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id":    0,
+		"substr": SubstrCP(Field("str"), 0, 4),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, "čaj.", out["substr"])
+}
+
+func TestIndexOfBytes(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"str": "abc.d",
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id":     0,
+		"found":   IndexOfBytes(Field("str"), "."),
+		"missing": IndexOfBytes(Field("str"), "x"),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, 3, out["found"])
+	assert.Equal(t, -1, out["missing"])
+}
+
+func TestSplit(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"str": "a.b.c",
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id":   0,
+		"split": Split(Field("str"), "."),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, []interface{}{"a", "b", "c"}, out["split"])
+}
+
+func TestReplace(t *testing.T) {
+	c := tests.SetupTestCol()
+	defer tests.TearDownTestCol(c)
+
+	c.Insert(bson.M{
+		"str1": `a"b"c`,
+		"str2": "abc",
+	})
+
+	p := NewPipeline()
+	p.AddStage("project", bson.M{
+		"_id":  0,
+		"str1": Replace(Field("str1"), `"`, `\"`),
+		"str2": Replace(Field("str2"), `"`, `\"`),
+	})
+
+	out := bson.M{}
+	p.One(tests.Collection(c), &out)
+
+	assert.Equal(t, `a\"b\"c`, out["str1"])
+	assert.Equal(t, "abc", out["str2"])
+}
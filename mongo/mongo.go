@@ -2,22 +2,23 @@
 package mongo
 
 import (
-	"gopkg.in/mgo.v2"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"github.com/mongoeye/mongoeye/mongo/driver/official"
 	"time"
 )
 
 // Connect to MongoDB host with given connection mode
-func Connect(host string, mode mgo.Mode) (*mgo.Session, error) {
-	session, err := mgo.DialWithTimeout(host, 5*time.Second)
-	if err == nil {
-		session.SetMode(mode, true)
-		session.SetSyncTimeout(5 * time.Minute)
-		session.SetSocketTimeout(5 * time.Minute)
-	}
-	return session, err
+func Connect(host string, mode driver.Mode) (driver.Session, error) {
+	return official.Dial(&driver.DialInfo{
+		URI:           host,
+		Mode:          mode,
+		Timeout:       5 * time.Second,
+		SyncTimeout:   5 * time.Minute,
+		SocketTimeout: 5 * time.Minute,
+	})
 }
 
 // Collection returns a value representing the named collection.
-func Collection(session *mgo.Session, db string, collection string) *mgo.Collection {
-	return session.DB(db).C(collection)
+func Collection(session driver.Session, db string, collection string) driver.Collection {
+	return session.Collection(db, collection)
 }
@@ -1,23 +1,24 @@
 package mongo
 
 import (
+	"github.com/mongoeye/mongoeye/mongo/driver"
 	"github.com/mongoeye/mongoeye/tests"
 	"github.com/stretchr/testify/assert"
-	"gopkg.in/mgo.v2"
 	"testing"
 )
 
 func TestConnect(t *testing.T) {
-	session, err := Connect(tests.TestDbUri, mgo.Secondary)
+	session, err := Connect(tests.TestDbUri, driver.Secondary)
 	if err != nil {
 		t.Error(err)
 	}
+	defer session.Close()
 
-	assert.IsType(t, &mgo.Session{}, session)
+	assert.Implements(t, (*driver.Session)(nil), session)
 }
 
 func TestCollection(t *testing.T) {
-	c := Collection(tests.TestDbSession, "_test", "_test")
-	assert.IsType(t, &mgo.Collection{}, c)
-	c.DropCollection()
+	c := Collection(tests.TestDbDriver, "_test", "_test")
+	assert.Equal(t, "_test", c.Database())
+	assert.Equal(t, "_test", c.Name())
 }
@@ -0,0 +1,283 @@
+// Package query evaluates MongoDB query filters and projections locally, without the database.
+// It is used when documents do not come from MongoDB (eg. BSON files).
+package query
+
+import (
+	"github.com/mongoeye/mongoeye/helpers"
+	"gopkg.in/mgo.v2/bson"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Order of types when comparing values of different BSON types.
+// https://docs.mongodb.com/manual/reference/bson-type-comparison-order/
+const (
+	orderMinKey = iota
+	orderNull
+	orderNumber
+	orderString
+	orderObject
+	orderArray
+	orderBinData
+	orderObjectId
+	orderBool
+	orderDate
+	orderTimestamp
+	orderRegex
+	orderOther
+	orderMaxKey
+)
+
+// TypeName returns name of BSON type of the value (the same names as in $type operator).
+func TypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case float64, float32:
+		return "double"
+	case string:
+		return "string"
+	case bson.M, map[string]interface{}, bson.D:
+		return "object"
+	case []interface{}:
+		return "array"
+	case []byte, bson.Binary:
+		return "binData"
+	case bson.ObjectId:
+		return "objectId"
+	case bool:
+		return "bool"
+	case time.Time:
+		return "date"
+	case bson.RegEx:
+		return "regex"
+	case bson.DBPointer:
+		return "dbPointer"
+	case bson.JavaScript:
+		return "javascript"
+	case bson.Symbol:
+		return "symbol"
+	case int, int32:
+		return "int"
+	case bson.MongoTimestamp:
+		return "timestamp"
+	case int64:
+		return "long"
+	case bson.Decimal128:
+		return "decimal"
+	}
+
+	switch value {
+	case bson.Undefined:
+		return "undefined"
+	case bson.MinKey:
+		return "minKey"
+	case bson.MaxKey:
+		return "maxKey"
+	}
+
+	return "unknown"
+}
+
+func typeOrder(value interface{}) int {
+	switch TypeName(value) {
+	case "minKey":
+		return orderMinKey
+	case "null", "undefined":
+		return orderNull
+	case "double", "int", "long", "decimal":
+		return orderNumber
+	case "string", "symbol":
+		return orderString
+	case "object":
+		return orderObject
+	case "array":
+		return orderArray
+	case "binData":
+		return orderBinData
+	case "objectId":
+		return orderObjectId
+	case "bool":
+		return orderBool
+	case "date":
+		return orderDate
+	case "timestamp":
+		return orderTimestamp
+	case "regex":
+		return orderRegex
+	case "maxKey":
+		return orderMaxKey
+	}
+
+	return orderOther
+}
+
+// Compare two values according to BSON comparison order.
+// Returns -1 if a < b, 0 if a == b and +1 if a > b.
+func Compare(a interface{}, b interface{}) int {
+	orderA := typeOrder(a)
+	orderB := typeOrder(b)
+	if orderA != orderB {
+		return cmpInt(orderA, orderB)
+	}
+
+	switch orderA {
+	case orderNumber:
+		return cmpFloat(toFloat(a), toFloat(b))
+	case orderString:
+		return strings.Compare(toString(a), toString(b))
+	case orderObject:
+		return compareObjects(toMap(a), toMap(b))
+	case orderArray:
+		return compareArrays(a.([]interface{}), b.([]interface{}))
+	case orderBinData:
+		return strings.Compare(string(toBytes(a)), string(toBytes(b)))
+	case orderObjectId:
+		return strings.Compare(string(a.(bson.ObjectId)), string(b.(bson.ObjectId)))
+	case orderBool:
+		return cmpBool(a.(bool), b.(bool))
+	case orderDate:
+		ta, tb := a.(time.Time), b.(time.Time)
+		if ta.Before(tb) {
+			return -1
+		} else if ta.After(tb) {
+			return 1
+		}
+		return 0
+	case orderTimestamp:
+		return cmpInt64(int64(a.(bson.MongoTimestamp)), int64(b.(bson.MongoTimestamp)))
+	case orderOther, orderRegex:
+		return strings.Compare(helpers.MarshalToJSON(a), helpers.MarshalToJSON(b))
+	}
+
+	// minKey, maxKey, null
+	return 0
+}
+
+// Equal returns true if values are equal according to BSON comparison.
+func Equal(a interface{}, b interface{}) bool {
+	return Compare(a, b) == 0
+}
+
+// Values with the same type order can be compared by $gt, $lt, ... operators.
+func sameTypeOrder(a interface{}, b interface{}) bool {
+	return typeOrder(a) == typeOrder(b)
+}
+
+func compareObjects(a map[string]interface{}, b map[string]interface{}) int {
+	if len(a) != len(b) {
+		return cmpInt(len(a), len(b))
+	}
+
+	keysA := sortedKeys(a)
+	keysB := sortedKeys(b)
+	for i, key := range keysA {
+		if c := strings.Compare(key, keysB[i]); c != 0 {
+			return c
+		}
+		if c := Compare(a[key], b[key]); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+func compareArrays(a []interface{}, b []interface{}) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+
+	return cmpInt(len(a), len(b))
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float32:
+		return float64(v)
+	case bson.Decimal128:
+		f := helpers.DecimalToDouble(v)
+		if math.IsNaN(f) {
+			return math.Inf(-1)
+		}
+		return f
+	}
+
+	return helpers.ToDouble(value)
+}
+
+func toString(value interface{}) string {
+	if s, ok := value.(bson.Symbol); ok {
+		return string(s)
+	}
+	return value.(string)
+}
+
+func toBytes(value interface{}) []byte {
+	if b, ok := value.(bson.Binary); ok {
+		return b.Data
+	}
+	return value.([]byte)
+}
+
+// Converts document representations to map.
+func toMap(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		return v
+	case map[string]interface{}:
+		return v
+	case bson.D:
+		return v.Map()
+	}
+
+	return nil
+}
+
+func isMap(value interface{}) bool {
+	return toMap(value) != nil
+}
+
+func cmpInt(a, b int) int {
+	return cmpInt64(int64(a), int64(b))
+}
+
+func cmpInt64(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func cmpFloat(a, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func cmpBool(a, b bool) int {
+	if a == b {
+		return 0
+	} else if !a {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,42 @@
+package query
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	assert.Equal(t, 0, Compare(1, 1.0))
+	assert.Equal(t, 0, Compare(int64(5), 5))
+	assert.Equal(t, -1, Compare(1, int64(2)))
+	assert.Equal(t, 1, Compare("b", "a"))
+	assert.Equal(t, -1, Compare(nil, 1))
+	assert.Equal(t, -1, Compare(100, "1"))
+	assert.Equal(t, -1, Compare("x", bson.ObjectIdHex("58ed0817344c64f7fca5847a")))
+	assert.Equal(t, -1, Compare(bson.ObjectIdHex("58ed0817344c64f7fca5847a"), bson.ObjectIdHex("58ed0817344c64f7fca5847b")))
+	assert.Equal(t, -1, Compare(time.Unix(10, 0), time.Unix(20, 0)))
+	assert.Equal(t, 0, Compare(bson.M{"a": 1}, map[string]interface{}{"a": 1.0}))
+	assert.Equal(t, -1, Compare([]interface{}{1, 2}, []interface{}{1, 3}))
+	assert.Equal(t, -1, Compare(bson.MinKey, nil))
+	assert.Equal(t, 1, Compare(bson.MaxKey, time.Now()))
+	assert.Equal(t, 0, Compare(helpersDecimal("1.5"), 1.5))
+}
+
+func TestTypeName(t *testing.T) {
+	assert.Equal(t, "null", TypeName(nil))
+	assert.Equal(t, "int", TypeName(1))
+	assert.Equal(t, "long", TypeName(int64(1)))
+	assert.Equal(t, "double", TypeName(1.5))
+	assert.Equal(t, "object", TypeName(bson.M{}))
+	assert.Equal(t, "array", TypeName([]interface{}{}))
+	assert.Equal(t, "undefined", TypeName(bson.Undefined))
+	assert.Equal(t, "minKey", TypeName(bson.MinKey))
+	assert.Equal(t, "maxKey", TypeName(bson.MaxKey))
+}
+
+func helpersDecimal(s string) bson.Decimal128 {
+	d, _ := bson.ParseDecimal128(s)
+	return d
+}
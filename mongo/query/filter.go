@@ -0,0 +1,636 @@
+package query
+
+import (
+	"fmt"
+	"gopkg.in/mgo.v2/bson"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Filter returns true if the document matches the query.
+type Filter func(doc map[string]interface{}) bool
+
+// Predicate is evaluated over all values found in the document at some path.
+type predicate func(values []interface{}) bool
+
+// CompileFilter compiles query document ($match aggregation syntax) to Filter.
+// Supported are logical ($and, $or, $nor, $not), comparison ($eq, $ne, $gt, $gte, $lt, $lte, $in, $nin),
+// element ($exists, $type), evaluation ($regex, $mod) and array ($all, $elemMatch, $size) operators.
+func CompileFilter(query map[string]interface{}) (Filter, error) {
+	filters := make([]Filter, 0, len(query))
+
+	for key, value := range query {
+		switch key {
+		case "$and", "$or", "$nor":
+			list, ok := toList(value)
+			if !ok || len(list) == 0 {
+				return nil, fmt.Errorf("%s must be a nonempty array", key)
+			}
+
+			subFilters := make([]Filter, len(list))
+			for i, item := range list {
+				m := toMap(item)
+				if m == nil {
+					return nil, fmt.Errorf("%s entries must be objects", key)
+				}
+
+				f, err := CompileFilter(m)
+				if err != nil {
+					return nil, err
+				}
+				subFilters[i] = f
+			}
+
+			switch key {
+			case "$and":
+				filters = append(filters, allFilters(subFilters))
+			case "$or":
+				filters = append(filters, anyFilter(subFilters))
+			case "$nor":
+				f := anyFilter(subFilters)
+				filters = append(filters, func(doc map[string]interface{}) bool { return !f(doc) })
+			}
+		case "$comment":
+			continue
+		default:
+			if strings.HasPrefix(key, "$") {
+				return nil, fmt.Errorf("unknown top level operator: %s", key)
+			}
+
+			p, err := compileCondition(value)
+			if err != nil {
+				return nil, err
+			}
+
+			path := strings.Split(key, ".")
+			filters = append(filters, func(doc map[string]interface{}) bool {
+				return p(resolvePath(doc, path))
+			})
+		}
+	}
+
+	return allFilters(filters), nil
+}
+
+func allFilters(filters []Filter) Filter {
+	return func(doc map[string]interface{}) bool {
+		for _, f := range filters {
+			if !f(doc) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func anyFilter(filters []Filter) Filter {
+	return func(doc map[string]interface{}) bool {
+		for _, f := range filters {
+			if f(doc) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Compile condition of one field: equality, regular expression or operators.
+func compileCondition(value interface{}) (predicate, error) {
+	if m := toMap(value); m != nil {
+		isOperator, err := isOperatorObject(m)
+		if err != nil {
+			return nil, err
+		}
+
+		if isOperator {
+			return compileOperators(m)
+		}
+	}
+
+	if re, ok := value.(bson.RegEx); ok {
+		return regexPredicate(re.Pattern, re.Options)
+	}
+
+	return eqPredicate(value), nil
+}
+
+// Object is operator object if all keys starts with $.
+func isOperatorObject(m map[string]interface{}) (bool, error) {
+	operators := 0
+	for key := range m {
+		if strings.HasPrefix(key, "$") {
+			operators++
+		}
+	}
+
+	if operators != 0 && operators != len(m) {
+		return false, fmt.Errorf("cannot mix operators and fields in one object")
+	}
+
+	return operators != 0, nil
+}
+
+func compileOperators(m map[string]interface{}) (predicate, error) {
+	predicates := make([]predicate, 0, len(m))
+
+	for op, arg := range m {
+		var p predicate
+		var err error
+
+		switch op {
+		case "$eq":
+			p = eqPredicate(arg)
+		case "$ne":
+			p = notPredicate(eqPredicate(arg))
+		case "$gt":
+			p = cmpPredicate(arg, func(c int) bool { return c > 0 })
+		case "$gte":
+			p = cmpPredicate(arg, func(c int) bool { return c >= 0 })
+		case "$lt":
+			p = cmpPredicate(arg, func(c int) bool { return c < 0 })
+		case "$lte":
+			p = cmpPredicate(arg, func(c int) bool { return c <= 0 })
+		case "$in":
+			p, err = inPredicate(arg)
+		case "$nin":
+			p, err = inPredicate(arg)
+			p = notPredicate(p)
+		case "$exists":
+			want := isTruthy(arg)
+			p = func(values []interface{}) bool { return (len(values) > 0) == want }
+		case "$not":
+			p, err = compileNot(arg)
+		case "$type":
+			p, err = typePredicate(arg)
+		case "$size":
+			p, err = sizePredicate(arg)
+		case "$all":
+			p, err = allPredicate(arg)
+		case "$elemMatch":
+			p, err = elemMatchPredicate(arg)
+		case "$mod":
+			p, err = modPredicate(arg)
+		case "$regex":
+			pattern, ok := arg.(string)
+			if !ok {
+				if re, isRegEx := arg.(bson.RegEx); isRegEx {
+					pattern = re.Pattern
+				} else {
+					return nil, fmt.Errorf("$regex has to be a string")
+				}
+			}
+			options, _ := m["$options"].(string)
+			p, err = regexPredicate(pattern, options)
+		case "$options":
+			if _, ok := m["$regex"]; !ok {
+				return nil, fmt.Errorf("$options needs a $regex")
+			}
+			continue
+		case "$comment":
+			continue
+		default:
+			return nil, fmt.Errorf("unknown operator: %s", op)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		predicates = append(predicates, p)
+	}
+
+	return func(values []interface{}) bool {
+		for _, p := range predicates {
+			if !p(values) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// Values and items of arrays are candidates for comparison.
+func candidates(values []interface{}) []interface{} {
+	out := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		out = append(out, v)
+		if a, ok := v.([]interface{}); ok {
+			out = append(out, a...)
+		}
+	}
+	return out
+}
+
+func notPredicate(p predicate) predicate {
+	return func(values []interface{}) bool {
+		return !p(values)
+	}
+}
+
+func eqPredicate(arg interface{}) predicate {
+	if re, ok := arg.(bson.RegEx); ok {
+		return func(values []interface{}) bool {
+			for _, c := range candidates(values) {
+				if other, ok := c.(bson.RegEx); ok && other == re {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	return func(values []interface{}) bool {
+		// Null matches also missing fields
+		if arg == nil && len(values) == 0 {
+			return true
+		}
+
+		for _, c := range candidates(values) {
+			if Equal(c, arg) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func cmpPredicate(arg interface{}, cmp func(c int) bool) predicate {
+	return func(values []interface{}) bool {
+		for _, c := range candidates(values) {
+			if sameTypeOrder(c, arg) && cmp(Compare(c, arg)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func inPredicate(arg interface{}) (predicate, error) {
+	list, ok := toList(arg)
+	if !ok {
+		return nil, fmt.Errorf("$in/$nin needs an array")
+	}
+
+	predicates := make([]predicate, len(list))
+	for i, item := range list {
+		if re, ok := item.(bson.RegEx); ok {
+			p, err := regexPredicate(re.Pattern, re.Options)
+			if err != nil {
+				return nil, err
+			}
+			predicates[i] = p
+		} else {
+			predicates[i] = eqPredicate(item)
+		}
+	}
+
+	return func(values []interface{}) bool {
+		for _, p := range predicates {
+			if p(values) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func compileNot(arg interface{}) (predicate, error) {
+	if re, ok := arg.(bson.RegEx); ok {
+		p, err := regexPredicate(re.Pattern, re.Options)
+		if err != nil {
+			return nil, err
+		}
+		return notPredicate(p), nil
+	}
+
+	m := toMap(arg)
+	if m == nil {
+		return nil, fmt.Errorf("$not needs a regex or a document")
+	}
+
+	isOperator, err := isOperatorObject(m)
+	if err != nil {
+		return nil, err
+	}
+	if !isOperator {
+		return nil, fmt.Errorf("$not needs a document with operators")
+	}
+
+	p, err := compileOperators(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return notPredicate(p), nil
+}
+
+// Aliases for number codes of types in $type operator.
+var typeCodes = map[int]string{
+	1:   "double",
+	2:   "string",
+	3:   "object",
+	4:   "array",
+	5:   "binData",
+	6:   "undefined",
+	7:   "objectId",
+	8:   "bool",
+	9:   "date",
+	10:  "null",
+	11:  "regex",
+	12:  "dbPointer",
+	13:  "javascript",
+	14:  "symbol",
+	15:  "javascriptWithScope",
+	16:  "int",
+	17:  "timestamp",
+	18:  "long",
+	19:  "decimal",
+	-1:  "minKey",
+	127: "maxKey",
+}
+
+func typePredicate(arg interface{}) (predicate, error) {
+	list, ok := toList(arg)
+	if !ok {
+		list = []interface{}{arg}
+	}
+
+	types := make(map[string]bool)
+	for _, item := range list {
+		switch t := item.(type) {
+		case string:
+			if !isTypeName(t) {
+				return nil, fmt.Errorf("invalid $type: %s", t)
+			}
+			types[t] = true
+		default:
+			code, isNumber := toInt(item)
+			if !isNumber || typeCodes[code] == "" {
+				return nil, fmt.Errorf("invalid $type: %v", item)
+			}
+			types[typeCodes[code]] = true
+		}
+	}
+
+	matchType := func(v interface{}) bool {
+		name := TypeName(v)
+		if types[name] {
+			return true
+		}
+		return types["number"] && (name == "double" || name == "int" || name == "long" || name == "decimal")
+	}
+
+	return func(values []interface{}) bool {
+		for _, c := range candidates(values) {
+			if matchType(c) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func isTypeName(name string) bool {
+	if name == "number" {
+		return true
+	}
+	for _, t := range typeCodes {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sizePredicate(arg interface{}) (predicate, error) {
+	size, ok := toInt(arg)
+	if !ok {
+		return nil, fmt.Errorf("$size needs a number")
+	}
+
+	return func(values []interface{}) bool {
+		for _, v := range values {
+			if a, ok := v.([]interface{}); ok && len(a) == size {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func allPredicate(arg interface{}) (predicate, error) {
+	list, ok := toList(arg)
+	if !ok {
+		return nil, fmt.Errorf("$all needs an array")
+	}
+
+	predicates := make([]predicate, len(list))
+	for i, item := range list {
+		predicates[i] = eqPredicate(item)
+	}
+
+	return func(values []interface{}) bool {
+		if len(predicates) == 0 {
+			return false
+		}
+		for _, p := range predicates {
+			if !p(values) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func elemMatchPredicate(arg interface{}) (predicate, error) {
+	m := toMap(arg)
+	if m == nil {
+		return nil, fmt.Errorf("$elemMatch needs an object")
+	}
+
+	isOperator, err := isOperatorObject(m)
+	if err != nil {
+		return nil, err
+	}
+
+	// Match items by operators, eg. {$elemMatch: {$gt: 5}}
+	var itemMatch func(item interface{}) bool
+	if isOperator {
+		p, err := compileOperators(m)
+		if err != nil {
+			return nil, err
+		}
+		itemMatch = func(item interface{}) bool {
+			return p([]interface{}{item})
+		}
+	} else {
+		f, err := CompileFilter(m)
+		if err != nil {
+			return nil, err
+		}
+		itemMatch = func(item interface{}) bool {
+			doc := toMap(item)
+			return doc != nil && f(doc)
+		}
+	}
+
+	return func(values []interface{}) bool {
+		for _, v := range values {
+			if a, ok := v.([]interface{}); ok {
+				for _, item := range a {
+					if itemMatch(item) {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}, nil
+}
+
+func modPredicate(arg interface{}) (predicate, error) {
+	list, ok := toList(arg)
+	if !ok || len(list) != 2 {
+		return nil, fmt.Errorf("$mod needs an array [divisor, remainder]")
+	}
+
+	divisor, ok1 := toInt(list[0])
+	remainder, ok2 := toInt(list[1])
+	if !ok1 || !ok2 || divisor == 0 {
+		return nil, fmt.Errorf("$mod needs an array [divisor, remainder]")
+	}
+
+	return func(values []interface{}) bool {
+		for _, c := range candidates(values) {
+			if typeOrder(c) == orderNumber && int64(toFloat(c))%int64(divisor) == int64(remainder) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func regexPredicate(pattern string, options string) (predicate, error) {
+	flags := ""
+	for _, o := range options {
+		switch o {
+		case 'i', 'm', 's':
+			flags += string(o)
+		default:
+			return nil, fmt.Errorf("unsupported regex option: %c", o)
+		}
+	}
+
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %s", err)
+	}
+
+	return func(values []interface{}) bool {
+		for _, c := range candidates(values) {
+			if s, ok := c.(string); ok && re.MatchString(s) {
+				return true
+			}
+			if s, ok := c.(bson.Symbol); ok && re.MatchString(string(s)) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// Get all values from path, arrays of documents are traversed.
+func resolvePath(value interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{value}
+	}
+
+	if m := toMap(value); m != nil {
+		v, ok := m[path[0]]
+		if !ok {
+			return nil
+		}
+		return resolvePath(v, path[1:])
+	}
+
+	if a, ok := value.([]interface{}); ok {
+		var out []interface{}
+
+		// Numeric path part can be an index
+		if i, err := strconv.Atoi(path[0]); err == nil && i >= 0 && i < len(a) {
+			out = append(out, resolvePath(a[i], path[1:])...)
+		}
+
+		for _, item := range a {
+			if isMap(item) {
+				out = append(out, resolvePath(item, path)...)
+			}
+		}
+
+		return out
+	}
+
+	return nil
+}
+
+func toList(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case []bson.M:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = item
+		}
+		return out, true
+	case []map[string]interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = item
+		}
+		return out, true
+	case []string:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = item
+		}
+		return out, true
+	}
+
+	return nil, false
+}
+
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), true
+		}
+	}
+
+	return 0, false
+}
+
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case int, int32, int64, float64:
+		return toFloat(v) != 0
+	}
+
+	return true
+}
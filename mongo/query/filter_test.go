@@ -0,0 +1,125 @@
+package query
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+var testDoc = bson.M{
+	"_id":   bson.ObjectIdHex("58ed0817344c64f7fca5847a"),
+	"int":   15,
+	"long":  int64(20),
+	"str":   "Abc",
+	"null":  nil,
+	"date":  time.Date(2017, 5, 1, 10, 0, 0, 0, time.UTC),
+	"tags":  []interface{}{"a", "b", "c"},
+	"nums":  []interface{}{1, 5, 9},
+	"obj":   bson.M{"x": 1, "y": bson.M{"z": "deep"}},
+	"items": []interface{}{bson.M{"k": 1, "v": "one"}, bson.M{"k": 2, "v": "two"}},
+}
+
+func match(t *testing.T, q string) bool {
+	m := bson.M{}
+	if err := json.Unmarshal([]byte(q), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := CompileFilter(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return f(testDoc)
+}
+
+func TestCompileFilter_Equality(t *testing.T) {
+	assert.True(t, match(t, `{}`))
+	assert.True(t, match(t, `{"int": 15}`))
+	assert.True(t, match(t, `{"long": 20}`))
+	assert.True(t, match(t, `{"str": "Abc"}`))
+	assert.False(t, match(t, `{"str": "abc"}`))
+	assert.True(t, match(t, `{"obj.y.z": "deep"}`))
+	assert.True(t, match(t, `{"obj": {"x": 1, "y": {"z": "deep"}}}`))
+	assert.False(t, match(t, `{"obj": {"x": 1}}`))
+}
+
+func TestCompileFilter_Null(t *testing.T) {
+	assert.True(t, match(t, `{"null": null}`))
+	assert.True(t, match(t, `{"missing": null}`))
+	assert.False(t, match(t, `{"str": null}`))
+}
+
+func TestCompileFilter_Comparison(t *testing.T) {
+	assert.True(t, match(t, `{"int": {"$gt": 10}}`))
+	assert.False(t, match(t, `{"int": {"$gt": 15}}`))
+	assert.True(t, match(t, `{"int": {"$gte": 15, "$lte": 15}}`))
+	assert.True(t, match(t, `{"int": {"$lt": 15.5}}`))
+	assert.True(t, match(t, `{"int": {"$ne": 16}}`))
+	assert.False(t, match(t, `{"str": {"$gt": 10}}`))
+	assert.True(t, match(t, `{"str": {"$gt": "Aaa"}}`))
+}
+
+func TestCompileFilter_In(t *testing.T) {
+	assert.True(t, match(t, `{"int": {"$in": [1, 15]}}`))
+	assert.False(t, match(t, `{"int": {"$in": [1, 2]}}`))
+	assert.True(t, match(t, `{"int": {"$nin": [1, 2]}}`))
+	assert.True(t, match(t, `{"tags": {"$in": ["x", "b"]}}`))
+}
+
+func TestCompileFilter_Arrays(t *testing.T) {
+	assert.True(t, match(t, `{"tags": "b"}`))
+	assert.True(t, match(t, `{"tags": ["a", "b", "c"]}`))
+	assert.True(t, match(t, `{"tags.1": "b"}`))
+	assert.True(t, match(t, `{"nums": {"$gt": 8}}`))
+	assert.True(t, match(t, `{"items.v": "two"}`))
+	assert.True(t, match(t, `{"tags": {"$size": 3}}`))
+	assert.True(t, match(t, `{"tags": {"$all": ["c", "a"]}}`))
+	assert.False(t, match(t, `{"tags": {"$all": ["c", "x"]}}`))
+	assert.True(t, match(t, `{"items": {"$elemMatch": {"k": 2, "v": "two"}}}`))
+	assert.False(t, match(t, `{"items": {"$elemMatch": {"k": 2, "v": "one"}}}`))
+	assert.True(t, match(t, `{"nums": {"$elemMatch": {"$gt": 4, "$lt": 6}}}`))
+}
+
+func TestCompileFilter_Element(t *testing.T) {
+	assert.True(t, match(t, `{"str": {"$exists": true}}`))
+	assert.True(t, match(t, `{"missing": {"$exists": false}}`))
+	assert.False(t, match(t, `{"missing": {"$exists": 1}}`))
+	assert.True(t, match(t, `{"int": {"$type": "int"}}`))
+	assert.True(t, match(t, `{"long": {"$type": 18}}`))
+	assert.True(t, match(t, `{"long": {"$type": "number"}}`))
+	assert.True(t, match(t, `{"tags": {"$type": "array"}}`))
+	assert.True(t, match(t, `{"date": {"$type": ["string", "date"]}}`))
+}
+
+func TestCompileFilter_Logical(t *testing.T) {
+	assert.True(t, match(t, `{"$and": [{"int": 15}, {"str": "Abc"}]}`))
+	assert.False(t, match(t, `{"$and": [{"int": 15}, {"str": "x"}]}`))
+	assert.True(t, match(t, `{"$or": [{"int": 1}, {"str": "Abc"}]}`))
+	assert.True(t, match(t, `{"$nor": [{"int": 1}, {"str": "x"}]}`))
+	assert.True(t, match(t, `{"int": {"$not": {"$gt": 20}}}`))
+}
+
+func TestCompileFilter_Evaluation(t *testing.T) {
+	assert.True(t, match(t, `{"str": {"$regex": "^ab", "$options": "i"}}`))
+	assert.False(t, match(t, `{"str": {"$regex": "^ab"}}`))
+	assert.True(t, match(t, `{"int": {"$mod": [4, 3]}}`))
+}
+
+func TestCompileFilter_Errors(t *testing.T) {
+	invalid := []bson.M{
+		{"$where": "this.a == 1"},
+		{"a": bson.M{"$near": 1}},
+		{"a": bson.M{"$gt": 1, "b": 1}},
+		{"$or": []interface{}{}},
+		{"a": bson.M{"$regex": "(", "$options": ""}},
+		{"a": bson.M{"$type": "xyz"}},
+	}
+
+	for _, q := range invalid {
+		_, err := CompileFilter(q)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,179 @@
+package query
+
+import (
+	"fmt"
+	"gopkg.in/mgo.v2/bson"
+	"sort"
+	"strings"
+)
+
+// Projection modifies document according to projection ($project aggregation syntax).
+// Order of fields in the document is preserved.
+type Projection func(doc bson.D) bson.D
+
+// Tree of projected paths, nil represents leaf.
+type projectionTree map[string]projectionTree
+
+// CompileProjection compiles projection document to Projection.
+// Only inclusion and exclusion of fields (with dot notation) are supported, not expressions.
+func CompileProjection(projection map[string]interface{}) (Projection, error) {
+	include := projectionTree{}
+	exclude := projectionTree{}
+	excludeId := false
+
+	for key, value := range projection {
+		if strings.HasPrefix(key, "$") {
+			return nil, fmt.Errorf("unsupported projection key: %s", key)
+		}
+
+		var included bool
+		switch v := value.(type) {
+		case bool:
+			included = v
+		case int, int32, int64, float64:
+			included = toFloat(v) != 0
+		default:
+			return nil, fmt.Errorf("unsupported projection of '%s', only inclusion and exclusion of fields are supported", key)
+		}
+
+		if key == "_id" {
+			excludeId = !included
+			continue
+		}
+
+		if included {
+			include.add(strings.Split(key, "."))
+		} else {
+			exclude.add(strings.Split(key, "."))
+		}
+	}
+
+	if len(include) != 0 && len(exclude) != 0 {
+		return nil, fmt.Errorf("projection cannot have a mix of inclusion and exclusion")
+	}
+
+	if len(include) != 0 {
+		if !excludeId {
+			include["_id"] = nil
+		}
+
+		return func(doc bson.D) bson.D {
+			return include.include(doc)
+		}, nil
+	}
+
+	if excludeId {
+		exclude["_id"] = nil
+	}
+
+	return func(doc bson.D) bson.D {
+		return exclude.exclude(doc)
+	}, nil
+}
+
+func (t projectionTree) add(path []string) {
+	sub, exists := t[path[0]]
+
+	if len(path) == 1 {
+		// Leaf overrides nested paths
+		t[path[0]] = nil
+		return
+	}
+
+	if exists && sub == nil {
+		// Parent is already projected as a whole
+		return
+	}
+
+	if sub == nil {
+		sub = projectionTree{}
+		t[path[0]] = sub
+	}
+
+	sub.add(path[1:])
+}
+
+func (t projectionTree) include(doc bson.D) bson.D {
+	out := bson.D{}
+
+	for _, e := range doc {
+		sub, ok := t[e.Name]
+		if !ok {
+			continue
+		}
+
+		if sub == nil {
+			out = append(out, e)
+		} else if d := toDoc(e.Value); d != nil {
+			out = append(out, bson.DocElem{Name: e.Name, Value: sub.include(d)})
+		} else if a, ok := e.Value.([]interface{}); ok {
+			// Only nested documents are kept in arrays
+			items := make([]interface{}, 0, len(a))
+			for _, item := range a {
+				if d := toDoc(item); d != nil {
+					items = append(items, sub.include(d))
+				}
+			}
+			out = append(out, bson.DocElem{Name: e.Name, Value: items})
+		}
+	}
+
+	return out
+}
+
+func (t projectionTree) exclude(doc bson.D) bson.D {
+	out := bson.D{}
+
+	for _, e := range doc {
+		sub, ok := t[e.Name]
+		if !ok {
+			out = append(out, e)
+			continue
+		}
+
+		if sub == nil {
+			continue
+		} else if d := toDoc(e.Value); d != nil {
+			out = append(out, bson.DocElem{Name: e.Name, Value: sub.exclude(d)})
+		} else if a, ok := e.Value.([]interface{}); ok {
+			items := make([]interface{}, len(a))
+			for i, item := range a {
+				if d := toDoc(item); d != nil {
+					items[i] = sub.exclude(d)
+				} else {
+					items[i] = item
+				}
+			}
+			out = append(out, bson.DocElem{Name: e.Name, Value: items})
+		} else {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+// Convert nested document to bson.D, fields of maps are sorted by name.
+func toDoc(value interface{}) bson.D {
+	if d, ok := value.(bson.D); ok {
+		return d
+	}
+
+	m := toMap(value)
+	if m == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	d := make(bson.D, len(keys))
+	for i, key := range keys {
+		d[i] = bson.DocElem{Name: key, Value: m[key]}
+	}
+
+	return d
+}
@@ -0,0 +1,67 @@
+package query
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+var testProjectionDoc = bson.D{
+	{Name: "_id", Value: bson.ObjectIdHex("58ed0817344c64f7fca5847a")},
+	{Name: "str", Value: "Abc"},
+	{Name: "int", Value: 15},
+	{Name: "tags", Value: []interface{}{"a", "b", "c"}},
+	{Name: "obj", Value: bson.D{{Name: "y", Value: bson.D{{Name: "z", Value: "deep"}}}, {Name: "x", Value: 1}}},
+	{Name: "items", Value: []interface{}{bson.M{"v": "one", "k": 1}, bson.D{{Name: "v", Value: "two"}, {Name: "k", Value: 2}}, 3}},
+}
+
+func project(t *testing.T, projection bson.M) bson.D {
+	p, err := CompileProjection(projection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p(testProjectionDoc)
+}
+
+func TestCompileProjection_Include(t *testing.T) {
+	assert.Equal(t, bson.D{
+		{Name: "_id", Value: bson.ObjectIdHex("58ed0817344c64f7fca5847a")},
+		{Name: "int", Value: 15},
+	}, project(t, bson.M{"int": 1}))
+
+	// Order of fields in the document is preserved
+	assert.Equal(t, bson.D{
+		{Name: "str", Value: "Abc"},
+		{Name: "int", Value: 15},
+	}, project(t, bson.M{"_id": 0, "int": true, "str": 1}))
+}
+
+func TestCompileProjection_IncludeNested(t *testing.T) {
+	assert.Equal(t, bson.D{
+		{Name: "obj", Value: bson.D{{Name: "y", Value: bson.D{{Name: "z", Value: "deep"}}}}},
+		{Name: "items", Value: []interface{}{bson.D{{Name: "v", Value: "one"}}, bson.D{{Name: "v", Value: "two"}}}},
+	}, project(t, bson.M{"_id": 0, "obj.y.z": 1, "items.v": 1}))
+}
+
+func TestCompileProjection_Exclude(t *testing.T) {
+	assert.Equal(t, bson.D{
+		{Name: "str", Value: "Abc"},
+		{Name: "int", Value: 15},
+		{Name: "obj", Value: bson.D{{Name: "y", Value: bson.D{{Name: "z", Value: "deep"}}}}},
+		{Name: "items", Value: []interface{}{bson.D{{Name: "v", Value: "one"}}, bson.D{{Name: "v", Value: "two"}}, 3}},
+	}, project(t, bson.M{"_id": 0, "tags": 0, "obj.x": 0, "items.k": 0}))
+}
+
+func TestCompileProjection_Errors(t *testing.T) {
+	invalid := []bson.M{
+		{"a": 1, "b": 0},
+		{"a": "$b"},
+		{"a": bson.M{"$slice": 1}},
+	}
+
+	for _, p := range invalid {
+		_, err := CompileProjection(p)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,76 @@
+// Package mongoeye is the library interface of MongoEYE.
+// It runs the analysis without command line interface, so it can be embedded into other applications.
+//
+// Documents can be analyzed from a MongoDB collection (analysis.NewCollectionSource, connect by official.Dial)
+// or from offline sources in the source package (BSON files, JSON files, in-memory documents).
+package mongoeye
+
+import (
+	"context"
+	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/analyzer"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Options of analysis, they have the same meaning as flags of command line interface.
+type Options = analyzer.Options
+
+// Result of analysis.
+type Result = analyzer.Result
+
+// Source of documents for analysis.
+type Source interface {
+	analysis.Source
+
+	// Count returns number of all documents in source.
+	Count() (int, error)
+}
+
+// DefaultOptions returns options with the same default values as command line interface.
+func DefaultOptions() *Options {
+	return analyzer.DefaultOptions()
+}
+
+// Analyze runs analysis of documents from the source.
+// If options are nil, then default options are used.
+// If the context is done, then partial result is returned together with the context error.
+// Deadline of the context is also used as the server-side time limit for MongoDB collections.
+func Analyze(ctx context.Context, src Source, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	// Offline sources are counted while reading
+	c, inDB := src.(*analysis.CollectionSource)
+	if !inDB {
+		return analyzer.Analyze(ctx, src, 0, driver.BuildInfo{}, opts)
+	}
+
+	count, err := c.Count()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot count documents: %s.", err)
+	}
+
+	// Server version is required only for analysis in database
+	var server driver.BuildInfo
+	if opts.Plan != "local" {
+		server, err = c.Collection.Session().BuildInfo()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get server version: %s.", err)
+		}
+	}
+
+	return analyzer.Analyze(ctx, src, count, server, opts)
+}
+
+// JsonSchema converts result of analysis to MongoDB $jsonSchema validator, see --format jsonschema.
+// If options are nil, then default options are used.
+func JsonSchema(result *Result, opts *Options) bson.M {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	return analyzer.JsonSchema(result, opts)
+}
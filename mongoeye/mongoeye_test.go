@@ -0,0 +1,574 @@
+package mongoeye
+
+import (
+	"context"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/decoder"
+	"github.com/mongoeye/mongoeye/source"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func testSource(t *testing.T) *source.Documents {
+	src, err := source.NewDocuments(
+		bson.M{"_id": 1, "str": "Abc", "int": 5},
+		bson.M{"_id": 2, "str": "Xyz"},
+		bson.M{"_id": 3, "obj": bson.M{"a": 1.5}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func findField(fields analysis.Fields, name string) *analysis.Field {
+	for _, f := range fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func TestDefaultOptions(t *testing.T) {
+	assert.Nil(t, DefaultOptions().Validate())
+}
+
+func TestAnalyze(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.Location = time.UTC
+
+	result, err := Analyze(context.Background(), testSource(t), opts)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "local", result.Plan)
+	assert.Equal(t, uint64(3), result.AllDocsCount)
+	assert.Equal(t, uint64(3), result.DocsCount)
+	assert.Equal(t, uint64(5), result.FieldsCount)
+
+	str := findField(result.Fields, "str")
+	assert.NotNil(t, str)
+	assert.Equal(t, uint64(2), str.Count)
+	assert.Equal(t, "string", str.Types[0].Name)
+
+	nested := findField(result.Fields, "obj.a")
+	assert.NotNil(t, nested)
+	assert.Equal(t, "double", nested.Types[0].Name)
+}
+
+func TestJsonSchema(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+
+	result, err := Analyze(context.Background(), testSource(t), opts)
+	assert.Nil(t, err)
+
+	schema := JsonSchema(result, nil)
+	assert.Equal(t, "object", schema["bsonType"])
+	assert.Equal(t, []string{"_id"}, schema["required"])
+
+	obj := schema["properties"].(bson.M)["obj"].(bson.M)
+	assert.Equal(t, bson.M{
+		"bsonType":   "object",
+		"required":   []string{"a"},
+		"properties": bson.M{"a": bson.M{"bsonType": "double"}},
+	}, obj)
+}
+
+func TestAnalyze_Presence(t *testing.T) {
+	src, err := source.NewDocuments(
+		bson.M{"_id": 1, "n": nil, "arr": []interface{}{bson.M{"a": 1}, bson.M{"a": 2, "b": 3}}},
+		bson.M{"_id": 2, "n": 5, "arr": []interface{}{}},
+		bson.M{"_id": 3, "arr": []interface{}{bson.M{"b": 4}}},
+		bson.M{"_id": 4},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+
+	// Null is present, absent is missing
+	n := findField(result.Fields, "n")
+	assert.Equal(t, uint64(2), n.DocsCount)
+	assert.Equal(t, uint64(2), n.Missing)
+	assert.Equal(t, float64(50), n.PresentInParent)
+
+	// Array items are counted once per array
+	items := findField(result.Fields, "arr.[]")
+	assert.Equal(t, uint64(3), items.Count)
+	assert.Equal(t, uint64(2), items.DocsCount)
+	assert.Equal(t, uint64(2), items.ParentsCount)
+	assert.Equal(t, uint64(2), items.Missing)
+	assert.InDelta(t, 66.7, items.PresentInParent, 0.1)
+
+	// Parent of nested field is object in array
+	b := findField(result.Fields, "arr.[].b")
+	assert.Equal(t, uint64(2), b.DocsCount)
+	assert.Equal(t, uint64(2), b.ParentsCount)
+	assert.InDelta(t, 66.7, b.PresentInParent, 0.1)
+}
+
+func TestAnalyze_UniqueMethod(t *testing.T) {
+	docs := make([]interface{}, 20000)
+	for i := range docs {
+		docs[i] = bson.M{"_id": i, "str": "value" + strconv.Itoa(i%5000), "int": i % 10}
+	}
+	src, _ := source.NewDocuments(docs...)
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.CountUnique = true
+
+	// Exact count, memory limit is not exceeded
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+	str := findField(result.Fields, "str").Types[0]
+	assert.Equal(t, uint64(5000), str.CountUnique)
+	assert.Equal(t, float64(0), str.CountUniqueError)
+
+	// Estimate
+	opts.UniqueMethod = "approx"
+	result, err = Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+	str = findField(result.Fields, "str").Types[0]
+	assert.InDelta(t, 5000, str.CountUnique, 4*str.CountUniqueError/100*5000)
+	assert.Equal(t, 1.625, str.CountUniqueError)
+	assert.Equal(t, uint64(10), findField(result.Fields, "int").Types[0].CountUnique)
+
+	// Memory limit exceeded, estimate is used for all fields
+	opts.UniqueMethod = "auto"
+	opts.UniqueMemory = 0
+	result, err = Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+	str = findField(result.Fields, "str").Types[0]
+	assert.InDelta(t, 5000, str.CountUnique, 4*str.CountUniqueError/100*5000)
+	assert.Equal(t, 1.625, str.CountUniqueError)
+}
+
+func TestAnalyze_Quantiles(t *testing.T) {
+	src, err := source.NewDocuments(
+		bson.M{"_id": 1, "int": 4, "str": "abcdef", "date": time.Unix(1000, 0)},
+		bson.M{"_id": 2, "int": 2, "str": "", "date": time.Unix(3000, 0)},
+		bson.M{"_id": 3, "int": 5, "str": "abcdefgh", "date": time.Unix(2000, 0)},
+		bson.M{"_id": 4, "int": 1, "str": "ab"},
+		bson.M{"_id": 5, "int": 3, "str": "abcd"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.Location = time.UTC
+	opts.MinMaxAvgValue = true
+	opts.MinMaxAvgLength = true
+	opts.Quantiles = true
+
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+
+	assert.Equal(t, &analysis.Quantiles{
+		P5:     1.2,
+		P25:    2.0,
+		Median: 3.0,
+		P75:    4.0,
+		P90:    4.6,
+		P95:    4.8,
+		P99:    4.96,
+	}, findField(result.Fields, "int").Types[0].ValueStats.Quantiles)
+
+	str := findField(result.Fields, "str").Types[0]
+	assert.Nil(t, str.ValueStats.Quantiles)
+	assert.Equal(t, 7.2, str.LengthStats.Quantiles.P90)
+
+	date := findField(result.Fields, "date").Types[0]
+	assert.Equal(t, time.Unix(2000, 0).In(time.UTC), date.ValueStats.Quantiles.Median)
+	assert.Equal(t, time.Unix(2800, 0).In(time.UTC), date.ValueStats.Quantiles.P90)
+}
+
+func TestAnalyze_SumAndDeviation(t *testing.T) {
+	src, err := source.NewDocuments(
+		bson.M{"_id": 1, "int": 1, "str": "a", "date": time.Unix(1000, 0)},
+		bson.M{"_id": 2, "int": 5, "str": "b", "date": time.Unix(3000, 0)},
+		bson.M{"_id": 3, "int": 3, "str": "c"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.Location = time.UTC
+	opts.MinMaxAvgValue = true
+
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+
+	stdDev := math.Sqrt(8.0 / 3)
+	stats := findField(result.Fields, "int").Types[0].ValueStats
+	assert.Equal(t, float64(9), stats.Sum)
+	assert.Equal(t, stdDev, stats.StdDev)
+	assert.Equal(t, stdDev*stdDev, stats.Variance)
+
+	str := findField(result.Fields, "str").Types[0].ValueStats
+	assert.Nil(t, str.Sum)
+	assert.Nil(t, str.StdDev)
+
+	// Standard deviation of dates is in seconds
+	date := findField(result.Fields, "date").Types[0].ValueStats
+	assert.Nil(t, date.Sum)
+	assert.Equal(t, float64(1000), date.StdDev)
+	assert.Equal(t, float64(1000000), date.Variance)
+}
+
+func TestAnalyze_StringFormats(t *testing.T) {
+	src, err := source.NewDocuments(
+		bson.M{"_id": 1, "value": "2017-04-01"},
+		bson.M{"_id": 2, "value": "42"},
+		bson.M{"_id": 3, "value": "abc"},
+		bson.M{"_id": 4, "value": 42},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.StringFormats = true
+
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+
+	types := findField(result.Fields, "value").Types
+	assert.Nil(t, types[0].StringFormats)
+	assert.Equal(t, "string", types[1].Name)
+	assert.Equal(t, &analysis.StringFormats{Integer: 1, Decimal: 1, Date: 1}, types[1].StringFormats)
+}
+
+func TestAnalyze_Truncation(t *testing.T) {
+	src, err := source.NewDocuments(
+		bson.M{"_id": 1, "tags": []int{1, 2, 3, 4}, "a": bson.M{"b": bson.M{"c": 1}}},
+		bson.M{"_id": 2, "tags": []int{1}, "a": bson.M{"b": bson.M{}}},
+		bson.M{"_id": 3, "tags": []int{1, 2, 3}, "a": bson.M{"b": bson.M{"c": 2}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.Depth = 1
+	opts.ArrayMaxLength = 2
+
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+
+	assert.Equal(t, &analysis.Truncation{Arrays: 2, Items: 3, MaxItems: 2}, findField(result.Fields, "tags").Types[0].Truncation)
+	assert.Nil(t, findField(result.Fields, "a").Types[0].Truncation)
+	assert.Equal(t, &analysis.Truncation{Depth: 2}, findField(result.Fields, "a.b").Types[0].Truncation)
+	assert.Nil(t, findField(result.Fields, "tags.[]").Types[0].Truncation)
+}
+
+func TestAnalyze_ArrayStats(t *testing.T) {
+	src, err := source.NewDocuments(
+		bson.M{"_id": 1, "tags": []interface{}{"a", "b"}, "point": []interface{}{14.4, 50.1}},
+		bson.M{"_id": 2, "tags": []interface{}{"b", "a", "b"}, "point": []interface{}{15.2, 49.8}},
+		bson.M{"_id": 3, "tags": []interface{}{}, "point": []interface{}{16.6, "x"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.ArrayStats = true
+	opts.ArrayPositions = 2
+
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+
+	assert.Equal(t, &analysis.ArrayStats{Empty: 1, Homogeneous: 2, Duplicates: 1, Sorted: 1}, findField(result.Fields, "tags").Types[0].ArrayStats)
+	assert.Equal(t, &analysis.ArrayStats{Homogeneous: 2, Sorted: 2}, findField(result.Fields, "point").Types[0].ArrayStats)
+	assert.Nil(t, findField(result.Fields, "point.[]").Types[0].ArrayStats)
+
+	// Tuple-like array, the second item has different types
+	first := findField(result.Fields, "point.[0]")
+	assert.Equal(t, uint64(3), first.Count)
+	assert.Equal(t, float64(100), first.PresentInParent)
+	assert.Equal(t, 1, len(first.Types))
+	second := findField(result.Fields, "point.[1]")
+	assert.Equal(t, 2, len(second.Types))
+	assert.Equal(t, uint64(6), findField(result.Fields, "point.[]").Count)
+	assert.Nil(t, findField(result.Fields, "tags.[2]"))
+}
+
+func TestAnalyze_EscapedKeys(t *testing.T) {
+	src, err := source.NewDocuments(
+		bson.D{{Name: "_id", Value: 1}, {Name: "a.b", Value: bson.M{"c": 1}}, {Name: "", Value: "x"}, {Name: "$x", Value: true}},
+		bson.D{{Name: "_id", Value: 2}, {Name: "a", Value: bson.M{"b": 2}}, {Name: "", Value: "y"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.DepthFor = map[string]uint{`"a.b"`: 0}
+
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+
+	// Key "a.b" is not mixed with nested field "b" of the object "a"
+	dotted := findField(result.Fields, `"a.b"`)
+	assert.Equal(t, uint64(1), dotted.Count)
+	assert.Equal(t, &analysis.Truncation{Depth: 1}, dotted.Types[0].Truncation)
+	assert.Nil(t, findField(result.Fields, `"a.b".c`))
+	assert.Equal(t, uint64(1), findField(result.Fields, "a.b").Count)
+	assert.Equal(t, float64(100), findField(result.Fields, "a.b").PresentInParent)
+	assert.Equal(t, uint64(2), findField(result.Fields, `""`).Count)
+	assert.Equal(t, uint(0), findField(result.Fields, "$x").Level)
+
+	schema := JsonSchema(result, nil)
+	properties := schema["properties"].(bson.M)
+	assert.Equal(t, []string{"", "_id"}, schema["required"])
+	assert.Equal(t, bson.M{"bsonType": "object"}, properties["a.b"])
+	assert.Equal(t, bson.M{"bsonType": "bool"}, properties["$x"])
+}
+
+func TestAnalyze_Maps(t *testing.T) {
+	src, err := source.NewDocuments(
+		bson.M{"_id": 1, "stats": bson.M{"2017-04-01": 1, "2017-04-02": 2, "2017-04-03": 3}},
+		bson.M{"_id": 2, "stats": bson.M{"2017-05-01": 4, "2017-05-02": 5, "2017-05-03": 6}},
+		bson.M{"_id": 3, "stats": bson.M{"2017-06-01": 7, "2017-06-02": 8}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.MapMinKeys = 3
+
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+
+	// The last object has not enough keys
+	names := []string{}
+	for _, f := range result.Fields {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"_id", "stats", "stats.2017-06-01", "stats.2017-06-02", "stats.{key}"}, names)
+
+	key := findField(result.Fields, "stats.{key}")
+	assert.Equal(t, uint64(6), key.Count)
+	assert.Equal(t, uint64(2), key.DocsCount)
+	assert.Equal(t, &analysis.KeyShapes{Date: 6}, key.Types[0].KeyShapes)
+
+	// Disabled
+	opts.MapMinKeys = 0
+	result, err = Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+	assert.Nil(t, findField(result.Fields, "stats.{key}"))
+}
+
+func TestAnalyze_Shapes(t *testing.T) {
+	src, err := source.NewDocuments(
+		bson.M{"_id": 1, "name": "a"},
+		bson.M{"_id": 2, "name": "b"},
+		bson.M{"_id": 3, "name": "c", "age": 30},
+		bson.M{"_id": 4, "name": 5},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.Concurrency = 1
+	opts.Shapes = 2
+
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), result.ShapesCount)
+	assert.Equal(t, analysis.Shapes{
+		{Count: 2, Examples: []interface{}{1, 2}},
+		{Count: 1, Examples: []interface{}{3}, Added: []string{"age (int)"}},
+	}, result.Shapes)
+
+	// Disabled
+	opts.Shapes = 0
+	result, err = Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+	assert.Nil(t, result.Shapes)
+}
+
+func TestAnalyze_DefaultOptions(t *testing.T) {
+	result, err := Analyze(context.Background(), testSource(t), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), result.DocsCount)
+}
+
+func TestAnalyze_Sample(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SampleMethod = "first"
+	opts.Limit = 2
+	opts.Match = bson.M{"str": bson.M{"$exists": true}}
+	opts.Project = bson.M{"int": 0}
+
+	result, err := Analyze(context.Background(), testSource(t), opts)
+	assert.Nil(t, err)
+
+	assert.Equal(t, uint64(2), result.DocsCount)
+	assert.NotNil(t, findField(result.Fields, "str"))
+	assert.Nil(t, findField(result.Fields, "int"))
+	assert.Nil(t, findField(result.Fields, "obj"))
+}
+
+func TestAnalyze_InvalidOptions(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SampleMethod = "xyz"
+
+	_, err := Analyze(context.Background(), testSource(t), opts)
+	assert.Error(t, err)
+}
+
+func TestAnalyze_InvalidMatch(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Match = bson.M{"$where": "true"}
+
+	_, err := Analyze(context.Background(), testSource(t), opts)
+	assert.Error(t, err)
+}
+
+func TestAnalyze_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Analyze(ctx, testSource(t), nil)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestAnalyze_Deadline(t *testing.T) {
+	docs := make([]interface{}, 50000)
+	for i := range docs {
+		docs[i] = bson.M{"_id": i, "str": "Abc", "arr": []interface{}{1, 2, 3}}
+	}
+	src, _ := source.NewDocuments(docs...)
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.CountUnique = true
+
+	goroutines := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	result, err := Analyze(ctx, src, opts)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.NotNil(t, result)
+
+	// All goroutines of analysis are finished
+	for i := 0; i < 100 && runtime.NumGoroutine() > goroutines; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, runtime.NumGoroutine() <= goroutines)
+}
+
+func corruptedJsonSource(t *testing.T) *source.JsonFiles {
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	path := filepath.Join(dir, "col.json")
+	ioutil.WriteFile(path, []byte("{\"a\": 1}\n{\"a\": \n{\"a\": 2}\n"), 0644)
+
+	src, err := source.NewJsonFiles([]string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestAnalyze_Corrupted(t *testing.T) {
+	src := corruptedJsonSource(t)
+	defer os.RemoveAll(filepath.Dir(src.Paths[0]))
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, result)
+	assert.IsType(t, &decoder.CorruptedError{}, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestAnalyze_SkipCorrupted(t *testing.T) {
+	src := corruptedJsonSource(t)
+	defer os.RemoveAll(filepath.Dir(src.Paths[0]))
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+	opts.SkipCorrupted = true
+
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), result.AllDocsCount)
+	assert.Equal(t, uint64(2), result.DocsCount)
+	assert.Equal(t, uint64(1), result.CorruptedDocsCount)
+	assert.Equal(t, uint64(2), findField(result.Fields, "a").Count)
+}
+
+func TestAnalyze_SkipCorruptedBson(t *testing.T) {
+	valid, _ := bson.Marshal(bson.M{"a": 1})
+	corrupted, _ := bson.Marshal(bson.D{{Name: "b", Value: "abc"}})
+	corrupted[len(corrupted)-2] = 0x01 // damage the end of string
+
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "col.bson")
+	ioutil.WriteFile(path, append(append(valid, corrupted...), valid...), 0644)
+
+	src, err := source.NewBsonFiles([]string{path})
+	assert.Nil(t, err)
+
+	opts := DefaultOptions()
+	opts.SampleMethod = "all"
+	opts.Limit = 0
+
+	_, err = Analyze(context.Background(), src, opts)
+	assert.IsType(t, &decoder.CorruptedError{}, err)
+
+	opts.SkipCorrupted = true
+	result, err := Analyze(context.Background(), src, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), result.CorruptedDocsCount)
+	assert.Equal(t, uint64(2), findField(result.Fields, "a").Count)
+	assert.Nil(t, findField(result.Fields, "b"))
+}
@@ -0,0 +1,185 @@
+package source
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"io"
+	"os"
+)
+
+// MaxDocumentSize is maximal accepted size of one document in BSON file (max. message size in MongoDB).
+const MaxDocumentSize = 48 * 1024 * 1024
+
+// BsonFiles reads documents from BSON files created by mongodump.
+// Files compressed by gzip (mongodump --gzip) are detected automatically.
+type BsonFiles struct {
+	Paths   []string
+	counter readCounter
+}
+
+// NewBsonFiles - BsonFiles factory.
+// It returns error if any of files cannot be opened.
+func NewBsonFiles(paths []string) (*BsonFiles, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("No BSON file specified.")
+	}
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot open BSON file '%s'.", path)
+		}
+		f.Close()
+	}
+
+	return &BsonFiles{Paths: paths}, nil
+}
+
+// Count returns number of documents in all files.
+// Only headers of documents are read, so counting is fast (except of compressed files).
+// If the files were already read to the end by ToRawChannel, the count of read documents is returned.
+func (s *BsonFiles) Count() (count int, err error) {
+	if count, ok := s.counter.get(); ok {
+		return count, nil
+	}
+
+	for _, path := range s.Paths {
+		err = readBsonFile(path, false, func(doc []byte) error {
+			count++
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+// Close - BsonFiles does not hold any resources.
+func (s *BsonFiles) Close() error {
+	return nil
+}
+
+// ToRawChannel reads documents from files and sends them to the output channel.
+// Files are read sequentially in given order.
+func (s *BsonFiles) ToRawChannel(ctx context.Context, pipeline *expr.Pipeline, outCh chan<- []byte, options *analysis.Options) {
+	if !checkEmptyPipeline(ctx, pipeline, outCh) {
+		return
+	}
+
+	go func() {
+		count := 0
+		for i, path := range s.Paths {
+			err := readBsonFile(path, true, func(doc []byte) error {
+				count++
+				return send(ctx, outCh, doc)
+			})
+			if err == context.Canceled || err == context.DeadlineExceeded {
+				break
+			} else if err != nil {
+				analysis.Fail(ctx, err)
+				break
+			}
+
+			// Documents are counted while reading, so compressed files are not read again by Count
+			if i == len(s.Paths)-1 {
+				s.counter.set(count)
+			}
+		}
+
+		close(outCh)
+	}()
+}
+
+// Read documents from BSON file, fn is called for each document.
+// If readBody is false, then only length of document is read and fn gets nil.
+// Error returned by fn stops reading and it is returned unchanged.
+func readBsonFile(path string, readBody bool, fn func(doc []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Cannot open BSON file '%s'.", path)
+	}
+	defer f.Close()
+
+	r, err := newFileReader(f)
+	if err != nil {
+		return fmt.Errorf("Cannot read BSON file '%s': %s.", path, err)
+	}
+
+	var fnErr error
+	err = ReadBsonStream(r, readBody, func(doc []byte) error {
+		fnErr = fn(doc)
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	} else if err != nil {
+		return fmt.Errorf("Cannot read BSON file '%s': %s.", path, err)
+	}
+
+	return nil
+}
+
+// ReadBsonStream reads concatenated BSON documents from reader, fn is called for each document.
+// If readBody is false, then only length of document is read and fn gets nil.
+// Error returned by fn stops reading.
+func ReadBsonStream(r io.Reader, readBody bool, fn func(doc []byte) error) error {
+	header := make([]byte, 4)
+	for {
+		_, err := io.ReadFull(r, header)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("unexpected end of file")
+		}
+
+		length := int(int32(binary.LittleEndian.Uint32(header)))
+		if length < 5 || length > MaxDocumentSize {
+			return fmt.Errorf("invalid document length %d", length)
+		}
+
+		if !readBody {
+			_, err = io.CopyN(io.Discard, r, int64(length-4))
+			if err != nil {
+				return fmt.Errorf("unexpected end of file")
+			}
+			if err := fn(nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		doc := make([]byte, length)
+		copy(doc, header)
+		_, err = io.ReadFull(r, doc[4:])
+		if err != nil {
+			return fmt.Errorf("unexpected end of file")
+		}
+
+		if doc[length-1] != '\x00' {
+			return fmt.Errorf("document is corrupted")
+		}
+
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+}
+
+// Create reader, gzip compression is detected by magic number.
+func newFileReader(f *os.File) (io.Reader, error) {
+	r := bufio.NewReader(f)
+
+	magic, err := r.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(r)
+	}
+
+	return r, nil
+}
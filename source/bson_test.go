@@ -0,0 +1,149 @@
+package source
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testOptions = &analysis.Options{
+	Location:    time.UTC,
+	Concurrency: 2,
+	BufferSize:  10,
+	BatchSize:   10,
+}
+
+func writeBsonFile(t *testing.T, path string, compress bool, docs ...interface{}) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var w interface {
+		Write([]byte) (int, error)
+	} = f
+
+	if compress {
+		gz := gzip.NewWriter(f)
+		defer gz.Close()
+		w = gz
+	}
+
+	for _, doc := range docs {
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(raw)
+	}
+}
+
+func readAll(ch chan []byte) []interface{} {
+	out := []interface{}{}
+	for raw := range ch {
+		m := bson.M{}
+		bson.Unmarshal(raw, &m)
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestBsonFiles(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	defer os.RemoveAll(dir)
+
+	plain := filepath.Join(dir, "col.bson")
+	compressed := filepath.Join(dir, "col2.bson.gz")
+	writeBsonFile(t, plain, false, bson.M{"a": 1}, bson.M{"a": 2})
+	writeBsonFile(t, compressed, true, bson.M{"b": "x"})
+
+	s, err := NewBsonFiles([]string{plain, compressed})
+	assert.Nil(t, err)
+
+	count, err := s.Count()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	ch := make(chan []byte, 10)
+	s.ToRawChannel(context.Background(), expr.NewPipeline(), ch, testOptions)
+
+	assert.Equal(t, []interface{}{
+		bson.M{"a": 1},
+		bson.M{"a": 2},
+		bson.M{"b": "x"},
+	}, readAll(ch))
+}
+
+func TestBsonFiles_CountWhileReading(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	defer os.RemoveAll(dir)
+
+	compressed := filepath.Join(dir, "col.bson.gz")
+	writeBsonFile(t, compressed, true, bson.M{"a": 1}, bson.M{"a": 2})
+
+	s, err := NewBsonFiles([]string{compressed})
+	assert.Nil(t, err)
+
+	ch := make(chan []byte, 10)
+	s.ToRawChannel(context.Background(), expr.NewPipeline(), ch, testOptions)
+	assert.Equal(t, 2, len(readAll(ch)))
+
+	// Documents were counted while reading, file is not read again
+	os.Remove(compressed)
+	count, err := s.Count()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestBsonFiles_MissingFile(t *testing.T) {
+	_, err := NewBsonFiles([]string{"/non/existing/file.bson"})
+	assert.Error(t, err)
+
+	_, err = NewBsonFiles([]string{})
+	assert.Error(t, err)
+}
+
+func TestBsonFiles_Truncated(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "col.bson")
+	raw, _ := bson.Marshal(bson.M{"a": 1})
+	ioutil.WriteFile(path, raw[:len(raw)-2], 0644)
+
+	s, err := NewBsonFiles([]string{path})
+	assert.Nil(t, err)
+
+	_, err = s.Count()
+	assert.Error(t, err)
+}
+
+func TestBsonFiles_PipelineNotSupported(t *testing.T) {
+	s := &BsonFiles{}
+
+	p := expr.NewPipeline()
+	p.AddStage("match", bson.M{"a": 1})
+
+	// Outside of analysis run the error is only logged and the output is closed
+	out := bytes.NewBuffer(nil)
+	log.SetOutput(out)
+	defer log.SetOutput(os.Stderr)
+
+	ch := make(chan []byte)
+	s.ToRawChannel(context.Background(), p, ch, testOptions)
+
+	_, ok := <-ch
+	assert.False(t, ok)
+	assert.Contains(t, out.String(), ErrPipelineNotSupported.Error())
+}
@@ -0,0 +1,68 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	driverbson "go.mongodb.org/mongo-driver/v2/bson"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Documents is in-memory source of documents.
+type Documents struct {
+	docs [][]byte
+}
+
+// NewDocuments - Documents factory.
+// Documents can be any values accepted by bson.Marshal, eg. bson.M, bson.D or structs.
+// Documents of the official driver (bson.D, bson.M, bson.Raw) are marshalled by the official driver.
+func NewDocuments(docs ...interface{}) (*Documents, error) {
+	s := &Documents{docs: make([][]byte, len(docs))}
+	for i, doc := range docs {
+		raw, err := marshalDocument(doc)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot marshal document %d: %s.", i, err)
+		}
+		s.docs[i] = raw
+	}
+
+	return s, nil
+}
+
+func marshalDocument(doc interface{}) ([]byte, error) {
+	switch d := doc.(type) {
+	case driverbson.Raw:
+		return d, d.Validate()
+	case driverbson.D, driverbson.M:
+		return driverbson.Marshal(d)
+	}
+	return bson.Marshal(doc)
+}
+
+// Count returns number of documents.
+func (s *Documents) Count() (int, error) {
+	return len(s.docs), nil
+}
+
+// Close - Documents does not hold any resources.
+func (s *Documents) Close() error {
+	return nil
+}
+
+// ToRawChannel sends documents to the output channel.
+func (s *Documents) ToRawChannel(ctx context.Context, pipeline *expr.Pipeline, outCh chan<- []byte, options *analysis.Options) {
+	if !checkEmptyPipeline(ctx, pipeline, outCh) {
+		return
+	}
+
+	go func() {
+		for _, doc := range s.docs {
+			if send(ctx, outCh, doc) != nil {
+				break
+			}
+		}
+
+		close(outCh)
+	}()
+}
@@ -0,0 +1,76 @@
+package source
+
+import (
+	"context"
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"github.com/stretchr/testify/assert"
+	driverbson "go.mongodb.org/mongo-driver/v2/bson"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+func TestDocuments(t *testing.T) {
+	s, err := NewDocuments(bson.M{"a": 1}, bson.D{{Name: "b", Value: "x"}})
+	assert.Nil(t, err)
+
+	count, err := s.Count()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	ch := make(chan []byte, 10)
+	s.ToRawChannel(context.Background(), expr.NewPipeline(), ch, testOptions)
+
+	assert.Equal(t, []interface{}{
+		bson.M{"a": 1},
+		bson.M{"b": "x"},
+	}, readAll(ch))
+
+	_, err = NewDocuments("not a document")
+	assert.Error(t, err)
+}
+
+func TestDocuments_Driver(t *testing.T) {
+	oid, _ := driverbson.ObjectIDFromHex("57605d5dc3d5a2429db0bd09")
+	raw, _ := driverbson.Marshal(driverbson.M{"c": 1.5})
+
+	s, err := NewDocuments(
+		driverbson.D{{Key: "_id", Value: oid}},
+		driverbson.M{"b": "x"},
+		driverbson.Raw(raw),
+	)
+	assert.Nil(t, err)
+
+	ch := make(chan []byte, 10)
+	s.ToRawChannel(context.Background(), expr.NewPipeline(), ch, testOptions)
+
+	assert.Equal(t, []interface{}{
+		bson.M{"_id": bson.ObjectIdHex("57605d5dc3d5a2429db0bd09")},
+		bson.M{"b": "x"},
+		bson.M{"c": 1.5},
+	}, readAll(ch))
+
+	_, err = NewDocuments(driverbson.Raw{1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestDocuments_Canceled(t *testing.T) {
+	docs := make([]interface{}, 100)
+	for i := range docs {
+		docs[i] = bson.M{"i": i}
+	}
+	s, _ := NewDocuments(docs...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := make(chan []byte)
+	s.ToRawChannel(ctx, expr.NewPipeline(), ch, testOptions)
+
+	<-ch
+	cancel()
+
+	count := 0
+	for range ch {
+		count++
+	}
+	assert.True(t, count < 99)
+}
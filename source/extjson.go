@@ -0,0 +1,388 @@
+package source
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/mgo.v2/bson"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExtJsonToBson converts one document in MongoDB Extended JSON (v2 canonical or relaxed, v1 is partially supported)
+// to BSON bytes. Numbers without wrapper are converted in the same way as mongoimport does:
+// integers to int32 or int64 (according to size) and other numbers to double.
+func ExtJsonToBson(data []byte) ([]byte, error) {
+	doc, err := ParseExtJson(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.Marshal(doc)
+}
+
+// ParseExtJson parses one document in MongoDB Extended JSON. Order of fields is preserved.
+func ParseExtJson(data []byte) (bson.D, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	value, err := parseExtJsonValue(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the end of document")
+	}
+
+	doc, ok := value.(bson.D)
+	if !ok {
+		return nil, fmt.Errorf("document must be a JSON object")
+	}
+
+	return doc, nil
+}
+
+func parseExtJsonValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err == io.EOF {
+		return nil, fmt.Errorf("unexpected end of JSON")
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			return parseExtJsonObject(dec)
+		case '[':
+			return parseExtJsonArray(dec)
+		}
+		return nil, fmt.Errorf("unexpected delimiter '%s'", t)
+	case json.Number:
+		return parseRelaxedNumber(t)
+	default:
+		// string, bool, nil
+		return t, nil
+	}
+}
+
+func parseExtJsonObject(dec *json.Decoder) (interface{}, error) {
+	doc := bson.D{}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid object key")
+		}
+
+		value, err := parseExtJsonValue(dec)
+		if err != nil {
+			return nil, err
+		}
+
+		doc = append(doc, bson.DocElem{Name: key, Value: value})
+	}
+
+	// Consume '}'
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	if len(doc) > 0 && strings.HasPrefix(doc[0].Name, "$") {
+		return convertExtJsonWrapper(doc)
+	}
+
+	return doc, nil
+}
+
+func parseExtJsonArray(dec *json.Decoder) (interface{}, error) {
+	arr := []interface{}{}
+	for dec.More() {
+		value, err := parseExtJsonValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, value)
+	}
+
+	// Consume ']'
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	return arr, nil
+}
+
+// Integers are converted to int32 or int64, other numbers to double.
+func parseRelaxedNumber(n json.Number) (interface{}, error) {
+	s := string(n)
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			if i >= math.MinInt32 && i <= math.MaxInt32 {
+				return int32(i), nil
+			}
+			return i, nil
+		}
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number '%s'", s)
+	}
+
+	return f, nil
+}
+
+// Convert type wrapper (eg. {"$oid": "..."}) to value.
+// Object with unknown keys is returned unchanged.
+func convertExtJsonWrapper(doc bson.D) (interface{}, error) {
+	keys := make([]string, len(doc))
+	values := make(map[string]interface{}, len(doc))
+	for i, elem := range doc {
+		keys[i] = elem.Name
+		values[elem.Name] = elem.Value
+	}
+
+	has := func(names ...string) bool {
+		if len(names) != len(keys) {
+			return false
+		}
+		for _, name := range names {
+			if _, ok := values[name]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case has("$oid"):
+		s, ok := values["$oid"].(string)
+		if !ok || !bson.IsObjectIdHex(s) {
+			return nil, fmt.Errorf("invalid $oid value")
+		}
+		return bson.ObjectIdHex(s), nil
+
+	case has("$date"):
+		return convertExtJsonDate(values["$date"])
+
+	case has("$numberInt"):
+		s, ok := values["$numberInt"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid $numberInt value")
+		}
+		i, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $numberInt value '%s'", s)
+		}
+		return int32(i), nil
+
+	case has("$numberLong"):
+		i, ok := extJsonInt64(values["$numberLong"])
+		if !ok {
+			return nil, fmt.Errorf("invalid $numberLong value")
+		}
+		return i, nil
+
+	case has("$numberDouble"):
+		s, ok := values["$numberDouble"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid $numberDouble value")
+		}
+		switch s {
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		case "NaN":
+			return math.NaN(), nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $numberDouble value '%s'", s)
+		}
+		return f, nil
+
+	case has("$numberDecimal"):
+		s, ok := values["$numberDecimal"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid $numberDecimal value")
+		}
+		d, err := bson.ParseDecimal128(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $numberDecimal value '%s'", s)
+		}
+		return d, nil
+
+	case has("$binary"):
+		// v2: {"$binary": {"base64": "...", "subType": "00"}}
+		d, ok := values["$binary"].(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("invalid $binary value")
+		}
+		m := d.Map()
+		data, _ := m["base64"].(string)
+		subType, _ := m["subType"].(string)
+		return convertExtJsonBinary(data, subType)
+
+	case has("$binary", "$type"):
+		// v1: {"$binary": "...", "$type": "00"}
+		data, _ := values["$binary"].(string)
+		subType, _ := values["$type"].(string)
+		return convertExtJsonBinary(data, subType)
+
+	case has("$uuid"):
+		s, _ := values["$uuid"].(string)
+		data, err := hex.DecodeString(strings.Replace(s, "-", "", -1))
+		if err != nil || len(data) != 16 {
+			return nil, fmt.Errorf("invalid $uuid value")
+		}
+		return bson.Binary{Kind: 0x04, Data: data}, nil
+
+	case has("$timestamp"):
+		d, ok := values["$timestamp"].(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("invalid $timestamp value")
+		}
+		m := d.Map()
+		t, okT := extJsonInt64(m["t"])
+		i, okI := extJsonInt64(m["i"])
+		if !okT || !okI {
+			return nil, fmt.Errorf("invalid $timestamp value")
+		}
+		return bson.MongoTimestamp(uint64(t)<<32 | uint64(uint32(i))), nil
+
+	case has("$regularExpression"):
+		d, ok := values["$regularExpression"].(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("invalid $regularExpression value")
+		}
+		m := d.Map()
+		pattern, okP := m["pattern"].(string)
+		options, okO := m["options"].(string)
+		if !okP || !okO {
+			return nil, fmt.Errorf("invalid $regularExpression value")
+		}
+		return bson.RegEx{Pattern: pattern, Options: options}, nil
+
+	case has("$regex", "$options"):
+		pattern, okP := values["$regex"].(string)
+		options, okO := values["$options"].(string)
+		if okP && okO {
+			return bson.RegEx{Pattern: pattern, Options: options}, nil
+		}
+
+	case has("$symbol"):
+		s, ok := values["$symbol"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid $symbol value")
+		}
+		return bson.Symbol(s), nil
+
+	case has("$code"):
+		s, ok := values["$code"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid $code value")
+		}
+		return bson.JavaScript{Code: s}, nil
+
+	case has("$code", "$scope"):
+		s, okC := values["$code"].(string)
+		scope, okS := values["$scope"].(bson.D)
+		if !okC || !okS {
+			return nil, fmt.Errorf("invalid $code value")
+		}
+		return bson.JavaScript{Code: s, Scope: scope}, nil
+
+	case has("$dbPointer"):
+		d, ok := values["$dbPointer"].(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("invalid $dbPointer value")
+		}
+		m := d.Map()
+		ns, okR := m["$ref"].(string)
+		id, okI := m["$id"].(bson.ObjectId)
+		if !okR || !okI {
+			return nil, fmt.Errorf("invalid $dbPointer value")
+		}
+		return bson.DBPointer{Namespace: ns, Id: id}, nil
+
+	case has("$minKey"):
+		return bson.MinKey, nil
+
+	case has("$maxKey"):
+		return bson.MaxKey, nil
+
+	case has("$undefined"):
+		return bson.Undefined, nil
+	}
+
+	return doc, nil
+}
+
+// Canonical: {"$date": {"$numberLong": "<millis>"}}, relaxed: {"$date": "<ISO-8601>"}.
+func convertExtJsonDate(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid $date value '%s'", s)
+	}
+
+	ms, ok := extJsonInt64(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid $date value")
+	}
+
+	return time.Unix(ms/1000, ms%1000*int64(time.Millisecond)).UTC(), nil
+}
+
+func convertExtJsonBinary(data string, subType string) (interface{}, error) {
+	bytes, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $binary value")
+	}
+
+	kind, err := strconv.ParseUint(strings.TrimPrefix(subType, "0x"), 16, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $binary subtype '%s'", subType)
+	}
+
+	if kind == 0 {
+		return bytes, nil
+	}
+
+	return bson.Binary{Kind: byte(kind), Data: bytes}, nil
+}
+
+// Get int64 from already converted value (relaxed number or string).
+func extJsonInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		if v == math.Trunc(v) {
+			return int64(v), true
+		}
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	}
+
+	return 0, false
+}
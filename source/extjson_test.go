@@ -0,0 +1,101 @@
+package source
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"math"
+	"testing"
+	"time"
+)
+
+func parseValue(t *testing.T, json string) interface{} {
+	doc, err := ParseExtJson([]byte(`{"v": ` + json + `}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc[0].Value
+}
+
+func decimal(s string) bson.Decimal128 {
+	d, _ := bson.ParseDecimal128(s)
+	return d
+}
+
+func TestParseExtJson_Relaxed(t *testing.T) {
+	assert.Equal(t, int32(15), parseValue(t, `15`))
+	assert.Equal(t, int32(-2147483648), parseValue(t, `-2147483648`))
+	assert.Equal(t, int64(2147483648), parseValue(t, `2147483648`))
+	assert.Equal(t, float64(1e20), parseValue(t, `100000000000000000000`))
+	assert.Equal(t, 1.5, parseValue(t, `1.5`))
+	assert.Equal(t, 10.0, parseValue(t, `1e1`))
+	assert.Equal(t, "abc", parseValue(t, `"abc"`))
+	assert.Equal(t, true, parseValue(t, `true`))
+	assert.Equal(t, nil, parseValue(t, `null`))
+	assert.Equal(t, []interface{}{int32(1), "a"}, parseValue(t, `[1, "a"]`))
+	assert.Equal(t, bson.D{{Name: "b", Value: int32(1)}, {Name: "a", Value: int32(2)}}, parseValue(t, `{"b": 1, "a": 2}`))
+	assert.Equal(t, time.Date(2017, 5, 1, 10, 0, 0, 123000000, time.UTC), parseValue(t, `{"$date": "2017-05-01T10:00:00.123Z"}`).(time.Time).UTC())
+	assert.Equal(t, time.Date(2017, 5, 1, 9, 0, 0, 0, time.UTC), parseValue(t, `{"$date": "2017-05-01T10:00:00+01:00"}`).(time.Time).UTC())
+}
+
+func TestParseExtJson_Canonical(t *testing.T) {
+	assert.Equal(t, bson.ObjectIdHex("58ed0817344c64f7fca5847a"), parseValue(t, `{"$oid": "58ed0817344c64f7fca5847a"}`))
+	assert.Equal(t, int32(5), parseValue(t, `{"$numberInt": "5"}`))
+	assert.Equal(t, int64(5), parseValue(t, `{"$numberLong": "5"}`))
+	assert.Equal(t, 5.0, parseValue(t, `{"$numberDouble": "5.0"}`))
+	assert.Equal(t, math.Inf(-1), parseValue(t, `{"$numberDouble": "-Infinity"}`))
+	assert.True(t, math.IsNaN(parseValue(t, `{"$numberDouble": "NaN"}`).(float64)))
+	assert.Equal(t, decimal("1.25"), parseValue(t, `{"$numberDecimal": "1.25"}`))
+	assert.Equal(t, time.Date(2017, 5, 1, 10, 0, 0, 0, time.UTC), parseValue(t, `{"$date": {"$numberLong": "1493632800000"}}`))
+	assert.Equal(t, time.Date(1969, 12, 31, 23, 59, 59, 500000000, time.UTC), parseValue(t, `{"$date": {"$numberLong": "-500"}}`))
+	assert.Equal(t, []byte("abc"), parseValue(t, `{"$binary": {"base64": "YWJj", "subType": "00"}}`))
+	assert.Equal(t, bson.Binary{Kind: 0x80, Data: []byte("abc")}, parseValue(t, `{"$binary": {"base64": "YWJj", "subType": "80"}}`))
+	assert.Equal(t, bson.Binary{Kind: 0x05, Data: []byte("abc")}, parseValue(t, `{"$binary": "YWJj", "$type": "05"}`))
+	assert.Equal(t, bson.Binary{Kind: 0x04, Data: make([]byte, 16)}, parseValue(t, `{"$uuid": "00000000-0000-0000-0000-000000000000"}`))
+	assert.Equal(t, bson.MongoTimestamp(1<<32|2), parseValue(t, `{"$timestamp": {"t": 1, "i": 2}}`))
+	assert.Equal(t, bson.RegEx{Pattern: "^a", Options: "i"}, parseValue(t, `{"$regularExpression": {"pattern": "^a", "options": "i"}}`))
+	assert.Equal(t, bson.RegEx{Pattern: "^a", Options: ""}, parseValue(t, `{"$regex": "^a", "$options": ""}`))
+	assert.Equal(t, bson.Symbol("s"), parseValue(t, `{"$symbol": "s"}`))
+	assert.Equal(t, bson.JavaScript{Code: "x"}, parseValue(t, `{"$code": "x"}`))
+	assert.Equal(t, bson.MinKey, parseValue(t, `{"$minKey": 1}`))
+	assert.Equal(t, bson.MaxKey, parseValue(t, `{"$maxKey": 1}`))
+	assert.Equal(t, bson.Undefined, parseValue(t, `{"$undefined": true}`))
+}
+
+func TestParseExtJson_UnknownWrapper(t *testing.T) {
+	assert.Equal(t, bson.D{{Name: "$x", Value: int32(1)}}, parseValue(t, `{"$x": 1}`))
+	assert.Equal(t, bson.D{{Name: "$oid", Value: "58ed0817344c64f7fca5847a"}, {Name: "a", Value: int32(1)}}, parseValue(t, `{"$oid": "58ed0817344c64f7fca5847a", "a": 1}`))
+}
+
+func TestParseExtJson_Errors(t *testing.T) {
+	invalid := []string{
+		``,
+		`[1, 2]`,
+		`{"a": 1} {"b": 2}`,
+		`{"a": }`,
+		`{"a": {"$oid": "xyz"}}`,
+		`{"a": {"$numberInt": "3000000000"}}`,
+		`{"a": {"$numberLong": "abc"}}`,
+		`{"a": {"$numberDecimal": "abc"}}`,
+		`{"a": {"$date": "yesterday"}}`,
+		`{"a": {"$binary": {"base64": "!!!", "subType": "00"}}}`,
+	}
+
+	for _, json := range invalid {
+		_, err := ParseExtJson([]byte(json))
+		assert.Error(t, err, json)
+	}
+}
+
+func TestExtJsonToBson(t *testing.T) {
+	line := `{"_id": {"$oid": "58ed0817344c64f7fca5847a"}, "n": 1, "l": {"$numberLong": "2"}, "d": {"$date": "2017-05-01T10:00:00Z"}}`
+	raw, err := ExtJsonToBson([]byte(line))
+	assert.Nil(t, err)
+
+	expected, _ := bson.Marshal(bson.D{
+		{Name: "_id", Value: bson.ObjectIdHex("58ed0817344c64f7fca5847a")},
+		{Name: "n", Value: int32(1)},
+		{Name: "l", Value: int64(2)},
+		{Name: "d", Value: time.Date(2017, 5, 1, 10, 0, 0, 0, time.UTC)},
+	})
+	assert.Equal(t, expected, raw)
+}
@@ -0,0 +1,196 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/decoder"
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// StdinPath is special path that represents standard input.
+const StdinPath = "-"
+
+// Standard input, it can be replaced in tests.
+var stdin io.Reader = os.Stdin
+
+// JsonFiles reads documents from NDJSON files (one document per line) in MongoDB Extended JSON,
+// eg. files created by mongoexport. Files compressed by gzip are detected automatically.
+type JsonFiles struct {
+	Paths   []string
+	tmpFile string
+	counter readCounter
+}
+
+// NewJsonFiles - JsonFiles factory.
+// Path "-" represents standard input, it is copied to temporary file, so documents can be counted before analysis.
+// It returns error if any of files cannot be opened.
+func NewJsonFiles(paths []string) (*JsonFiles, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("No JSON file specified.")
+	}
+
+	s := &JsonFiles{Paths: make([]string, len(paths))}
+	for i, path := range paths {
+		if path == StdinPath {
+			if s.tmpFile == "" {
+				tmp, err := spoolStdin()
+				if err != nil {
+					return nil, err
+				}
+				s.tmpFile = tmp
+			}
+			s.Paths[i] = s.tmpFile
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("Cannot open JSON file '%s'.", path)
+		}
+		f.Close()
+		s.Paths[i] = path
+	}
+
+	return s, nil
+}
+
+// Close removes temporary file created for standard input.
+func (s *JsonFiles) Close() error {
+	if s.tmpFile == "" {
+		return nil
+	}
+
+	err := os.Remove(s.tmpFile)
+	s.tmpFile = ""
+	return err
+}
+
+// Count returns number of documents (non-empty lines) in all files.
+// If the files were already read to the end by ToRawChannel, the count of read lines is returned.
+func (s *JsonFiles) Count() (count int, err error) {
+	if count, ok := s.counter.get(); ok {
+		return count, nil
+	}
+
+	for _, path := range s.Paths {
+		err = readJsonFile(path, func(line int, data []byte) error {
+			count++
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+// ToRawChannel reads documents from files, converts them to BSON and sends them to the output channel.
+// Files are read sequentially in given order. Invalid lines are reported as corrupted documents.
+func (s *JsonFiles) ToRawChannel(ctx context.Context, pipeline *expr.Pipeline, outCh chan<- []byte, options *analysis.Options) {
+	if !checkEmptyPipeline(ctx, pipeline, outCh) {
+		return
+	}
+
+	go func() {
+		count := 0
+		for i, path := range s.Paths {
+			err := readJsonFile(path, func(line int, data []byte) error {
+				count++
+				doc, err := ExtJsonToBson(data)
+				if err != nil {
+					// Invalid line is reported as corrupted document, so it can be skipped
+					analysis.Corrupted(ctx, &decoder.CorruptedError{Reason: fmt.Sprintf("line %d of JSON file '%s': %s", line, path, err)})
+					return ctx.Err()
+				}
+
+				return send(ctx, outCh, doc)
+			})
+			if err == context.Canceled || err == context.DeadlineExceeded {
+				break
+			} else if err != nil {
+				analysis.Fail(ctx, err)
+				break
+			}
+
+			// Lines are counted while reading, so compressed files are not read again by Count
+			if i == len(s.Paths)-1 {
+				s.counter.set(count)
+			}
+		}
+
+		close(outCh)
+	}()
+}
+
+// Read lines from JSON file, fn is called for each non-empty line.
+// Context errors returned by fn are returned unchanged.
+func readJsonFile(path string, fn func(line int, data []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Cannot open JSON file '%s'.", path)
+	}
+	defer f.Close()
+
+	r, err := newFileReader(f)
+	if err != nil {
+		return fmt.Errorf("Cannot read JSON file '%s': %s.", path, err)
+	}
+
+	err = ReadJsonStream(r, fn)
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("Cannot read JSON file '%s': %s.", path, err)
+	}
+
+	return nil
+}
+
+// ReadJsonStream reads NDJSON from reader, fn is called for each non-empty line.
+func ReadJsonStream(r io.Reader, fn func(line int, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxDocumentSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		if err := fn(line, data); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("line %d: %s", line+1, err)
+	}
+
+	return nil
+}
+
+// Copy standard input to temporary file.
+func spoolStdin() (string, error) {
+	f, err := ioutil.TempFile("", "mongoeye-stdin-")
+	if err != nil {
+		return "", fmt.Errorf("Cannot create temporary file for standard input: %s.", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, stdin); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("Cannot read standard input: %s.", err)
+	}
+
+	return f.Name(), nil
+}
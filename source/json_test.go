@@ -0,0 +1,118 @@
+package source
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeJsonFile(t *testing.T, path string, compress bool, lines ...string) {
+	data := []byte(strings.Join(lines, "\n"))
+
+	if compress {
+		buf := bytes.NewBuffer(nil)
+		gz := gzip.NewWriter(buf)
+		gz.Write(data)
+		gz.Close()
+		data = buf.Bytes()
+	}
+
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJsonFiles(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	defer os.RemoveAll(dir)
+
+	plain := filepath.Join(dir, "col.json")
+	compressed := filepath.Join(dir, "col2.json.gz")
+	writeJsonFile(t, plain, false, `{"a": 1}`, ``, `{"a": {"$numberLong": "2"}}`)
+	writeJsonFile(t, compressed, true, `{"b": "x"}`)
+
+	s, err := NewJsonFiles([]string{plain, compressed})
+	assert.Nil(t, err)
+	defer s.Close()
+
+	count, err := s.Count()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	ch := make(chan []byte, 10)
+	s.ToRawChannel(context.Background(), expr.NewPipeline(), ch, testOptions)
+
+	assert.Equal(t, []interface{}{
+		bson.M{"a": 1},
+		bson.M{"a": int64(2)},
+		bson.M{"b": "x"},
+	}, readAll(ch))
+}
+
+func TestJsonFiles_CountWhileReading(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "mongoeye")
+	defer os.RemoveAll(dir)
+
+	compressed := filepath.Join(dir, "col.json.gz")
+	writeJsonFile(t, compressed, true, `{"a": 1}`, ``, `{"a": 2}`)
+
+	s, err := NewJsonFiles([]string{compressed})
+	assert.Nil(t, err)
+	defer s.Close()
+
+	ch := make(chan []byte, 10)
+	s.ToRawChannel(context.Background(), expr.NewPipeline(), ch, testOptions)
+	assert.Equal(t, 2, len(readAll(ch)))
+
+	// Lines were counted while reading, file is not read again
+	os.Remove(compressed)
+	count, err := s.Count()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestJsonFiles_Stdin(t *testing.T) {
+	stdin = strings.NewReader("{\"a\": 1}\n{\"a\": 2}\n")
+	defer func() { stdin = os.Stdin }()
+
+	s, err := NewJsonFiles([]string{StdinPath})
+	assert.Nil(t, err)
+
+	count, err := s.Count()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	ch := make(chan []byte, 10)
+	s.ToRawChannel(context.Background(), expr.NewPipeline(), ch, testOptions)
+	assert.Equal(t, []interface{}{bson.M{"a": 1}, bson.M{"a": 2}}, readAll(ch))
+
+	tmpFile := s.Paths[0]
+	assert.Nil(t, s.Close())
+	_, err = os.Stat(tmpFile)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestJsonFiles_MissingFile(t *testing.T) {
+	_, err := NewJsonFiles([]string{"/non/existing/file.json"})
+	assert.Error(t, err)
+
+	_, err = NewJsonFiles([]string{})
+	assert.Error(t, err)
+}
+
+func TestReadJsonStream_InvalidDocument(t *testing.T) {
+	r := strings.NewReader("{\"a\": 1}\n{\"a\": \n")
+	err := ReadJsonStream(r, func(line int, data []byte) error {
+		_, err := ExtJsonToBson(data)
+		return err
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+// Package source contains sources of documents that can be analyzed without a running MongoDB server.
+package source
+
+import (
+	"context"
+	"errors"
+	"github.com/mongoeye/mongoeye/analysis"
+	"github.com/mongoeye/mongoeye/mongo/expr"
+	"sync"
+)
+
+// Files is a source of documents stored in files.
+type Files interface {
+	analysis.Source
+
+	// Count returns number of documents in files.
+	// If the files were already read to the end, the count is not read again.
+	Count() (int, error)
+
+	// Close releases resources held by source.
+	Close() error
+}
+
+// ErrPipelineNotSupported - offline sources cannot run aggregation pipeline.
+var ErrPipelineNotSupported = errors.New("Offline source cannot run stages in the database. Please, use only stages that run locally.")
+
+// Send document to the output channel, context error is returned if the context is done.
+func send(ctx context.Context, outCh chan<- []byte, doc []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case outCh <- doc:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Offline source can run only empty pipeline, otherwise the analysis fails and the output channel is closed.
+func checkEmptyPipeline(ctx context.Context, pipeline *expr.Pipeline, outCh chan<- []byte) bool {
+	if pipeline != nil && len(pipeline.GetStages()) != 0 {
+		analysis.Fail(ctx, ErrPipelineNotSupported)
+		close(outCh)
+		return false
+	}
+	return true
+}
+
+// Number of documents counted when all files were read to the end.
+type readCounter struct {
+	mutex sync.Mutex
+	count int
+	known bool
+}
+
+func (c *readCounter) get() (int, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.count, c.known
+}
+
+func (c *readCounter) set(count int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.count, c.known = count, true
+}
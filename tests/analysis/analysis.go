@@ -2,15 +2,17 @@
 package analysisTests
 
 import (
+	"context"
 	"github.com/mongoeye/mongoeye/analysis"
-	"gopkg.in/mgo.v2"
+	"github.com/mongoeye/mongoeye/tests"
 	"runtime"
 	"time"
 )
 
 // RunStages runs analysis for testing purposes.
 // It also allows only some stages to run.
-func RunStages(c *mgo.Collection, location *time.Location, stages []*analysis.Stage) interface{} {
+// Error is returned if stages cannot be linked together.
+func RunStages(c *tests.TestCollection, location *time.Location, stages []*analysis.Stage) (interface{}, error) {
 	options := analysis.Options{
 		Location:    location,
 		Concurrency: runtime.NumCPU(),
@@ -20,15 +22,20 @@ func RunStages(c *mgo.Collection, location *time.Location, stages []*analysis.St
 
 	runtime.GOMAXPROCS(options.Concurrency)
 
-	pipeline, in, out := analysis.LinkStages(stages, &options)
+	ctx := context.Background()
+	pipeline, in, out, err := analysis.LinkStages(ctx, stages, &options)
+	if err != nil {
+		return nil, err
+	}
 
 	pipeline.ToRawChannel(
-		c,
+		ctx,
+		tests.Collection(c),
 		in,
 		2,
 		options.BufferSize,
 		options.BatchSize,
 	)
 
-	return out
+	return out, nil
 }
@@ -16,6 +16,12 @@ func HasMongoDBDecimalSupport() bool {
 	return TestDbInfo.VersionAtLeast(3, 4)
 }
 
+// HasMongoDBRegexMatchSupport returns true if MongoDB support $regexMatch operator.
+func HasMongoDBRegexMatchSupport() bool {
+	// $regexMatch is new in version 4.2
+	return TestDbInfo.VersionAtLeast(4, 2)
+}
+
 // IsMongoDBVersionOld return true if MongoDB version don't support all features.
 func IsMongoDBVersionOld() bool {
 	return !TestDbInfo.VersionAtLeast(aggregationAlgorithmMinVersion...)
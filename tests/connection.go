@@ -1,24 +1,31 @@
 package tests
 
 import (
+	"errors"
 	"fmt"
 	"github.com/elgs/gostrgen"
-	"gopkg.in/mgo.v2"
+	"github.com/mongoeye/mongoeye/mongo/driver"
+	"github.com/mongoeye/mongoeye/mongo/driver/official"
+	"gopkg.in/mgo.v2/bson"
 	"os"
+	"strings"
 	"time"
 )
 
-// TestDbSession - connection to test database
-var TestDbSession *mgo.Session
+// Max number of documents in one insert command.
+const insertBatchSize = 1000
+
+// TestDbDriver - connection to test database, it is used to prepare test data and by tested code
+var TestDbDriver driver.Session
 
 // TestDbUri - URI of test database
 var TestDbUri = os.Getenv("TEST_MONGO_URI")
 
 // TestDbInfo - server info of test database
-var TestDbInfo mgo.BuildInfo
+var TestDbInfo driver.BuildInfo
 
-// BenchmarkDbSession - connection to benchmark database
-var BenchmarkDbSession *mgo.Session
+// BenchmarkDbDriver - connection to benchmark database, it is used by tested code
+var BenchmarkDbDriver driver.Session
 
 // BenchmarkDbUri - URI of benchmark database
 var BenchmarkDbUri = os.Getenv("BENCHMARK_MONGO_URI")
@@ -29,65 +36,124 @@ var BenchmarkDb = os.Getenv("BENCHMARK_DB")
 // BenchmarkCol - name of benchmark collection
 var BenchmarkCol = os.Getenv("BENCHMARK_COL")
 
+// TestDatabase - database of the test collection.
+type TestDatabase struct {
+	Name string
+}
+
+// TestCollection - collection with test data, documents are written by commands of the driver session.
+type TestCollection struct {
+	Database *TestDatabase
+	Name     string
+	FullName string
+	session  driver.Session
+}
+
 func init() {
-	TestDbSession = connect(TestDbUri)
-	BenchmarkDbSession = connect(BenchmarkDbUri)
+	TestDbDriver = dial(TestDbUri)
+	BenchmarkDbDriver = dial(BenchmarkDbUri)
 
 	var err error
-	TestDbInfo, err = TestDbSession.BuildInfo()
+	TestDbInfo, err = TestDbDriver.BuildInfo()
 	if err != nil {
 		panic(fmt.Errorf("Error: cannot get server info, msg: %s\n", err.Error()))
 	}
 }
 
-func connect(url string) *mgo.Session {
-	session, err := mgo.DialWithTimeout(url, time.Second)
+func dial(url string) driver.Session {
+	session, err := official.Dial(&driver.DialInfo{
+		URI:           url,
+		Mode:          driver.Strong,
+		Timeout:       time.Second,
+		SyncTimeout:   5 * time.Second,
+		SocketTimeout: 5 * time.Minute,
+	})
 	if err != nil {
 		panic(fmt.Errorf("Error: cannot connect to: %s, msg: %s\n", url, err.Error()))
 	}
 
-	session.SetSyncTimeout(5 * time.Second)
-	session.SetSocketTimeout(5 * time.Second)
-
-	session.SetMode(mgo.Strong, true)
-	session.SetSafe(&mgo.Safe{WMode: "majority"})
-
-	err = session.Ping()
-	if err != nil {
-		panic(fmt.Errorf("Error: cannot ping to: %s, msg: %s\n", url, err.Error()))
-	}
-
-	session.SetSyncTimeout(20 * time.Minute)
-	session.SetSocketTimeout(5 * time.Minute)
-
 	return session
 }
 
 // CreateTestCollection creates test collection with random name.
-func CreateTestCollection(s *mgo.Session) *mgo.Collection {
+func CreateTestCollection(s driver.Session) *TestCollection {
 	random, err := gostrgen.RandGen(20, gostrgen.Lower|gostrgen.Digit, "", "")
 	if err != nil {
 		panic(err)
 	}
-	return s.DB("_test").C("_test_" + random)
+	return newTestCollection(s, "_test", "_test_"+random)
+}
+
+func newTestCollection(s driver.Session, db string, name string) *TestCollection {
+	return &TestCollection{
+		Database: &TestDatabase{Name: db},
+		Name:     name,
+		FullName: db + "." + name,
+		session:  s,
+	}
+}
+
+// Insert documents to the collection, the write is acknowledged by the majority.
+func (c *TestCollection) Insert(docs ...interface{}) error {
+	for start := 0; start < len(docs); start += insertBatchSize {
+		end := start + insertBatchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+
+		result := struct {
+			WriteErrors []struct {
+				ErrMsg string `bson:"errmsg"`
+			} `bson:"writeErrors"`
+		}{}
+
+		err := c.session.Run(c.Database.Name, bson.D{
+			{Name: "insert", Value: c.Name},
+			{Name: "documents", Value: docs[start:end]},
+			{Name: "writeConcern", Value: bson.M{"w": "majority"}},
+		}, &result)
+		if err != nil {
+			return err
+		}
+
+		if len(result.WriteErrors) > 0 {
+			msgs := make([]string, len(result.WriteErrors))
+			for i, e := range result.WriteErrors {
+				msgs[i] = e.ErrMsg
+			}
+			return errors.New(strings.Join(msgs, "\n"))
+		}
+	}
+
+	return nil
+}
+
+// DropCollection drops the collection.
+func (c *TestCollection) DropCollection() error {
+	return c.session.Run(c.Database.Name, bson.D{{Name: "drop", Value: c.Name}}, nil)
 }
 
 // DropTestCollection drops test collection.
-func DropTestCollection(c *mgo.Collection) {
+func DropTestCollection(c *TestCollection) {
 	c.DropCollection()
 }
 
 // SetupTestCol creates test collection with random name.
-func SetupTestCol() *mgo.Collection {
-	return CreateTestCollection(TestDbSession)
+func SetupTestCol() *TestCollection {
+	return CreateTestCollection(TestDbDriver)
 }
 
 // TearDownTestCol drops test collection.
-func TearDownTestCol(c *mgo.Collection) {
+func TearDownTestCol(c *TestCollection) {
 	DropTestCollection(c)
 }
 
 // GetBenchmarkCol gets collection for benchmarks.
-func GetBenchmarkCol() *mgo.Collection {
-	return BenchmarkDbSession.DB(BenchmarkDb).C(BenchmarkCol)
+func GetBenchmarkCol() *TestCollection {
+	return newTestCollection(BenchmarkDbDriver, BenchmarkDb, BenchmarkCol)
+}
+
+// Collection returns the same collection for the tested code.
+func Collection(c *TestCollection) driver.Collection {
+	return c.session.Collection(c.Database.Name, c.Name)
 }